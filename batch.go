@@ -0,0 +1,244 @@
+package anymapper
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ElementError is an error that occurred while mapping a single element
+// of a batch.
+type ElementError struct {
+	// Key is the index of the element in the source slice or array, or its
+	// key in the source map.
+	Key any
+
+	// Err is the mapping error.
+	Err error
+}
+
+func (e *ElementError) Error() string {
+	return fmt.Sprintf("element %v: %s", e.Key, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ElementError) Unwrap() error {
+	return e.Err
+}
+
+// MapBatch maps a slice, array or map of elements to a slice, array or map
+// of elements, element by element.
+//
+// It is shorthand for Default.MapBatch(src, dst).
+func MapBatch(src, dst any) error {
+	return Default.MapBatch(src, dst)
+}
+
+// MapBatchContext maps a slice, array or map of elements to a slice, array
+// or map of elements, element by element, using the given context.
+//
+// It is shorthand for Default.MapBatchContext(ctx, src, dst).
+func MapBatchContext(ctx *Context, src, dst any) error {
+	return Default.MapBatchContext(ctx, src, dst)
+}
+
+// MapBatch maps a slice, array or map of elements to a slice, array or map
+// of elements, element by element.
+//
+// Unlike Map, MapBatch does not stop on the first failed element. Every
+// element that was mapped successfully is written to the destination, and
+// errors of the failed elements are returned as MappingErrors containing
+// an ElementError for each of them.
+//
+// A slice or array source must be mapped to a slice or array. The
+// destination slice is allocated to the length of the source, and failed
+// elements are set to their zero values, so the indexes of both slices
+// match. A map source must be mapped to a map. Existing entries of the
+// destination map are removed, and failed elements are not added to it.
+// Errors of map elements are sorted by their keys.
+func (m *Mapper) MapBatch(src, dst any) error {
+	return m.MapBatchContext(m.Context, src, dst)
+}
+
+// MapBatchContext maps a slice, array or map of elements to a slice, array
+// or map of elements, element by element, using the given context. See
+// MapBatch for details.
+func (m *Mapper) MapBatchContext(ctx *Context, src, dst any) error {
+	if ctx == nil {
+		ctx = m.Context
+	}
+	srcVal := m.srcValue(ctx, reflect.ValueOf(src))
+	dstVal := m.dstValue(ctx, reflect.ValueOf(dst))
+	if !srcVal.IsValid() {
+		return InvalidSrcErr
+	}
+	if !dstVal.IsValid() {
+		return InvalidDstErr
+	}
+	ctx = ctx.withState(srcVal, dstVal)
+	if err := checkLimits(ctx, srcVal); err != nil {
+		return err
+	}
+	switch {
+	case isListKind(srcVal.Kind()) && isListKind(dstVal.Kind()):
+		return m.mapBatchList(ctx, srcVal, dstVal)
+	case srcVal.Kind() == reflect.Map && dstVal.Kind() == reflect.Map:
+		return m.mapBatchMap(ctx, srcVal, dstVal)
+	}
+	return NewInvalidMappingError(srcVal.Type(), dstVal.Type(), "batch requires a slice, array or map on both sides")
+}
+
+func (m *Mapper) mapBatchList(ctx *Context, src, dst reflect.Value) error {
+	n := src.Len()
+	if dst.Kind() == reflect.Slice {
+		if !dst.CanSet() {
+			return InvalidDstErr
+		}
+		dst.Set(reflect.MakeSlice(dst.Type(), n, n))
+	} else if dst.Len() != n {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "length mismatch")
+	}
+	var errs []error
+	for i := 0; i < n; i++ {
+		elem := dst.Index(i)
+		if err := m.MapReflContext(ctx, src.Index(i), elem); err != nil {
+			elem.Set(reflect.Zero(elem.Type()))
+			errs = append(errs, &ElementError{Key: i, Err: err})
+		}
+	}
+	return joinErrors(errs)
+}
+
+func (m *Mapper) mapBatchMap(ctx *Context, src, dst reflect.Value) error {
+	if dst.IsNil() {
+		if !dst.CanSet() {
+			return InvalidDstErr
+		}
+		dst.Set(reflect.MakeMapWithSize(dst.Type(), src.Len()))
+	}
+	for _, key := range dst.MapKeys() {
+		dst.SetMapIndex(key, reflect.Value{})
+	}
+	var errs []error
+	keyTy, elemTy := dst.Type().Key(), dst.Type().Elem()
+	keys := src.MapKeys()
+	sortKeys(keys)
+	for _, srcKey := range keys {
+		key := reflect.New(keyTy).Elem()
+		if err := m.MapReflContext(ctx, srcKey, key); err != nil {
+			errs = append(errs, &ElementError{Key: srcKey.Interface(), Err: err})
+			continue
+		}
+		elem := reflect.New(elemTy).Elem()
+		if err := m.MapReflContext(ctx, src.MapIndex(srcKey), elem); err != nil {
+			errs = append(errs, &ElementError{Key: srcKey.Interface(), Err: err})
+			continue
+		}
+		dst.SetMapIndex(key, elem)
+	}
+	return joinErrors(errs)
+}
+
+func isListKind(k reflect.Kind) bool {
+	return k == reflect.Slice || k == reflect.Array
+}
+
+// MapEach maps every element of a slice, array, map or sequence to the
+// destination value and calls fn after each element.
+//
+// It is shorthand for Default.MapEach(src, dst, fn).
+func MapEach(src, dst any, fn func(dst any) error) error {
+	return Default.MapEach(src, dst, fn)
+}
+
+// MapEachContext maps every element of a slice, array, map or sequence to
+// the destination value using the given context and calls fn after each
+// element.
+//
+// It is shorthand for Default.MapEachContext(ctx, src, dst, fn).
+func MapEachContext(ctx *Context, src, dst any, fn func(dst any) error) error {
+	return Default.MapEachContext(ctx, src, dst, fn)
+}
+
+// MapEach maps every element of a slice, array, map or sequence, such as
+// iter.Seq, to the destination value and calls fn after each element, so
+// large collections can be processed without allocating the whole mapped
+// collection.
+//
+// The destination must be a pointer. It is reused for all elements and is
+// reset to its zero value before every element, so fn must not retain it.
+// Values of maps are mapped in an unspecified order.
+//
+// If mapping of an element fails, MapEach stops and returns an
+// ElementError. If fn returns an error, MapEach stops and returns it as is.
+func (m *Mapper) MapEach(src, dst any, fn func(dst any) error) error {
+	return m.MapEachContext(m.Context, src, dst, fn)
+}
+
+// MapEachContext maps every element of a slice, array, map or sequence to
+// the destination value using the given context and calls fn after each
+// element. See MapEach for details.
+func (m *Mapper) MapEachContext(ctx *Context, src, dst any, fn func(dst any) error) error {
+	if ctx == nil {
+		ctx = m.Context
+	}
+	srcVal := m.srcValue(ctx, reflect.ValueOf(src))
+	dstPtr := reflect.ValueOf(dst)
+	if !srcVal.IsValid() {
+		return InvalidSrcErr
+	}
+	if dstPtr.Kind() != reflect.Pointer || dstPtr.IsNil() {
+		return InvalidDstErr
+	}
+	ctx = ctx.withState(srcVal, dstPtr)
+	dstVal := dstPtr.Elem()
+	zero := reflect.Zero(dstVal.Type())
+	each := func(key any, elem reflect.Value) error {
+		dstVal.Set(zero)
+		if err := m.MapReflContext(ctx, elem, dstPtr); err != nil {
+			return &ElementError{Key: key, Err: err}
+		}
+		return fn(dst)
+	}
+	switch {
+	case isListKind(srcVal.Kind()):
+		if err := checkLimits(ctx, srcVal); err != nil {
+			return err
+		}
+		for i := 0; i < srcVal.Len(); i++ {
+			if err := each(i, srcVal.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case srcVal.Kind() == reflect.Map:
+		if err := checkLimits(ctx, srcVal); err != nil {
+			return err
+		}
+		for _, key := range ctx.mapKeys(srcVal) {
+			if err := each(key.Interface(), srcVal.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case isSeq(srcVal.Type()):
+		if srcVal.IsNil() {
+			return nil
+		}
+		var (
+			i   int
+			err error
+		)
+		yield := reflect.MakeFunc(srcVal.Type().In(0), func(args []reflect.Value) []reflect.Value {
+			if err == nil {
+				if err = checkCount(ctx, srcVal.Type(), false, i+1, 1); err == nil {
+					err = each(i, args[0])
+				}
+				i++
+			}
+			return []reflect.Value{reflect.ValueOf(err == nil)}
+		})
+		srcVal.Call([]reflect.Value{yield})
+		return err
+	}
+	return NewInvalidMappingError(srcVal.Type(), dstVal.Type(), "source must be a slice, array, map or sequence")
+}
@@ -0,0 +1,144 @@
+package anymapper
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapBatch(t *testing.T) {
+	type record struct {
+		ID   int
+		Name string
+	}
+	t.Run("slice", func(t *testing.T) {
+		src := []map[string]any{
+			{"ID": "1", "Name": "foo"},
+			{"ID": "x", "Name": "bar"},
+			{"ID": "3", "Name": "baz"},
+		}
+		var dst []record
+		err := MapBatch(src, &dst)
+		require.Error(t, err)
+		var errs MappingErrors
+		require.True(t, errors.As(err, &errs))
+		require.Len(t, errs, 1)
+		var elemErr *ElementError
+		require.True(t, errors.As(errs[0], &elemErr))
+		assert.Equal(t, 1, elemErr.Key)
+		assert.Equal(t, []record{{ID: 1, Name: "foo"}, {}, {ID: 3, Name: "baz"}}, dst)
+	})
+	t.Run("array", func(t *testing.T) {
+		var dst [2]int
+		require.NoError(t, MapBatch([]string{"1", "2"}, &dst))
+		assert.Equal(t, [2]int{1, 2}, dst)
+		assert.Error(t, MapBatch([]string{"1"}, &dst))
+	})
+	t.Run("map", func(t *testing.T) {
+		src := map[string]string{"a": "1", "b": "x"}
+		var dst map[string]int
+		err := MapBatch(src, &dst)
+		require.Error(t, err)
+		var elemErr *ElementError
+		require.True(t, errors.As(err, &elemErr))
+		assert.Equal(t, "b", elemErr.Key)
+		assert.Equal(t, map[string]int{"a": 1}, dst)
+	})
+	t.Run("map-order", func(t *testing.T) {
+		src := map[string]string{"d": "x", "a": "1", "c": "y", "b": "z"}
+		dst := map[string]int{"a": 5, "old": 1}
+		err := MapBatch(src, &dst)
+		var errs MappingErrors
+		require.True(t, errors.As(err, &errs))
+		var keys []any
+		for _, err := range errs {
+			keys = append(keys, err.(*ElementError).Key)
+		}
+		assert.Equal(t, []any{"b", "c", "d"}, keys)
+		assert.Equal(t, map[string]int{"a": 1}, dst)
+	})
+	t.Run("map-key", func(t *testing.T) {
+		src := map[string]string{"1": "a", "x": "b"}
+		var dst map[int]string
+		err := MapBatch(src, &dst)
+		require.Error(t, err)
+		assert.Equal(t, map[int]string{1: "a"}, dst)
+	})
+	t.Run("no-errors", func(t *testing.T) {
+		var dst []int
+		require.NoError(t, MapBatch([]string{"1", "2"}, &dst))
+		assert.Equal(t, []int{1, 2}, dst)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		var dst []int
+		assert.Error(t, MapBatch(map[string]int{"a": 1}, &dst))
+	})
+}
+
+func TestMapEach(t *testing.T) {
+	type row struct {
+		ID   int
+		Name string
+	}
+	t.Run("slice", func(t *testing.T) {
+		src := []map[string]any{
+			{"ID": "1", "Name": "foo"},
+			{"ID": "2"},
+		}
+		var rows []row
+		var dst row
+		require.NoError(t, MapEach(src, &dst, func(dst any) error {
+			rows = append(rows, *dst.(*row))
+			return nil
+		}))
+		// The destination is reset before every element.
+		assert.Equal(t, []row{{ID: 1, Name: "foo"}, {ID: 2}}, rows)
+	})
+	t.Run("map", func(t *testing.T) {
+		var sum int
+		var dst int
+		require.NoError(t, MapEach(map[string]string{"a": "1", "b": "2"}, &dst, func(dst any) error {
+			sum += *dst.(*int)
+			return nil
+		}))
+		assert.Equal(t, 3, sum)
+	})
+	t.Run("seq", func(t *testing.T) {
+		var out []string
+		var dst string
+		require.NoError(t, MapEach(seqOf(1, 2), &dst, func(dst any) error {
+			out = append(out, *dst.(*string))
+			return nil
+		}))
+		assert.Equal(t, []string{"1", "2"}, out)
+	})
+	t.Run("element-error", func(t *testing.T) {
+		var calls int
+		var dst int
+		err := MapEach([]string{"1", "x", "3"}, &dst, func(any) error {
+			calls++
+			return nil
+		})
+		var elemErr *ElementError
+		require.True(t, errors.As(err, &elemErr))
+		assert.Equal(t, 1, elemErr.Key)
+		assert.Equal(t, 1, calls)
+	})
+	t.Run("callback-error", func(t *testing.T) {
+		stop := errors.New("stop")
+		var calls int
+		var dst int
+		err := MapEach(seqOf(1, 2, 3), &dst, func(any) error {
+			calls++
+			return stop
+		})
+		assert.Equal(t, stop, err)
+		assert.Equal(t, 1, calls)
+	})
+	t.Run("invalid-dst", func(t *testing.T) {
+		var dst int
+		assert.Equal(t, InvalidDstErr, MapEach([]int{1}, dst, func(any) error { return nil }))
+	})
+}
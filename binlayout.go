@@ -0,0 +1,469 @@
+package anymapper
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// BinaryTag is the name of the struct tag that describes the binary layout
+// of a struct. Structs that have at least one field with this tag are
+// mapped to and from byte slices and byte arrays as packed binary records,
+// e.g. protocol headers.
+//
+// The tag has the following format: `bin:"option1,option2"`. If the tag is
+// "-", the field is skipped. Fields are placed one after another in the
+// order of declaration, unless an offset is given. Fields must not
+// overlap. Supported options:
+//
+//   - offset=N - the field starts at byte N.
+//   - bitoffset=N - the field starts at bit N. Bits are numbered from the
+//     most significant bit of the first byte.
+//   - size=N - the field is N bytes long.
+//   - bits=N - the field is N bits long.
+//   - order=big|little - the byte order of the field, overrides
+//     Context.ByteOrder.
+//
+// Fields may be bools, integers, floats, byte arrays, byte slices, strings
+// and nested structs. By default, fields have the size of their types, int
+// and uint are 64 bits long and bools are 8 bits long. Byte slices and
+// strings must have a size, unless they are the last field, in which case
+// they hold the rest of the data. Strings are padded with zero bytes, which
+// are removed when strings are read.
+//
+// Bools and integers may have any size up to the size of their types. If a
+// field is not byte-aligned or its size is not a multiple of 8 bits, it is
+// read as a big-endian bit string, regardless of the byte order. Signed
+// integers are sign-extended. Other fields must be byte-aligned.
+const BinaryTag = "bin"
+
+// binLayout is the parsed binary layout of a struct.
+type binLayout struct {
+	fields []binField
+	size   int  // size in bytes, without the rest field
+	rest   bool // the last field holds the rest of the data
+}
+
+// binField is a field of a binary layout.
+type binField struct {
+	index  int
+	name   string
+	bitOff int
+	bits   int              // -1 for the rest field
+	order  binary.ByteOrder // nil if Context.ByteOrder is used
+	layout *binLayout       // layout of a nested struct
+}
+
+type binLayoutResult struct {
+	layout *binLayout
+	err    error
+}
+
+// binLayouts caches parsed layouts. Layouts depend only on types, so they
+// are shared by all mappers.
+var binLayouts sync.Map // map[reflect.Type]binLayoutResult
+
+// hasBinaryLayout reports whether t is a struct with at least one field
+// tagged with BinaryTag.
+func hasBinaryLayout(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup(BinaryTag); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isByteList reports whether t is a byte slice or a byte array.
+func isByteList(t reflect.Type) bool {
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() == reflect.Uint8
+}
+
+// binaryLayoutFunc returns the MapFunc for mappings between structs with
+// a binary layout and byte slices or arrays. It returns nil for other
+// types.
+func binaryLayoutFunc(src, dst reflect.Type) MapFunc {
+	switch {
+	case isByteList(src) && hasBinaryLayout(dst):
+		return mapBytesToBinaryLayout
+	case hasBinaryLayout(src) && isByteList(dst):
+		return mapBinaryLayoutToBytes
+	}
+	return nil
+}
+
+// binaryLayoutOf returns the binary layout of a struct type.
+func binaryLayoutOf(t reflect.Type) (*binLayout, error) {
+	if v, ok := binLayouts.Load(t); ok {
+		r := v.(binLayoutResult)
+		return r.layout, r.err
+	}
+	l, err := parseBinaryLayout(t)
+	binLayouts.Store(t, binLayoutResult{layout: l, err: err})
+	return l, err
+}
+
+func parseBinaryLayout(t reflect.Type) (*binLayout, error) {
+	l := &binLayout{}
+	pos := 0
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get(BinaryTag)
+		if !sf.IsExported() || tag == "-" {
+			continue
+		}
+		if l.rest {
+			return nil, fmt.Errorf("field %s follows a field without size", sf.Name)
+		}
+		f := binField{index: i, name: sf.Name, bitOff: pos, bits: -1}
+		if err := f.parseTag(tag); err != nil {
+			return nil, fmt.Errorf("field %s: %w", sf.Name, err)
+		}
+		if err := f.check(sf.Type); err != nil {
+			return nil, fmt.Errorf("field %s: %w", sf.Name, err)
+		}
+		for _, g := range l.fields {
+			if f.overlaps(&g) {
+				return nil, fmt.Errorf("field %s overlaps field %s", sf.Name, g.name)
+			}
+		}
+		if f.bits < 0 {
+			l.rest = true
+			pos = f.bitOff
+		} else {
+			pos = f.bitOff + f.bits
+		}
+		if size := (pos + 7) / 8; size > l.size {
+			l.size = size
+		}
+		l.fields = append(l.fields, f)
+	}
+	return l, nil
+}
+
+// overlaps reports whether the bits of the fields overlap. The rest field
+// extends to the end of the data.
+func (f *binField) overlaps(g *binField) bool {
+	fEnd, gEnd := f.bitOff+f.bits, g.bitOff+g.bits
+	if f.bits < 0 {
+		fEnd = math.MaxInt
+	}
+	if g.bits < 0 {
+		gEnd = math.MaxInt
+	}
+	return f.bitOff < gEnd && g.bitOff < fEnd
+}
+
+// parseTag parses the options of the field tag.
+func (f *binField) parseTag(tag string) error {
+	for _, opt := range strings.Split(tag, ",") {
+		if opt == "" {
+			continue
+		}
+		key, val, _ := strings.Cut(opt, "=")
+		switch key {
+		case "offset", "bitoffset", "size", "bits":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid %s option: %q", key, val)
+			}
+			switch key {
+			case "offset":
+				f.bitOff = n * 8
+			case "bitoffset":
+				f.bitOff = n
+			case "size":
+				f.bits = n * 8
+			case "bits":
+				f.bits = n
+			}
+		case "order":
+			switch val {
+			case "big":
+				f.order = binary.BigEndian
+			case "little":
+				f.order = binary.LittleEndian
+			default:
+				return fmt.Errorf("invalid order option: %q", val)
+			}
+		default:
+			return fmt.Errorf("unknown option: %q", key)
+		}
+	}
+	return nil
+}
+
+// check sets the default size of the field and verifies that the field
+// type can be stored in it.
+func (f *binField) check(t reflect.Type) error {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		max := 8
+		if t.Kind() != reflect.Bool {
+			max = bitSize(t)
+		}
+		if f.bits < 0 {
+			f.bits = max
+		}
+		if f.bits == 0 || f.bits > max {
+			return fmt.Errorf("invalid size of %v: %d bits", t, f.bits)
+		}
+		return nil
+	case reflect.Float32, reflect.Float64:
+		if f.bits < 0 {
+			f.bits = t.Bits()
+		}
+		if f.bits != t.Bits() {
+			return fmt.Errorf("invalid size of %v: %d bits", t, f.bits)
+		}
+	case reflect.Array:
+		if t.Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("unsupported type: %v", t)
+		}
+		if f.bits < 0 {
+			f.bits = t.Len() * 8
+		}
+		if f.bits != t.Len()*8 {
+			return fmt.Errorf("invalid size of %v: %d bits", t, f.bits)
+		}
+	case reflect.Slice, reflect.String:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("unsupported type: %v", t)
+		}
+	case reflect.Struct:
+		l, err := binaryLayoutOf(t)
+		if err != nil {
+			return err
+		}
+		if l.rest {
+			return fmt.Errorf("nested struct %v must have a fixed size", t)
+		}
+		if f.bits < 0 {
+			f.bits = l.size * 8
+		}
+		if f.bits != l.size*8 {
+			return fmt.Errorf("invalid size of %v: %d bits", t, f.bits)
+		}
+		f.layout = l
+	default:
+		return fmt.Errorf("unsupported type: %v", t)
+	}
+	if f.bitOff%8 != 0 || (f.bits > 0 && f.bits%8 != 0) {
+		return fmt.Errorf("%v must be byte-aligned", t)
+	}
+	return nil
+}
+
+func mapBytesToBinaryLayout(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	l, err := binaryLayoutOf(dst.Type())
+	if err != nil {
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	b := byteListBytes(src)
+	if len(b) < l.size {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "data too short")
+	}
+	l.read(ctx, b, dst)
+	return nil
+}
+
+func mapBinaryLayoutToBytes(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	l, err := binaryLayoutOf(src.Type())
+	if err != nil {
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	size := l.size
+	if l.rest {
+		size += src.Field(l.fields[len(l.fields)-1].index).Len()
+	}
+	b := make([]byte, size)
+	if err := l.write(ctx, b, src); err != nil {
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	if dst.Kind() == reflect.Array {
+		if dst.Len() < len(b) {
+			return NewInvalidMappingError(src.Type(), dst.Type(), "invalid array length")
+		}
+		reflect.Copy(dst, reflect.ValueOf(b))
+		for i := len(b); i < dst.Len(); i++ {
+			dst.Index(i).SetUint(0)
+		}
+		return nil
+	}
+	dst.SetBytes(b)
+	return nil
+}
+
+// byteListBytes returns the bytes of a byte slice or array.
+func byteListBytes(v reflect.Value) []byte {
+	if v.Kind() == reflect.Slice {
+		return v.Bytes()
+	}
+	b := make([]byte, v.Len())
+	reflect.Copy(reflect.ValueOf(b), v)
+	return b
+}
+
+// read sets the fields of the struct v from b. The length of b must be at
+// least l.size.
+func (l *binLayout) read(ctx *Context, b []byte, v reflect.Value) {
+	for _, f := range l.fields {
+		fv := v.Field(f.index)
+		off := f.bitOff / 8
+		var data []byte
+		if f.bits < 0 {
+			data = b[off:]
+		} else if f.bitOff%8 == 0 && f.bits%8 == 0 {
+			data = b[off : off+f.bits/8]
+		}
+		switch fv.Kind() {
+		case reflect.Slice:
+			fv.SetBytes(append([]byte{}, data...))
+		case reflect.String:
+			fv.SetString(string(bytes.TrimRight(data, "\x00")))
+		case reflect.Array:
+			reflect.Copy(fv, reflect.ValueOf(data))
+		case reflect.Struct:
+			f.layout.read(ctx, data, fv)
+		case reflect.Float32:
+			fv.SetFloat(float64(math.Float32frombits(uint32(f.readUint(ctx, b)))))
+		case reflect.Float64:
+			fv.SetFloat(math.Float64frombits(f.readUint(ctx, b)))
+		case reflect.Bool:
+			fv.SetBool(f.readUint(ctx, b) != 0)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			u := f.readUint(ctx, b)
+			if f.bits < 64 && u>>uint(f.bits-1)&1 == 1 {
+				// Sign-extend the two's complement value.
+				u |= ^uint64(0) << uint(f.bits)
+			}
+			fv.SetInt(int64(u))
+		default:
+			fv.SetUint(f.readUint(ctx, b))
+		}
+	}
+}
+
+// write writes the fields of the struct v to b. The length of b must be at
+// least l.size.
+func (l *binLayout) write(ctx *Context, b []byte, v reflect.Value) error {
+	for _, f := range l.fields {
+		fv := v.Field(f.index)
+		off := f.bitOff / 8
+		switch fv.Kind() {
+		case reflect.Slice, reflect.String, reflect.Array:
+			n := fv.Len()
+			if f.bits >= 0 && n > f.bits/8 {
+				return fmt.Errorf("field %s: value longer than %d bytes", f.name, f.bits/8)
+			}
+			data := b[off : off+n]
+			if fv.Kind() == reflect.String {
+				copy(data, fv.String())
+			} else {
+				reflect.Copy(reflect.ValueOf(data), fv)
+			}
+		case reflect.Struct:
+			if err := f.layout.write(ctx, b[off:off+f.bits/8], fv); err != nil {
+				return fmt.Errorf("field %s: %w", f.name, err)
+			}
+		case reflect.Float32:
+			f.writeUint(ctx, b, uint64(math.Float32bits(float32(fv.Float()))))
+		case reflect.Float64:
+			f.writeUint(ctx, b, math.Float64bits(fv.Float()))
+		case reflect.Bool:
+			var u uint64
+			if fv.Bool() {
+				u = 1
+			}
+			f.writeUint(ctx, b, u)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i := fv.Int()
+			if f.bits < 64 && (i < -1<<uint(f.bits-1) || i > 1<<uint(f.bits-1)-1) {
+				return fmt.Errorf("field %s: value does not fit in %d bits", f.name, f.bits)
+			}
+			f.writeUint(ctx, b, uint64(i))
+		default:
+			u := fv.Uint()
+			if f.bits < 64 && u>>uint(f.bits) != 0 {
+				return fmt.Errorf("field %s: value does not fit in %d bits", f.name, f.bits)
+			}
+			f.writeUint(ctx, b, u)
+		}
+	}
+	return nil
+}
+
+// isLittleEndian reports whether the field uses the little-endian byte
+// order.
+func (f *binField) isLittleEndian(ctx *Context) bool {
+	order := f.order
+	if order == nil {
+		order = ctx.ByteOrder
+	}
+	if order == nil {
+		return false
+	}
+	var buf [2]byte
+	order.PutUint16(buf[:], 1)
+	return buf[0] == 1
+}
+
+// readUint reads the field from b as an unsigned integer.
+func (f *binField) readUint(ctx *Context, b []byte) (u uint64) {
+	if f.bitOff%8 != 0 || f.bits%8 != 0 {
+		for i := f.bitOff; i < f.bitOff+f.bits; i++ {
+			u = u<<1 | uint64(b[i/8]>>uint(7-i%8)&1)
+		}
+		return u
+	}
+	data := b[f.bitOff/8 : (f.bitOff+f.bits)/8]
+	little := f.isLittleEndian(ctx)
+	for i := range data {
+		if little {
+			u |= uint64(data[i]) << uint(8*i)
+		} else {
+			u = u<<8 | uint64(data[i])
+		}
+	}
+	return u
+}
+
+// writeUint writes the lowest f.bits bits of u to the field in b.
+func (f *binField) writeUint(ctx *Context, b []byte, u uint64) {
+	if f.bitOff%8 != 0 || f.bits%8 != 0 {
+		for i := f.bitOff + f.bits - 1; i >= f.bitOff; i-- {
+			mask := byte(1) << uint(7-i%8)
+			if u&1 == 1 {
+				b[i/8] |= mask
+			} else {
+				b[i/8] &^= mask
+			}
+			u >>= 1
+		}
+		return
+	}
+	data := b[f.bitOff/8 : (f.bitOff+f.bits)/8]
+	little := f.isLittleEndian(ctx)
+	for i := range data {
+		if little {
+			data[i] = byte(u >> uint(8*i))
+		} else {
+			data[len(data)-1-i] = byte(u >> uint(8*i))
+		}
+	}
+}
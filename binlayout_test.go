@@ -0,0 +1,161 @@
+package anymapper
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBinaryLayout(t *testing.T) {
+	type flags struct {
+		A bool  `bin:"bits=1"`
+		B bool  `bin:"bits=1"`
+		C uint8 `bin:"bits=6"`
+	}
+	type header struct {
+		Version  uint8   `bin:"bits=4"`
+		Len      uint8   `bin:"bits=4"`
+		Total    uint16  `bin:""`
+		Offset   int16   `bin:"bits=13,bitoffset=35"`
+		Count    uint32  `bin:"offset=6,size=3,order=little"`
+		Flags    flags   `bin:""`
+		Tag      [2]byte `bin:""`
+		Name     string  `bin:"size=4"`
+		Ignored  int     `bin:"-"`
+		internal int
+		Payload  []byte `bin:""`
+	}
+	data := []byte{
+		0x45,       // version, len
+		0x01, 0x02, // total
+		0x00, 0x1f, 0xff, // offset (bits 35-47)
+		0x03, 0x02, 0x01, // count
+		0xbf,       // flags
+		0xaa, 0xbb, // tag
+		'a', 'b', 0, 0, // name
+		1, 2, 3, // payload
+	}
+	want := header{
+		Version: 4,
+		Len:     5,
+		Total:   0x0102,
+		Offset:  -1,
+		Count:   0x010203,
+		Flags:   flags{A: true, B: false, C: 0x3f},
+		Tag:     [2]byte{0xaa, 0xbb},
+		Name:    "ab",
+		Payload: []byte{1, 2, 3},
+	}
+
+	t.Run("bytes-to-struct", func(t *testing.T) {
+		var h header
+		require.NoError(t, Map(data, &h))
+		assert.Equal(t, want, h)
+	})
+	t.Run("struct-to-bytes", func(t *testing.T) {
+		var b []byte
+		require.NoError(t, Map(want, &b))
+		assert.Equal(t, data, b)
+	})
+	t.Run("array", func(t *testing.T) {
+		type pair struct {
+			A uint16 `bin:""`
+			B int8   `bin:""`
+		}
+		var arr [4]byte
+		require.NoError(t, Map(pair{A: 0x0102, B: -2}, &arr))
+		assert.Equal(t, [4]byte{1, 2, 0xfe, 0}, arr)
+		var p pair
+		require.NoError(t, Map(arr, &p))
+		assert.Equal(t, pair{A: 0x0102, B: -2}, p)
+		var small [2]byte
+		assert.Error(t, Map(pair{}, &small))
+	})
+	t.Run("byte-order", func(t *testing.T) {
+		type rec struct {
+			A uint16  `bin:""`
+			B float32 `bin:"order=big"`
+		}
+		var b []byte
+		ctx := Default.Context.WithByteOrder(binary.LittleEndian)
+		require.NoError(t, MapContext(ctx, rec{A: 1, B: 1}, &b))
+		assert.Equal(t, []byte{1, 0, 0x3f, 0x80, 0, 0}, b)
+		var r rec
+		require.NoError(t, MapContext(ctx, b, &r))
+		assert.Equal(t, rec{A: 1, B: 1}, r)
+	})
+	t.Run("in-map", func(t *testing.T) {
+		type msg struct {
+			Header flags `map:"header"`
+		}
+		var m msg
+		require.NoError(t, Map(map[string]any{"header": []byte{0x81}}, &m))
+		assert.Equal(t, flags{A: true, C: 1}, m.Header)
+	})
+	t.Run("errors", func(t *testing.T) {
+		var h header
+		assert.Error(t, Map(data[:10], &h), "data too short")
+		var b []byte
+		assert.Error(t, Map(flags{C: 64}, &b), "overflow")
+		assert.Error(t, Map(header{Name: "abcde"}, &b), "string too long")
+		type signed struct {
+			A int8 `bin:"bits=3"`
+		}
+		assert.Error(t, Map(signed{A: 4}, &b))
+		assert.Error(t, Map(signed{A: -5}, &b))
+		require.NoError(t, Map(signed{A: -4}, &b))
+		assert.Equal(t, []byte{0x80}, b)
+		ctx := Default.Context.WithStrictTypes(true)
+		assert.Error(t, MapContext(ctx, data, &h))
+	})
+	t.Run("invalid-layouts", func(t *testing.T) {
+		tests := []any{
+			&struct {
+				A uint8 `bin:"bits=9"`
+			}{},
+			&struct {
+				A uint8 `bin:"foo"`
+			}{},
+			&struct {
+				A uint8 `bin:"size=x"`
+			}{},
+			&struct {
+				A uint8 `bin:"order=middle"`
+			}{},
+			&struct {
+				A []byte `bin:""`
+				B uint8
+			}{},
+			&struct {
+				A float32 `bin:"bits=16"`
+			}{},
+			&struct {
+				A bool    `bin:"bits=1"`
+				B [1]byte `bin:""`
+			}{},
+			&struct {
+				A []int `bin:""`
+			}{},
+			&struct {
+				A map[string]int `bin:""`
+			}{},
+			&struct {
+				A uint16 `bin:""`
+				B uint8  `bin:"offset=1"`
+			}{},
+			&struct {
+				A uint8 `bin:"bits=4"`
+				B uint8 `bin:"bitoffset=2,bits=4"`
+			}{},
+			&struct {
+				A uint8  `bin:"offset=2"`
+				B []byte `bin:"offset=1"`
+			}{},
+		}
+		for _, dst := range tests {
+			assert.Error(t, Map([]byte{1, 2, 3, 4}, dst))
+		}
+	})
+}
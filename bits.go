@@ -0,0 +1,243 @@
+package anymapper
+
+import (
+	"reflect"
+	"strings"
+)
+
+// BitOrder is the order of bits used when integers and byte slices are
+// mapped to and from bit strings and bool slices.
+type BitOrder int
+
+const (
+	// MSBFirst places the most significant bit first, so the number 6
+	// stored in a uint8 is represented as "00000110".
+	MSBFirst BitOrder = iota
+
+	// LSBFirst places the least significant bit first, so the number 6
+	// stored in a uint8 is represented as "01100000".
+	LSBFirst
+)
+
+// intBits returns the bits of an int or uint value. The number of bits is
+// equal to the size of the type, int and uint are always considered to be
+// 64 bits.
+func intBits(v reflect.Value, order BitOrder) []bool {
+	var u uint64
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		u = uint64(v.Int())
+	default:
+		u = v.Uint()
+	}
+	n := bitSize(v.Type())
+	bits := make([]bool, n)
+	for i := 0; i < n; i++ {
+		bit := u>>uint(i)&1 == 1
+		if order == MSBFirst {
+			bits[n-1-i] = bit
+		} else {
+			bits[i] = bit
+		}
+	}
+	return bits
+}
+
+// setIntBits sets the int or uint value from bits. If there are as many
+// bits as the size of a signed type, the value is interpreted as a two's
+// complement number. If there are fewer bits, the value is always
+// positive. It returns false if there are more bits than the size of the
+// type.
+func setIntBits(v reflect.Value, bits []bool, order BitOrder) bool {
+	n := bitSize(v.Type())
+	if len(bits) > n {
+		return false
+	}
+	var u uint64
+	for i, bit := range bits {
+		if !bit {
+			continue
+		}
+		pos := i
+		if order == MSBFirst {
+			pos = len(bits) - 1 - i
+		}
+		u |= 1 << uint(pos)
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := int64(u)
+		if len(bits) >= n && n < 64 && u>>uint(n-1)&1 == 1 {
+			// Sign-extend the two's complement value.
+			i = int64(u | ^uint64(0)<<uint(n))
+		}
+		if v.OverflowInt(i) {
+			return false
+		}
+		v.SetInt(i)
+	default:
+		v.SetUint(u)
+	}
+	return true
+}
+
+// bytesBits returns the bits of a byte slice. Bytes are kept in their
+// order, the bit order applies to the bits within each byte.
+func bytesBits(b []byte, order BitOrder) []bool {
+	bits := make([]bool, 0, len(b)*8)
+	for _, c := range b {
+		for i := 0; i < 8; i++ {
+			pos := i
+			if order == MSBFirst {
+				pos = 7 - i
+			}
+			bits = append(bits, c>>uint(pos)&1 == 1)
+		}
+	}
+	return bits
+}
+
+// bitsBytes returns the byte slice represented by bits. The number of bits
+// must be a multiple of 8.
+func bitsBytes(bits []bool, order BitOrder) ([]byte, bool) {
+	if len(bits)%8 != 0 {
+		return nil, false
+	}
+	b := make([]byte, len(bits)/8)
+	for i, bit := range bits {
+		if !bit {
+			continue
+		}
+		pos := i % 8
+		if order == MSBFirst {
+			pos = 7 - pos
+		}
+		b[i/8] |= 1 << uint(pos)
+	}
+	return b, true
+}
+
+// formatBits returns the bits as a string of binary digits.
+func formatBits(bits []bool) string {
+	var sb strings.Builder
+	sb.Grow(len(bits))
+	for _, bit := range bits {
+		if bit {
+			sb.WriteByte('1')
+		} else {
+			sb.WriteByte('0')
+		}
+	}
+	return sb.String()
+}
+
+// parseBits parses a string of binary digits.
+func parseBits(s string) ([]bool, bool) {
+	bits := make([]bool, len(s))
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '0':
+		case '1':
+			bits[i] = true
+		default:
+			return nil, false
+		}
+	}
+	return bits, true
+}
+
+// boolSlice returns the bool slice or array value as []bool.
+func boolSlice(v reflect.Value) []bool {
+	bits := make([]bool, v.Len())
+	for i := range bits {
+		bits[i] = v.Index(i).Bool()
+	}
+	return bits
+}
+
+// setBoolSlice sets the bool slice or array value from bits. Slices are
+// resized, arrays must have the same length as bits.
+func setBoolSlice(v reflect.Value, bits []bool) bool {
+	if v.Kind() == reflect.Slice {
+		v.Set(reflect.MakeSlice(v.Type(), len(bits), len(bits)))
+	} else if v.Len() != len(bits) {
+		return false
+	}
+	for i, bit := range bits {
+		v.Index(i).SetBool(bit)
+	}
+	return true
+}
+
+// bitSize returns the size of the int or uint type in bits.
+func bitSize(t reflect.Type) int {
+	if t.Kind() == reflect.Int || t.Kind() == reflect.Uint {
+		return 64
+	}
+	return t.Bits()
+}
+
+func mapIntToBoolSlice(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if !ctx.Bits {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "")
+	}
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	if !setBoolSlice(dst, intBits(src, ctx.BitOrder)) {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "invalid array length")
+	}
+	return nil
+}
+
+func mapBoolSliceToInt(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if !ctx.Bits {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "")
+	}
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	if !setIntBits(dst, boolSlice(src), ctx.BitOrder) {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "overflow")
+	}
+	return nil
+}
+
+// stringBitsToInt sets the int or uint value from a string of binary
+// digits.
+func stringBitsToInt(src, dst reflect.Value, order BitOrder) error {
+	bits, ok := parseBits(src.String())
+	if !ok {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "invalid bit string")
+	}
+	if !setIntBits(dst, bits, order) {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "overflow")
+	}
+	return nil
+}
+
+func mapByteSliceToBoolSlice(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if !ctx.Bits {
+		return mapSliceToSlice(m, ctx, src, dst)
+	}
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	setBoolSlice(dst, bytesBits(src.Bytes(), ctx.BitOrder))
+	return nil
+}
+
+func mapBoolSliceToByteSlice(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if !ctx.Bits {
+		return mapSliceToSlice(m, ctx, src, dst)
+	}
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	b, ok := bitsBytes(boolSlice(src), ctx.BitOrder)
+	if !ok {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "number of bits must be a multiple of 8")
+	}
+	dst.SetBytes(b)
+	return nil
+}
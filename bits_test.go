@@ -0,0 +1,110 @@
+package anymapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBitStrings(t *testing.T) {
+	msb := Default.Context.WithBits(true)
+	lsb := msb.WithBitOrder(LSBFirst)
+	t.Run("int-to-string", func(t *testing.T) {
+		var s string
+		require.NoError(t, MapContext(msb, uint8(6), &s))
+		assert.Equal(t, "00000110", s)
+		require.NoError(t, MapContext(lsb, uint8(6), &s))
+		assert.Equal(t, "01100000", s)
+		require.NoError(t, MapContext(msb, int8(-1), &s))
+		assert.Equal(t, "11111111", s)
+		require.NoError(t, MapContext(msb, 1, &s))
+		assert.Len(t, s, 64)
+	})
+	t.Run("string-to-int", func(t *testing.T) {
+		var u uint8
+		require.NoError(t, MapContext(msb, "110", &u))
+		assert.Equal(t, uint8(6), u)
+		require.NoError(t, MapContext(lsb, "011", &u))
+		assert.Equal(t, uint8(6), u)
+		require.NoError(t, MapContext(msb, "00000110", &u))
+		assert.Equal(t, uint8(6), u)
+		assert.Error(t, MapContext(msb, "000000000110", &u))
+		assert.Error(t, MapContext(msb, "100000000", &u))
+		assert.Error(t, MapContext(msb, "12", &u))
+
+		var i int8
+		require.NoError(t, MapContext(msb, "11111110", &i))
+		assert.Equal(t, int8(-2), i)
+		require.NoError(t, MapContext(msb, "1111110", &i))
+		assert.Equal(t, int8(126), i)
+
+		var i64 int64
+		require.NoError(t, MapContext(msb, "1111111111111111111111111111111111111111111111111111111111111111", &i64))
+		assert.Equal(t, int64(-1), i64)
+	})
+	t.Run("bytes-to-string", func(t *testing.T) {
+		var s string
+		require.NoError(t, MapContext(msb, []byte{0xb2, 0x01}, &s))
+		assert.Equal(t, "1011001000000001", s)
+		require.NoError(t, MapContext(lsb, []byte{0xb2, 0x01}, &s))
+		assert.Equal(t, "0100110110000000", s)
+		require.NoError(t, MapContext(msb, [1]byte{0xb2}, &s))
+		assert.Equal(t, "10110010", s)
+	})
+	t.Run("string-to-bytes", func(t *testing.T) {
+		var b []byte
+		require.NoError(t, MapContext(msb, "1011001000000001", &b))
+		assert.Equal(t, []byte{0xb2, 0x01}, b)
+		require.NoError(t, MapContext(lsb, "0100110110000000", &b))
+		assert.Equal(t, []byte{0xb2, 0x01}, b)
+		assert.Error(t, MapContext(msb, "101", &b))
+	})
+	t.Run("disabled", func(t *testing.T) {
+		var s string
+		require.NoError(t, Map(uint8(6), &s))
+		assert.Equal(t, "6", s)
+		var b []bool
+		require.NoError(t, Map([]byte{0, 2}, &b))
+		assert.Equal(t, []bool{false, true}, b)
+	})
+}
+
+func TestBitSlices(t *testing.T) {
+	t.Run("int", func(t *testing.T) {
+		ctx := Default.Context.WithBits(true)
+		var b []bool
+		require.NoError(t, MapContext(ctx, uint8(6), &b))
+		assert.Equal(t, []bool{false, false, false, false, false, true, true, false}, b)
+
+		var u uint8
+		require.NoError(t, MapContext(ctx, []bool{true, true, false}, &u))
+		assert.Equal(t, uint8(6), u)
+		assert.Error(t, MapContext(ctx, make([]bool, 9), &u))
+
+		lsb := ctx.WithBitOrder(LSBFirst)
+		var a [4]bool
+		require.NoError(t, MapContext(lsb, uint16(6), &b))
+		assert.Len(t, b, 16)
+		assert.Error(t, MapContext(lsb, uint16(6), &a))
+		require.NoError(t, MapContext(lsb, [4]bool{false, true, true, false}, &u))
+		assert.Equal(t, uint8(6), u)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		var b []bool
+		assert.Error(t, Map(uint8(6), &b))
+		var u uint8
+		assert.Error(t, Map([]bool{true}, &u))
+	})
+	t.Run("bytes", func(t *testing.T) {
+		ctx := Default.Context.WithBits(true)
+		var b []bool
+		require.NoError(t, MapContext(ctx, []byte{0x81}, &b))
+		assert.Equal(t, []bool{true, false, false, false, false, false, false, true}, b)
+
+		var bs []byte
+		require.NoError(t, MapContext(ctx, []bool{true, false, false, false, false, false, false, true}, &bs))
+		assert.Equal(t, []byte{0x81}, bs)
+		assert.Error(t, MapContext(ctx, []bool{true}, &bs))
+	})
+}
@@ -0,0 +1,424 @@
+// Package bsontypes provides mapping functions for the MongoDB BSON
+// primitive types.
+//
+// The Register function adds mapper providers for the primitive.ObjectID,
+// primitive.Decimal128 and primitive.DateTime types to the Mapper:
+//
+//   - ObjectID ⇔ string, []byte, and ObjectID ⇒ time.Time using the
+//     timestamp stored in the ID.
+//   - Decimal128 ⇔ string, big.Int, big.Float, intX, uintX, floatX.
+//   - DateTime ⇔ time.Time, string, intX, uintX, where numbers are the
+//     number of milliseconds since the Unix epoch.
+//
+// The package is a separate module, so the MongoDB driver is not required
+// by the main module.
+package bsontypes
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/defiweb/go-anymapper"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var errFractional = errors.New("decimal has a fractional part")
+
+var (
+	objectIDTy   = reflect.TypeOf(primitive.ObjectID{})
+	decimal128Ty = reflect.TypeOf(primitive.Decimal128{})
+	dateTimeTy   = reflect.TypeOf(primitive.DateTime(0))
+	timeTy       = reflect.TypeOf(time.Time{})
+	bigIntTy     = reflect.TypeOf(big.Int{})
+	bigFloatTy   = reflect.TypeOf(big.Float{})
+)
+
+// Register adds the mapper providers for the BSON primitive types to the
+// Mapper. It should be called before the Mapper is used, because mapping
+// functions resolved earlier may be cached.
+//
+// The provider for time.Time is wrapped, so that time.Time values are
+// mapped to DateTime as milliseconds instead of seconds.
+func Register(m *anymapper.Mapper) {
+	m.Mappers[objectIDTy] = objectIDTypeMapper
+	m.Mappers[decimal128Ty] = decimal128TypeMapper
+	m.Mappers[dateTimeTy] = dateTimeTypeMapper
+	timeMapper := m.Mappers[timeTy]
+	m.Mappers[timeTy] = func(m *anymapper.Mapper, src, dst reflect.Type) anymapper.MapFunc {
+		if src == timeTy && dst == dateTimeTy {
+			return mapTimeToDateTime
+		}
+		if timeMapper != nil {
+			return timeMapper(m, src, dst)
+		}
+		return nil
+	}
+}
+
+func objectIDTypeMapper(_ *anymapper.Mapper, src, dst reflect.Type) anymapper.MapFunc {
+	if src == dst {
+		return mapDirect
+	}
+	switch {
+	case src == objectIDTy:
+		switch {
+		case dst.Kind() == reflect.String:
+			return mapObjectIDToString
+		case dst.Kind() == reflect.Slice && dst.Elem().Kind() == reflect.Uint8:
+			return mapObjectIDToBytes
+		case dst == timeTy:
+			return mapObjectIDToTime
+		}
+	case dst == objectIDTy:
+		switch {
+		case src.Kind() == reflect.String:
+			return mapStringToObjectID
+		case src.Kind() == reflect.Slice && src.Elem().Kind() == reflect.Uint8:
+			return mapBytesToObjectID
+		}
+	}
+	return nil
+}
+
+func decimal128TypeMapper(_ *anymapper.Mapper, src, dst reflect.Type) anymapper.MapFunc {
+	if src == dst {
+		return mapDirect
+	}
+	switch {
+	case src == decimal128Ty:
+		switch dst.Kind() {
+		case reflect.String:
+			return mapDecimal128ToString
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return mapDecimal128ViaBigInt
+		case reflect.Float32, reflect.Float64:
+			return mapDecimal128ToFloat
+		case reflect.Struct:
+			switch dst {
+			case bigIntTy:
+				return mapDecimal128ToBigInt
+			case bigFloatTy:
+				return mapDecimal128ToBigFloat
+			}
+		}
+	case dst == decimal128Ty:
+		switch src.Kind() {
+		case reflect.String:
+			return mapStringToDecimal128
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return mapToDecimal128ViaBigInt
+		case reflect.Float32, reflect.Float64:
+			return mapFloatToDecimal128
+		case reflect.Struct:
+			switch src {
+			case bigIntTy:
+				return mapBigIntToDecimal128
+			case bigFloatTy:
+				return mapBigFloatToDecimal128
+			}
+		}
+	}
+	return nil
+}
+
+func dateTimeTypeMapper(_ *anymapper.Mapper, src, dst reflect.Type) anymapper.MapFunc {
+	if src == dst {
+		return mapDirect
+	}
+	switch {
+	case src == dateTimeTy:
+		switch dst.Kind() {
+		case reflect.String:
+			return mapDateTimeViaTime
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return mapDateTimeViaInt64
+		case reflect.Struct:
+			if dst == timeTy {
+				return mapDateTimeToTime
+			}
+		}
+	case dst == dateTimeTy:
+		switch src.Kind() {
+		case reflect.String:
+			return mapToDateTimeViaTime
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return mapToDateTimeViaInt64
+		}
+	}
+	return nil
+}
+
+func mapDirect(_ *anymapper.Mapper, _ *anymapper.Context, src, dst reflect.Value) error {
+	dst.Set(src)
+	return nil
+}
+
+func mapObjectIDToString(_ *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	dst.SetString(src.Interface().(primitive.ObjectID).Hex())
+	return nil
+}
+
+func mapObjectIDToBytes(_ *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	id := src.Interface().(primitive.ObjectID)
+	dst.SetBytes(id[:])
+	return nil
+}
+
+func mapObjectIDToTime(_ *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	dst.Set(reflect.ValueOf(src.Interface().(primitive.ObjectID).Timestamp()))
+	return nil
+}
+
+func mapStringToObjectID(_ *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	id, err := primitive.ObjectIDFromHex(src.String())
+	if err != nil {
+		return anymapper.WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	dst.Set(reflect.ValueOf(id))
+	return nil
+}
+
+func mapBytesToObjectID(_ *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	var id primitive.ObjectID
+	if src.Len() != len(id) {
+		return anymapper.NewInvalidMappingError(src.Type(), dst.Type(), "invalid byte slice length")
+	}
+	copy(id[:], src.Bytes())
+	dst.Set(reflect.ValueOf(id))
+	return nil
+}
+
+func mapDecimal128ToString(_ *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	dst.SetString(src.Interface().(primitive.Decimal128).String())
+	return nil
+}
+
+func mapDecimal128ToBigInt(_ *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	v, err := decimal128ToBigInt(src.Interface().(primitive.Decimal128))
+	if err != nil {
+		return anymapper.WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	dst.Set(reflect.ValueOf(v).Elem())
+	return nil
+}
+
+func mapDecimal128ViaBigInt(m *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	v, err := decimal128ToBigInt(src.Interface().(primitive.Decimal128))
+	if err != nil {
+		return anymapper.WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	return m.MapReflContext(ctx, reflect.ValueOf(v), dst)
+}
+
+func mapDecimal128ToBigFloat(_ *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	d := src.Interface().(primitive.Decimal128)
+	v := new(big.Float)
+	switch {
+	case d.IsNaN():
+		return anymapper.NewInvalidMappingError(src.Type(), dst.Type(), "NaN")
+	case d.IsInf() != 0:
+		v.SetInf(d.IsInf() < 0)
+	default:
+		bi, exp, err := d.BigInt()
+		if err != nil {
+			return anymapper.WrapInvalidMappingError(src.Type(), dst.Type(), err)
+		}
+		v.SetRat(scaleRat(bi, exp))
+	}
+	dst.Set(reflect.ValueOf(v).Elem())
+	return nil
+}
+
+func mapDecimal128ToFloat(_ *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	v, err := strconv.ParseFloat(src.Interface().(primitive.Decimal128).String(), dst.Type().Bits())
+	if err != nil {
+		return anymapper.WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	dst.SetFloat(v)
+	return nil
+}
+
+func mapStringToDecimal128(_ *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	d, err := primitive.ParseDecimal128(src.String())
+	if err != nil {
+		return anymapper.WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	dst.Set(reflect.ValueOf(d))
+	return nil
+}
+
+func mapBigIntToDecimal128(_ *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	d, ok := primitive.ParseDecimal128FromBigInt(src.Addr().Interface().(*big.Int), 0)
+	if !ok {
+		return anymapper.NewInvalidMappingError(src.Type(), dst.Type(), "overflow")
+	}
+	dst.Set(reflect.ValueOf(d))
+	return nil
+}
+
+func mapToDecimal128ViaBigInt(m *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	v := new(big.Int)
+	if err := m.MapReflContext(ctx, src, reflect.ValueOf(v)); err != nil {
+		return err
+	}
+	d, ok := primitive.ParseDecimal128FromBigInt(v, 0)
+	if !ok {
+		return anymapper.NewInvalidMappingError(src.Type(), dst.Type(), "overflow")
+	}
+	dst.Set(reflect.ValueOf(d))
+	return nil
+}
+
+func mapBigFloatToDecimal128(_ *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	d, err := primitive.ParseDecimal128(src.Addr().Interface().(*big.Float).Text('e', -1))
+	if err != nil {
+		return anymapper.WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	dst.Set(reflect.ValueOf(d))
+	return nil
+}
+
+func mapFloatToDecimal128(_ *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	d, err := primitive.ParseDecimal128(strconv.FormatFloat(src.Float(), 'g', -1, src.Type().Bits()))
+	if err != nil {
+		return anymapper.WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	dst.Set(reflect.ValueOf(d))
+	return nil
+}
+
+func mapDateTimeToTime(_ *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	dst.Set(reflect.ValueOf(primitive.DateTime(src.Int()).Time()))
+	return nil
+}
+
+func mapDateTimeViaTime(m *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	return m.MapReflContext(ctx, reflect.ValueOf(primitive.DateTime(src.Int()).Time().UTC()), dst)
+}
+
+func mapDateTimeViaInt64(m *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	return m.MapReflContext(ctx, reflect.ValueOf(src.Int()), dst)
+}
+
+func mapTimeToDateTime(_ *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	dst.SetInt(int64(primitive.NewDateTimeFromTime(src.Interface().(time.Time))))
+	return nil
+}
+
+func mapToDateTimeViaTime(m *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	var t time.Time
+	if err := m.MapReflContext(ctx, src, reflect.ValueOf(&t)); err != nil {
+		return err
+	}
+	dst.SetInt(int64(primitive.NewDateTimeFromTime(t)))
+	return nil
+}
+
+func mapToDateTimeViaInt64(m *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	var v int64
+	if err := m.MapReflContext(ctx, src, reflect.ValueOf(&v)); err != nil {
+		return err
+	}
+	dst.SetInt(v)
+	return nil
+}
+
+// decimal128ToBigInt converts the decimal to an integer. It fails if the
+// decimal has a fractional part.
+func decimal128ToBigInt(d primitive.Decimal128) (*big.Int, error) {
+	bi, exp, err := d.BigInt()
+	if err != nil {
+		return nil, err
+	}
+	r := scaleRat(bi, exp)
+	if !r.IsInt() {
+		return nil, errFractional
+	}
+	return new(big.Int).Set(r.Num()), nil
+}
+
+// scaleRat returns bi * 10^exp as a big.Rat.
+func scaleRat(bi *big.Int, exp int) *big.Rat {
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(exp))), nil)
+	if exp >= 0 {
+		return new(big.Rat).SetInt(new(big.Int).Mul(bi, pow))
+	}
+	return new(big.Rat).SetFrac(bi, pow)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
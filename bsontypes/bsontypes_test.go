@@ -0,0 +1,163 @@
+package bsontypes
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/defiweb/go-anymapper"
+)
+
+func newMapper() *anymapper.Mapper {
+	m := anymapper.New()
+	Register(m)
+	return m
+}
+
+func TestObjectID(t *testing.T) {
+	m := newMapper()
+	hex := "5f1a2b3c4d5e6f7081920a1b"
+	exp, err := primitive.ObjectIDFromHex(hex)
+	require.NoError(t, err)
+
+	var id primitive.ObjectID
+	require.NoError(t, m.Map(hex, &id))
+	assert.Equal(t, exp, id)
+
+	var s string
+	require.NoError(t, m.Map(id, &s))
+	assert.Equal(t, hex, s)
+
+	var b []byte
+	require.NoError(t, m.Map(id, &b))
+	assert.Equal(t, exp[:], b)
+
+	id = primitive.ObjectID{}
+	require.NoError(t, m.Map(b, &id))
+	assert.Equal(t, exp, id)
+
+	var tm time.Time
+	require.NoError(t, m.Map(id, &tm))
+	assert.Equal(t, exp.Timestamp(), tm)
+
+	assert.Error(t, m.Map("foo", &id))
+	assert.Error(t, m.Map([]byte{1, 2, 3}, &id))
+	assert.Error(t, m.MapContext(m.Context.WithStrictTypes(true), hex, &id))
+}
+
+func TestDecimal128(t *testing.T) {
+	m := newMapper()
+	dec := func(s string) primitive.Decimal128 {
+		d, err := primitive.ParseDecimal128(s)
+		require.NoError(t, err)
+		return d
+	}
+	t.Run("string", func(t *testing.T) {
+		var d primitive.Decimal128
+		require.NoError(t, m.Map("12.345", &d))
+		assert.Equal(t, dec("12.345"), d)
+
+		var s string
+		require.NoError(t, m.Map(d, &s))
+		assert.Equal(t, "12.345", s)
+
+		assert.Error(t, m.Map("foo", &d))
+	})
+	t.Run("big.Int", func(t *testing.T) {
+		var bi *big.Int
+		require.NoError(t, m.Map(dec("1.2E+3"), &bi))
+		assert.Equal(t, big.NewInt(1200), bi)
+		assert.Error(t, m.Map(dec("1.5"), &bi))
+
+		var d primitive.Decimal128
+		require.NoError(t, m.Map(big.NewInt(-42), &d))
+		assert.Equal(t, "-42", d.String())
+	})
+	t.Run("big.Float", func(t *testing.T) {
+		var bf *big.Float
+		require.NoError(t, m.Map(dec("1.25"), &bf))
+		f, _ := bf.Float64()
+		assert.Equal(t, 1.25, f)
+
+		require.NoError(t, m.Map(dec("-Infinity"), &bf))
+		assert.True(t, bf.IsInf())
+
+		var d primitive.Decimal128
+		require.NoError(t, m.Map(big.NewFloat(0.5), &d))
+		assert.Equal(t, "0.5", d.String())
+	})
+	t.Run("numbers", func(t *testing.T) {
+		var i int8
+		require.NoError(t, m.Map(dec("100"), &i))
+		assert.Equal(t, int8(100), i)
+		assert.Error(t, m.Map(dec("1000"), &i))
+
+		var f float64
+		require.NoError(t, m.Map(dec("1.5"), &f))
+		assert.Equal(t, 1.5, f)
+
+		var d primitive.Decimal128
+		require.NoError(t, m.Map(uint64(42), &d))
+		assert.Equal(t, "42", d.String())
+		require.NoError(t, m.Map(0.25, &d))
+		assert.Equal(t, "0.25", d.String())
+	})
+}
+
+func TestDateTime(t *testing.T) {
+	m := newMapper()
+	tm := time.Date(2023, 5, 6, 7, 8, 9, 123000000, time.UTC)
+	ms := tm.UnixMilli()
+
+	var dt primitive.DateTime
+	require.NoError(t, m.Map(tm, &dt))
+	assert.Equal(t, primitive.DateTime(ms), dt)
+
+	var out time.Time
+	require.NoError(t, m.Map(dt, &out))
+	assert.True(t, tm.Equal(out))
+
+	var i int64
+	require.NoError(t, m.Map(dt, &i))
+	assert.Equal(t, ms, i)
+
+	dt = 0
+	require.NoError(t, m.Map(ms, &dt))
+	assert.Equal(t, primitive.DateTime(ms), dt)
+
+	// Strings use the same format as time.Time values.
+	var s string
+	require.NoError(t, m.Map(dt, &s))
+	assert.Equal(t, "2023-05-06T07:08:09Z", s)
+
+	dt = 0
+	require.NoError(t, m.Map(s, &dt))
+	assert.Equal(t, primitive.DateTime(tm.Unix()*1000), dt)
+
+	// Other time.Time mappings are not affected.
+	var sec int64
+	require.NoError(t, m.Map(tm, &sec))
+	assert.Equal(t, tm.Unix(), sec)
+}
+
+func TestStruct(t *testing.T) {
+	m := newMapper()
+	type Doc struct {
+		ID      primitive.ObjectID   `map:"_id"`
+		Amount  primitive.Decimal128 `map:"amount"`
+		Created primitive.DateTime   `map:"created"`
+	}
+	var doc Doc
+	require.NoError(t, m.Map(map[string]any{
+		"_id":     "5f1a2b3c4d5e6f7081920a1b",
+		"amount":  "10.5",
+		"created": int64(1000),
+	}, &doc))
+	assert.Equal(t, "5f1a2b3c4d5e6f7081920a1b", doc.ID.Hex())
+	assert.Equal(t, "10.5", doc.Amount.String())
+	assert.Equal(t, primitive.DateTime(1000), doc.Created)
+}
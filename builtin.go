@@ -3,10 +3,12 @@ package anymapper
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"math"
 	"reflect"
 	"strconv"
+	"strings"
 )
 
 func builtInTypesMapper(_ *Mapper, src, dst reflect.Type) MapFunc {
@@ -37,8 +39,15 @@ func builtInTypesMapper(_ *Mapper, src, dst reflect.Type) MapFunc {
 		case reflect.String:
 			return mapIntToString
 		case reflect.Slice, reflect.Array:
-			if dst.Elem().Kind() == reflect.Uint8 {
+			switch dst.Elem().Kind() {
+			case reflect.Uint8:
 				return mapIntToByteSliceOrByteArray
+			case reflect.Bool:
+				return mapIntToBoolSlice
+			}
+		case reflect.Struct, reflect.Map:
+			if src == durationTy && (isSecondsNanos(dst) || isSecondsNanosMap(dst)) {
+				return mapDurationToSecondsNanos
 			}
 		}
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
@@ -54,8 +63,11 @@ func builtInTypesMapper(_ *Mapper, src, dst reflect.Type) MapFunc {
 		case reflect.String:
 			return mapUintToString
 		case reflect.Slice, reflect.Array:
-			if dst.Elem().Kind() == reflect.Uint8 {
+			switch dst.Elem().Kind() {
+			case reflect.Uint8:
 				return mapUintToByteSliceOrByteArray
+			case reflect.Bool:
+				return mapIntToBoolSlice
 			}
 		}
 	case reflect.Float32, reflect.Float64:
@@ -87,6 +99,13 @@ func builtInTypesMapper(_ *Mapper, src, dst reflect.Type) MapFunc {
 			return mapStringToFloat
 		case reflect.String:
 			return mapStringToString
+		case reflect.Struct:
+			switch {
+			case isCivilDate(dst):
+				return mapStringToCivilDate
+			case isCivilTime(dst):
+				return mapStringToCivilTime
+			}
 		case reflect.Slice:
 			if dst.Elem().Kind() == reflect.Uint8 {
 				return mapStringToByteSlice
@@ -101,25 +120,53 @@ func builtInTypesMapper(_ *Mapper, src, dst reflect.Type) MapFunc {
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
 			reflect.Float32, reflect.Float64:
-			if src.Elem().Kind() == reflect.Uint8 {
+			switch src.Elem().Kind() {
+			case reflect.Uint8:
 				return mapByteSliceToNumber
+			case reflect.Bool:
+				if dst.Kind() != reflect.Float32 && dst.Kind() != reflect.Float64 {
+					return mapBoolSliceToInt
+				}
 			}
 		case reflect.String:
 			if src.Elem().Kind() == reflect.Uint8 {
 				return mapByteSliceToString
 			}
 		case reflect.Slice:
+			switch {
+			case src.Elem().Kind() == reflect.Uint8 && dst.Elem().Kind() == reflect.Bool:
+				return mapByteSliceToBoolSlice
+			case src.Elem().Kind() == reflect.Bool && dst.Elem().Kind() == reflect.Uint8:
+				return mapBoolSliceToByteSlice
+			}
 			return mapSliceToSlice
 		case reflect.Array:
 			return mapSliceToArray
+		case reflect.Map:
+			switch {
+			case isIntKind(dst.Key().Kind()) && src.Elem().Kind() != reflect.Uint8:
+				// Byte slices are binary data rather than lists.
+				return mapSliceToIndexMap
+			case isKVSlice(src):
+				return mapKVToMap
+			}
+		case reflect.Struct:
+			if isKVSlice(src) {
+				return mapKVToStruct
+			}
 		}
 	case reflect.Array:
 		switch dst.Kind() {
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
 			reflect.Float32, reflect.Float64:
-			if src.Elem().Kind() == reflect.Uint8 {
+			switch src.Elem().Kind() {
+			case reflect.Uint8:
 				return mapByteArrayToNumber
+			case reflect.Bool:
+				if dst.Kind() != reflect.Float32 && dst.Kind() != reflect.Float64 {
+					return mapBoolSliceToInt
+				}
 			}
 		case reflect.String:
 			if src.Elem().Kind() == reflect.Uint8 {
@@ -136,6 +183,17 @@ func builtInTypesMapper(_ *Mapper, src, dst reflect.Type) MapFunc {
 			return mapMapToMap
 		case reflect.Struct:
 			return mapMapToStruct
+		case reflect.Slice:
+			switch {
+			case isIntKind(src.Key().Kind()):
+				return mapIndexMapToSlice
+			case isKVSlice(dst):
+				return mapMapToKV
+			}
+		case reflect.Int64:
+			if dst == durationTy && isSecondsNanosMap(src) {
+				return mapSecondsNanosToDuration
+			}
 		}
 	case reflect.Struct:
 		switch dst.Kind() {
@@ -150,6 +208,28 @@ func builtInTypesMapper(_ *Mapper, src, dst reflect.Type) MapFunc {
 			if dst.Key().Kind() == reflect.String {
 				return mapStructToMap
 			}
+		case reflect.Slice:
+			if isKVSlice(dst) {
+				return mapStructToKV
+			}
+		case reflect.Int64:
+			if dst == durationTy && isSecondsNanos(src) {
+				return mapSecondsNanosToDuration
+			}
+		case reflect.String:
+			switch {
+			case isCivilDate(src):
+				return mapCivilDateToString
+			case isCivilTime(src):
+				return mapCivilTimeToString
+			}
+		}
+	case reflect.Func:
+		switch {
+		case dst.Kind() == reflect.Slice && isSeq(src):
+			return mapSeqToSlice
+		case dst.Kind() == reflect.Map && isSeq2(src):
+			return mapSeq2ToMap
 		}
 	default:
 		return nil
@@ -250,6 +330,9 @@ func mapIntToFloat(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
+	if ctx.Lossless && !exactIntToFloat(src.Int(), dst.Kind()) {
+		return newLossError(src.Type(), dst.Type())
+	}
 	dst.SetFloat(float64(src.Int()))
 	return nil
 }
@@ -258,14 +341,28 @@ func mapIntToString(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
-	dst.SetString(strconv.FormatInt(src.Int(), 10))
+	if ctx.Bits {
+		dst.SetString(formatBits(intBits(src, ctx.BitOrder)))
+		return nil
+	}
+	if ctx.Suffixes != NoSuffixes {
+		if s := formatSuffixedInt(ctx.Suffixes, src.Int()); s != "" {
+			dst.SetString(s)
+			return nil
+		}
+	}
+	var buf [24]byte
+	setStringBytes(dst, strconv.AppendInt(buf[:0], src.Int(), 10))
 	return nil
 }
 
-func mapIntToByteSliceOrByteArray(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+func mapIntToByteSliceOrByteArray(m *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
+	if isTextBytes(ctx, dst) {
+		return mapNumberToText(m, ctx, src, dst)
+	}
 	return numberToBytes(ctx, src, dst)
 }
 
@@ -306,6 +403,9 @@ func mapUintToFloat(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
+	if ctx.Lossless && !exactUintToFloat(src.Uint(), dst.Kind()) {
+		return newLossError(src.Type(), dst.Type())
+	}
 	dst.SetFloat(float64(src.Uint()))
 	return nil
 }
@@ -314,14 +414,28 @@ func mapUintToString(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
-	dst.SetString(strconv.FormatUint(src.Uint(), 10))
+	if ctx.Bits {
+		dst.SetString(formatBits(intBits(src, ctx.BitOrder)))
+		return nil
+	}
+	if ctx.Suffixes != NoSuffixes {
+		if s := formatSuffixed(ctx.Suffixes, false, src.Uint()); s != "" {
+			dst.SetString(s)
+			return nil
+		}
+	}
+	var buf [24]byte
+	setStringBytes(dst, strconv.AppendUint(buf[:0], src.Uint(), 10))
 	return nil
 }
 
-func mapUintToByteSliceOrByteArray(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+func mapUintToByteSliceOrByteArray(m *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
+	if isTextBytes(ctx, dst) {
+		return mapNumberToText(m, ctx, src, dst)
+	}
 	return numberToBytes(ctx, src, dst)
 }
 
@@ -343,6 +457,9 @@ func mapFloatToInt(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if dst.OverflowInt(int64(src.Float())) {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "overflow")
 	}
+	if ctx.Lossless && isFraction(src.Float()) {
+		return newLossError(src.Type(), dst.Type())
+	}
 	dst.SetInt(int64(src.Float()))
 	return nil
 }
@@ -357,6 +474,9 @@ func mapFloatToUint(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if dst.OverflowUint(uint64(src.Float())) {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "overflow")
 	}
+	if ctx.Lossless && isFraction(src.Float()) {
+		return newLossError(src.Type(), dst.Type())
+	}
 	dst.SetUint(uint64(src.Float()))
 	return nil
 }
@@ -368,6 +488,9 @@ func mapFloatToFloat(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if dst.OverflowFloat(src.Float()) {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "overflow")
 	}
+	if ctx.Lossless && !exactFloat(src.Float(), dst.Kind()) {
+		return newLossError(src.Type(), dst.Type())
+	}
 	dst.SetFloat(src.Float())
 	return nil
 }
@@ -376,14 +499,24 @@ func mapFloatToString(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
-	dst.SetString(strconv.FormatFloat(src.Float(), 'f', -1, 64))
+	if ctx.Suffixes != NoSuffixes {
+		if s := formatSuffixedFloat(ctx.Suffixes, src.Float()); s != "" {
+			dst.SetString(s)
+			return nil
+		}
+	}
+	var buf [32]byte
+	setStringBytes(dst, strconv.AppendFloat(buf[:0], src.Float(), 'f', -1, 64))
 	return nil
 }
 
-func mapFloatToByteSliceOrByteArray(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+func mapFloatToByteSliceOrByteArray(m *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
+	if isTextBytes(ctx, dst) {
+		return mapNumberToText(m, ctx, src, dst)
+	}
 	return numberToBytes(ctx, src, dst)
 }
 
@@ -391,7 +524,7 @@ func mapStringToBool(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
-	switch src.String() {
+	switch ctx.parseInput(src.String()) {
 	case "true":
 		dst.SetBool(true)
 	case "false":
@@ -406,9 +539,16 @@ func mapStringToInt(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
-	v, err := strconv.ParseInt(src.String(), 10, 64)
+	if ctx.Bits {
+		return stringBitsToInt(src, dst, ctx.BitOrder)
+	}
+	str := ctx.parseInput(src.String())
+	if ok, err := mapSuffixedNumber(ctx, str, src, dst); ok {
+		return err
+	}
+	v, err := strconv.ParseInt(str, 10, 64)
 	if err != nil {
-		return NewInvalidMappingError(src.Type(), dst.Type(), err.Error())
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
 	}
 	if dst.OverflowInt(v) {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "overflow")
@@ -421,9 +561,16 @@ func mapStringToUint(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
-	v, err := strconv.ParseUint(src.String(), 10, 64)
+	if ctx.Bits {
+		return stringBitsToInt(src, dst, ctx.BitOrder)
+	}
+	str := ctx.parseInput(src.String())
+	if ok, err := mapSuffixedNumber(ctx, str, src, dst); ok {
+		return err
+	}
+	v, err := strconv.ParseUint(str, 10, 64)
 	if err != nil {
-		return NewInvalidMappingError(src.Type(), dst.Type(), err.Error())
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
 	}
 	if dst.OverflowUint(v) {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "overflow")
@@ -436,9 +583,13 @@ func mapStringToFloat(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
-	v, err := strconv.ParseFloat(src.String(), 64)
+	str := ctx.parseInput(src.String())
+	if ok, err := mapSuffixedNumber(ctx, str, src, dst); ok {
+		return err
+	}
+	v, err := strconv.ParseFloat(str, 64)
 	if err != nil {
-		return NewInvalidMappingError(src.Type(), dst.Type(), err.Error())
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
 	}
 	if dst.OverflowFloat(v) {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "overflow")
@@ -455,11 +606,21 @@ func mapStringToString(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 	return nil
 }
 
-func mapStringToByteArray(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+func mapStringToByteArray(m *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
+	enc, ok := m.stringEncoding(ctx)
+	if !ok {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "unknown string encoding: "+ctx.StringEncoding)
+	}
 	b := []byte(src.String())
+	if enc != nil {
+		var err error
+		if b, err = enc.DecodeString(src.String()); err != nil {
+			return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+		}
+	}
 	if len(b) != dst.Len() {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "length mismatch")
 	}
@@ -469,25 +630,64 @@ func mapStringToByteArray(_ *Mapper, ctx *Context, src, dst reflect.Value) error
 	return nil
 }
 
-func mapStringToByteSlice(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+func mapStringToByteSlice(m *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
+	enc, ok := m.stringEncoding(ctx)
+	if !ok {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "unknown string encoding: "+ctx.StringEncoding)
+	}
+	if enc != nil {
+		b, err := enc.DecodeString(src.String())
+		if err != nil {
+			return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+		}
+		dst.SetBytes(b)
+		return nil
+	}
+	if ctx.Bits {
+		bits, ok := parseBits(src.String())
+		if !ok {
+			return NewInvalidMappingError(src.Type(), dst.Type(), "invalid bit string")
+		}
+		b, ok := bitsBytes(bits, ctx.BitOrder)
+		if !ok {
+			return NewInvalidMappingError(src.Type(), dst.Type(), "number of bits must be a multiple of 8")
+		}
+		dst.SetBytes(b)
+		return nil
+	}
 	dst.SetBytes([]byte(src.String()))
 	return nil
 }
 
-func mapByteSliceToNumber(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+func mapByteSliceToNumber(m *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
+	if ctx.TextBytes {
+		return mapTextToNumber(m, ctx, src, dst)
+	}
 	return numberFromBytes(ctx, src.Bytes(), dst)
 }
 
-func mapByteSliceToString(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+func mapByteSliceToString(m *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
+	enc, ok := m.stringEncoding(ctx)
+	if !ok {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "unknown string encoding: "+ctx.StringEncoding)
+	}
+	if enc != nil {
+		dst.SetString(enc.EncodeToString(src.Bytes()))
+		return nil
+	}
+	if ctx.Bits {
+		dst.SetString(formatBits(bytesBits(src.Bytes(), ctx.BitOrder)))
+		return nil
+	}
 	dst.SetString(string(src.Bytes()))
 	return nil
 }
@@ -503,14 +703,26 @@ func mapByteArrayToNumber(_ *Mapper, ctx *Context, src, dst reflect.Value) error
 	return numberFromBytes(ctx, b, dst)
 }
 
-func mapByteArrayToString(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+func mapByteArrayToString(m *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
+	enc, ok := m.stringEncoding(ctx)
+	if !ok {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "unknown string encoding: "+ctx.StringEncoding)
+	}
 	b := make([]byte, src.Len())
 	for i := 0; i < src.Len(); i++ {
 		b[i] = byte(src.Index(i).Uint())
 	}
+	if enc != nil {
+		dst.SetString(enc.EncodeToString(b))
+		return nil
+	}
+	if ctx.Bits {
+		dst.SetString(formatBits(bytesBits(b, ctx.BitOrder)))
+		return nil
+	}
 	dst.SetString(string(b))
 	return nil
 }
@@ -519,11 +731,14 @@ func mapSliceToSlice(m *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes && src.Type() != dst.Type() {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
-	mapper := m.mapperFor(ctx, src.Type().Elem(), dst.Type().Elem())
-	if src.Type() == dst.Type() && dst.CanSet() {
+	if src.Type() == dst.Type() && dst.CanSet() && !ctx.tracksPaths() && len(ctx.InputTransforms) == 0 {
 		dst.Set(src)
 		return nil
 	}
+	if err := checkLimits(ctx, src); err != nil {
+		return err
+	}
+	mapper := m.mapperFor(ctx, src.Type().Elem(), dst.Type().Elem())
 	if src.Len() > dst.Len() {
 		if dst.Cap() >= src.Len() {
 			dst.SetLen(src.Len())
@@ -534,25 +749,32 @@ func mapSliceToSlice(m *Mapper, ctx *Context, src, dst reflect.Value) error {
 			)
 		}
 	}
+	var errs []error
 	for i := 0; i < src.Len(); i++ {
-		srcVal := m.srcValue(src.Index(i))
-		dstVal := m.dstValue(dst.Index(i))
-		srcValTyp := srcVal.Type()
-		dstValTyp := dstVal.Type()
-		if !mapper.match(srcValTyp, dstValTyp) {
-			mapper = m.mapperFor(ctx, srcValTyp, dstValTyp)
-		}
-		if err := mapper.mapRefl(m, ctx, srcVal, dstVal); err != nil {
-			return err
+		if m.sharedNode(ctx, src.Index(i), dst.Index(i)) {
+			continue
+		}
+		srcVal := m.srcValue(ctx, src.Index(i))
+		if !srcVal.IsValid() && m.mapNil(ctx, dst.Index(i)) {
+			continue
+		}
+		dstVal := m.dstValue(ctx, dst.Index(i))
+		if err := m.mapValue(ctx.enterIndex(i), &mapper, srcVal, dstVal); err != nil {
+			if err := collectError(ctx, &errs, dst.Index(i), indexPathError(err, i)); err != nil {
+				return err
+			}
 		}
 	}
-	return nil
+	return joinErrors(errs)
 }
 
 func mapSliceToArray(m *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes && src.Type() != dst.Type() {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
+	if err := checkLimits(ctx, src); err != nil {
+		return err
+	}
 	if src.Len() != dst.Len() {
 		return NewInvalidMappingError(
 			src.Type(),
@@ -563,69 +785,83 @@ func mapSliceToArray(m *Mapper, ctx *Context, src, dst reflect.Value) error {
 	srcTyp := src.Type().Elem()
 	dstTyp := dst.Type().Elem()
 	mapper := m.mapperFor(ctx, srcTyp, dstTyp)
-	if srcTyp == dstTyp && dst.CanSet() {
+	if srcTyp == dstTyp && dst.CanSet() && len(ctx.InputTransforms) == 0 {
 		reflect.Copy(dst, src)
 		return nil
 	}
+	var errs []error
 	for i := 0; i < src.Len(); i++ {
-		srcVal := m.srcValue(src.Index(i))
-		dstVal := m.dstValue(dst.Index(i))
-		srcValTyp := srcVal.Type()
-		dstValTyp := dstVal.Type()
-		if !mapper.match(srcValTyp, dstValTyp) {
-			mapper = m.mapperFor(ctx, srcValTyp, dstValTyp)
-		}
-		if err := mapper.mapRefl(m, ctx, m.srcValue(src.Index(i)), m.dstValue(dst.Index(i))); err != nil {
-			return err
+		if m.sharedNode(ctx, src.Index(i), dst.Index(i)) {
+			continue
+		}
+		srcVal := m.srcValue(ctx, src.Index(i))
+		if !srcVal.IsValid() && m.mapNil(ctx, dst.Index(i)) {
+			continue
+		}
+		dstVal := m.dstValue(ctx, dst.Index(i))
+		if err := m.mapValue(ctx.enterIndex(i), &mapper, srcVal, dstVal); err != nil {
+			if err := collectError(ctx, &errs, dst.Index(i), indexPathError(err, i)); err != nil {
+				return err
+			}
 		}
 	}
 	for i := src.Len(); i < dst.Len(); i++ {
 		dst.Index(i).Set(reflect.Zero(dst.Type().Elem()))
 	}
-	return nil
+	return joinErrors(errs)
 }
 
 func mapArrayToSlice(m *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes && src.Type() != dst.Type() {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
+	if err := checkLimits(ctx, src); err != nil {
+		return err
+	}
 	srcTyp := src.Type().Elem()
 	dstTyp := dst.Type().Elem()
 	mapper := m.mapperFor(ctx, srcTyp, dstTyp)
-	if srcTyp == dstTyp && dst.CanSet() {
+	if srcTyp == dstTyp && dst.CanSet() && len(ctx.InputTransforms) == 0 {
 		dst.Set(reflect.MakeSlice(dst.Type(), src.Len(), src.Len()))
 		reflect.Copy(dst, src)
-	} else {
-		if src.Len() > dst.Len() {
-			if dst.Cap() >= src.Len() {
-				dst.SetLen(src.Len())
-			} else {
-				dst.Set(reflect.AppendSlice(
-					dst,
-					reflect.MakeSlice(dst.Type(), src.Len()-dst.Len(), src.Len()-dst.Len())),
-				)
-			}
-		}
-		for i := 0; i < src.Len(); i++ {
-			srcVal := m.srcValue(src.Index(i))
-			dstVal := m.dstValue(dst.Index(i))
-			srcValTyp := srcVal.Type()
-			dstValTyp := dstVal.Type()
-			if !mapper.match(srcValTyp, dstValTyp) {
-				mapper = m.mapperFor(ctx, srcValTyp, dstValTyp)
-			}
-			if err := mapper.mapRefl(m, ctx, srcVal, dstVal); err != nil {
+		return nil
+	}
+	if src.Len() > dst.Len() {
+		if dst.Cap() >= src.Len() {
+			dst.SetLen(src.Len())
+		} else {
+			dst.Set(reflect.AppendSlice(
+				dst,
+				reflect.MakeSlice(dst.Type(), src.Len()-dst.Len(), src.Len()-dst.Len())),
+			)
+		}
+	}
+	var errs []error
+	for i := 0; i < src.Len(); i++ {
+		if m.sharedNode(ctx, src.Index(i), dst.Index(i)) {
+			continue
+		}
+		srcVal := m.srcValue(ctx, src.Index(i))
+		if !srcVal.IsValid() && m.mapNil(ctx, dst.Index(i)) {
+			continue
+		}
+		dstVal := m.dstValue(ctx, dst.Index(i))
+		if err := m.mapValue(ctx.enterIndex(i), &mapper, srcVal, dstVal); err != nil {
+			if err := collectError(ctx, &errs, dst.Index(i), indexPathError(err, i)); err != nil {
 				return err
 			}
 		}
 	}
-	return nil
+	return joinErrors(errs)
 }
 
 func mapArrayToArray(m *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes && src.Type() != dst.Type() {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
+	if err := checkLimits(ctx, src); err != nil {
+		return err
+	}
 	if src.Len() != dst.Len() {
 		return NewInvalidMappingError(
 			src.Type(),
@@ -636,55 +872,153 @@ func mapArrayToArray(m *Mapper, ctx *Context, src, dst reflect.Value) error {
 	srcTyp := src.Type().Elem()
 	dstTyp := dst.Type().Elem()
 	mapper := m.mapperFor(ctx, srcTyp, dstTyp)
-	if srcTyp == dstTyp && dst.CanSet() {
+	if srcTyp == dstTyp && dst.CanSet() && len(ctx.InputTransforms) == 0 {
 		reflect.Copy(dst, src)
 		return nil
 	}
+	var errs []error
 	for i := 0; i < src.Len(); i++ {
-		srcVal := m.srcValue(src.Index(i))
-		dstVal := m.dstValue(dst.Index(i))
-		srcValTyp := srcVal.Type()
-		dstValTyp := dstVal.Type()
-		if !mapper.match(srcValTyp, dstValTyp) {
-			mapper = m.mapperFor(ctx, srcValTyp, dstValTyp)
-		}
-		if err := mapper.mapRefl(m, ctx, srcVal, dstVal); err != nil {
-			return err
+		if m.sharedNode(ctx, src.Index(i), dst.Index(i)) {
+			continue
+		}
+		srcVal := m.srcValue(ctx, src.Index(i))
+		if !srcVal.IsValid() && m.mapNil(ctx, dst.Index(i)) {
+			continue
+		}
+		dstVal := m.dstValue(ctx, dst.Index(i))
+		if err := m.mapValue(ctx.enterIndex(i), &mapper, srcVal, dstVal); err != nil {
+			if err := collectError(ctx, &errs, dst.Index(i), indexPathError(err, i)); err != nil {
+				return err
+			}
 		}
 	}
-	return nil
+	return joinErrors(errs)
 }
 
 func mapMapToStruct(m *Mapper, ctx *Context, src, dst reflect.Value) error {
-	mapper := &typeMapper{}
-	dstNum := dst.Type().NumField()
+	var (
+		mapper = &typeMapper{}
+		fields = m.structFields(ctx, dst.Type())
+		dstNum = numFields(dst.Type(), fields)
+		used   map[string]bool
+		folded map[string]reflect.Value
+		errs   []error
+	)
+	if err := checkLimits(ctx, src); err != nil {
+		return err
+	}
+	if m.reportsUnmappedKeys(ctx) {
+		used = make(map[string]bool, dstNum)
+	}
 	for i := 0; i < dstNum; i++ {
-		dstFld := dst.Type().Field(i)
-		if !dstFld.IsExported() {
+		dstFld := fieldAt(dst.Type(), fields, i)
+		if !m.mappedField(dstFld) {
 			continue
 		}
-		tag, skip := m.parseTag(ctx, dstFld)
-		if skip {
+		tag := m.parseTag(ctx, dstFld)
+		if tag.Skip {
 			// If the tag is "-", skip it.
 			continue
 		}
-		srcKey := reflect.ValueOf(tag)
-		srcVal := m.srcValue(src.MapIndex(srcKey))
-		if !srcVal.IsValid() {
+		// Nil pointers to embedded structs are initialized only if one of
+		// their fields is mapped.
+		dstField := fieldByIndex(dst, dstFld.Index, false)
+		key := m.fieldKey(ctx, dstFld, tag.Name, dstField)
+		fctx, ok := ctx.enter(key)
+		if !ok {
+			// Fields excluded from mapping are not reported as unmapped.
+			if used != nil {
+				used[key] = true
+			}
+			continue
+		}
+		srcRaw := src.MapIndex(reflect.ValueOf(key))
+		if !srcRaw.IsValid() {
+			// Try the alternative keys, in the order of the tag.
+			for _, alias := range tag.Aliases {
+				alias = m.fieldKey(ctx, dstFld, alias, dstField)
+				if srcRaw = src.MapIndex(reflect.ValueOf(alias)); srcRaw.IsValid() {
+					if used != nil {
+						used[alias] = true
+					}
+					break
+				}
+			}
+		}
+		if !srcRaw.IsValid() && ctx.CaseInsensitiveKeys {
+			// Try the key and the alternative keys again, ignoring case.
+			for i, k := range append([]string{key}, tag.Aliases...) {
+				if i > 0 {
+					k = m.fieldKey(ctx, dstFld, k, dstField)
+				}
+				var srcKey string
+				if srcRaw, srcKey = foldedIndex(src, &folded, k); srcRaw.IsValid() {
+					if used != nil {
+						used[srcKey] = true
+					}
+					break
+				}
+			}
+		}
+		if !srcRaw.IsValid() && ctx.isTagPath(key) {
+			if v, _, err := m.lookupPath(ctx, src, key); err == nil {
+				srcRaw = v
+				if used != nil {
+					used[pathRoot(key)] = true
+				}
+			}
+		}
+		if !srcRaw.IsValid() {
+			if def, ok := defaultValue(ctx, dstFld); ok {
+				srcRaw = reflect.ValueOf(def)
+			}
+		}
+		if !srcRaw.IsValid() {
 			// If the source map doesn't have a value for the key, skip it.
+			if err := m.missingField(ctx, dstFld, key, dst); err != nil {
+				return err
+			}
 			continue
 		}
-		dstVal := m.dstValue(dst.Field(i))
-		srcValTyp := srcVal.Type()
-		dstValTyp := dstVal.Type()
-		if !mapper.match(srcValTyp, dstValTyp) {
-			mapper = m.mapperFor(ctx, srcValTyp, dstValTyp)
+		if used != nil {
+			used[key] = true
 		}
-		if err := mapper.mapRefl(m, ctx, srcVal, dstVal); err != nil {
-			return err
+		if skipsValue(ctx, srcRaw) {
+			continue
+		}
+		if !dstField.IsValid() {
+			dstField = fieldByIndex(dst, dstFld.Index, true)
+		}
+		if m.keepsValue(ctx, dstField) {
+			continue
+		}
+		if m.sharedNode(ctx, srcRaw, dstField) {
+			continue
+		}
+		srcVal := m.srcValue(ctx, srcRaw)
+		if !srcVal.IsValid() {
+			m.mapNil(ctx, dstField)
+			continue
+		}
+		dstVal := m.dstValue(ctx, dstField)
+		if err := m.mapField(fctx, &mapper, nil, &tag, srcVal, dstVal); err != nil {
+			if err := collectError(ctx, &errs, dstField, fieldPathError(err, key)); err != nil {
+				return err
+			}
 		}
 	}
-	return nil
+	if used != nil {
+		// Report the keys that were not used by any of the struct fields.
+		for _, srcKey := range ctx.mapKeys(src) {
+			if used[srcKey.String()] {
+				continue
+			}
+			if err := m.unmappedKey(ctx, srcKey.String(), src.MapIndex(srcKey)); err != nil {
+				return err
+			}
+		}
+	}
+	return joinErrors(errs)
 }
 
 func mapMapToMap(m *Mapper, ctx *Context, src, dst reflect.Value) error {
@@ -695,47 +1029,80 @@ func mapMapToMap(m *Mapper, ctx *Context, src, dst reflect.Value) error {
 		dstElemTyp = dst.Type().Elem()
 		keyMapper  = m.mapperFor(ctx, srcKeyTyp, dstKeyTyp)
 		elemMapper = m.mapperFor(ctx, srcElemTyp, dstElemTyp)
+		keyCtx     = ctx.keyContext()
 		sameKeys   = srcKeyTyp == dstKeyTyp
+		synced     map[any]bool
+		errs       []error
 	)
-	for _, srcKey := range src.MapKeys() {
+	if err := checkLimits(ctx, src); err != nil {
+		return err
+	}
+	if dst.IsNil() {
+		dst.Set(reflect.MakeMapWithSize(dst.Type(), src.Len()))
+	}
+	if ctx.SyncMaps {
+		synced = make(map[any]bool, src.Len())
+	}
+	for _, srcKey := range ctx.mapKeys(src) {
 		dstKey := srcKey
 		if !sameKeys {
 			dstKey = reflect.New(dstKeyTyp).Elem()
-			if err := keyMapper.mapRefl(m, ctx, m.srcValue(srcKey), m.dstValue(dstKey)); err != nil {
-				return NewInvalidMappingError(srcKey.Type(), dstKeyTyp, "unable to map key")
+			if err := keyMapper.mapRefl(m, keyCtx, m.srcValue(ctx, srcKey), m.dstValue(ctx, dstKey)); err != nil {
+				err = NewInvalidMappingError(srcKey.Type(), dstKeyTyp, "unable to map key")
+				if err := collectError(ctx, &errs, reflect.Value{}, keyPathError(err, srcKey)); err != nil {
+					return err
+				}
+				continue
 			}
 		}
-		srcVal := m.srcValue(src.MapIndex(srcKey))
-		dstVal := m.dstValue(dst.MapIndex(dstKey))
+		if synced != nil {
+			synced[dstKey.Interface()] = true
+		}
+		ectx := ctx
+		if ctx.tracksPaths() {
+			var ok bool
+			if ectx, ok = ctx.enterKey(dstKey); !ok {
+				continue
+			}
+		}
+		srcElem := src.MapIndex(srcKey)
+		if skipsValue(ctx, srcElem) || (ctx.NoOverwrite && m.keepsValue(ctx, dst.MapIndex(dstKey))) {
+			continue
+		}
+		srcVal := m.srcValue(ctx, srcElem)
+		if !srcVal.IsValid() && m.mapNilEntry(ctx, dst, dstKey) {
+			continue
+		}
+		dstVal := m.dstValue(ctx, dst.MapIndex(dstKey))
 		if dstVal.IsValid() {
 			// If the destination map already has a value for the key.
-			srcValTyp := srcVal.Type()
-			dstValTyp := dstVal.Type()
-			if !elemMapper.match(srcValTyp, dstValTyp) {
-				elemMapper = m.mapperFor(ctx, srcValTyp, dstValTyp)
-			}
-			if err := elemMapper.mapRefl(m, ctx, srcVal, dstVal); err != nil {
-				return err
+			if err := m.mapValue(ectx, &elemMapper, srcVal, dstVal); err != nil {
+				if err := collectError(ctx, &errs, reflect.Value{}, keyPathError(err, dstKey)); err != nil {
+					return err
+				}
 			}
 		} else {
 			// If the destination map doesn't have a value for the key.
 			newVal := reflect.New(dstElemTyp).Elem()
-			dstVal := m.dstValue(newVal)
-			srcValTyp := srcVal.Type()
-			dstValTyp := dstVal.Type()
-			if !dstVal.IsValid() {
+			if ctx.state != nil && ctx.state.nodes != nil && m.sharedNode(ctx, srcElem, newVal) {
+				dst.SetMapIndex(dstKey, newVal)
 				continue
 			}
-			if !elemMapper.match(srcValTyp, dstValTyp) {
-				elemMapper = m.mapperFor(ctx, srcValTyp, dstValTyp)
+			dstVal := m.dstValue(ctx, newVal)
+			if !dstVal.IsValid() {
+				continue
 			}
-			if err := elemMapper.mapRefl(m, ctx, srcVal, dstVal); err != nil {
-				return err
+			if err := m.mapValue(ectx, &elemMapper, srcVal, dstVal); err != nil {
+				if err := collectError(ctx, &errs, reflect.Value{}, keyPathError(err, dstKey)); err != nil {
+					return err
+				}
+				continue
 			}
 			dst.SetMapIndex(dstKey, newVal)
 		}
 	}
-	return nil
+	deleteStaleKeys(ctx, dst, synced)
+	return joinErrors(errs)
 }
 
 func mapStructsOfSameType(m *Mapper, ctx *Context, src, dst reflect.Value) error {
@@ -743,130 +1110,721 @@ func mapStructsOfSameType(m *Mapper, ctx *Context, src, dst reflect.Value) error
 		mapper = &typeMapper{}
 		srcTyp = src.Type()
 		srcNum = src.NumField()
+		rules  = m.fieldRules(srcTyp, srcTyp)
+		errs   []error
 	)
 	for i := 0; i < srcNum; i++ {
 		srcFld := srcTyp.Field(i)
-		if !srcFld.IsExported() {
+		if !m.mappedField(srcFld) || ruleField(rules, srcFld.Name, true) {
 			continue
 		}
-		if _, skip := m.parseTag(ctx, srcFld); skip {
+		tag := m.parseTag(ctx, srcFld)
+		if tag.Skip {
 			// If the tag is "-", skip it.
 			continue
 		}
-		srcVal := m.srcValue(src.Field(i))
-		dstVal := m.dstValue(dst.Field(i))
-		srcValTyp := srcVal.Type()
-		dstValTyp := dstVal.Type()
-		if !mapper.match(srcValTyp, dstValTyp) {
-			mapper = m.mapperFor(ctx, srcValTyp, dstValTyp)
+		fctx, ok := ctx.enter(tag.Name)
+		if !ok || skipsValue(ctx, src.Field(i)) || m.keepsValue(ctx, dst.Field(i)) {
+			continue
 		}
-		if err := mapper.mapRefl(m, ctx, srcVal, dstVal); err != nil {
-			return err
+		if m.sharedNode(ctx, src.Field(i), dst.Field(i)) {
+			continue
+		}
+		srcVal := m.srcValue(ctx, src.Field(i))
+		if !srcVal.IsValid() {
+			continue
+		}
+		dstVal := m.dstValue(ctx, dst.Field(i))
+		if err := m.mapField(fctx, &mapper, &tag, &tag, srcVal, dstVal); err != nil {
+			if err := collectError(ctx, &errs, dst.Field(i), fieldPathError(err, tag.Name)); err != nil {
+				return err
+			}
 		}
 	}
-	return nil
+	if err := m.applyFieldRules(ctx, rules, src, dst, &errs); err != nil {
+		return err
+	}
+	return joinErrors(errs)
+}
+
+// deleteFieldKeys removes the key of the field and its aliases from valMap,
+// so that none of them is reported as unmapped or mapped again by path.
+func deleteFieldKeys(valMap map[string]fieldValue, tag *structTag) {
+	delete(valMap, tag.Name)
+	for _, alias := range tag.Aliases {
+		delete(valMap, alias)
+	}
 }
 
 func mapStructsOfDifferentTypes(m *Mapper, ctx *Context, src, dst reflect.Value) error {
 	var (
-		mapper = &typeMapper{}
-		srcTyp = src.Type()
-		dstTyp = dst.Type()
-		srcNum = srcTyp.NumField()
-		dstNum = dstTyp.NumField()
-		valMap = map[string]reflect.Value{}
+		mapper    = &typeMapper{}
+		srcTyp    = src.Type()
+		dstTyp    = dst.Type()
+		srcFields = m.structFields(ctx, srcTyp)
+		dstFields = m.structFields(ctx, dstTyp)
+		srcNum    = numFields(srcTyp, srcFields)
+		dstNum    = numFields(dstTyp, dstFields)
+		rules     = m.fieldRules(srcTyp, dstTyp)
+		valMap    = map[string]fieldValue{}
+		keys      []string
+		paths     []string
+		roots     map[string]bool
+		errs      []error
 	)
 	// Map the source struct to a map of values.
 	for i := 0; i < srcNum; i++ {
-		srcVal := src.Field(i)
-		srcFld := srcTyp.Field(i)
-		if !srcFld.IsExported() {
+		srcFld := fieldAt(srcTyp, srcFields, i)
+		if !m.mappedField(srcFld) {
 			continue
 		}
-		tag, skip := m.parseTag(ctx, srcFld)
-		if skip {
+		srcVal := fieldByIndex(src, srcFld.Index, false)
+		if !srcVal.IsValid() {
+			// Fields of nil embedded structs are skipped.
+			continue
+		}
+		tag := m.parseTag(ctx, srcFld)
+		if tag.Skip {
 			continue
 		}
-		valMap[tag] = srcVal
+		valMap[tag.Name] = fieldValue{tag: tag, val: srcVal}
+		if ctx.isTagPath(tag.Name) {
+			paths = append(paths, tag.Name)
+		}
+		if m.reportsUnmappedKeys(ctx) && !ruleField(rules, srcFld.Name, false) {
+			keys = append(keys, tag.Name)
+		}
 	}
 	// Map the values to the destination struct.
 	for i := 0; i < dstNum; i++ {
-		dstFld := dst.Type().Field(i)
-		if !dstFld.IsExported() {
+		dstFld := fieldAt(dstTyp, dstFields, i)
+		if !m.mappedField(dstFld) || ruleField(rules, dstFld.Name, true) {
 			continue
 		}
-		tag, skip := m.parseTag(ctx, dstFld)
-		if skip {
+		tag := m.parseTag(ctx, dstFld)
+		if tag.Skip {
 			// If the tag is "-", skip it.
 			continue
 		}
-		var srcVal reflect.Value
-		if val, ok := valMap[tag]; ok {
-			srcVal = m.srcValue(val)
-		} else {
+		fctx, ok := ctx.enter(tag.Name)
+		if !ok {
+			// Fields excluded from mapping are not reported as unmapped.
+			deleteFieldKeys(valMap, &tag)
+			continue
+		}
+		fv, ok := valMap[tag.Name]
+		for _, alias := range tag.Aliases {
+			if ok {
+				break
+			}
+			fv, ok = valMap[alias]
+		}
+		if !ok && ctx.isTagPath(tag.Name) {
+			if v, secret, err := m.lookupPath(ctx, src, tag.Name); err == nil {
+				fv, ok = fieldValue{tag: structTag{Secret: secret}, val: v}, true
+				if roots == nil {
+					roots = map[string]bool{}
+				}
+				roots[pathRoot(tag.Name)] = true
+			}
+		}
+		if !ok {
+			if def, hasDef := defaultValue(ctx, dstFld); hasDef {
+				fv, ok = fieldValue{val: reflect.ValueOf(def)}, true
+			}
+		}
+		if !ok {
 			// If the source struct doesn't have a value for the key, skip it.
+			if err := m.missingField(ctx, dstFld, tag.Name, dst); err != nil {
+				return err
+			}
 			continue
 		}
-		dstVal := m.dstValue(dst.Field(i))
-		srcValTyp := srcVal.Type()
-		dstValTyp := dstVal.Type()
-		if !mapper.match(srcValTyp, dstValTyp) {
-			mapper = m.mapperFor(ctx, srcValTyp, dstValTyp)
+		deleteFieldKeys(valMap, &tag)
+		if skipsValue(ctx, fv.val) {
+			continue
 		}
-		if err := mapper.mapRefl(m, ctx, srcVal, dstVal); err != nil {
-			return err
+		dstField := fieldByIndex(dst, dstFld.Index, true)
+		if m.keepsValue(ctx, dstField) {
+			continue
+		}
+		if m.sharedNode(ctx, fv.val, dstField) {
+			continue
+		}
+		srcVal := m.srcValue(ctx, fv.val)
+		if !srcVal.IsValid() {
+			continue
+		}
+		dstVal := m.dstValue(ctx, dstField)
+		if err := m.mapField(fctx, &mapper, &fv.tag, &tag, srcVal, dstVal); err != nil {
+			if err := collectError(ctx, &errs, dstField, fieldPathError(err, tag.Name)); err != nil {
+				return err
+			}
 		}
 	}
-	return nil
+	// Source fields with paths that were not used by any of the destination
+	// fields are mapped to the nested destination fields.
+	for _, path := range paths {
+		fv, ok := valMap[path]
+		if !ok {
+			continue
+		}
+		fctx, ok := ctx.enter(path)
+		if !ok {
+			continue
+		}
+		if skipsValue(ctx, fv.val) {
+			delete(valMap, path)
+			continue
+		}
+		srcVal := m.srcValue(ctx, fv.val)
+		if !srcVal.IsValid() {
+			continue
+		}
+		err := m.setPath(fctx, dst, splitPath(path), path, fv.tag.Secret, func(v reflect.Value, _ bool) error {
+			return m.mapField(fctx, &mapper, &fv.tag, nil, srcVal, m.dstValue(fctx, v))
+		})
+		if errors.Is(err, InvalidPathErr) {
+			// The destination struct doesn't have the nested field.
+			continue
+		}
+		delete(valMap, path)
+		if err != nil {
+			if err := collectError(ctx, &errs, reflect.Value{}, fieldPathError(err, path)); err != nil {
+				return err
+			}
+		}
+	}
+	if m.reportsUnmappedKeys(ctx) {
+		// Report the source fields that were not used by any of the
+		// destination fields. Used fields were removed from valMap.
+		for _, key := range keys {
+			fv, ok := valMap[key]
+			if !ok || roots[key] {
+				continue
+			}
+			if err := m.unmappedKey(ctx, key, fv.val); err != nil {
+				return err
+			}
+		}
+	}
+	if err := m.applyFieldRules(ctx, rules, src, dst, &errs); err != nil {
+		return err
+	}
+	return joinErrors(errs)
 }
 
 func mapStructToMap(m *Mapper, ctx *Context, src, dst reflect.Value) error {
 	var (
 		mapper     = &typeMapper{}
-		srcNum     = src.Type().NumField()
+		fields     = m.structFields(ctx, src.Type())
+		srcNum     = numFields(src.Type(), fields)
 		dstElemTyp = dst.Type().Elem()
+		synced     map[any]bool
+		errs       []error
 	)
+	if dst.IsNil() {
+		dst.Set(reflect.MakeMapWithSize(dst.Type(), srcNum))
+	}
+	if ctx.SyncMaps {
+		synced = make(map[any]bool, srcNum)
+	}
 	for i := 0; i < srcNum; i++ {
-		srcFld := src.Type().Field(i)
-		if !srcFld.IsExported() {
+		srcFld := fieldAt(src.Type(), fields, i)
+		if !m.mappedField(srcFld) {
 			continue
 		}
-		tag, skip := m.parseTag(ctx, srcFld)
-		if skip {
+		tag := m.parseTag(ctx, srcFld)
+		if tag.Skip || (tag.Secret && ctx.SkipSecrets) {
 			// If the tag is "-", skip it.
 			continue
 		}
-		dstKey := reflect.ValueOf(tag)
-		srcVal := m.srcValue(src.Field(i))
-		dstVal := m.dstValue(dst.MapIndex(dstKey))
+		srcField := fieldByIndex(src, srcFld.Index, false)
+		if !srcField.IsValid() {
+			// Fields of nil embedded structs are skipped.
+			continue
+		}
+		if (tag.OmitEmpty || ctx.OmitEmpty) && isEmptyValue(srcField) {
+			continue
+		}
+		key := m.fieldKey(ctx, srcFld, tag.Name, srcField)
+		fctx, ok := ctx.enter(key)
+		if !ok {
+			continue
+		}
+		dstKey := reflect.ValueOf(key)
+		srcVal := m.srcValue(ctx, srcField)
+		if !srcVal.IsValid() {
+			continue
+		}
+		if ctx.isTagPath(key) {
+			if synced != nil {
+				synced[pathRoot(key)] = true
+			}
+			if skipsValue(ctx, srcField) {
+				continue
+			}
+			err := m.setPath(fctx, dst, splitPath(key), key, tag.Secret, func(v reflect.Value, _ bool) error {
+				return m.mapField(fctx, &mapper, &tag, nil, srcVal, m.dstValue(fctx, v))
+			})
+			if err != nil {
+				if err := collectError(ctx, &errs, reflect.Value{}, fieldPathError(err, key)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if synced != nil {
+			synced[dstKey.Interface()] = true
+		}
+		if skipsValue(ctx, srcField) || (ctx.NoOverwrite && m.keepsValue(ctx, dst.MapIndex(dstKey))) {
+			continue
+		}
+		dstVal := m.dstValue(ctx, dst.MapIndex(dstKey))
 		if dstVal.IsValid() {
 			// If the destination map already has a value for the key.
-			srcValTyp := srcVal.Type()
-			dstValTyp := dstVal.Type()
-			if !mapper.match(srcValTyp, dstValTyp) {
-				mapper = m.mapperFor(ctx, srcValTyp, dstValTyp)
-			}
-			if err := mapper.mapRefl(m, ctx, srcVal, dstVal); err != nil {
-				return err
+			if err := m.mapField(fctx, &mapper, &tag, nil, srcVal, dstVal); err != nil {
+				if err := collectError(ctx, &errs, reflect.Value{}, fieldPathError(err, key)); err != nil {
+					return err
+				}
 			}
 		} else {
 			// If the destination map doesn't have a value for the key.
 			newVal := reflect.New(dstElemTyp).Elem()
-			dstVal := m.dstValue(newVal)
-			srcValTyp := srcVal.Type()
-			dstValTyp := dstVal.Type()
-			if !dstVal.IsValid() {
+			if m.sharedNode(ctx, srcField, newVal) {
+				dst.SetMapIndex(dstKey, newVal)
 				continue
 			}
-			if !mapper.match(srcValTyp, dstValTyp) {
-				mapper = m.mapperFor(ctx, srcValTyp, dstValTyp)
+			dstVal := m.dstValue(ctx, newVal)
+			if !dstVal.IsValid() {
+				continue
 			}
-			if err := mapper.mapRefl(m, ctx, srcVal, dstVal); err != nil {
-				return err
+			if err := m.mapField(fctx, &mapper, &tag, nil, srcVal, dstVal); err != nil {
+				if err := collectError(ctx, &errs, reflect.Value{}, fieldPathError(err, key)); err != nil {
+					return err
+				}
+				continue
 			}
 			dst.SetMapIndex(dstKey, newVal)
 		}
 	}
+	deleteStaleKeys(ctx, dst, synced)
+	return joinErrors(errs)
+}
+
+// deleteStaleKeys deletes the keys of dst that are not in synced, so the
+// destination map contains only the keys mapped from the source. Keys of
+// destination values excluded from the mapping using Context.Fields or
+// Context.ExcludeFields are kept. If synced is nil, nothing is deleted.
+func deleteStaleKeys(ctx *Context, dst reflect.Value, synced map[any]bool) {
+	if synced == nil {
+		return
+	}
+	for _, key := range dst.MapKeys() {
+		if synced[key.Interface()] {
+			continue
+		}
+		if ctx.tracksPaths() {
+			if _, ok := ctx.enterKey(key); !ok {
+				continue
+			}
+		}
+		dst.SetMapIndex(key, reflect.Value{})
+	}
+}
+
+// isEmptyValue returns true if the value is considered empty by the
+// omitempty option. It follows the same rules as the encoding/json package.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+// fieldValue is a value of a struct field along with its parsed tag.
+type fieldValue struct {
+	tag structTag
+	val reflect.Value
+}
+
+// mapField maps a struct field or map value using mapValue and applies
+// the tag options of the source and destination fields. Tags are nil if
+// the corresponding value is not a struct field.
+func (m *Mapper) mapField(ctx *Context, tm **typeMapper, srcTag, dstTag *structTag, src, dst reflect.Value) error {
+	var err error
+	if fn := m.pathMapFunc(ctx); fn != nil && src.IsValid() && dst.IsValid() {
+		if err = fn(m, ctx, src, dst); err != nil && secret(srcTag, dstTag) {
+			return redactError(src.Type(), dst.Type(), err)
+		}
+		return err
+	}
+	if conv := converter(srcTag, dstTag); conv != "" && src.IsValid() && dst.IsValid() {
+		val, err := m.convert(ctx, conv, src, dst)
+		if err != nil {
+			if secret(srcTag, dstTag) {
+				return redactError(src.Type(), dst.Type(), err)
+			}
+			return err
+		}
+		if src = val; !src.IsValid() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+	}
+	if srcTag != nil && srcTag.Float16 {
+		src = float16Source(src, dst)
+	}
+	if dstTag != nil && dstTag.Float16 {
+		dst = asFloat16(dst)
+	}
+	if enc := encoding(srcTag, dstTag); enc != "" && enc != ctx.StringEncoding {
+		ctx = ctx.WithStringEncoding(enc)
+	}
+	if l := layout(srcTag, dstTag); l != "" && l != ctx.TimeLayout {
+		ctx = ctx.WithTimeLayout(l)
+	}
+	switch width, pad := padding(srcTag, dstTag); {
+	case width == invalidPadding && src.IsValid() && dst.IsValid():
+		err = NewInvalidMappingError(src.Type(), dst.Type(), "invalid width or pad tag option")
+	case width > 0 && src.IsValid() && dst.IsValid():
+		err = m.mapPadded(ctx, tm, width, pad, src, dst)
+	default:
+		err = m.mapValue(ctx, tm, src, dst)
+	}
+	if err != nil && secret(srcTag, dstTag) && src.IsValid() && dst.IsValid() {
+		return redactError(src.Type(), dst.Type(), err)
+	}
+	return err
+}
+
+// mapPadded maps src to dst using the width and pad tag options. Integers
+// mapped to strings, or to the any type, are padded, and padding is removed
+// from strings mapped to integers. Other values are mapped as usual.
+func (m *Mapper) mapPadded(ctx *Context, tm **typeMapper, width int, pad byte, src, dst reflect.Value) error {
+	if src.Kind() == reflect.Interface && !src.IsNil() {
+		src = src.Elem()
+	}
+	switch {
+	case isIntKind(src.Kind()) && dst.Kind() == reflect.String:
+		if err := m.mapValue(ctx, tm, src, dst); err != nil {
+			return err
+		}
+		dst.SetString(padNumber(dst.String(), width, pad))
+		return nil
+	case isIntKind(src.Kind()) && dst.Type() == anyTy:
+		var s string
+		if err := m.mapNested(ctx, src, &s); err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(padNumber(s, width, pad)))
+		return nil
+	case src.Kind() == reflect.String && isIntKind(dst.Kind()):
+		src = reflect.ValueOf(unpadNumber(src.String(), pad)).Convert(src.Type())
+	}
+	return m.mapValue(ctx, tm, src, dst)
+}
+
+// isIntKind returns true for signed and unsigned integer kinds.
+func isIntKind(k reflect.Kind) bool {
+	return (k >= reflect.Int && k <= reflect.Int64) || (k >= reflect.Uint && k <= reflect.Uint64)
+}
+
+// mapValue maps src to dst using the given typeMapper. If the typeMapper
+// does not match the types of the values, a new one is found and stored in
+// tm, so it can be reused for the next values. The src and dst values must
+// be already unpacked using srcValue and dstValue.
+func (m *Mapper) mapValue(ctx *Context, tm **typeMapper, src, dst reflect.Value) error {
+	if !src.IsValid() {
+		return InvalidSrcErr
+	}
+	if !dst.IsValid() {
+		return InvalidDstErr
+	}
+	srcTyp := src.Type()
+	dstTyp := dst.Type()
+	if !(*tm).match(srcTyp, dstTyp) {
+		*tm = m.mapperFor(ctx, srcTyp, dstTyp)
+	}
+	return (*tm).mapRefl(m, ctx, src, dst)
+}
+
+// parseInput returns the string that should be parsed into a number, bool,
+// time or big number. If Context.TrimStrings is enabled, surrounding
+// whitespace and quotes are removed.
+func (c *Context) parseInput(s string) string {
+	if !c.TrimStrings {
+		return s
+	}
+	s = strings.TrimSpace(s)
+	if n := len(s); n >= 2 && (s[0] == '"' || s[0] == '\'') && s[n-1] == s[0] {
+		s = strings.TrimSpace(s[1 : n-1])
+	}
+	return s
+}
+
+// setStringBytes sets dst to the string represented by b. Numbers are
+// formatted into stack buffers, so the only allocation is the resulting
+// string.
+func setStringBytes(dst reflect.Value, b []byte) {
+	dst.SetString(string(b))
+}
+
+// collectError handles an error that occurred while mapping a single element
+// of a slice, array, map or struct. In the best-effort mode, the destination
+// element is reset to its zero value, the error is appended to errs and nil
+// is returned, so the mapping can continue. Otherwise, the error is returned.
+//
+// If dst is invalid, the destination element is left unchanged. Errors
+// caused by exceeded limits always stop the mapping.
+func collectError(ctx *Context, errs *[]error, dst reflect.Value, err error) error {
+	if !ctx.BestEffort || errors.Is(err, LimitExceededErr) {
+		return err
+	}
+	if merr, ok := err.(MappingErrors); ok {
+		// Nested element was mapped partially, keep what was mapped.
+		*errs = append(*errs, merr...)
+		return nil
+	}
+	if dst.IsValid() && dst.CanSet() {
+		dst.Set(reflect.Zero(dst.Type()))
+	}
+	*errs = append(*errs, err)
+	return nil
+}
+
+// redactError replaces an error that occurred during mapping of a secret
+// value with an error that does not contain any details that could expose
+// the value.
+func redactError(src, dst reflect.Type, err error) error {
+	switch terr := err.(type) {
+	case MappingErrors:
+		errs := make(MappingErrors, len(terr))
+		for i, err := range terr {
+			errs[i] = redactError(src, dst, err)
+		}
+		return errs
+	case *InvalidMappingErr:
+		return &InvalidMappingErr{From: terr.From, To: terr.To, Path: terr.Path, Reason: "secret value", redacted: true}
+	}
+	return &InvalidMappingErr{From: src, To: dst, Reason: "secret value", redacted: true}
+}
+
+// fieldPathError prepends the key of a struct field or a map entry to the
+// paths of the InvalidMappingErr errors contained in err, and returns err.
+func fieldPathError(err error, key string) error {
+	return PrependErrorPath(err, key)
+}
+
+// indexPathError prepends the index of a slice or array element to the
+// paths of the InvalidMappingErr errors contained in err, and returns err.
+func indexPathError(err error, i int) error {
+	return PrependErrorPath(err, "["+strconv.Itoa(i)+"]")
+}
+
+// keyPathError prepends the map key to the paths of the InvalidMappingErr
+// errors contained in err, and returns err. String keys are used as field
+// keys, other keys are formatted in brackets, like indexes.
+func keyPathError(err error, key reflect.Value) error {
+	if key.Kind() == reflect.Interface {
+		key = key.Elem()
+	}
+	if key.Kind() == reflect.String {
+		return PrependErrorPath(err, key.String())
+	}
+	return PrependErrorPath(err, fmt.Sprintf("[%v]", key))
+}
+
+// snapshotValue returns a copy of the error with the InvalidMappingErr.Value
+// field set to a printable representation of the source value, truncated to
+// the length set in Context.ValueSnapshotLen. Errors that already have a
+// value, or were redacted, are returned unchanged. The error itself is not
+// modified, because it may be shared between mapping calls.
+func snapshotValue(ctx *Context, err error, src reflect.Value) error {
+	e, ok := err.(*InvalidMappingErr)
+	if !ok || e.redacted || e.Value != "" || !src.IsValid() || !src.CanInterface() {
+		return err
+	}
+	var s string
+	if src.Kind() == reflect.String {
+		s = strconv.Quote(src.String())
+	} else {
+		s = fmt.Sprintf("%v", src.Interface())
+	}
+	if r := []rune(s); len(r) > ctx.ValueSnapshotLen {
+		s = string(r[:ctx.ValueSnapshotLen]) + "..."
+	}
+	cpy := *e
+	cpy.Value = s
+	return &cpy
+}
+
+// joinErrors returns the errors as MappingErrors or nil if there are no
+// errors.
+func joinErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return MappingErrors(errs)
+}
+
+// enter returns the context used to map the destination value stored under
+// the given key, and reports whether the value should be mapped at all. If
+// the context does not need to track paths, it is returned as is.
+func (c *Context) enter(key string) (*Context, bool) {
+	if !c.tracksPaths() {
+		return c, true
+	}
+	fieldPath := key
+	if c.fieldPath != "" {
+		fieldPath = c.fieldPath + "." + key
+	}
+	if len(c.Fields) > 0 && !pathSelected(c.Fields, fieldPath) {
+		return nil, false
+	}
+	if pathExcluded(c.ExcludeFields, fieldPath) {
+		return nil, false
+	}
+	cpy := *c
+	cpy.path = appendPath(c.path, key)
+	cpy.fieldPath = fieldPath
+	return &cpy, true
+}
+
+// enterIndex returns the context used to map the slice or array element at
+// the given index. Elements share the field path of their parent, so they
+// are never excluded from the mapping.
+func (c *Context) enterIndex(i int) *Context {
+	if !c.tracksPaths() {
+		return c
+	}
+	cpy := *c
+	cpy.path = appendPath(c.path, "["+strconv.Itoa(i)+"]")
+	return &cpy
+}
+
+// enterKey returns the context used to map the map value stored under the
+// given key, and reports whether the value should be mapped at all. String
+// keys are used as field keys, values of other keys are handled like slice
+// elements.
+func (c *Context) enterKey(key reflect.Value) (*Context, bool) {
+	if key.Kind() == reflect.Interface {
+		key = key.Elem()
+	}
+	if key.Kind() == reflect.String {
+		return c.enter(key.String())
+	}
+	if !c.tracksPaths() {
+		return c, true
+	}
+	cpy := *c
+	cpy.path = appendPath(c.path, fmt.Sprintf("[%v]", key))
+	return &cpy, true
+}
+
+// appendPath appends the path element to the path, in the format used by
+// InvalidMappingErr.Path.
+func appendPath(path, elem string) string {
+	switch {
+	case path == "":
+		return elem
+	case elem[0] == '[':
+		return path + elem
+	default:
+		return path + "." + elem
+	}
+}
+
+// tracksPaths reports whether the paths of mapped values are needed.
+func (c *Context) tracksPaths() bool {
+	return c.pathMappers || len(c.Fields) > 0 || len(c.ExcludeFields) > 0
+}
+
+// pathSelected reports whether the path is listed in fields, is nested in
+// one of the listed paths, or is a parent of one of them.
+func pathSelected(fields []string, path string) bool {
+	for _, f := range fields {
+		if f == path || strings.HasPrefix(f, path+".") || strings.HasPrefix(path, f+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// pathExcluded reports whether the path is listed in fields or is nested
+// in one of the listed paths.
+func pathExcluded(fields []string, path string) bool {
+	for _, f := range fields {
+		if f == path || strings.HasPrefix(path, f+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldKey returns the map key for the given struct field. If the KeyHook
+// is set, it is used to rewrite the key. The KeyHook of the context
+// receives the key returned by the KeyHook of the mapper.
+func (m *Mapper) fieldKey(ctx *Context, fld reflect.StructField, key string, val reflect.Value) string {
+	if m.Hooks.KeyHook != nil {
+		key = m.Hooks.KeyHook(m, ctx, fld, key, val)
+	}
+	if ctx.Hooks != nil && ctx.Hooks.KeyHook != nil {
+		key = ctx.Hooks.KeyHook(m, ctx, fld, key, val)
+	}
+	return key
+}
+
+// missingField calls the MissingFieldHook of the mapper and then of the
+// context, if they are set, for a destination struct field that has no
+// corresponding value in the source. The dst argument is the struct that
+// contains the field. Nil pointers to embedded structs that contain the
+// field are initialized only if a hook is set.
+func (m *Mapper) missingField(ctx *Context, fld reflect.StructField, key string, dst reflect.Value) error {
+	hasCtxHook := ctx.Hooks != nil && ctx.Hooks.MissingFieldHook != nil
+	if m.Hooks.MissingFieldHook == nil && !hasCtxHook {
+		return nil
+	}
+	dst = fieldByIndex(dst, fld.Index, true)
+	if m.Hooks.MissingFieldHook != nil {
+		if err := m.Hooks.MissingFieldHook(m, ctx, fld, key, dst); err != nil {
+			return err
+		}
+	}
+	if hasCtxHook {
+		return ctx.Hooks.MissingFieldHook(m, ctx, fld, key, dst)
+	}
+	return nil
+}
+
+// reportsUnmappedKeys reports whether the UnmappedKeyHook is set in the
+// mapper or in the context.
+func (m *Mapper) reportsUnmappedKeys(ctx *Context) bool {
+	return m.Hooks.UnmappedKeyHook != nil || (ctx.Hooks != nil && ctx.Hooks.UnmappedKeyHook != nil)
+}
+
+// unmappedKey calls the UnmappedKeyHook of the mapper and then of the
+// context, if they are set, for a source key that was not mapped.
+func (m *Mapper) unmappedKey(ctx *Context, key string, src reflect.Value) error {
+	if m.Hooks.UnmappedKeyHook != nil {
+		if err := m.Hooks.UnmappedKeyHook(m, ctx, key, src); err != nil {
+			return err
+		}
+	}
+	if ctx.Hooks != nil && ctx.Hooks.UnmappedKeyHook != nil {
+		return ctx.Hooks.UnmappedKeyHook(m, ctx, key, src)
+	}
 	return nil
 }
 
@@ -881,24 +1839,58 @@ func numberToBytes(ctx *Context, src, dst reflect.Value) error {
 	case reflect.Uint:
 		src = reflect.ValueOf(src.Uint())
 	}
-	var buf bytes.Buffer
-	if err := binary.Write(&buf, ctx.ByteOrder, src.Interface()); err != nil {
-		return NewInvalidMappingError(src.Type(), dst.Type(), err.Error())
+	var (
+		b       []byte
+		err     error
+		handled bool // b was encoded by NumberCodec or in minimal form
+	)
+	switch src.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch {
+		case ctx.NumberCodec != nil:
+			b, err = ctx.NumberCodec.EncodeInt(src.Int())
+			handled = true
+		case ctx.MinimalBytes && dst.Kind() == reflect.Slice:
+			if src.Int() < 0 {
+				return NewInvalidMappingError(src.Type(), dst.Type(), "cannot encode negative number in minimal form")
+			}
+			b = minimalBytes(uint64(src.Int()))
+			handled = true
+		}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch {
+		case ctx.NumberCodec != nil:
+			b, err = ctx.NumberCodec.EncodeUint(src.Uint())
+			handled = true
+		case ctx.MinimalBytes && dst.Kind() == reflect.Slice:
+			b = minimalBytes(src.Uint())
+			handled = true
+		}
+	}
+	if err != nil {
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	if !handled {
+		var buf bytes.Buffer
+		if err := binary.Write(&buf, ctx.ByteOrder, src.Interface()); err != nil {
+			return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+		}
+		b = buf.Bytes()
 	}
 	switch dst.Kind() {
 	case reflect.Slice:
 		if dst.Type().Elem().Kind() != reflect.Uint8 {
 			return NewInvalidMappingError(src.Type(), dst.Type(), "")
 		}
-		dst.SetBytes(buf.Bytes())
+		dst.SetBytes(b)
 	case reflect.Array:
 		if dst.Type().Elem().Kind() != reflect.Uint8 {
 			return NewInvalidMappingError(src.Type(), dst.Type(), "")
 		}
-		if dst.Len() != buf.Len() {
+		if dst.Len() != len(b) {
 			return NewInvalidMappingError(src.Type(), dst.Type(), "invalid array length")
 		}
-		reflect.Copy(dst, reflect.ValueOf(buf.Bytes()))
+		reflect.Copy(dst, reflect.ValueOf(b))
 	default:
 		return NewInvalidMappingError(src.Type(), dst.Type(), "")
 	}
@@ -907,6 +1899,37 @@ func numberToBytes(ctx *Context, src, dst reflect.Value) error {
 
 // numberFromBytes converts a byte slice to an int ot uint using binary.Read.
 func numberFromBytes(ctx *Context, src []byte, dst reflect.Value) error {
+	if ctx.NumberCodec != nil {
+		switch dst.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			v, err := ctx.NumberCodec.DecodeInt(src)
+			if err != nil {
+				return WrapInvalidMappingError(reflect.TypeOf(src), dst.Type(), err)
+			}
+			if dst.OverflowInt(v) {
+				return NewInvalidMappingError(reflect.TypeOf(src), dst.Type(), "overflow")
+			}
+			dst.SetInt(v)
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			v, err := ctx.NumberCodec.DecodeUint(src)
+			if err != nil {
+				return WrapInvalidMappingError(reflect.TypeOf(src), dst.Type(), err)
+			}
+			if dst.OverflowUint(v) {
+				return NewInvalidMappingError(reflect.TypeOf(src), dst.Type(), "overflow")
+			}
+			dst.SetUint(v)
+			return nil
+		}
+	}
+	if ctx.MinimalBytes {
+		switch dst.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return minimalNumberFromBytes(src, dst)
+		}
+	}
 	if len(src) != int(dst.Type().Size()) {
 		return NewInvalidMappingError(reflect.TypeOf(src), dst.Type(), "invalid byte slice length")
 	}
@@ -914,7 +1937,7 @@ func numberFromBytes(ctx *Context, src []byte, dst reflect.Value) error {
 	case reflect.Int:
 		var v int64
 		if err := binary.Read(bytes.NewReader(src), ctx.ByteOrder, &v); err != nil {
-			return NewInvalidMappingError(reflect.TypeOf(src), dst.Type(), err.Error())
+			return WrapInvalidMappingError(reflect.TypeOf(src), dst.Type(), err)
 		}
 		if dst.OverflowInt(v) {
 			return NewInvalidMappingError(reflect.TypeOf(src), dst.Type(), "overflow")
@@ -923,7 +1946,7 @@ func numberFromBytes(ctx *Context, src []byte, dst reflect.Value) error {
 	case reflect.Uint:
 		var v uint64
 		if err := binary.Read(bytes.NewReader(src), ctx.ByteOrder, &v); err != nil {
-			return NewInvalidMappingError(reflect.TypeOf(src), dst.Type(), err.Error())
+			return WrapInvalidMappingError(reflect.TypeOf(src), dst.Type(), err)
 		}
 		if dst.OverflowUint(v) {
 			return NewInvalidMappingError(reflect.TypeOf(src), dst.Type(), "overflow")
@@ -931,8 +1954,46 @@ func numberFromBytes(ctx *Context, src []byte, dst reflect.Value) error {
 		dst.SetUint(v)
 	default:
 		if err := binary.Read(bytes.NewBuffer(src), ctx.ByteOrder, dst.Addr().Interface()); err != nil {
-			return NewInvalidMappingError(reflect.TypeOf(src), dst.Type(), err.Error())
+			return WrapInvalidMappingError(reflect.TypeOf(src), dst.Type(), err)
+		}
+	}
+	return nil
+}
+
+// minimalBytes returns the big-endian representation of v without leading
+// zero bytes.
+func minimalBytes(v uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	n := 0
+	for n < len(b) && b[n] == 0 {
+		n++
+	}
+	return b[n:]
+}
+
+// minimalNumberFromBytes converts a big-endian byte slice of any length to
+// an int or uint. Leading zero bytes are ignored.
+func minimalNumberFromBytes(src []byte, dst reflect.Value) error {
+	b := bytes.TrimLeft(src, "\x00")
+	if len(b) > 8 {
+		return NewInvalidMappingError(reflect.TypeOf(src), dst.Type(), "overflow")
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v > math.MaxInt64 || dst.OverflowInt(int64(v)) {
+			return NewInvalidMappingError(reflect.TypeOf(src), dst.Type(), "overflow")
+		}
+		dst.SetInt(int64(v))
+	default:
+		if dst.OverflowUint(v) {
+			return NewInvalidMappingError(reflect.TypeOf(src), dst.Type(), "overflow")
 		}
+		dst.SetUint(v)
 	}
 	return nil
 }
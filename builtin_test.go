@@ -3,9 +3,12 @@ package anymapper
 import (
 	"math"
 	"math/big"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBuiltInTypes(t *testing.T) {
@@ -344,6 +347,65 @@ func TestBuiltInTypes(t *testing.T) {
 	}
 }
 
+func TestNumberToStringAllocs(t *testing.T) {
+	type Src struct {
+		A int
+		B uint
+		C float64
+	}
+	type Dst struct {
+		A string
+		B string
+		C string
+	}
+	src := Src{A: -123456789, B: 123456789, C: 3.14159}
+	var dst Dst
+	require.NoError(t, Map(src, &dst))
+	assert.Equal(t, Dst{A: "-123456789", B: "123456789", C: "3.14159"}, dst)
+
+	// Only the resulting strings are allocated.
+	srcVal, dstVal := reflect.ValueOf(src), reflect.ValueOf(&dst)
+	allocs := testing.AllocsPerRun(100, func() {
+		dst = Dst{}
+		_ = MapRefl(srcVal, dstVal)
+	})
+	assert.Equal(t, float64(3), allocs)
+}
+
+func TestTrimStrings(t *testing.T) {
+	ctx := Default.Context.WithTrimStrings(true)
+	tests := []struct {
+		src string
+		dst any
+		exp any
+	}{
+		{src: " 42 ", dst: new(int), exp: 42},
+		{src: "\t42\n", dst: new(uint), exp: uint(42)},
+		{src: ` "1.5" `, dst: new(float64), exp: 1.5},
+		{src: `' true '`, dst: new(bool), exp: true},
+		{src: " 2024-01-01T00:00:00Z ", dst: new(time.Time), exp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{src: ` "123" `, dst: new(big.Int), exp: big.NewInt(123)},
+		{src: " 1/2 ", dst: new(big.Rat), exp: big.NewRat(1, 2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			require.Error(t, Map(tt.src, tt.dst))
+			require.NoError(t, MapContext(ctx, tt.src, tt.dst))
+			assert.Equal(t, exp(tt.exp), dst(tt.dst))
+		})
+	}
+	t.Run("unbalanced-quotes", func(t *testing.T) {
+		var dst int
+		assert.Error(t, MapContext(ctx, `"42`, &dst))
+	})
+	t.Run("string", func(t *testing.T) {
+		// Strings that are not parsed are left unchanged.
+		var dst string
+		require.NoError(t, MapContext(ctx, " foo ", &dst))
+		assert.Equal(t, " foo ", dst)
+	})
+}
+
 func TestStrictTypes(t *testing.T) {
 	type (
 		myBool   bool
@@ -456,6 +518,86 @@ func TestStrictTypes(t *testing.T) {
 	}
 }
 
+func TestStrictKinds(t *testing.T) {
+	type (
+		myInt    int
+		myString string
+		mySlice  []myString
+	)
+	tests := []struct {
+		name string
+		src  any
+		dst  any
+		exp  any
+		err  bool
+	}{
+		{name: `int->int`, src: 1, dst: new(int), exp: 1},
+		{name: `int->myInt`, src: 1, dst: new(myInt), exp: myInt(1)},
+		{name: `myInt->int`, src: myInt(1), dst: new(int), exp: 1},
+		{name: `int->int64`, src: 1, dst: new(int64), err: true},   // error
+		{name: `string->int`, src: "1", dst: new(int), err: true},  // error
+		{name: `int->string`, src: 1, dst: new(string), err: true}, // error
+		{name: `string->myString`, src: "a", dst: new(myString), exp: myString("a")},
+		{name: `[]string->mySlice`, src: []string{"a"}, dst: new(mySlice), exp: mySlice{"a"}},
+		{name: `[]int->mySlice`, src: []int{1}, dst: new(mySlice), err: true},             // error
+		{name: `[1]string->[]string`, src: [1]string{"a"}, dst: new([]string), err: true}, // error
+		{name: `map->struct`, src: map[string]myInt{"A": 1}, dst: new(struct{ A int }), exp: struct{ A int }{1}},
+		{name: `struct->map`, src: struct{ A myInt }{1}, dst: new(map[string]int), exp: map[string]int{"A": 1}},
+		{name: `struct->struct#field`, src: struct{ A string }{"1"}, dst: new(struct{ A int }), err: true}, // error
+		{name: `time.Time->big.Int`, src: time.Unix(1, 0), dst: new(big.Int), err: true},                   // error
+		{name: `string->time.Time`, src: "2024-01-01T00:00:00Z", dst: new(time.Time), err: true},           // error
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := MapContext(Default.Context.WithStrictKinds(true), tt.src, tt.dst)
+			if tt.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, exp(tt.exp), dst(tt.dst))
+			}
+		})
+	}
+	t.Run("int->any", func(t *testing.T) {
+		var dst any
+		require.NoError(t, MapContext(Default.Context.WithStrictKinds(true), 1, &dst))
+		assert.Equal(t, 1, dst)
+	})
+}
+
+func TestStrictExceptions(t *testing.T) {
+	strTy := reflect.TypeOf("")
+	timeTy := reflect.TypeOf(time.Time{})
+	intTy := reflect.TypeOf(0)
+	t.Run("strict-types", func(t *testing.T) {
+		ctx := Default.Context.WithStrictTypes(true).WithStrictExceptions(TypePair{Src: strTy, Dst: timeTy})
+		var dst time.Time
+		require.NoError(t, MapContext(ctx, "2024-01-01T00:00:00Z", &dst))
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), dst.UTC())
+		var num int
+		assert.Error(t, MapContext(ctx, "1", &num))
+	})
+	t.Run("strict-kinds", func(t *testing.T) {
+		ctx := Default.Context.WithStrictKinds(true).WithStrictExceptions(TypePair{Src: strTy, Dst: intTy})
+		var dst int
+		require.NoError(t, MapContext(ctx, "1", &dst))
+		assert.Equal(t, 1, dst)
+	})
+	t.Run("nested", func(t *testing.T) {
+		// The exception for []string -> []int does not apply to the
+		// string -> int mapping of the elements.
+		ctx := Default.Context.WithStrictTypes(true).WithStrictExceptions(TypePair{Src: reflect.TypeOf([]string{}), Dst: reflect.TypeOf([]int{})})
+		var dst []int
+		assert.Error(t, MapContext(ctx, []string{"1"}, &dst))
+		ctx = ctx.WithStrictExceptions(
+			TypePair{Src: reflect.TypeOf([]string{}), Dst: reflect.TypeOf([]int{})},
+			TypePair{Src: strTy, Dst: intTy},
+		)
+		require.NoError(t, MapContext(ctx, []string{"1"}, &dst))
+		assert.Equal(t, []int{1}, dst)
+	})
+}
+
 func TestTags(t *testing.T) {
 	t.Run("struct-map", func(t *testing.T) {
 		type Src struct {
@@ -627,6 +769,100 @@ func TestMapToMap(t *testing.T) {
 	}, dst)
 }
 
+func TestMapToMapReuse(t *testing.T) {
+	type Item struct {
+		Price int
+		Name  string
+	}
+	foo := &Item{Price: 1, Name: "foo"}
+	t.Run("map-to-map", func(t *testing.T) {
+		dst := map[string]*Item{"foo": foo}
+		require.NoError(t, Map(map[string]map[string]any{
+			"foo": {"Price": 2},
+			"bar": {"Price": 3},
+		}, &dst))
+		assert.Same(t, foo, dst["foo"])
+		assert.Equal(t, &Item{Price: 2, Name: "foo"}, dst["foo"])
+		assert.Equal(t, &Item{Price: 3}, dst["bar"])
+	})
+	t.Run("struct-to-map", func(t *testing.T) {
+		type Items struct {
+			Foo map[string]any
+		}
+		dst := map[string]*Item{"Foo": foo}
+		require.NoError(t, Map(Items{Foo: map[string]any{"Price": 4}}, &dst))
+		assert.Same(t, foo, dst["Foo"])
+		assert.Equal(t, &Item{Price: 4, Name: "foo"}, dst["Foo"])
+	})
+	t.Run("nil-map", func(t *testing.T) {
+		var dst *map[string]int
+		require.NoError(t, Map(map[string]string{"a": "1"}, &dst))
+		assert.Equal(t, map[string]int{"a": 1}, *dst)
+	})
+	t.Run("empty-map", func(t *testing.T) {
+		var dst map[string]int
+		require.NoError(t, Map(map[string]string{}, &dst))
+		assert.NotNil(t, dst)
+	})
+	t.Run("custom-provider", func(t *testing.T) {
+		type set map[string]bool
+		m := New()
+		m.Mappers[reflect.TypeOf(set{})] = func(m *Mapper, src, dst reflect.Type) MapFunc {
+			if src.Kind() != reflect.Slice {
+				return nil
+			}
+			return func(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+				for i := 0; i < src.Len(); i++ {
+					dst.SetMapIndex(src.Index(i), reflect.ValueOf(true))
+				}
+				return nil
+			}
+		}
+		var dst set
+		require.NoError(t, m.Map([]string{"a", "b"}, &dst))
+		assert.Equal(t, set{"a": true, "b": true}, dst)
+	})
+}
+
+func TestSyncMaps(t *testing.T) {
+	ctx := Default.Context.WithSyncMaps(true)
+	t.Run("map-to-map", func(t *testing.T) {
+		dst := map[string]int{"a": 1, "stale": 2}
+		require.NoError(t, MapContext(ctx, map[string]string{"a": "3", "b": "4"}, &dst))
+		assert.Equal(t, map[string]int{"a": 3, "b": 4}, dst)
+	})
+	t.Run("different-key-types", func(t *testing.T) {
+		dst := map[int]string{1: "a", 2: "b"}
+		require.NoError(t, MapContext(ctx, map[string]string{"1": "c"}, &dst))
+		assert.Equal(t, map[int]string{1: "c"}, dst)
+	})
+	t.Run("struct-to-map", func(t *testing.T) {
+		type Src struct {
+			Foo  int
+			Bar  string `map:",omitempty"`
+			Skip int    `map:"-"`
+		}
+		dst := map[string]any{"Foo": 1, "Bar": "x", "Skip": 1, "Baz": 2}
+		require.NoError(t, MapContext(ctx, Src{Foo: 2}, &dst))
+		assert.Equal(t, map[string]any{"Foo": 2}, dst)
+	})
+	t.Run("nested", func(t *testing.T) {
+		dst := map[string]map[string]int{"a": {"x": 1, "y": 2}, "b": {}}
+		require.NoError(t, MapContext(ctx, map[string]map[string]string{"a": {"x": "3"}}, &dst))
+		assert.Equal(t, map[string]map[string]int{"a": {"x": 3}}, dst)
+	})
+	t.Run("excluded-fields", func(t *testing.T) {
+		dst := map[string]int{"a": 1, "b": 2, "c": 3}
+		require.NoError(t, MapContext(ctx.WithoutFields("b"), map[string]string{"a": "4"}, &dst))
+		assert.Equal(t, map[string]int{"a": 4, "b": 2}, dst)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		dst := map[string]int{"a": 1, "stale": 2}
+		require.NoError(t, Map(map[string]string{"a": "3"}, &dst))
+		assert.Equal(t, map[string]int{"a": 3, "stale": 2}, dst)
+	})
+}
+
 func TestStructToMap(t *testing.T) {
 	type Str struct {
 		Foo int
@@ -650,3 +886,135 @@ func TestStructToMap(t *testing.T) {
 		"Baz": big.NewInt(3),
 	}, dst)
 }
+
+func TestNilSourceFields(t *testing.T) {
+	type Inner struct {
+		X int
+	}
+	type Src struct {
+		P *Inner
+		A any
+		N int
+	}
+	type Dst struct {
+		P Inner
+		A int
+		N int
+	}
+	t.Run("struct", func(t *testing.T) {
+		dst := Dst{P: Inner{X: 1}, A: 2, N: 3}
+		require.NoError(t, Map(Src{N: 4}, &dst))
+		assert.Equal(t, Dst{P: Inner{X: 1}, A: 2, N: 4}, dst)
+	})
+	t.Run("same-type", func(t *testing.T) {
+		p := &Inner{X: 1}
+		dst := Src{P: p, A: 2, N: 3}
+		require.NoError(t, Map(Src{N: 4}, &dst))
+		assert.Equal(t, Src{P: p, A: 2, N: 4}, dst)
+	})
+	t.Run("map", func(t *testing.T) {
+		var dst map[string]any
+		require.NoError(t, Map(Src{N: 4}, &dst))
+		assert.Equal(t, map[string]any{"N": 4}, dst)
+	})
+}
+
+func TestFields(t *testing.T) {
+	type Address struct {
+		City string
+		Zip  string
+	}
+	type User struct {
+		Name     string
+		Email    string
+		Password string
+		Address  Address
+		Previous []Address
+	}
+	src := User{
+		Name:     "foo",
+		Email:    "foo@example.com",
+		Password: "secret",
+		Address:  Address{City: "Warsaw", Zip: "00-001"},
+		Previous: []Address{{City: "Berlin", Zip: "10115"}},
+	}
+	ctx := Default.Context.WithFields("Name", "Address.City", "Previous.Zip")
+	t.Run("struct-to-struct", func(t *testing.T) {
+		var dst User
+		require.NoError(t, MapContext(ctx, src, &dst))
+		assert.Equal(t, User{
+			Name:     "foo",
+			Address:  Address{City: "Warsaw"},
+			Previous: []Address{{Zip: "10115"}},
+		}, dst)
+	})
+	t.Run("struct-to-different-struct", func(t *testing.T) {
+		var dst struct {
+			Name    string
+			Email   string
+			Address map[string]string
+		}
+		require.NoError(t, MapContext(ctx, src, &dst))
+		assert.Equal(t, "foo", dst.Name)
+		assert.Empty(t, dst.Email)
+		assert.Equal(t, map[string]string{"City": "Warsaw"}, dst.Address)
+	})
+	t.Run("struct-to-map", func(t *testing.T) {
+		var dst map[string]any
+		require.NoError(t, MapContext(ctx.WithFields("Email"), src, &dst))
+		assert.Equal(t, map[string]any{"Email": "foo@example.com"}, dst)
+	})
+	t.Run("map-to-struct", func(t *testing.T) {
+		var dst User
+		require.NoError(t, MapContext(ctx, map[string]any{
+			"Name":    "foo",
+			"Email":   "foo@example.com",
+			"Address": map[string]any{"City": "Warsaw", "Zip": "00-001"},
+		}, &dst))
+		assert.Equal(t, User{Name: "foo", Address: Address{City: "Warsaw"}}, dst)
+	})
+	t.Run("map-to-map", func(t *testing.T) {
+		var dst map[string]any
+		require.NoError(t, MapContext(ctx, map[string]any{"Name": "foo", "Email": "foo@example.com"}, &dst))
+		assert.Equal(t, map[string]any{"Name": "foo"}, dst)
+	})
+}
+
+func TestWithoutFields(t *testing.T) {
+	type Address struct {
+		City string
+		Zip  string
+	}
+	type User struct {
+		Name     string
+		Password string
+		Address  Address
+	}
+	src := User{Name: "foo", Password: "secret", Address: Address{City: "Warsaw", Zip: "00-001"}}
+	ctx := Default.Context.WithoutFields("Password", "Address.Zip")
+	t.Run("struct-to-struct", func(t *testing.T) {
+		var dst User
+		require.NoError(t, MapContext(ctx, src, &dst))
+		assert.Equal(t, User{Name: "foo", Address: Address{City: "Warsaw"}}, dst)
+	})
+	t.Run("struct-to-map", func(t *testing.T) {
+		var dst map[string]any
+		require.NoError(t, MapContext(Default.Context.WithoutFields("Password"), src, &dst))
+		assert.Equal(t, map[string]any{"Name": "foo", "Address": src.Address}, dst)
+	})
+	t.Run("map-to-struct", func(t *testing.T) {
+		var dst User
+		require.NoError(t, MapContext(ctx, map[string]any{"Name": "foo", "Password": "secret"}, &dst))
+		assert.Equal(t, User{Name: "foo"}, dst)
+	})
+	t.Run("whole-subtree", func(t *testing.T) {
+		var dst User
+		require.NoError(t, MapContext(Default.Context.WithoutFields("Address"), src, &dst))
+		assert.Equal(t, User{Name: "foo", Password: "secret"}, dst)
+	})
+	t.Run("with-fields", func(t *testing.T) {
+		var dst User
+		require.NoError(t, MapContext(ctx.WithFields("Address"), src, &dst))
+		assert.Equal(t, User{Address: Address{City: "Warsaw"}}, dst)
+	})
+}
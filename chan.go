@@ -0,0 +1,63 @@
+package anymapper
+
+import (
+	"context"
+	"reflect"
+)
+
+// MapChan maps every element of a slice, array, map or sequence and sends
+// it to the channel dst.
+//
+// It is shorthand for Default.MapChan(ctx, src, dst).
+func MapChan(ctx context.Context, src, dst any) error {
+	return Default.MapChan(ctx, src, dst)
+}
+
+// MapChanContext maps every element of a slice, array, map or sequence
+// using the given mapper context and sends it to the channel dst.
+//
+// It is shorthand for Default.MapChanContext(ctx, mctx, src, dst).
+func MapChanContext(ctx context.Context, mctx *Context, src, dst any) error {
+	return Default.MapChanContext(ctx, mctx, src, dst)
+}
+
+// MapChan maps every element of a slice, array, map or sequence, such as
+// iter.Seq, to the element type of the channel dst and sends it as soon as
+// it is mapped, so the mapped elements can be processed by a pipeline
+// without building the whole mapped collection.
+//
+// The destination must be a channel that values can be sent to. It is not
+// closed by MapChan. Every element is mapped to a new value, so receivers
+// may retain the values. Values of maps are mapped in an unspecified order.
+//
+// If ctx is canceled while MapChan waits for a receiver, it stops and
+// returns the error of the context. If mapping of an element fails, it
+// stops and returns an ElementError.
+func (m *Mapper) MapChan(ctx context.Context, src, dst any) error {
+	return m.MapChanContext(ctx, m.Context, src, dst)
+}
+
+// MapChanContext maps every element of a slice, array, map or sequence
+// using the given mapper context and sends it to the channel dst. See
+// MapChan for details.
+func (m *Mapper) MapChanContext(ctx context.Context, mctx *Context, src, dst any) error {
+	ch := reflect.ValueOf(dst)
+	if ch.Kind() != reflect.Chan || ch.IsNil() || ch.Type().ChanDir()&reflect.SendDir == 0 {
+		return InvalidDstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectSend, Chan: ch},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+	}
+	elem := reflect.New(ch.Type().Elem())
+	return m.MapEachContext(mctx, src, elem.Interface(), func(any) error {
+		cases[0].Send = elem.Elem()
+		if chosen, _, _ := reflect.Select(cases); chosen == 1 {
+			return ctx.Err()
+		}
+		return nil
+	})
+}
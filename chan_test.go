@@ -0,0 +1,78 @@
+package anymapper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapChan(t *testing.T) {
+	type item struct {
+		ID   int      `map:"id"`
+		Tags []string `map:"tags"`
+	}
+
+	t.Run("slice", func(t *testing.T) {
+		ch := make(chan item, 3)
+		src := []map[string]any{
+			{"id": "1", "tags": []any{"a"}},
+			{"id": 2},
+			{"id": 3.0, "tags": []any{"b", "c"}},
+		}
+		require.NoError(t, MapChan(context.Background(), src, ch))
+		close(ch)
+		var got []item
+		for v := range ch {
+			got = append(got, v)
+		}
+		assert.Equal(t, []item{{ID: 1, Tags: []string{"a"}}, {ID: 2}, {ID: 3, Tags: []string{"b", "c"}}}, got)
+	})
+	t.Run("pipeline", func(t *testing.T) {
+		ch := make(chan string)
+		errc := make(chan error, 1)
+		go func() {
+			errc <- MapChan(context.Background(), []int{1, 2, 3}, (chan<- string)(ch))
+			close(ch)
+		}()
+		var got []string
+		for v := range ch {
+			got = append(got, v)
+		}
+		require.NoError(t, <-errc)
+		assert.Equal(t, []string{"1", "2", "3"}, got)
+	})
+	t.Run("map", func(t *testing.T) {
+		ch := make(chan int, 2)
+		ctx := Default.Context.WithSortMapKeys(true)
+		require.NoError(t, MapChanContext(context.Background(), ctx, map[string]string{"a": "1", "b": "2"}, ch))
+		assert.Equal(t, 1, <-ch)
+		assert.Equal(t, 2, <-ch)
+	})
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := make(chan int, 1)
+		go func() {
+			<-ch
+			cancel()
+		}()
+		err := MapChan(ctx, []int{1, 2, 3, 4}, ch)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.ErrorIs(t, MapChan(ctx, []int{1}, ch), context.Canceled)
+	})
+	t.Run("element-error", func(t *testing.T) {
+		ch := make(chan int, 2)
+		err := MapChan(context.Background(), []string{"1", "x"}, ch)
+		var elemErr *ElementError
+		require.ErrorAs(t, err, &elemErr)
+		assert.Equal(t, 1, elemErr.Key)
+		assert.Equal(t, 1, <-ch)
+	})
+	t.Run("invalid-dst", func(t *testing.T) {
+		var nilCh chan int
+		assert.ErrorIs(t, MapChan(context.Background(), []int{1}, nilCh), InvalidDstErr)
+		assert.ErrorIs(t, MapChan(context.Background(), []int{1}, make(<-chan int)), InvalidDstErr)
+		assert.ErrorIs(t, MapChan(context.Background(), []int{1}, new(int)), InvalidDstErr)
+	})
+}
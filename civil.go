@@ -0,0 +1,205 @@
+package anymapper
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	// DateOnly is the layout of dates without a time, e.g. "2006-01-02".
+	// Such strings are parsed into time.Time values at midnight UTC.
+	DateOnly = "2006-01-02"
+
+	// TimeOnly is the layout of times of day without a date, e.g.
+	// "15:04:05". Such strings are parsed into time.Time values with the
+	// zero date, January 1 of year 0, in UTC, unless Context.TimeLayout
+	// holds a date.
+	TimeOnly = "15:04:05"
+)
+
+// timeLayouts are the layouts tried, in order, when strings are parsed into
+// time.Time values.
+var timeLayouts = []string{time.RFC3339, DateOnly, TimeOnly}
+
+// timeLayout returns the layout used to format times as strings.
+func (c *Context) timeLayout() string {
+	if c.TimeLayout != "" {
+		return c.TimeLayout
+	}
+	return time.RFC3339
+}
+
+// parseTime parses a time using Context.TimeLayout, if set, and then the
+// default layouts. If none of them matches, the error of the first one is
+// returned. If Context.TimeLayout holds a date, time-only strings are not
+// accepted, so they are not mapped to dates in year 0.
+func (c *Context) parseTime(s string) (time.Time, error) {
+	var err error
+	layouts := timeLayouts
+	if c.TimeLayout != "" {
+		layouts = append([]string{c.TimeLayout}, layouts...)
+	}
+	dateOnly := c.TimeLayout != "" && layoutHasDate(c.TimeLayout)
+	for _, l := range layouts {
+		if l == TimeOnly && dateOnly {
+			continue
+		}
+		t, e := time.Parse(l, s)
+		if e == nil {
+			return t, nil
+		}
+		if err == nil {
+			err = e
+		}
+	}
+	return time.Time{}, err
+}
+
+// layoutHasDate reports whether the layout formats any part of the date,
+// that is, whether times on different days are formatted differently.
+func layoutHasDate(layout string) bool {
+	a := time.Date(2006, time.January, 2, 0, 0, 0, 0, time.UTC)
+	b := time.Date(2007, time.February, 3, 0, 0, 0, 0, time.UTC)
+	return a.Format(layout) != b.Format(layout)
+}
+
+// civilDateFields and civilTimeFields are the fields of structs that
+// represent dates and times of day, like the Date and Time types of the
+// cloud.google.com/go/civil package.
+var (
+	civilDateFields = []string{"Year", "Month", "Day"}
+	civilTimeFields = []string{"Hour", "Minute", "Second", "Nanosecond"}
+)
+
+// isCivilDate reports whether t is a struct with the Year, Month and Day
+// integer fields and no other exported fields.
+func isCivilDate(t reflect.Type) bool {
+	return hasIntFields(t, civilDateFields)
+}
+
+// isCivilTime reports whether t is a struct with the Hour, Minute, Second
+// and Nanosecond integer fields and no other exported fields.
+func isCivilTime(t reflect.Type) bool {
+	return hasIntFields(t, civilTimeFields)
+}
+
+// hasIntFields reports whether t is a struct whose exported fields are
+// exactly the given signed integer fields.
+func hasIntFields(t reflect.Type, names []string) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	exported := 0
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			exported++
+		}
+	}
+	if exported != len(names) {
+		return false
+	}
+	for _, name := range names {
+		f, ok := t.FieldByName(name)
+		if !ok || len(f.Index) != 1 || f.Type.Kind() < reflect.Int || f.Type.Kind() > reflect.Int64 {
+			return false
+		}
+	}
+	return true
+}
+
+// setIntFields sets the given integer fields of a struct. It returns false
+// if one of the values overflows its field.
+func setIntFields(dst reflect.Value, names []string, values ...int) bool {
+	for i, name := range names {
+		f := dst.FieldByName(name)
+		if f.OverflowInt(int64(values[i])) {
+			return false
+		}
+		f.SetInt(int64(values[i]))
+	}
+	return true
+}
+
+// civilDate returns the date stored in a civil date struct.
+func civilDate(src reflect.Value) (year, month, day int) {
+	return int(src.FieldByName("Year").Int()), int(src.FieldByName("Month").Int()), int(src.FieldByName("Day").Int())
+}
+
+// civilTime returns the time of day stored in a civil time struct.
+func civilTime(src reflect.Value) (hour, min, sec, nsec int) {
+	return int(src.FieldByName("Hour").Int()), int(src.FieldByName("Minute").Int()),
+		int(src.FieldByName("Second").Int()), int(src.FieldByName("Nanosecond").Int())
+}
+
+func mapStringToCivilDate(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	t, err := time.Parse(DateOnly, ctx.parseInput(src.String()))
+	if err != nil {
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	return mapTimeToCivil(nil, ctx, reflect.ValueOf(t), dst)
+}
+
+func mapCivilDateToString(_ *Mapper, _ *Context, src, dst reflect.Value) error {
+	year, month, day := civilDate(src)
+	dst.SetString(fmt.Sprintf("%04d-%02d-%02d", year, month, day))
+	return nil
+}
+
+func mapStringToCivilTime(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	t, err := time.Parse(TimeOnly, ctx.parseInput(src.String()))
+	if err != nil {
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	return mapTimeToCivil(nil, ctx, reflect.ValueOf(t), dst)
+}
+
+func mapCivilTimeToString(_ *Mapper, _ *Context, src, dst reflect.Value) error {
+	hour, min, sec, nsec := civilTime(src)
+	s := fmt.Sprintf("%02d:%02d:%02d", hour, min, sec)
+	if nsec != 0 {
+		s += strings.TrimRight(fmt.Sprintf(".%09d", nsec), "0")
+	}
+	dst.SetString(s)
+	return nil
+}
+
+// mapTimeToCivil maps a time.Time to a civil date or time struct, using the
+// date or the clock of the time in its location.
+func mapTimeToCivil(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	t := src.Interface().(time.Time)
+	var ok bool
+	if isCivilDate(dst.Type()) {
+		year, month, day := t.Date()
+		ok = setIntFields(dst, civilDateFields, year, int(month), day)
+	} else {
+		hour, min, sec := t.Clock()
+		ok = setIntFields(dst, civilTimeFields, hour, min, sec, t.Nanosecond())
+	}
+	if !ok {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "overflow")
+	}
+	return nil
+}
+
+// mapCivilToTime maps a civil date struct to a time.Time at midnight UTC,
+// or a civil time struct to a time.Time with the zero date in UTC.
+func mapCivilToTime(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	var t time.Time
+	if isCivilDate(src.Type()) {
+		year, month, day := civilDate(src)
+		t = time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	} else {
+		hour, min, sec, nsec := civilTime(src)
+		t = time.Date(0, time.January, 1, hour, min, sec, nsec, time.UTC)
+	}
+	dst.Set(reflect.ValueOf(t))
+	return nil
+}
@@ -0,0 +1,117 @@
+package anymapper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDateAndTimeOnly(t *testing.T) {
+	t.Run("date-only", func(t *testing.T) {
+		var dst time.Time
+		require.NoError(t, Map("2023-05-06", &dst))
+		assert.Equal(t, time.Date(2023, 5, 6, 0, 0, 0, 0, time.UTC), dst)
+	})
+	t.Run("time-only", func(t *testing.T) {
+		var dst time.Time
+		require.NoError(t, Map("13:14:15.5", &dst))
+		assert.Equal(t, time.Date(0, 1, 1, 13, 14, 15, 5e8, time.UTC), dst)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		var dst time.Time
+		assert.Error(t, Map("2023-13-06", &dst))
+	})
+	t.Run("time-only-date-layout", func(t *testing.T) {
+		var dst time.Time
+		assert.Error(t, MapContext(Default.Context.WithTimeLayout(DateOnly), "13:14:15", &dst))
+		var born struct {
+			Born time.Time `map:",layout=02.01.2006"`
+		}
+		assert.Error(t, Map(map[string]any{"Born": "13:14:15"}, &born))
+		require.NoError(t, MapContext(Default.Context.WithTimeLayout("15:04"), "13:14:15", &dst))
+		assert.Equal(t, time.Date(0, 1, 1, 13, 14, 15, 0, time.UTC), dst)
+	})
+	t.Run("layout", func(t *testing.T) {
+		ctx := Default.Context.WithTimeLayout(DateOnly)
+		var s string
+		require.NoError(t, MapContext(ctx, time.Date(2023, 5, 6, 7, 8, 9, 0, time.UTC), &s))
+		assert.Equal(t, "2023-05-06", s)
+		var b []byte
+		require.NoError(t, MapContext(ctx.WithTextBytes(true), time.Date(2023, 5, 6, 7, 8, 9, 0, time.UTC), &b))
+		assert.Equal(t, []byte("2023-05-06"), b)
+		var tm time.Time
+		ctx = Default.Context.WithTimeLayout("02.01.2006")
+		require.NoError(t, MapContext(ctx, "06.05.2023", &tm))
+		assert.Equal(t, time.Date(2023, 5, 6, 0, 0, 0, 0, time.UTC), tm)
+	})
+	t.Run("layout-tag", func(t *testing.T) {
+		type Src struct {
+			Born  time.Time
+			Alarm time.Time
+		}
+		type Dst struct {
+			Born  string `map:",layout=2006-01-02"`
+			Alarm string `map:",layout=15:04"`
+		}
+		var dst Dst
+		src := Src{Born: time.Date(1990, 2, 3, 4, 5, 6, 0, time.UTC), Alarm: time.Date(0, 1, 1, 7, 30, 0, 0, time.UTC)}
+		require.NoError(t, Map(src, &dst))
+		assert.Equal(t, Dst{Born: "1990-02-03", Alarm: "07:30"}, dst)
+		var back Src
+		require.NoError(t, Map(dst, &back))
+		assert.Equal(t, Src{Born: time.Date(1990, 2, 3, 0, 0, 0, 0, time.UTC), Alarm: src.Alarm}, back)
+	})
+}
+
+func TestCivil(t *testing.T) {
+	// Date and Time have the same shape as the types of the
+	// cloud.google.com/go/civil package.
+	type Date struct {
+		Year  int
+		Month time.Month
+		Day   int
+	}
+	type Time struct {
+		Hour       int
+		Minute     int
+		Second     int
+		Nanosecond int
+	}
+	t.Run("string-date", func(t *testing.T) {
+		var d Date
+		require.NoError(t, Map("2023-05-06", &d))
+		assert.Equal(t, Date{Year: 2023, Month: time.May, Day: 6}, d)
+		var s string
+		require.NoError(t, Map(d, &s))
+		assert.Equal(t, "2023-05-06", s)
+		assert.Error(t, Map("2023-05-06T00:00:00Z", &d))
+	})
+	t.Run("string-time", func(t *testing.T) {
+		var c Time
+		require.NoError(t, Map("13:14:15.25", &c))
+		assert.Equal(t, Time{Hour: 13, Minute: 14, Second: 15, Nanosecond: 25e7}, c)
+		var s string
+		require.NoError(t, Map(c, &s))
+		assert.Equal(t, "13:14:15.25", s)
+		require.NoError(t, Map(Time{Hour: 1}, &s))
+		assert.Equal(t, "01:00:00", s)
+	})
+	t.Run("time-date", func(t *testing.T) {
+		var d Date
+		require.NoError(t, Map(time.Date(2023, 5, 6, 7, 8, 9, 0, time.UTC), &d))
+		assert.Equal(t, Date{Year: 2023, Month: time.May, Day: 6}, d)
+		var tm time.Time
+		require.NoError(t, Map(d, &tm))
+		assert.Equal(t, time.Date(2023, 5, 6, 0, 0, 0, 0, time.UTC), tm)
+	})
+	t.Run("time-clock", func(t *testing.T) {
+		var c Time
+		require.NoError(t, Map(time.Date(2023, 5, 6, 7, 8, 9, 10, time.UTC), &c))
+		assert.Equal(t, Time{Hour: 7, Minute: 8, Second: 9, Nanosecond: 10}, c)
+		var tm time.Time
+		require.NoError(t, Map(c, &tm))
+		assert.Equal(t, time.Date(0, 1, 1, 7, 8, 9, 10, time.UTC), tm)
+	})
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"reflect"
+	"strings"
+)
+
+// pair is a source and destination type pair for which a mapping function
+// is generated.
+type pair struct {
+	Src  *types.Named
+	Dst  *types.Named
+	Func string
+}
+
+// generator generates mapping functions for the given type pairs.
+type generator struct {
+	pkg   *types.Package
+	tag   string
+	pairs []pair
+
+	buf     bytes.Buffer
+	reflect bool // reflect package is used by the generated code
+}
+
+// generate returns the formatted source code of the mapping functions.
+func (g *generator) generate() ([]byte, error) {
+	var body bytes.Buffer
+	for _, p := range g.pairs {
+		g.buf.Reset()
+		if err := g.pair(p); err != nil {
+			return nil, err
+		}
+		body.Write(g.buf.Bytes())
+	}
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by anymapper-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\n", g.pkg.Name())
+	fmt.Fprintf(&out, "import (\n")
+	if g.reflect {
+		fmt.Fprintf(&out, "\t\"reflect\"\n\n")
+	}
+	fmt.Fprintf(&out, "\t\"github.com/defiweb/go-anymapper\"\n")
+	fmt.Fprintf(&out, ")\n")
+	out.Write(body.Bytes())
+	return format.Source(out.Bytes())
+}
+
+// pair generates the mapping function for a single type pair.
+func (g *generator) pair(p pair) error {
+	srcName := g.typeName(p.Src)
+	dstName := g.typeName(p.Dst)
+	g.printf("\n// %s maps %s to %s.\n", p.Func, srcName, dstName)
+	g.printf("func %s(src %s, dst *%s) error {\n", p.Func, srcName, dstName)
+	defer g.printf("}\n")
+
+	srcStruct, srcOK := p.Src.Underlying().(*types.Struct)
+	dstStruct, dstOK := p.Dst.Underlying().(*types.Struct)
+	if !srcOK || !dstOK || implements(p.Src, "MapTo") || implements(p.Dst, "MapFrom") {
+		// Mapping of these types is fully handled by the runtime mapper.
+		g.printf("return anymapper.Map(src, dst)\n")
+		return nil
+	}
+	if hasRuntimeTags(g.tag, srcStruct) || hasRuntimeTags(g.tag, dstStruct) {
+		// Tag options other than names, aliases and secret are applied only
+		// by the runtime mapper.
+		g.printf("return anymapper.Map(src, dst)\n")
+		return nil
+	}
+	// Field rules and ignored types are registered at runtime, so they
+	// can only be checked by the generated code.
+	g.printf("if len(anymapper.Default.FieldRules) > 0 || len(anymapper.Default.IgnoredTypes) > 0 {\n")
+	g.printf("return anymapper.Map(src, dst)\n")
+	g.printf("}\n")
+	if types.Identical(p.Src, p.Dst) {
+		// Structs of the same type are mapped field by field, using the
+		// field positions.
+		for i := 0; i < srcStruct.NumFields(); i++ {
+			fld := srcStruct.Field(i)
+			if !fld.Exported() {
+				continue
+			}
+			tag := parseTag(g.tag, srcStruct.Tag(i), fld.Name())
+			if tag.skip {
+				continue
+			}
+			g.field(fld, fld, tag.name, tag.secret)
+		}
+		g.printf("return nil\n")
+		return nil
+	}
+	if hasEmbeddedStruct(srcStruct) || hasEmbeddedStruct(dstStruct) {
+		// Fields of embedded structs are promoted by the runtime mapper.
+		g.printf("return anymapper.Map(src, dst)\n")
+		return nil
+	}
+	// Structs of different types are mapped using the field names. If the
+	// source struct has multiple fields with the same name, the last one
+	// is used.
+	srcFields := map[string]int{}
+	for i := 0; i < srcStruct.NumFields(); i++ {
+		fld := srcStruct.Field(i)
+		if !fld.Exported() {
+			continue
+		}
+		tag := parseTag(g.tag, srcStruct.Tag(i), fld.Name())
+		if tag.skip {
+			continue
+		}
+		srcFields[tag.name] = i
+	}
+	for i := 0; i < dstStruct.NumFields(); i++ {
+		dstFld := dstStruct.Field(i)
+		if !dstFld.Exported() {
+			continue
+		}
+		dstTag := parseTag(g.tag, dstStruct.Tag(i), dstFld.Name())
+		if dstTag.skip {
+			continue
+		}
+		name := dstTag.name
+		j, ok := srcFields[name]
+		for _, alias := range dstTag.aliases {
+			if ok {
+				break
+			}
+			name = alias
+			j, ok = srcFields[name]
+		}
+		if !ok {
+			continue
+		}
+		delete(srcFields, name)
+		srcFld := srcStruct.Field(j)
+		srcTag := parseTag(g.tag, srcStruct.Tag(j), srcFld.Name())
+		g.field(srcFld, dstFld, dstTag.name, srcTag.secret || dstTag.secret)
+	}
+	g.printf("return nil\n")
+	return nil
+}
+
+// field generates the code that maps a single struct field. The key of the
+// destination field is added to the paths of the returned errors.
+func (g *generator) field(src, dst *types.Var, key string, secret bool) {
+	srcExpr := "src." + src.Name()
+	dstExpr := "dst." + dst.Name()
+
+	// Nil pointers and interfaces are skipped by the runtime mapper.
+	nilable := false
+	switch src.Type().Underlying().(type) {
+	case *types.Pointer, *types.Interface:
+		nilable = true
+		g.printf("if %s != nil {\n", srcExpr)
+	}
+
+	switch {
+	case isPredeclared(src.Type()) && types.Identical(src.Type(), dst.Type()):
+		// Values of the same predeclared type are assigned directly.
+		g.printf("%s = %s\n", dstExpr, srcExpr)
+	default:
+		call := fmt.Sprintf("anymapper.Map(%s, &%s)", srcExpr, dstExpr)
+		if fn := g.funcFor(src.Type(), dst.Type()); fn != "" {
+			call = fmt.Sprintf("%s(%s, &%s)", fn, srcExpr, dstExpr)
+		}
+		g.printf("if err := %s; err != nil {\n", call)
+		if secret {
+			g.reflect = true
+			g.printf(
+				"return anymapper.PrependErrorPath(anymapper.NewInvalidMappingError(reflect.TypeOf(%s), reflect.TypeOf(%s), \"secret value\"), %q)\n",
+				srcExpr,
+				dstExpr,
+				key,
+			)
+		} else {
+			g.printf("return anymapper.PrependErrorPath(err, %q)\n", key)
+		}
+		g.printf("}\n")
+	}
+
+	if nilable {
+		g.printf("}\n")
+	}
+}
+
+// funcFor returns the name of the generated function that maps src to dst
+// or an empty string if there is no such function.
+func (g *generator) funcFor(src, dst types.Type) string {
+	for _, p := range g.pairs {
+		if types.Identical(p.Src, src) && types.Identical(p.Dst, dst) {
+			return p.Func
+		}
+	}
+	return ""
+}
+
+// typeName returns the name of the type relative to the generated package.
+func (g *generator) typeName(t types.Type) string {
+	return types.TypeString(t, types.RelativeTo(g.pkg))
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// fieldTag is a parsed struct field tag. It follows the same rules as the
+// tag parser used by the runtime mapper.
+type fieldTag struct {
+	name    string
+	aliases []string
+	skip    bool
+	secret  bool
+	runtime bool // the tag has options supported only by the runtime mapper
+}
+
+// parseTag parses the struct field tag with the given key.
+func parseTag(key, raw, field string) (tag fieldTag) {
+	val, ok := reflect.StructTag(raw).Lookup(key)
+	if val == "-" {
+		tag.skip = true
+		return tag
+	}
+	if ok {
+		name, opts, _ := strings.Cut(val, ",")
+		if strings.Contains(name, "|") {
+			names := strings.Split(name, "|")
+			name, tag.aliases = names[0], names[1:]
+		}
+		tag.name = name
+		// Names with dots are paths if Context.TagPaths is enabled.
+		tag.runtime = strings.Contains(name, ".")
+		for opts != "" {
+			var opt string
+			opt, opts, _ = strings.Cut(opts, ",")
+			switch opt {
+			case "":
+			case "secret":
+				tag.secret = true
+			default:
+				tag.runtime = true
+			}
+		}
+	}
+	if tag.name == "" {
+		tag.name = field
+	}
+	return tag
+}
+
+// hasRuntimeTags returns true if any of the exported fields of the struct
+// has a tag with options supported only by the runtime mapper.
+func hasRuntimeTags(key string, s *types.Struct) bool {
+	for i := 0; i < s.NumFields(); i++ {
+		if s.Field(i).Exported() && parseTag(key, s.Tag(i), s.Field(i).Name()).runtime {
+			return true
+		}
+	}
+	return false
+}
+
+// isPredeclared returns true if t is one of the predeclared basic types,
+// like int or string. Named types are not included, because a custom
+// mapper may be registered for them at runtime.
+func isPredeclared(t types.Type) bool {
+	b, ok := t.(*types.Basic)
+	return ok && b.Kind() != types.UnsafePointer && b.Kind() != types.UntypedNil
+}
+
+// hasEmbeddedStruct returns true if the struct has an embedded struct or
+// pointer to a struct field.
+func hasEmbeddedStruct(s *types.Struct) bool {
+	for i := 0; i < s.NumFields(); i++ {
+		fld := s.Field(i)
+		if !fld.Embedded() {
+			continue
+		}
+		t := fld.Type()
+		if p, ok := t.Underlying().(*types.Pointer); ok {
+			t = p.Elem()
+		}
+		if _, ok := t.Underlying().(*types.Struct); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// implements returns true if the type or a pointer to it has a method
+// with the given name.
+func implements(t types.Type, method string) bool {
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(t), true, nil, method)
+	_, ok := obj.(*types.Func)
+	return ok
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := filepath.Join("internal", "example")
+	exp, err := os.ReadFile(filepath.Join(dir, "example_gen.go"))
+	require.NoError(t, err)
+	src, err := generate(dir, "example_gen.go", "map", []string{
+		"UserDTO:User",
+		"User:UserDTO",
+		"AddressDTO:Address",
+		"Address:AddressDTO",
+		"User:User",
+		"ItemDTO:Item",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, string(exp), string(src), "example_gen.go is out of date, run go generate")
+}
+
+func TestGenerateRuntimeFallback(t *testing.T) {
+	src, err := generate(filepath.Join("internal", "example"), "example_gen.go", "map", []string{"ItemDTO:Item", "AddressDTO:Address"})
+	require.NoError(t, err)
+	// Tag options supported only by the runtime mapper.
+	assert.Contains(t, string(src), "func MapItemDTOToItem(src ItemDTO, dst *Item) error {\n\treturn anymapper.Map(src, dst)\n}")
+	// Field rules and ignored types registered at runtime.
+	assert.Contains(t, string(src), "if len(anymapper.Default.FieldRules) > 0 || len(anymapper.Default.IgnoredTypes) > 0 {\n\t\treturn anymapper.Map(src, dst)\n\t}")
+}
+
+func TestGenerateErrors(t *testing.T) {
+	dir := filepath.Join("internal", "example")
+	tests := []struct {
+		arg string
+	}{
+		{arg: "User"},
+		{arg: "User:User:Func:Extra"},
+		{arg: "Unknown:User"},
+		{arg: "User:Unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			_, err := generate(dir, "example_gen.go", "map", []string{tt.arg})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want fieldTag
+	}{
+		{raw: ``, want: fieldTag{name: "Field"}},
+		{raw: `map:"-"`, want: fieldTag{skip: true}},
+		{raw: `map:"name"`, want: fieldTag{name: "name"}},
+		{raw: `map:",secret"`, want: fieldTag{name: "Field", secret: true}},
+		{raw: `json:"name"`, want: fieldTag{name: "Field"}},
+		{raw: `map:"name|full_name,secret"`, want: fieldTag{name: "name", aliases: []string{"full_name"}, secret: true}},
+		{raw: `map:"code,width=6,pad=0"`, want: fieldTag{name: "code", runtime: true}},
+		{raw: `map:"port,default=8080"`, want: fieldTag{name: "port", runtime: true}},
+		{raw: `map:"price,conv=toCents"`, want: fieldTag{name: "price", runtime: true}},
+		{raw: `map:"meta.created"`, want: fieldTag{name: "meta.created", runtime: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseTag("map", tt.raw, "Field"))
+		})
+	}
+}
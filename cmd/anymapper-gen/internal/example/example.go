@@ -0,0 +1,55 @@
+// Package example contains types used to test the code generated by
+// anymapper-gen.
+package example
+
+import (
+	"math/big"
+	"time"
+)
+
+//go:generate go run github.com/defiweb/go-anymapper/cmd/anymapper-gen -out example_gen.go UserDTO:User User:UserDTO AddressDTO:Address Address:AddressDTO User:User ItemDTO:Item
+
+type UserDTO struct {
+	ID        string `map:"id"`
+	Name      string `map:"name"`
+	Password  string `map:"password,secret"`
+	Balance   string `map:"balance"`
+	CreatedAt int64  `map:"created_at"`
+	Address   AddressDTO
+	Tags      []string
+	Internal  string `map:"-"`
+}
+
+type User struct {
+	ID        uint64    `map:"id"`
+	Name      string    `map:"name"`
+	Password  []byte    `map:"password,secret"`
+	Balance   *big.Int  `map:"balance"`
+	CreatedAt time.Time `map:"created_at"`
+	Address   Address
+	Tags      []string
+	Internal  string
+	private   string
+}
+
+type AddressDTO struct {
+	City string
+	Zip  *string
+}
+
+type Address struct {
+	City string
+	Zip  string
+}
+
+type ItemDTO struct {
+	Code  int    `map:"code,width=6,pad=0"`
+	Port  int    `map:"port,default=8080"`
+	Price string `map:"meta.price"`
+}
+
+type Item struct {
+	Code  string `map:"code"`
+	Port  int    `map:"port"`
+	Price string `map:"meta.price"`
+}
@@ -0,0 +1,124 @@
+// Code generated by anymapper-gen. DO NOT EDIT.
+
+package example
+
+import (
+	"reflect"
+
+	"github.com/defiweb/go-anymapper"
+)
+
+// MapUserDTOToUser maps UserDTO to User.
+func MapUserDTOToUser(src UserDTO, dst *User) error {
+	if len(anymapper.Default.FieldRules) > 0 || len(anymapper.Default.IgnoredTypes) > 0 {
+		return anymapper.Map(src, dst)
+	}
+	if err := anymapper.Map(src.ID, &dst.ID); err != nil {
+		return anymapper.PrependErrorPath(err, "id")
+	}
+	dst.Name = src.Name
+	if err := anymapper.Map(src.Password, &dst.Password); err != nil {
+		return anymapper.PrependErrorPath(anymapper.NewInvalidMappingError(reflect.TypeOf(src.Password), reflect.TypeOf(dst.Password), "secret value"), "password")
+	}
+	if err := anymapper.Map(src.Balance, &dst.Balance); err != nil {
+		return anymapper.PrependErrorPath(err, "balance")
+	}
+	if err := anymapper.Map(src.CreatedAt, &dst.CreatedAt); err != nil {
+		return anymapper.PrependErrorPath(err, "created_at")
+	}
+	if err := MapAddressDTOToAddress(src.Address, &dst.Address); err != nil {
+		return anymapper.PrependErrorPath(err, "Address")
+	}
+	if err := anymapper.Map(src.Tags, &dst.Tags); err != nil {
+		return anymapper.PrependErrorPath(err, "Tags")
+	}
+	return nil
+}
+
+// MapUserToUserDTO maps User to UserDTO.
+func MapUserToUserDTO(src User, dst *UserDTO) error {
+	if len(anymapper.Default.FieldRules) > 0 || len(anymapper.Default.IgnoredTypes) > 0 {
+		return anymapper.Map(src, dst)
+	}
+	if err := anymapper.Map(src.ID, &dst.ID); err != nil {
+		return anymapper.PrependErrorPath(err, "id")
+	}
+	dst.Name = src.Name
+	if err := anymapper.Map(src.Password, &dst.Password); err != nil {
+		return anymapper.PrependErrorPath(anymapper.NewInvalidMappingError(reflect.TypeOf(src.Password), reflect.TypeOf(dst.Password), "secret value"), "password")
+	}
+	if src.Balance != nil {
+		if err := anymapper.Map(src.Balance, &dst.Balance); err != nil {
+			return anymapper.PrependErrorPath(err, "balance")
+		}
+	}
+	if err := anymapper.Map(src.CreatedAt, &dst.CreatedAt); err != nil {
+		return anymapper.PrependErrorPath(err, "created_at")
+	}
+	if err := MapAddressToAddressDTO(src.Address, &dst.Address); err != nil {
+		return anymapper.PrependErrorPath(err, "Address")
+	}
+	if err := anymapper.Map(src.Tags, &dst.Tags); err != nil {
+		return anymapper.PrependErrorPath(err, "Tags")
+	}
+	return nil
+}
+
+// MapAddressDTOToAddress maps AddressDTO to Address.
+func MapAddressDTOToAddress(src AddressDTO, dst *Address) error {
+	if len(anymapper.Default.FieldRules) > 0 || len(anymapper.Default.IgnoredTypes) > 0 {
+		return anymapper.Map(src, dst)
+	}
+	dst.City = src.City
+	if src.Zip != nil {
+		if err := anymapper.Map(src.Zip, &dst.Zip); err != nil {
+			return anymapper.PrependErrorPath(err, "Zip")
+		}
+	}
+	return nil
+}
+
+// MapAddressToAddressDTO maps Address to AddressDTO.
+func MapAddressToAddressDTO(src Address, dst *AddressDTO) error {
+	if len(anymapper.Default.FieldRules) > 0 || len(anymapper.Default.IgnoredTypes) > 0 {
+		return anymapper.Map(src, dst)
+	}
+	dst.City = src.City
+	if err := anymapper.Map(src.Zip, &dst.Zip); err != nil {
+		return anymapper.PrependErrorPath(err, "Zip")
+	}
+	return nil
+}
+
+// MapUserToUser maps User to User.
+func MapUserToUser(src User, dst *User) error {
+	if len(anymapper.Default.FieldRules) > 0 || len(anymapper.Default.IgnoredTypes) > 0 {
+		return anymapper.Map(src, dst)
+	}
+	dst.ID = src.ID
+	dst.Name = src.Name
+	if err := anymapper.Map(src.Password, &dst.Password); err != nil {
+		return anymapper.PrependErrorPath(anymapper.NewInvalidMappingError(reflect.TypeOf(src.Password), reflect.TypeOf(dst.Password), "secret value"), "password")
+	}
+	if src.Balance != nil {
+		if err := anymapper.Map(src.Balance, &dst.Balance); err != nil {
+			return anymapper.PrependErrorPath(err, "balance")
+		}
+	}
+	if err := anymapper.Map(src.CreatedAt, &dst.CreatedAt); err != nil {
+		return anymapper.PrependErrorPath(err, "created_at")
+	}
+	if err := anymapper.Map(src.Address, &dst.Address); err != nil {
+		return anymapper.PrependErrorPath(err, "Address")
+	}
+	if err := anymapper.Map(src.Tags, &dst.Tags); err != nil {
+		return anymapper.PrependErrorPath(err, "Tags")
+	}
+	dst.Internal = src.Internal
+	return nil
+}
+
+// MapItemDTOToItem maps ItemDTO to Item.
+func MapItemDTOToItem(src ItemDTO, dst *Item) error {
+	return anymapper.Map(src, dst)
+}
@@ -0,0 +1,76 @@
+package example
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defiweb/go-anymapper"
+)
+
+func TestGeneratedMatchesRuntime(t *testing.T) {
+	zip := "00-001"
+	dto := UserDTO{
+		ID:        "42",
+		Name:      "foo",
+		Password:  "secret",
+		Balance:   "100",
+		CreatedAt: 1666666666,
+		Address:   AddressDTO{City: "Warsaw", Zip: &zip},
+		Tags:      []string{"a", "b"},
+		Internal:  "internal",
+	}
+
+	var gen, rt User
+	require.NoError(t, MapUserDTOToUser(dto, &gen))
+	require.NoError(t, anymapper.Map(dto, &rt))
+	assert.Equal(t, rt, gen)
+	assert.Equal(t, uint64(42), gen.ID)
+	assert.Equal(t, big.NewInt(100), gen.Balance)
+	assert.Equal(t, time.Unix(1666666666, 0).Unix(), gen.CreatedAt.Unix())
+	assert.Empty(t, gen.Internal)
+
+	var genDTO, rtDTO UserDTO
+	require.NoError(t, MapUserToUserDTO(gen, &genDTO))
+	require.NoError(t, anymapper.Map(gen, &rtDTO))
+	assert.Equal(t, rtDTO, genDTO)
+
+	var genCpy, rtCpy User
+	require.NoError(t, MapUserToUser(gen, &genCpy))
+	require.NoError(t, anymapper.Map(gen, &rtCpy))
+	assert.Equal(t, rtCpy, genCpy)
+}
+
+func TestGeneratedErrors(t *testing.T) {
+	var gen, rt User
+	dto := UserDTO{ID: "foo"}
+	genErr := MapUserDTOToUser(dto, &gen)
+	rtErr := anymapper.Map(dto, &rt)
+	require.Error(t, genErr)
+	assert.Equal(t, rtErr.Error(), genErr.Error())
+}
+
+func TestGeneratedRuntimeFallback(t *testing.T) {
+	t.Run("tag-options", func(t *testing.T) {
+		var gen, rt Item
+		require.NoError(t, MapItemDTOToItem(ItemDTO{Code: 42, Port: 80}, &gen))
+		require.NoError(t, anymapper.Map(ItemDTO{Code: 42, Port: 80}, &rt))
+		assert.Equal(t, rt, gen)
+		assert.Equal(t, "000042", gen.Code)
+	})
+	t.Run("ignored-types", func(t *testing.T) {
+		m := anymapper.Default
+		anymapper.Default = m.Copy()
+		defer func() { anymapper.Default = m }()
+		anymapper.Default.IgnoreTypes(reflect.TypeOf(AddressDTO{}))
+		var gen, rt User
+		require.NoError(t, MapUserDTOToUser(UserDTO{ID: "1", Balance: "1", Address: AddressDTO{City: "Warsaw"}}, &gen))
+		require.NoError(t, anymapper.Map(UserDTO{ID: "1", Balance: "1", Address: AddressDTO{City: "Warsaw"}}, &rt))
+		assert.Equal(t, rt, gen)
+		assert.Empty(t, gen.Address.City)
+	})
+}
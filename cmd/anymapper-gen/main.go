@@ -0,0 +1,160 @@
+// Command anymapper-gen generates mapping functions between struct types
+// that do not use reflection for fields that can be assigned directly.
+//
+// Usage:
+//
+//	anymapper-gen [flags] Src:Dst[:FuncName] ...
+//
+// For every type pair, a function with the following signature is
+// generated:
+//
+//	func MapSrcToDst(src Src, dst *Dst) error
+//
+// The generated functions follow the same rules as the runtime mapper:
+// fields are matched using the struct tags, unexported and skipped fields
+// are ignored, and nil source pointers are skipped. Fields of the same
+// predeclared type are assigned directly, fields whose types form another
+// generated pair are mapped using the generated function, and all other
+// fields are mapped using anymapper.Map, so registered mapper providers
+// and hooks of the Default mapper still apply to them.
+//
+// Pairs of structs with fields whose tags have options other than names,
+// aliases and secret, or names with dots, are mapped entirely using
+// anymapper.Map. The generated functions also fall back to anymapper.Map
+// at runtime if the Default mapper has field rules or ignored types.
+//
+// The generated code does not support the best-effort mode, the
+// FieldMapper function, the path mapping functions and the hooks of the
+// Default mapper that operate on struct fields.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	var (
+		dir = flag.String("dir", ".", "directory of the package containing the types")
+		out = flag.String("out", "anymapper_gen.go", "output file name, relative to the package directory")
+		tag = flag.String("tag", "map", "struct tag used to match fields")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: anymapper-gen [flags] Src:Dst[:FuncName] ...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*dir, *out, *tag, flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "anymapper-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run generates the mapping functions for the given type pairs and writes
+// them to the output file.
+func run(dir, out, tag string, args []string) error {
+	src, err := generate(dir, out, tag, args)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, out), src, 0o644)
+}
+
+// generate returns the source code of the mapping functions for the given
+// type pairs. The output file, if it exists, is ignored while loading the
+// package, so the functions can be regenerated after the types change.
+func generate(dir, out, tag string, args []string) ([]byte, error) {
+	pkg, err := loadPackage(dir, out)
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{pkg: pkg, tag: tag}
+	for _, arg := range args {
+		p, err := parsePair(pkg, arg)
+		if err != nil {
+			return nil, err
+		}
+		g.pairs = append(g.pairs, p)
+	}
+	return g.generate()
+}
+
+// loadPackage parses and type-checks the non-test Go files in dir.
+func loadPackage(dir, out string) (*types.Package, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go") && fi.Name() != out
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", dir, len(pkgs))
+	}
+	var files []*ast.File
+	var name string
+	for n, p := range pkgs {
+		name = n
+		for _, f := range p.Files {
+			files = append(files, f)
+		}
+	}
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		// Errors are ignored, because the package may refer to the
+		// functions that are not yet generated.
+		Error: func(error) {},
+	}
+	pkg, _ := conf.Check(name, fset, files, nil)
+	if pkg == nil {
+		return nil, errors.New("unable to type-check package")
+	}
+	return pkg, nil
+}
+
+// parsePair parses a type pair in the Src:Dst[:FuncName] format.
+func parsePair(pkg *types.Package, arg string) (pair, error) {
+	parts := strings.Split(arg, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return pair{}, fmt.Errorf("invalid type pair %q", arg)
+	}
+	src, err := lookupType(pkg, parts[0])
+	if err != nil {
+		return pair{}, err
+	}
+	dst, err := lookupType(pkg, parts[1])
+	if err != nil {
+		return pair{}, err
+	}
+	fn := "Map" + parts[0] + "To" + parts[1]
+	if len(parts) == 3 {
+		fn = parts[2]
+	}
+	return pair{Src: src, Dst: dst, Func: fn}, nil
+}
+
+// lookupType returns the named type declared in the package.
+func lookupType(pkg *types.Package, name string) (*types.Named, error) {
+	obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("type %s not found in package %s", name, pkg.Name())
+	}
+	typ, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a named type", name)
+	}
+	return typ, nil
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/defiweb/go-anymapper"
+)
+
+// kind is the inferred kind of a JSON value.
+type kind int
+
+const (
+	kindNull kind = iota // only null values were seen
+	kindBool
+	kindInt
+	kindBigInt
+	kindFloat
+	kindString
+	kindTime
+	kindObject
+	kindArray
+	kindAny // values of conflicting kinds were seen
+)
+
+// shape describes the values seen at a single position in the samples.
+type shape struct {
+	kind     kind
+	nullable bool              // at least one of the values was null
+	fields   map[string]*shape // fields of an object
+	elem     *shape            // elements of an array
+}
+
+// merge merges the other shape into s.
+func (s *shape) merge(o *shape) {
+	if o.nullable || o.kind == kindNull {
+		s.nullable = true
+	}
+	switch {
+	case o.kind == kindNull || s.kind == kindAny:
+		return
+	case s.kind == kindNull:
+		s.kind, s.fields, s.elem = o.kind, o.fields, o.elem
+		return
+	case s.kind == o.kind:
+	case isNumber(s.kind) && isNumber(o.kind):
+		// Numbers are widened to the type that can hold all of them.
+		if s.kind < o.kind {
+			s.kind = o.kind
+		}
+		return
+	case isString(s.kind) && isString(o.kind):
+		// A string that is not a valid time cannot be mapped to time.Time.
+		s.kind = kindString
+		return
+	default:
+		s.kind, s.fields, s.elem = kindAny, nil, nil
+		return
+	}
+	switch s.kind {
+	case kindObject:
+		for key, f := range o.fields {
+			if sf, ok := s.fields[key]; ok {
+				sf.merge(f)
+			} else {
+				s.fields[key] = f
+			}
+		}
+	case kindArray:
+		s.elem.merge(o.elem)
+	}
+}
+
+func isNumber(k kind) bool {
+	return k == kindInt || k == kindBigInt || k == kindFloat
+}
+
+func isString(k kind) bool {
+	return k == kindString || k == kindTime
+}
+
+// inferrer infers struct definitions from sample JSON objects.
+type inferrer struct {
+	root *shape
+}
+
+func newInferrer() *inferrer {
+	return &inferrer{root: &shape{kind: kindObject, fields: map[string]*shape{}}}
+}
+
+// read reads all samples from r. Every sample must be a JSON object or an
+// array of objects.
+func (inf *inferrer) read(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	for {
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if err := inf.add(v); err != nil {
+			return err
+		}
+	}
+}
+
+// add adds a single decoded sample.
+func (inf *inferrer) add(v any) error {
+	switch v := v.(type) {
+	case map[string]any:
+		inf.root.merge(shapeOf(v))
+	case []any:
+		for _, e := range v {
+			if err := inf.add(e); err != nil {
+				return err
+			}
+		}
+	default:
+		return errors.New("sample must be a JSON object or an array of objects")
+	}
+	return nil
+}
+
+// shapeOf returns the shape of a value decoded by encoding/json.
+//
+// Numbers and strings are classified by mapping them using the Default
+// mapper, so the inferred types are the ones the values can later be
+// mapped to.
+func shapeOf(v any) *shape {
+	switch v := v.(type) {
+	case nil:
+		return &shape{kind: kindNull}
+	case bool:
+		return &shape{kind: kindBool}
+	case json.Number:
+		var i int64
+		if anymapper.Map(v.String(), &i) == nil {
+			return &shape{kind: kindInt}
+		}
+		var b big.Int
+		if anymapper.Map(v.String(), &b) == nil {
+			return &shape{kind: kindBigInt}
+		}
+		return &shape{kind: kindFloat}
+	case string:
+		var t time.Time
+		if anymapper.Map(v, &t) == nil {
+			return &shape{kind: kindTime}
+		}
+		return &shape{kind: kindString}
+	case map[string]any:
+		s := &shape{kind: kindObject, fields: make(map[string]*shape, len(v))}
+		for key, f := range v {
+			s.fields[key] = shapeOf(f)
+		}
+		return s
+	case []any:
+		s := &shape{kind: kindArray, elem: &shape{kind: kindNull}}
+		for _, e := range v {
+			s.elem.merge(shapeOf(e))
+		}
+		return s
+	}
+	return &shape{kind: kindAny}
+}
+
+// generate returns the formatted source code of the inferred struct types.
+func (inf *inferrer) generate(name, pkg, tag string) ([]byte, error) {
+	if !isIdent(name) {
+		return nil, fmt.Errorf("invalid type name %q", name)
+	}
+	g := &generator{tag: tag, names: map[string]bool{name: true}}
+	g.queue = append(g.queue, named{name: name, shape: inf.root})
+	for len(g.queue) > 0 {
+		n := g.queue[0]
+		g.queue = g.queue[1:]
+		g.structType(n)
+	}
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by anymapper-infer.\n\n")
+	if pkg != "" {
+		fmt.Fprintf(&out, "package %s\n\n", pkg)
+	}
+	if g.big || g.time {
+		fmt.Fprintf(&out, "import (\n")
+		if g.big {
+			fmt.Fprintf(&out, "\t\"math/big\"\n")
+		}
+		if g.time {
+			fmt.Fprintf(&out, "\t\"time\"\n")
+		}
+		fmt.Fprintf(&out, ")\n")
+	}
+	out.Write(g.buf.Bytes())
+	return format.Source(out.Bytes())
+}
+
+// named is an object shape for which a struct type is generated.
+type named struct {
+	name  string
+	shape *shape
+}
+
+// generator generates struct types for object shapes.
+type generator struct {
+	tag   string
+	queue []named
+	names map[string]bool // used type names
+
+	buf  bytes.Buffer
+	big  bool // math/big package is used by the generated code
+	time bool // time package is used by the generated code
+}
+
+// structType generates a struct type. Struct types of nested objects are
+// added to the queue.
+func (g *generator) structType(n named) {
+	keys := make([]string, 0, len(n.shape.fields))
+	for key := range n.shape.fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fields := map[string]bool{}
+	g.printf("\ntype %s struct {\n", n.name)
+	for _, key := range keys {
+		field := unique(fields, exportedName(key))
+		typ := g.typeOf(field, n.shape.fields[key])
+		g.printf("%s %s `%s:%s`\n", field, typ, g.tag, strconv.Quote(key))
+	}
+	g.printf("}\n")
+}
+
+// typeOf returns the Go type for the shape. The field name is used to name
+// the struct types of nested objects.
+func (g *generator) typeOf(field string, s *shape) string {
+	var typ string
+	switch s.kind {
+	case kindNull, kindAny:
+		return "any"
+	case kindBool:
+		typ = "bool"
+	case kindInt:
+		typ = "int64"
+	case kindBigInt:
+		// big.Int is always used through a pointer.
+		g.big = true
+		return "*big.Int"
+	case kindFloat:
+		typ = "float64"
+	case kindString:
+		typ = "string"
+	case kindTime:
+		g.time = true
+		typ = "time.Time"
+	case kindObject:
+		typ = unique(g.names, field)
+		g.queue = append(g.queue, named{name: typ, shape: s})
+	case kindArray:
+		return "[]" + g.typeOf(field, s.elem)
+	}
+	if s.nullable {
+		return "*" + typ
+	}
+	return typ
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// commonInitialisms are the words that are written in upper case in
+// exported names.
+var commonInitialisms = map[string]bool{
+	"API": true, "ID": true, "IP": true, "JSON": true, "HTTP": true,
+	"URI": true, "URL": true, "UUID": true, "UTC": true,
+}
+
+// exportedName converts a map key to an exported Go identifier, for
+// example "created_at" to "CreatedAt".
+func exportedName(key string) string {
+	var b strings.Builder
+	words := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		if u := strings.ToUpper(w); commonInitialisms[u] {
+			b.WriteString(u)
+			continue
+		}
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	name := b.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "F" + name
+	}
+	return name
+}
+
+// unique returns name, or name with a numeric suffix if it is already used,
+// and marks the result as used.
+func unique(used map[string]bool, name string) string {
+	res := name
+	for i := 2; used[res]; i++ {
+		res = name + strconv.Itoa(i)
+	}
+	used[res] = true
+	return res
+}
+
+// isIdent returns true if s is a valid exported or unexported Go
+// identifier.
+func isIdent(s string) bool {
+	for i, r := range s {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return s != ""
+}
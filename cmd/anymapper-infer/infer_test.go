@@ -0,0 +1,95 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	samples := `
+{"id": 1, "name": "a", "created_at": "2023-01-02T03:04:05Z", "price": 1, "address": {"city": "x"}, "tags": ["a"]}
+[
+  {"id": 2, "balance": 123456789012345678901234567890, "price": 1.5, "address": null, "mixed": 1},
+  {"id": 3, "created_at": "yesterday", "items": [{"n": 1}, {"n": null}], "mixed": "s", "http_url": "x"}
+]`
+	inf := newInferrer()
+	require.NoError(t, inf.read(strings.NewReader(samples)))
+	src, err := inf.generate("Payload", "example", "map")
+	require.NoError(t, err)
+	// Tags are written with single quotes for readability.
+	exp := `// Code generated by anymapper-infer.
+
+package example
+
+import (
+	"math/big"
+)
+
+type Payload struct {
+	Address   *Address 'map:"address"'
+	Balance   *big.Int 'map:"balance"'
+	CreatedAt string   'map:"created_at"'
+	HTTPURL   string   'map:"http_url"'
+	ID        int64    'map:"id"'
+	Items     []Items  'map:"items"'
+	Mixed     any      'map:"mixed"'
+	Name      string   'map:"name"'
+	Price     float64  'map:"price"'
+	Tags      []string 'map:"tags"'
+}
+
+type Address struct {
+	City string 'map:"city"'
+}
+
+type Items struct {
+	N *int64 'map:"n"'
+}
+`
+	assert.Equal(t, strings.ReplaceAll(exp, "'", "`"), string(src))
+}
+
+func TestGenerateTime(t *testing.T) {
+	inf := newInferrer()
+	require.NoError(t, inf.read(strings.NewReader(`{"ts": "2023-01-02T03:04:05Z"} {"ts": null}`)))
+	src, err := inf.generate("Event", "", "json")
+	require.NoError(t, err)
+	assert.Contains(t, string(src), `"time"`)
+	assert.Contains(t, string(src), "Ts *time.Time `json:\"ts\"`")
+	assert.NotContains(t, string(src), "package")
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []string{
+		`1`,
+		`["a"]`,
+		`{"a": `,
+	}
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			assert.Error(t, newInferrer().read(strings.NewReader(tt)))
+		})
+	}
+}
+
+func TestExportedName(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "name", want: "Name"},
+		{key: "created_at", want: "CreatedAt"},
+		{key: "user-id", want: "UserID"},
+		{key: "fooBar", want: "FooBar"},
+		{key: "1st", want: "F1st"},
+		{key: "", want: "F"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.want, exportedName(tt.key))
+		})
+	}
+}
@@ -0,0 +1,87 @@
+// Command anymapper-infer infers Go struct definitions from sample JSON
+// payloads.
+//
+// Usage:
+//
+//	anymapper-infer [flags] [file ...]
+//
+// Every file may contain one or more JSON objects, or arrays of objects.
+// If no files are given, samples are read from the standard input. All
+// samples are merged into a single struct type, so fields that are present
+// only in some of the samples are included as well.
+//
+// Field types are chosen using the parsing rules of the mapper: numbers
+// that can be mapped to int64 without loss become int64, larger integers
+// become *big.Int and other numbers float64. Strings that can be mapped to
+// time.Time become time.Time. Nested objects become separate struct types
+// named after their keys, and fields that are null in some of the samples
+// become pointers. Fields with conflicting types become any.
+//
+// The generated fields have map tags with the original keys, so the
+// payloads can be mapped to the structs using anymapper.Map. Keys that
+// contain the "," or "|" characters cannot be used in tags and must be
+// handled manually.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+	var (
+		name = flag.String("name", "Payload", "name of the root struct type")
+		pkg  = flag.String("pkg", "", "package name; if empty, the package clause is omitted")
+		tag  = flag.String("tag", "map", "struct tag used for keys")
+		out  = flag.String("out", "", "output file name; if empty, the output is written to stdout")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: anymapper-infer [flags] [file ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if err := run(*name, *pkg, *tag, *out, flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "anymapper-infer: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run infers the struct definitions from the given files, or from the
+// standard input, and writes them to the output file or stdout.
+func run(name, pkg, tag, out string, files []string) error {
+	inf := newInferrer()
+	if len(files) == 0 {
+		if err := inf.read(os.Stdin); err != nil {
+			return err
+		}
+	}
+	for _, file := range files {
+		if err := readFile(inf, file); err != nil {
+			return err
+		}
+	}
+	src, err := inf.generate(name, pkg, tag)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(out, src, 0o644)
+}
+
+// readFile reads the samples from the file.
+func readFile(inf *inferrer, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := inf.read(f); err != nil && err != io.EOF {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	return nil
+}
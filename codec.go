@@ -0,0 +1,62 @@
+package anymapper
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// NumberCodec encodes and decodes integers when they are mapped to and from
+// byte slices and arrays. If Context.NumberCodec is set, it is used instead
+// of the Context.ByteOrder and Context.MinimalBytes fields. Floating-point
+// numbers are not affected.
+//
+// The mapper checks if the decoded value fits in the destination type, so
+// codecs do not have to do it.
+type NumberCodec interface {
+	// EncodeInt encodes a signed integer.
+	EncodeInt(v int64) ([]byte, error)
+
+	// EncodeUint encodes an unsigned integer.
+	EncodeUint(v uint64) ([]byte, error)
+
+	// DecodeInt decodes a signed integer.
+	DecodeInt(b []byte) (int64, error)
+
+	// DecodeUint decodes an unsigned integer.
+	DecodeUint(b []byte) (uint64, error)
+}
+
+// VarintCodec is a NumberCodec that encodes integers as protobuf-style
+// varints. Signed integers use the zigzag encoding, so small negative
+// numbers are encoded using a small number of bytes.
+var VarintCodec NumberCodec = varintCodec{}
+
+var errInvalidVarint = errors.New("invalid varint")
+
+type varintCodec struct{}
+
+func (varintCodec) EncodeInt(v int64) ([]byte, error) {
+	b := make([]byte, binary.MaxVarintLen64)
+	return b[:binary.PutVarint(b, v)], nil
+}
+
+func (varintCodec) EncodeUint(v uint64) ([]byte, error) {
+	b := make([]byte, binary.MaxVarintLen64)
+	return b[:binary.PutUvarint(b, v)], nil
+}
+
+func (varintCodec) DecodeInt(b []byte) (int64, error) {
+	v, n := binary.Varint(b)
+	if n <= 0 || n != len(b) {
+		return 0, errInvalidVarint
+	}
+	return v, nil
+}
+
+func (varintCodec) DecodeUint(b []byte) (uint64, error) {
+	v, n := binary.Uvarint(b)
+	if n <= 0 || n != len(b) {
+		return 0, errInvalidVarint
+	}
+	return v, nil
+}
@@ -0,0 +1,78 @@
+package anymapper
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVarintCodec(t *testing.T) {
+	ctx := Default.Context.WithNumberCodec(VarintCodec)
+	tests := []struct {
+		src  any
+		want []byte
+	}{
+		{src: 0, want: []byte{0}},
+		{src: 1, want: []byte{2}},
+		{src: -1, want: []byte{1}},
+		{src: int8(-64), want: []byte{0x7f}},
+		{src: uint(1), want: []byte{1}},
+		{src: uint16(300), want: []byte{0xac, 0x02}},
+		{src: uint64(math.MaxUint64), want: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%T(%v)", tt.src, tt.src), func(t *testing.T) {
+			var dst []byte
+			require.NoError(t, MapContext(ctx, tt.src, &dst))
+			assert.Equal(t, tt.want, dst)
+
+			// Map back to the same type.
+			back := reflect.New(reflect.TypeOf(tt.src))
+			require.NoError(t, MapContext(ctx, dst, back.Interface()))
+			assert.Equal(t, tt.src, back.Elem().Interface())
+		})
+	}
+	t.Run("array", func(t *testing.T) {
+		var dst [2]byte
+		require.NoError(t, MapContext(ctx, uint16(300), &dst))
+		assert.Equal(t, [2]byte{0xac, 0x02}, dst)
+		var small [1]byte
+		assert.Error(t, MapContext(ctx, uint16(300), &small))
+	})
+	t.Run("overflow", func(t *testing.T) {
+		var dst uint8
+		assert.Error(t, MapContext(ctx, []byte{0xac, 0x02}, &dst))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		var dst uint64
+		assert.Error(t, MapContext(ctx, []byte{0xac}, &dst))
+		assert.Error(t, MapContext(ctx, []byte{0x01, 0x02}, &dst))
+		assert.Error(t, MapContext(ctx, []byte{}, &dst))
+	})
+	t.Run("float", func(t *testing.T) {
+		var dst []byte
+		require.NoError(t, MapContext(ctx, 1.0, &dst))
+		assert.Len(t, dst, 8)
+	})
+}
+
+// emptyZeroCodec encodes zero as no bytes.
+type emptyZeroCodec struct{ varintCodec }
+
+func (emptyZeroCodec) EncodeUint(v uint64) ([]byte, error) {
+	if v == 0 {
+		return nil, nil
+	}
+	return varintCodec{}.EncodeUint(v)
+}
+
+func TestNumberCodecEmptyBytes(t *testing.T) {
+	ctx := Default.Context.WithNumberCodec(emptyZeroCodec{})
+	dst := []byte{1}
+	require.NoError(t, MapContext(ctx, uint64(0), &dst))
+	assert.Empty(t, dst)
+}
@@ -0,0 +1,121 @@
+package anymapper
+
+import "reflect"
+
+// Collection is implemented by collection types, such as immutable lists,
+// ring buffers or typed sets, that can be mapped to slices, arrays and
+// other collections, as if they were slices.
+type Collection interface {
+	// Len returns the number of elements.
+	Len() int
+
+	// Elem returns the i-th element.
+	Elem(i int) any
+}
+
+// CollectionBuilder is implemented by collection types that slices, arrays
+// and other collections can be mapped to. The previous content of the
+// collection is replaced.
+type CollectionBuilder interface {
+	// ElemType returns the type of the elements.
+	ElemType() reflect.Type
+
+	// Reset removes all elements.
+	Reset()
+
+	// Append appends an element of the type returned by ElemType.
+	Append(v any) error
+}
+
+var (
+	collectionTy        = reflect.TypeOf((*Collection)(nil)).Elem()
+	collectionBuilderTy = reflect.TypeOf((*CollectionBuilder)(nil)).Elem()
+)
+
+// implCollection returns true if the type or a pointer to it implements
+// the given collection interface.
+func implCollection(t, iface reflect.Type) bool {
+	return t.Kind() != reflect.Interface && reflect.PointerTo(t).Implements(iface)
+}
+
+// collectionFunc returns the MapFunc for mappings where the source type
+// implements Collection or the destination type implements
+// CollectionBuilder, and the other side is a slice, an array or a
+// collection. It returns nil for other types.
+func collectionFunc(src, dst reflect.Type) MapFunc {
+	if src == dst {
+		return nil
+	}
+	var (
+		isSrcColl = implCollection(src, collectionTy)
+		isDstColl = implCollection(dst, collectionBuilderTy)
+		isSrcList = src.Kind() == reflect.Slice || src.Kind() == reflect.Array
+		isDstList = dst.Kind() == reflect.Slice || dst.Kind() == reflect.Array
+	)
+	switch {
+	case isSrcColl && (isDstColl || isDstList):
+		return mapFromCollection
+	case isDstColl && isSrcList:
+		return mapToCollection
+	}
+	return nil
+}
+
+// mapFromCollection maps a Collection to a slice, an array or a
+// CollectionBuilder, as if it was a slice.
+func mapFromCollection(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	coll := addr(src).Interface().(Collection)
+	elems := reflect.MakeSlice(reflect.SliceOf(anyTy), coll.Len(), coll.Len())
+	for i := 0; i < elems.Len(); i++ {
+		if v := coll.Elem(i); v != nil {
+			elems.Index(i).Set(reflect.ValueOf(v))
+		}
+	}
+	switch {
+	case implCollection(dst.Type(), collectionBuilderTy):
+		return mapToCollection(m, ctx, elems, dst)
+	case dst.Kind() == reflect.Slice:
+		return mapSliceToSlice(m, ctx, elems, dst)
+	}
+	return mapSliceToArray(m, ctx, elems, dst)
+}
+
+// mapToCollection maps a slice or an array to a CollectionBuilder. The
+// elements are mapped to a slice of the element type of the collection
+// first, and then appended to the collection.
+func mapToCollection(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	coll := dst.Addr().Interface().(CollectionBuilder)
+	elems := reflect.New(reflect.SliceOf(coll.ElemType())).Elem()
+	var err error
+	if src.Kind() == reflect.Slice {
+		err = mapSliceToSlice(m, ctx, src, elems)
+	} else {
+		err = mapArrayToSlice(m, ctx, src, elems)
+	}
+	if _, ok := err.(MappingErrors); err != nil && !ok {
+		return err
+	}
+	coll.Reset()
+	for i := 0; i < elems.Len(); i++ {
+		if err := coll.Append(elems.Index(i).Interface()); err != nil {
+			return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+		}
+	}
+	return err
+}
+
+// addr returns a pointer to the value. Unaddressable values are copied.
+func addr(v reflect.Value) reflect.Value {
+	if !v.CanAddr() {
+		cpy := reflect.New(v.Type()).Elem()
+		cpy.Set(v)
+		v = cpy
+	}
+	return v.Addr()
+}
@@ -0,0 +1,81 @@
+package anymapper
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testImmutableList is an immutable list that implements Collection.
+type testImmutableList struct {
+	elems []int
+}
+
+func (l testImmutableList) Len() int       { return len(l.elems) }
+func (l testImmutableList) Elem(i int) any { return l.elems[i] }
+
+// testRing is a buffer that implements Collection and CollectionBuilder.
+// If size is not zero, it is the maximum number of elements.
+type testRing struct {
+	elems []string
+	size  int
+}
+
+func (r *testRing) Len() int               { return len(r.elems) }
+func (r *testRing) Elem(i int) any         { return r.elems[i] }
+func (r *testRing) ElemType() reflect.Type { return reflect.TypeOf("") }
+func (r *testRing) Reset()                 { r.elems = nil }
+func (r *testRing) Append(v any) error {
+	if r.size > 0 && len(r.elems) == r.size {
+		return errors.New("ring is full")
+	}
+	r.elems = append(r.elems, v.(string))
+	return nil
+}
+
+func TestCollection(t *testing.T) {
+	t.Run("collection-to-slice", func(t *testing.T) {
+		var dst []string
+		require.NoError(t, Map(testImmutableList{elems: []int{1, 2}}, &dst))
+		assert.Equal(t, []string{"1", "2"}, dst)
+	})
+	t.Run("collection-to-array", func(t *testing.T) {
+		var dst [2]uint8
+		require.NoError(t, Map(testImmutableList{elems: []int{1, 2}}, &dst))
+		assert.Equal(t, [2]uint8{1, 2}, dst)
+	})
+	t.Run("slice-to-collection", func(t *testing.T) {
+		dst := testRing{elems: []string{"old"}, size: 3}
+		require.NoError(t, Map([]int{1, 2}, &dst))
+		assert.Equal(t, []string{"1", "2"}, dst.elems)
+	})
+	t.Run("array-to-collection", func(t *testing.T) {
+		dst := testRing{size: 3}
+		require.NoError(t, Map([2]bool{true, false}, &dst))
+		assert.Equal(t, []string{"true", "false"}, dst.elems)
+	})
+	t.Run("collection-to-collection", func(t *testing.T) {
+		dst := testRing{size: 3}
+		require.NoError(t, Map(testImmutableList{elems: []int{7}}, &dst))
+		assert.Equal(t, []string{"7"}, dst.elems)
+	})
+	t.Run("struct-field", func(t *testing.T) {
+		type Dst struct {
+			Ring *testRing
+		}
+		var dst Dst
+		require.NoError(t, Map(map[string]any{"Ring": []any{"a", 1}}, &dst))
+		assert.Equal(t, []string{"a", "1"}, dst.Ring.elems)
+	})
+	t.Run("append-error", func(t *testing.T) {
+		dst := testRing{size: 1}
+		assert.Error(t, Map([]string{"a", "b"}, &dst))
+	})
+	t.Run("element-error", func(t *testing.T) {
+		var dst []uint
+		assert.Error(t, Map(testImmutableList{elems: []int{-1}}, &dst))
+	})
+}
@@ -0,0 +1,50 @@
+package anymapper
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Converter is a named conversion function that can be applied to struct
+// fields using the conv tag option, e.g. `map:"price,conv=toCents"`.
+//
+// The function receives the source value of the field and returns the
+// converted value, which is then mapped to the destination using the usual
+// mapping rules. If the function returns nil, the destination is set to its
+// zero value.
+type Converter func(src any) (any, error)
+
+// RegisterNamedConverter registers a converter that can be referenced by
+// name from the conv tag option. If a converter with the same name already
+// exists, it is replaced.
+//
+// Converters must be registered before the mapper is used, the method is
+// not safe for concurrent use with the mapping methods.
+func (m *Mapper) RegisterNamedConverter(name string, fn Converter) {
+	if m.Converters == nil {
+		m.Converters = make(map[string]Converter)
+	}
+	m.Converters[name] = fn
+}
+
+// convert applies the named converter to the source value. It returns an
+// invalid value if the converter returned nil.
+func (m *Mapper) convert(ctx *Context, name string, src, dst reflect.Value) (reflect.Value, error) {
+	fn, ok := m.Converters[name]
+	if !ok {
+		return reflect.Value{}, NewInvalidMappingError(src.Type(), dst.Type(), "unknown converter: "+name)
+	}
+	if !src.CanInterface() {
+		return reflect.Value{}, NewInvalidMappingError(src.Type(), dst.Type(), "unexported value")
+	}
+	v, err := fn(src.Interface())
+	if err != nil {
+		return reflect.Value{}, &InvalidMappingErr{
+			From:   src.Type(),
+			To:     dst.Type(),
+			Reason: fmt.Sprintf("converter %s: %v", name, err),
+			Err:    err,
+		}
+	}
+	return m.srcValue(ctx, reflect.ValueOf(v)), nil
+}
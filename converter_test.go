@@ -0,0 +1,87 @@
+package anymapper
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamedConverter(t *testing.T) {
+	m := New()
+	m.RegisterNamedConverter("toCents", func(src any) (any, error) {
+		f, ok := src.(float64)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type %T", src)
+		}
+		return int64(f*100 + 0.5), nil
+	})
+	m.RegisterNamedConverter("fromCents", func(src any) (any, error) {
+		return float64(src.(int64)) / 100, nil
+	})
+	m.RegisterNamedConverter("nil", func(src any) (any, error) {
+		return nil, nil
+	})
+	type product struct {
+		Price float64 `map:"price"`
+	}
+	type row struct {
+		Price int64 `map:"price,conv=toCents"`
+	}
+	t.Run("struct-to-struct", func(t *testing.T) {
+		var dst row
+		require.NoError(t, m.Map(product{Price: 12.34}, &dst))
+		assert.Equal(t, int64(1234), dst.Price)
+	})
+	t.Run("map-to-struct", func(t *testing.T) {
+		var dst row
+		require.NoError(t, m.Map(map[string]any{"price": 0.5}, &dst))
+		assert.Equal(t, int64(50), dst.Price)
+	})
+	t.Run("struct-to-map", func(t *testing.T) {
+		type src struct {
+			Price int64 `map:"price,conv=fromCents"`
+		}
+		var dst map[string]string
+		require.NoError(t, m.Map(src{Price: 1250}, &dst))
+		assert.Equal(t, map[string]string{"price": "12.5"}, dst)
+	})
+	t.Run("nil", func(t *testing.T) {
+		type dstTy struct {
+			Price int64 `map:"price,conv=nil"`
+		}
+		dst := dstTy{Price: 1}
+		require.NoError(t, m.Map(product{Price: 1}, &dst))
+		assert.Equal(t, int64(0), dst.Price)
+	})
+	t.Run("error", func(t *testing.T) {
+		var dst row
+		err := m.Map(map[string]any{"price": "1"}, &dst)
+		var mErr *InvalidMappingErr
+		require.True(t, errors.As(err, &mErr))
+		assert.Contains(t, mErr.Reason, "converter toCents")
+	})
+	t.Run("unknown", func(t *testing.T) {
+		type dstTy struct {
+			Price int64 `map:"price,conv=unknown"`
+		}
+		var dst dstTy
+		assert.Error(t, m.Map(product{Price: 1}, &dst))
+	})
+	t.Run("field-converters", func(t *testing.T) {
+		type dstTy struct {
+			Price int64 `map:"price"`
+		}
+		var dst dstTy
+		ctx := m.Context.WithFieldConverters(map[string]string{"Price": "toCents"})
+		require.NoError(t, m.MapContext(ctx, product{Price: 1.5}, &dst))
+		assert.Equal(t, int64(150), dst.Price)
+	})
+	t.Run("copy", func(t *testing.T) {
+		cpy := m.Copy()
+		delete(cpy.Converters, "toCents")
+		assert.Contains(t, m.Converters, "toCents")
+	})
+}
@@ -0,0 +1,135 @@
+package anymapper
+
+import "reflect"
+
+// Decoder maps successive source values into the same destination value.
+//
+// The destination value is resolved once, when the decoder is created, and
+// the mapping function is resolved once for every source type, which makes
+// the decoder faster than calling Map repeatedly. It is useful for streaming
+// consumers that decode many records of the same shape. Every Decode call
+// is a separate mapping call, so Context.MaxElements and
+// Context.PreserveIdentity apply to every source separately.
+//
+// Decoder is not safe for concurrent use.
+type Decoder struct {
+	// Merge disables resetting the destination value to its zero value
+	// before every Decode call. If enabled, values from the previous source
+	// that are not present in the next one are retained.
+	Merge bool
+
+	// CollectMetadata enables collecting the metadata of every Decode call,
+	// which is returned by the Metadata method.
+	CollectMetadata bool
+
+	m       *Mapper
+	ctx     *Context
+	metaCtx *Context
+	dst     reflect.Value
+	err     error
+	mapper  *typeMapper
+	meta    Metadata
+}
+
+// Metadata describes the last Decode call of a Decoder.
+type Metadata struct {
+	// Unused are the keys of the source maps, or the fields of the source
+	// structs, that were not mapped to the destination.
+	Unused []string
+
+	// Missing are the keys of the destination struct fields for which the
+	// source had no value.
+	Missing []string
+}
+
+// NewDecoder returns a new Decoder that maps source values into dst using
+// the Default mapper. The dst must be a pointer.
+//
+// It is shorthand for Default.NewDecoder(dst, opts...).
+func NewDecoder(dst any, opts ...Option) *Decoder {
+	return Default.NewDecoder(dst, opts...)
+}
+
+// NewDecoder returns a new Decoder that maps source values into dst. The dst
+// must be a pointer or a non-nil map.
+func (m *Mapper) NewDecoder(dst any, opts ...Option) *Decoder {
+	d := &Decoder{
+		m:      m,
+		ctx:    applyOptions(m.Context, opts),
+		mapper: &typeMapper{},
+	}
+	d.dst = m.dstValue(d.ctx, reflect.ValueOf(dst))
+	if !d.dst.IsValid() || (!d.dst.CanSet() && d.dst.Kind() != reflect.Map) {
+		d.err = InvalidDstErr
+	}
+	return d
+}
+
+// Decode maps src into the destination value of the decoder.
+func (d *Decoder) Decode(src any) error {
+	if d.err != nil {
+		return d.err
+	}
+	srcVal := d.m.srcValue(d.ctx, reflect.ValueOf(src))
+	if !srcVal.IsValid() {
+		return InvalidSrcErr
+	}
+	if !d.Merge {
+		d.reset()
+	}
+	ctx := d.ctx
+	if d.CollectMetadata {
+		d.meta = Metadata{}
+		ctx = d.metadataContext()
+	}
+	ctx = ctx.withState(srcVal, d.dst).withPathMappers(d.m)
+	return d.m.mapValue(ctx, &d.mapper, srcVal, d.dst)
+}
+
+// Metadata returns the metadata of the last Decode call. It is empty unless
+// CollectMetadata is enabled.
+func (d *Decoder) Metadata() Metadata {
+	return d.meta
+}
+
+// metadataContext returns the context of the decoder with the hooks that
+// collect the metadata. The hooks of the context are still called.
+func (d *Decoder) metadataContext() *Context {
+	if d.metaCtx != nil {
+		return d.metaCtx
+	}
+	var hooks Hooks
+	if d.ctx.Hooks != nil {
+		hooks = *d.ctx.Hooks
+	}
+	missing, unmapped := hooks.MissingFieldHook, hooks.UnmappedKeyHook
+	hooks.MissingFieldHook = func(m *Mapper, ctx *Context, field reflect.StructField, key string, dst reflect.Value) error {
+		d.meta.Missing = append(d.meta.Missing, key)
+		if missing != nil {
+			return missing(m, ctx, field, key, dst)
+		}
+		return nil
+	}
+	hooks.UnmappedKeyHook = func(m *Mapper, ctx *Context, key string, src reflect.Value) error {
+		d.meta.Unused = append(d.meta.Unused, key)
+		if unmapped != nil {
+			return unmapped(m, ctx, key, src)
+		}
+		return nil
+	}
+	d.metaCtx = d.ctx.WithHooks(&hooks)
+	return d.metaCtx
+}
+
+// reset sets the destination value to its zero value. Maps that cannot be
+// replaced, because they were passed to the decoder directly, are cleared.
+func (d *Decoder) reset() {
+	if !d.dst.CanSet() {
+		for _, key := range d.dst.MapKeys() {
+			d.dst.SetMapIndex(key, reflect.Value{})
+		}
+		return
+	}
+	d.dst.Set(reflect.Zero(d.dst.Type()))
+	d.m.initValue(d.dst)
+}
@@ -0,0 +1,107 @@
+package anymapper
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder(t *testing.T) {
+	type Record struct {
+		A int
+		B string
+	}
+	t.Run("struct", func(t *testing.T) {
+		var dst Record
+		dec := NewDecoder(&dst)
+		require.NoError(t, dec.Decode(map[string]any{"A": "1", "B": "foo"}))
+		assert.Equal(t, Record{A: 1, B: "foo"}, dst)
+		require.NoError(t, dec.Decode(map[string]any{"A": 2}))
+		assert.Equal(t, Record{A: 2}, dst)
+	})
+	t.Run("merge", func(t *testing.T) {
+		var dst Record
+		dec := NewDecoder(&dst)
+		dec.Merge = true
+		require.NoError(t, dec.Decode(map[string]any{"A": "1", "B": "foo"}))
+		require.NoError(t, dec.Decode(map[string]any{"A": 2}))
+		assert.Equal(t, Record{A: 2, B: "foo"}, dst)
+	})
+	t.Run("map", func(t *testing.T) {
+		var dst map[string]int
+		dec := NewDecoder(&dst)
+		require.NoError(t, dec.Decode(map[string]any{"a": "1"}))
+		assert.Equal(t, map[string]int{"a": 1}, dst)
+		require.NoError(t, dec.Decode(struct{ A int }{A: 2}))
+		assert.Equal(t, map[string]int{"A": 2}, dst)
+	})
+	t.Run("map-value", func(t *testing.T) {
+		dst := map[string]int{"old": 1}
+		dec := NewDecoder(dst)
+		require.NoError(t, dec.Decode(map[string]any{"a": "1"}))
+		assert.Equal(t, map[string]int{"a": 1}, dst)
+	})
+	t.Run("options", func(t *testing.T) {
+		var dst Record
+		dec := NewDecoder(&dst, WithStrictTypes(true))
+		assert.Error(t, dec.Decode(map[string]any{"A": "1"}))
+		require.NoError(t, dec.Decode(map[string]any{"A": 1}))
+		assert.Equal(t, Record{A: 1}, dst)
+	})
+	t.Run("invalid-dst", func(t *testing.T) {
+		dec := NewDecoder(nil)
+		assert.ErrorIs(t, dec.Decode(1), InvalidDstErr)
+		dec = NewDecoder(Record{})
+		assert.ErrorIs(t, dec.Decode(1), InvalidDstErr)
+	})
+	t.Run("max-elements", func(t *testing.T) {
+		var dst []int
+		dec := NewDecoder(&dst, WithMaxElements(2))
+		assert.ErrorIs(t, dec.Decode([]string{"1", "2", "3", "4", "5"}), LimitExceededErr)
+		require.NoError(t, dec.Decode([]string{"1", "2"}))
+		require.NoError(t, dec.Decode([]string{"3", "4"}))
+		assert.Equal(t, []int{3, 4}, dst)
+	})
+	t.Run("preserve-identity", func(t *testing.T) {
+		type node struct{ N int }
+		type pair struct{ A, B *node }
+		var dst pair
+		dec := NewDecoder(&dst, WithPreserveIdentity(true))
+		shared := &node{N: 1}
+		require.NoError(t, dec.Decode(pair{A: shared, B: shared}))
+		assert.Same(t, dst.A, dst.B)
+	})
+	t.Run("path-map-func", func(t *testing.T) {
+		m := New()
+		m.AddPathMapFunc("A", func(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+			dst.SetInt(src.Int() * 10)
+			return nil
+		})
+		var dst Record
+		dec := m.NewDecoder(&dst)
+		require.NoError(t, dec.Decode(Record{A: 1, B: "foo"}))
+		assert.Equal(t, Record{A: 10, B: "foo"}, dst)
+	})
+	t.Run("metadata", func(t *testing.T) {
+		var dst Record
+		var hooked []string
+		hooks := &Hooks{UnmappedKeyHook: func(m *Mapper, ctx *Context, key string, src reflect.Value) error {
+			hooked = append(hooked, key)
+			return nil
+		}}
+		dec := NewDecoder(&dst, WithHooks(hooks))
+		dec.CollectMetadata = true
+		require.NoError(t, dec.Decode(map[string]any{"A": 1, "C": 2}))
+		assert.Equal(t, Metadata{Unused: []string{"C"}, Missing: []string{"B"}}, dec.Metadata())
+		assert.Equal(t, []string{"C"}, hooked)
+		require.NoError(t, dec.Decode(map[string]any{"A": 1, "B": "foo"}))
+		assert.Equal(t, Metadata{}, dec.Metadata())
+	})
+	t.Run("invalid-src", func(t *testing.T) {
+		var dst Record
+		dec := NewDecoder(&dst)
+		assert.ErrorIs(t, dec.Decode(nil), InvalidSrcErr)
+	})
+}
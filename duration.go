@@ -0,0 +1,149 @@
+package anymapper
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// durationTypeMapper maps time.Duration values to and from strings in the
+// format of time.ParseDuration, e.g. "1h30m", and to and from floats and
+// big.Float values as seconds. Other numbers, including big.Int values, are
+// nanoseconds and are mapped using the built-in integer rules.
+func durationTypeMapper(m *Mapper, src, dst reflect.Type) MapFunc {
+	if src == dst {
+		return mapDirect
+	}
+	switch {
+	case src == durationTy:
+		switch dst.Kind() {
+		case reflect.String:
+			return mapDurationToString
+		case reflect.Float32, reflect.Float64:
+			return mapDurationToFloat
+		case reflect.Struct:
+			if dst == bigFloatTy {
+				return mapDurationToBigFloat
+			}
+		}
+	case dst == durationTy:
+		switch src.Kind() {
+		case reflect.String:
+			return mapStringToDuration
+		case reflect.Float32, reflect.Float64:
+			return mapFloatToDuration
+		case reflect.Struct:
+			if src == bigFloatTy {
+				return mapBigFloatToDuration
+			}
+		}
+	}
+	if dst == anyTy {
+		return mapAny
+	}
+	if isByteSliceOrArray(src) || isByteSliceOrArray(dst) {
+		return mapDurationBytes(builtInTypesMapper(m, src, dst))
+	}
+	return builtInTypesMapper(m, src, dst)
+}
+
+// mapDurationBytes returns a function that maps durations to and from byte
+// slices using mapFunc, or using their string representations if the byte
+// slice is treated as text.
+func mapDurationBytes(mapFunc MapFunc) MapFunc {
+	if mapFunc == nil {
+		return nil
+	}
+	return func(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+		switch {
+		case isTextBytes(ctx, src):
+			return textError(src, mapStringToDuration(m, ctx, reflect.ValueOf(string(src.Bytes())), dst))
+		case isTextBytes(ctx, dst):
+			dst.SetBytes([]byte(time.Duration(src.Int()).String()))
+			return nil
+		}
+		return mapFunc(m, ctx, src, dst)
+	}
+}
+
+func mapDurationToString(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	dst.SetString(time.Duration(src.Int()).String())
+	return nil
+}
+
+func mapDurationToFloat(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	dst.SetFloat(time.Duration(src.Int()).Seconds())
+	return nil
+}
+
+func mapDurationToBigFloat(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	bf := new(big.Float).SetInt64(src.Int())
+	bf = bf.Quo(bf, big.NewFloat(1e9))
+	dst.Set(reflect.ValueOf(bf).Elem())
+	return nil
+}
+
+// mapStringToDuration parses durations in the time.ParseDuration format.
+// Integers without units are nanoseconds, as they were before durations
+// had their own format.
+func mapStringToDuration(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	s := ctx.parseInput(src.String())
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		n, nerr := strconv.ParseInt(s, 10, 64)
+		if nerr != nil {
+			return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+		}
+		d = time.Duration(n)
+	}
+	dst.SetInt(int64(d))
+	return nil
+}
+
+func mapFloatToDuration(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	ns := src.Float() * 1e9
+	if math.IsNaN(ns) {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "NaN")
+	}
+	if ns >= math.MaxInt64 || ns < math.MinInt64 {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "overflow")
+	}
+	if ctx.Lossless && isFraction(ns) {
+		return newLossError(src.Type(), dst.Type())
+	}
+	dst.SetInt(int64(ns))
+	return nil
+}
+
+func mapBigFloatToDuration(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	bf := src.Addr().Interface().(*big.Float)
+	ns, acc := new(big.Float).Mul(bf, big.NewFloat(1e9)).Int(nil)
+	if !ns.IsInt64() {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "overflow")
+	}
+	if ctx.Lossless && acc != big.Exact {
+		return newLossError(src.Type(), dst.Type())
+	}
+	dst.SetInt(ns.Int64())
+	return nil
+}
@@ -0,0 +1,92 @@
+package anymapper
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuration(t *testing.T) {
+	const d = time.Hour + 30*time.Minute
+
+	t.Run("string", func(t *testing.T) {
+		var s string
+		require.NoError(t, Map(d, &s))
+		assert.Equal(t, "1h30m0s", s)
+		var dst time.Duration
+		require.NoError(t, Map("1h30m", &dst))
+		assert.Equal(t, d, dst)
+		require.NoError(t, Map("-1.5s", &dst))
+		assert.Equal(t, -1500*time.Millisecond, dst)
+	})
+	t.Run("string-nanoseconds", func(t *testing.T) {
+		var dst time.Duration
+		require.NoError(t, Map("1500", &dst))
+		assert.Equal(t, time.Duration(1500), dst)
+	})
+	t.Run("int", func(t *testing.T) {
+		var n int64
+		require.NoError(t, Map(d, &n))
+		assert.Equal(t, int64(d), n)
+		var dst time.Duration
+		require.NoError(t, Map(uint32(1000), &dst))
+		assert.Equal(t, time.Microsecond, dst)
+	})
+	t.Run("float", func(t *testing.T) {
+		var f float64
+		require.NoError(t, Map(1500*time.Millisecond, &f))
+		assert.Equal(t, 1.5, f)
+		var dst time.Duration
+		require.NoError(t, Map(0.25, &dst))
+		assert.Equal(t, 250*time.Millisecond, dst)
+	})
+	t.Run("big-int", func(t *testing.T) {
+		var bi big.Int
+		require.NoError(t, Map(d, &bi))
+		assert.Equal(t, big.NewInt(int64(d)), &bi)
+		var dst time.Duration
+		require.NoError(t, Map(big.NewInt(5), &dst))
+		assert.Equal(t, time.Duration(5), dst)
+	})
+	t.Run("big-float", func(t *testing.T) {
+		var bf big.Float
+		require.NoError(t, Map(1500*time.Millisecond, &bf))
+		f, _ := bf.Float64()
+		assert.Equal(t, 1.5, f)
+		var dst time.Duration
+		require.NoError(t, Map(big.NewFloat(2.5), &dst))
+		assert.Equal(t, 2500*time.Millisecond, dst)
+	})
+	t.Run("text-bytes", func(t *testing.T) {
+		ctx := Default.Context.WithTextBytes(true)
+		var b []byte
+		require.NoError(t, MapContext(ctx, d, &b))
+		assert.Equal(t, []byte("1h30m0s"), b)
+		var dst time.Duration
+		require.NoError(t, MapContext(ctx, []byte("2m"), &dst))
+		assert.Equal(t, 2*time.Minute, dst)
+	})
+	t.Run("any", func(t *testing.T) {
+		var dst any
+		require.NoError(t, Map(d, &dst))
+		assert.Equal(t, d, dst)
+	})
+	t.Run("lossless", func(t *testing.T) {
+		var dst time.Duration
+		ctx := Default.Context.WithLossless(true)
+		require.NoError(t, MapContext(ctx, 0.5, &dst))
+		assert.Error(t, MapContext(ctx, 1e-10, &dst))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		var dst time.Duration
+		assert.Error(t, Map("1x", &dst))
+		assert.Error(t, Map(math.NaN(), &dst))
+		assert.Error(t, Map(1e10, &dst))
+		assert.Error(t, Map(big.NewFloat(1e10), &dst))
+		assert.Error(t, MapContext(Default.Context.WithStrictTypes(true), "1s", &dst))
+	})
+}
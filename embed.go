@@ -0,0 +1,228 @@
+package anymapper
+
+import (
+	"reflect"
+	"strings"
+)
+
+// structFields returns the mapped fields of the struct type t, with the
+// fields of embedded structs promoted as if they were declared in t, the
+// same way as in the encoding/json package. The Index of a promoted field
+// is the index sequence used by reflect.Value.FieldByIndex.
+//
+// If t has no embedded structs to promote, nil is returned and the fields
+// of t are used directly, see numFields and fieldAt.
+//
+// An embedded struct is mapped as a regular field if its tag has a name or
+// the "nested" option, or if its type has a registered mapper provider,
+// like time.Time. If multiple fields have the same key, the one that is
+// the least nested is used. If there are multiple such fields, the one
+// with a tag name is used, and if there is none or more than one, all of
+// them are ignored.
+//
+// The result is cached, unless the keys of the fields depend on the
+// FieldMapper or Renames fields of the context.
+func (m *Mapper) structFields(ctx *Context, t reflect.Type) []reflect.StructField {
+	c := m.cacheFor(ctx)
+	if c == nil || ctx.FieldMapper != nil || len(ctx.Renames) > 0 {
+		return m.resolveStructFields(ctx, t)
+	}
+	k := fieldsKey{typ: t, tag: ctx.Tag}
+	if v, ok := c.fields.Load(k); ok {
+		return v.([]reflect.StructField)
+	}
+	fields := m.resolveStructFields(ctx, t)
+	c.fields.Store(k, fields)
+	return fields
+}
+
+// fieldsKey is the key of the fields cached by structFields.
+type fieldsKey struct {
+	typ reflect.Type
+	tag string
+}
+
+// resolveStructFields returns the fields of the struct type t, see
+// structFields.
+func (m *Mapper) resolveStructFields(ctx *Context, t reflect.Type) []reflect.StructField {
+	promotes := false
+	for i := 0; i < t.NumField(); i++ {
+		if m.promotes(ctx, t.Field(i)) {
+			promotes = true
+			break
+		}
+	}
+	if !promotes {
+		return nil
+	}
+	var (
+		fields []promotedField
+		count  = map[string]int{}
+	)
+	m.collectFields(ctx, t, nil, 0, map[reflect.Type]bool{t: true}, &fields)
+	// Find the dominant field for every key.
+	best := map[string]promotedField{}
+	for _, f := range fields {
+		b, ok := best[f.key]
+		switch {
+		case !ok || f.depth < b.depth:
+			best[f.key] = f
+			count[f.key] = 1
+		case f.depth == b.depth:
+			if f.tagged && !b.tagged {
+				best[f.key] = f
+				count[f.key] = 1
+			} else if f.tagged == b.tagged {
+				count[f.key]++
+			}
+		}
+	}
+	res := make([]reflect.StructField, 0, len(fields))
+	for _, f := range fields {
+		if count[f.key] == 1 && sameIndex(best[f.key].field.Index, f.field.Index) {
+			res = append(res, f.field)
+		}
+	}
+	return res
+}
+
+// promotedField is a candidate field collected by collectFields.
+type promotedField struct {
+	field  reflect.StructField
+	key    string
+	depth  int
+	tagged bool
+}
+
+// collectFields appends the fields of t, and the fields of its embedded
+// structs, in the order of declaration. Types on the visited path are not
+// visited again, so recursive types through pointers are supported.
+func (m *Mapper) collectFields(ctx *Context, t reflect.Type, index []int, depth int, visited map[reflect.Type]bool, fields *[]promotedField) {
+	for i := 0; i < t.NumField(); i++ {
+		fld := t.Field(i)
+		fld.Index = append(append([]int{}, index...), i)
+		if m.promotes(ctx, fld) {
+			ft := fld.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if visited[ft] {
+				continue
+			}
+			visited[ft] = true
+			m.collectFields(ctx, ft, fld.Index, depth+1, visited, fields)
+			delete(visited, ft)
+			continue
+		}
+		if !m.mappedField(fld) {
+			continue
+		}
+		tag := m.parseTag(ctx, fld)
+		if tag.Skip {
+			continue
+		}
+		raw := fld.Tag.Get(ctx.Tag)
+		name, _, _ := strings.Cut(raw, ",")
+		*fields = append(*fields, promotedField{
+			field:  fld,
+			key:    tag.Name,
+			depth:  depth,
+			tagged: name != "",
+		})
+	}
+}
+
+// promotes reports whether the fields of the embedded struct field f are
+// promoted.
+func (m *Mapper) promotes(ctx *Context, f reflect.StructField) bool {
+	if !f.Anonymous {
+		return false
+	}
+	t := f.Type
+	if t.Kind() == reflect.Pointer {
+		if !f.IsExported() {
+			// Pointers to unexported types cannot be initialized.
+			return false
+		}
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || m.IgnoredTypes[t] {
+		return false
+	}
+	raw, ok := f.Tag.Lookup(ctx.Tag)
+	if ok {
+		name, opts, _ := strings.Cut(raw, ",")
+		if name != "" || hasTagOption(opts, "nested") {
+			return false
+		}
+	}
+	if _, ok := m.provider(ctx, t); ok {
+		return false
+	}
+	return true
+}
+
+// hasTagOption reports whether the comma-separated tag options contain
+// the given option.
+func hasTagOption(opts, opt string) bool {
+	for opts != "" {
+		var o string
+		o, opts, _ = strings.Cut(opts, ",")
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+func sameIndex(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// numFields returns the number of fields returned by fieldAt.
+func numFields(t reflect.Type, fields []reflect.StructField) int {
+	if fields == nil {
+		return t.NumField()
+	}
+	return len(fields)
+}
+
+// fieldAt returns the i-th field of the struct type t, or the i-th field
+// of fields, if it is not nil.
+func fieldAt(t reflect.Type, fields []reflect.StructField, i int) reflect.StructField {
+	if fields == nil {
+		return t.Field(i)
+	}
+	return fields[i]
+}
+
+// fieldByIndex returns the field of the struct v with the given index
+// sequence. If a pointer to an embedded struct on the way is nil, it is
+// initialized if init is true and v is settable, otherwise an invalid
+// value is returned.
+func fieldByIndex(v reflect.Value, index []int, init bool) reflect.Value {
+	if len(index) == 1 {
+		return v.Field(index[0])
+	}
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !init || !v.CanSet() {
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
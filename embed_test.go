@@ -0,0 +1,159 @@
+package anymapper
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type embedBase struct {
+	ID      int    `map:"id"`
+	Created string `map:"created"`
+}
+
+type EmbedAudit struct {
+	By string `map:"by"`
+}
+
+func TestEmbeddedStructs(t *testing.T) {
+	type user struct {
+		embedBase
+		*EmbedAudit
+		Name string `map:"name"`
+	}
+	type flat struct {
+		ID   int    `map:"id"`
+		By   string `map:"by"`
+		Name string `map:"name"`
+	}
+
+	t.Run("struct-to-map", func(t *testing.T) {
+		var dst map[string]any
+		src := user{embedBase: embedBase{ID: 1, Created: "x"}, EmbedAudit: &EmbedAudit{By: "a"}, Name: "n"}
+		require.NoError(t, Map(src, &dst))
+		assert.Equal(t, map[string]any{"id": 1, "created": "x", "by": "a", "name": "n"}, dst)
+	})
+	t.Run("nil-embedded-pointer", func(t *testing.T) {
+		var dst map[string]any
+		require.NoError(t, Map(user{Name: "n"}, &dst))
+		assert.Equal(t, map[string]any{"id": 0, "created": "", "name": "n"}, dst)
+	})
+	t.Run("map-to-struct", func(t *testing.T) {
+		var dst user
+		require.NoError(t, Map(map[string]any{"id": "2", "by": "b", "name": "n"}, &dst))
+		assert.Equal(t, 2, dst.ID)
+		assert.Equal(t, "n", dst.Name)
+		require.NotNil(t, dst.EmbedAudit)
+		assert.Equal(t, "b", dst.By)
+
+		// Pointers are not initialized if none of their fields is mapped.
+		var other user
+		require.NoError(t, Map(map[string]any{"id": 3}, &other))
+		assert.Nil(t, other.EmbedAudit)
+	})
+	t.Run("struct-to-struct", func(t *testing.T) {
+		var dst flat
+		require.NoError(t, Map(user{embedBase: embedBase{ID: 4}, EmbedAudit: &EmbedAudit{By: "c"}, Name: "n"}, &dst))
+		assert.Equal(t, flat{ID: 4, By: "c", Name: "n"}, dst)
+
+		var back user
+		require.NoError(t, Map(dst, &back))
+		assert.Equal(t, 4, back.ID)
+		require.NotNil(t, back.EmbedAudit)
+		assert.Equal(t, "c", back.By)
+	})
+	t.Run("shadowing", func(t *testing.T) {
+		type outer struct {
+			embedBase
+			ID string `map:"id"`
+		}
+		var dst map[string]any
+		require.NoError(t, Map(outer{embedBase: embedBase{ID: 1}, ID: "outer"}, &dst))
+		assert.Equal(t, map[string]any{"id": "outer", "created": ""}, dst)
+	})
+	t.Run("ambiguous", func(t *testing.T) {
+		type a struct{ X int }
+		type b struct{ X int }
+		type c struct {
+			X int `map:"X"`
+		}
+		type both struct {
+			a
+			b
+		}
+		var dst map[string]any
+		require.NoError(t, Map(both{a: a{X: 1}, b: b{X: 2}}, &dst))
+		assert.Empty(t, dst)
+
+		type tagged struct {
+			a
+			c
+		}
+		require.NoError(t, Map(tagged{a: a{X: 1}, c: c{X: 3}}, &dst))
+		assert.Equal(t, map[string]any{"X": 3}, dst)
+	})
+	t.Run("opt-out", func(t *testing.T) {
+		type named struct {
+			EmbedAudit `map:"audit"`
+			Name       string `map:"name"`
+		}
+		type nested struct {
+			*EmbedAudit `map:",nested"`
+		}
+		var dst map[string]any
+		require.NoError(t, Map(named{EmbedAudit: EmbedAudit{By: "a"}, Name: "n"}, &dst))
+		assert.Equal(t, map[string]any{"audit": EmbedAudit{By: "a"}, "name": "n"}, dst)
+
+		dst = nil
+		require.NoError(t, Map(nested{EmbedAudit: &EmbedAudit{By: "b"}}, &dst))
+		assert.Equal(t, map[string]any{"EmbedAudit": EmbedAudit{By: "b"}}, dst)
+	})
+	t.Run("provider-types", func(t *testing.T) {
+		type event struct {
+			time.Time
+		}
+		ts := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+		var dst map[string]any
+		require.NoError(t, Map(event{Time: ts}, &dst))
+		assert.Equal(t, map[string]any{"Time": ts.Unix()}, dst)
+	})
+	t.Run("kv-and-paths", func(t *testing.T) {
+		var kv []KV
+		require.NoError(t, Map(user{embedBase: embedBase{ID: 1}, Name: "n"}, &kv))
+		assert.Equal(t, []KV{{Key: "id", Value: 1}, {Key: "created", Value: ""}, {Key: "name", Value: "n"}}, kv)
+
+		var dst user
+		require.NoError(t, MapPath(map[string]any{"x": "b"}, "x", &dst, "by"))
+		require.NotNil(t, dst.EmbedAudit)
+		assert.Equal(t, "b", dst.By)
+	})
+}
+
+func TestStructFieldsCache(t *testing.T) {
+	type user struct {
+		embedBase
+		Name string `map:"name" json:"full_name"`
+	}
+	m := New()
+	typ := reflect.TypeOf(user{})
+	fields := m.structFields(m.Context, typ)
+	require.Len(t, fields, 3)
+	assert.Same(t, &fields[0], &m.structFields(m.Context, typ)[0])
+
+	// The fields are cached separately for every tag.
+	var dst map[string]any
+	require.NoError(t, m.MapContext(m.Context.WithTag("json"), user{Name: "a"}, &dst))
+	assert.Equal(t, "a", dst["full_name"])
+
+	// Field mappers are applied to uncached fields.
+	dst = nil
+	require.NoError(t, m.MapContext(m.Context.WithTag("-").WithFieldMapper(strings.ToLower), user{Name: "a"}, &dst))
+	assert.Equal(t, "a", dst["name"])
+
+	m.InvalidateCache()
+	assert.NotSame(t, &fields[0], &m.structFields(m.Context, typ)[0])
+}
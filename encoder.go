@@ -0,0 +1,155 @@
+package anymapper
+
+import (
+	"math/big"
+	"reflect"
+)
+
+// Encode maps the source value to a map[string]any using the Default
+// mapper.
+//
+// It is shorthand for Default.Encode(src, opts...).
+func Encode(src any, opts ...Option) (map[string]any, error) {
+	return Default.Encode(src, opts...)
+}
+
+// EncodeSlice maps every element of the source slice or array to a
+// map[string]any using the Default mapper.
+//
+// It is shorthand for Default.EncodeSlice(src, opts...).
+func EncodeSlice(src any, opts ...Option) ([]map[string]any, error) {
+	return Default.EncodeSlice(src, opts...)
+}
+
+// Encode maps the source value, usually a struct, to a map[string]any.
+//
+// Unlike mapping a struct to a map using Map, Encode converts values
+// recursively: nested structs and maps with string keys are converted to
+// map[string]any, and slices and arrays of them are converted to []any.
+// Types that have a registered mapper provider, such as time.Time or
+// big.Int, are left as is.
+//
+// If Context.FlattenSeparator is set, nested maps are flattened into the
+// returned map.
+func (m *Mapper) Encode(src any, opts ...Option) (map[string]any, error) {
+	ctx := encodeContext(applyOptions(m.Context, opts))
+	return m.encode(ctx, reflect.ValueOf(src))
+}
+
+// EncodeSlice maps every element of the source slice or array to a
+// map[string]any in the same way as Encode.
+func (m *Mapper) EncodeSlice(src any, opts ...Option) ([]map[string]any, error) {
+	ctx := encodeContext(applyOptions(m.Context, opts))
+	srcVal := m.srcValue(ctx, reflect.ValueOf(src))
+	if !srcVal.IsValid() {
+		return nil, InvalidSrcErr
+	}
+	if srcVal.Kind() != reflect.Slice && srcVal.Kind() != reflect.Array {
+		return nil, NewInvalidMappingError(srcVal.Type(), reflect.TypeOf([]map[string]any{}), "source must be a slice or an array")
+	}
+	res := make([]map[string]any, srcVal.Len())
+	for i := range res {
+		v, err := m.encode(ctx, srcVal.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		res[i] = v
+	}
+	return res, nil
+}
+
+// encodeContext returns a copy of the context in which values of types with
+// mapper providers are assigned to the any type as is, instead of being
+// mapped by their providers.
+func encodeContext(ctx *Context) *Context {
+	cpy := *ctx
+	cpy.keepAny = true
+	return &cpy
+}
+
+// encode maps src to a map[string]any and converts its values recursively.
+func (m *Mapper) encode(ctx *Context, src reflect.Value) (map[string]any, error) {
+	res := make(map[string]any)
+	if err := m.MapReflContext(ctx, src, reflect.ValueOf(&res)); err != nil {
+		return nil, err
+	}
+	for k, v := range res {
+		n, err := m.encodeValue(ctx, reflect.ValueOf(v))
+		if err != nil {
+			return nil, err
+		}
+		res[k] = n
+	}
+	if ctx.FlattenSeparator != "" {
+		flat := make(map[string]any, len(res))
+		flattenMap(flat, "", ctx.FlattenSeparator, res)
+		res = flat
+	}
+	return res, nil
+}
+
+// encodeValue converts structs and maps with string keys to map[string]any
+// and slices and arrays of them to []any. Other values are returned as is.
+func (m *Mapper) encodeValue(ctx *Context, v reflect.Value) (any, error) {
+	v = m.srcValue(ctx, v)
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if m.hasProvider(ctx, v.Type()) {
+		return providerValue(v), nil
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		return m.encode(ctx, v)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return v.Interface(), nil
+		}
+		return m.encode(ctx, v)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface(), nil
+		}
+		res := make([]any, v.Len())
+		for i := range res {
+			n, err := m.encodeValue(ctx, v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			res[i] = n
+		}
+		return res, nil
+	}
+	return v.Interface(), nil
+}
+
+// providerValue returns the value of a type with a mapper provider. Values
+// of the math/big types are returned as pointers, like everywhere else in
+// the package.
+func providerValue(v reflect.Value) any {
+	switch x := v.Interface().(type) {
+	case big.Int:
+		return new(big.Int).Set(&x)
+	case big.Float:
+		return new(big.Float).Copy(&x)
+	case big.Rat:
+		return new(big.Rat).Set(&x)
+	}
+	return v.Interface()
+}
+
+// flattenMap copies values from src to dst. Values that are maps are
+// flattened recursively, and their keys are joined with the parent key
+// using the separator.
+func flattenMap(dst map[string]any, prefix, sep string, src map[string]any) {
+	for k, v := range src {
+		if prefix != "" {
+			k = prefix + sep + k
+		}
+		if n, ok := v.(map[string]any); ok {
+			flattenMap(dst, k, sep, n)
+			continue
+		}
+		dst[k] = v
+	}
+}
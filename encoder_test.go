@@ -0,0 +1,88 @@
+package anymapper
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncode(t *testing.T) {
+	type Address struct {
+		City string
+		Zip  string `map:"zip,omitempty"`
+	}
+	type User struct {
+		Name      string
+		Age       int `map:"age"`
+		Address   Address
+		Previous  []Address
+		Tags      []string
+		Balance   *big.Int
+		CreatedAt time.Time
+		Nickname  string `map:",omitempty"`
+	}
+	tm := time.Unix(1666666666, 0).UTC()
+	src := User{
+		Name:      "foo",
+		Age:       42,
+		Address:   Address{City: "Warsaw", Zip: "00-001"},
+		Previous:  []Address{{City: "Berlin"}},
+		Tags:      []string{"a", "b"},
+		Balance:   big.NewInt(100),
+		CreatedAt: tm,
+	}
+	t.Run("default", func(t *testing.T) {
+		res, err := Encode(src)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"Name":      "foo",
+			"age":       42,
+			"Address":   map[string]any{"City": "Warsaw", "zip": "00-001"},
+			"Previous":  []any{map[string]any{"City": "Berlin"}},
+			"Tags":      []any{"a", "b"},
+			"Balance":   big.NewInt(100),
+			"CreatedAt": tm,
+		}, res)
+	})
+	t.Run("flatten", func(t *testing.T) {
+		res, err := Encode(src, WithFlattenSeparator("."))
+		require.NoError(t, err)
+		assert.Equal(t, "Warsaw", res["Address.City"])
+		assert.Equal(t, "00-001", res["Address.zip"])
+		assert.NotContains(t, res, "Address")
+	})
+	t.Run("omit-empty", func(t *testing.T) {
+		res, err := Encode(User{Name: "foo"}, WithOmitEmpty(true))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"Name":      "foo",
+			"Address":   map[string]any{},
+			"CreatedAt": time.Time{},
+		}, res)
+	})
+	t.Run("field-mapper", func(t *testing.T) {
+		res, err := Encode(Address{City: "Warsaw"}, WithFieldMapper(strings.ToLower))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"city": "Warsaw"}, res)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		_, err := Encode(42)
+		assert.Error(t, err)
+	})
+}
+
+func TestEncodeSlice(t *testing.T) {
+	type Item struct {
+		ID int
+	}
+	res, err := EncodeSlice([]Item{{ID: 1}, {ID: 2}})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"ID": 1}, {"ID": 2}}, res)
+
+	_, err = EncodeSlice(Item{ID: 1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,136 @@
+package anymapper
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// StringEncoding encodes and decodes byte slices when they are mapped to and
+// from strings. The encodings from the encoding/base32 and encoding/base64
+// packages implement this interface.
+//
+// Encodings are registered by name in Mapper.Encodings and selected using
+// Context.StringEncoding or the encoding tag option.
+type StringEncoding interface {
+	// EncodeToString returns the string encoding of b.
+	EncodeToString(b []byte) string
+
+	// DecodeString returns the bytes represented by the string s.
+	DecodeString(s string) ([]byte, error)
+}
+
+// Names of the built-in string encodings.
+const (
+	EncodingHex       = "hex"
+	EncodingBase32    = "base32"
+	EncodingBase58    = "base58"
+	EncodingBase64    = "base64"
+	EncodingBase64URL = "base64url"
+)
+
+// HexEncoding is a StringEncoding that uses lowercase hexadecimal strings.
+var HexEncoding StringEncoding = hexEncoding{}
+
+// Base58Encoding is a StringEncoding that uses the Bitcoin base58
+// alphabet.
+var Base58Encoding StringEncoding = base58Encoding{}
+
+// defaultEncodings returns the string encodings registered in new mappers.
+func defaultEncodings() map[string]StringEncoding {
+	return map[string]StringEncoding{
+		EncodingHex:       HexEncoding,
+		EncodingBase32:    base32.StdEncoding,
+		EncodingBase58:    Base58Encoding,
+		EncodingBase64:    base64.StdEncoding,
+		EncodingBase64URL: base64.URLEncoding,
+	}
+}
+
+// stringEncoding returns the encoding selected in the context. It returns
+// nil if no encoding is selected and bytes are mapped to strings as is.
+func (m *Mapper) stringEncoding(ctx *Context) (StringEncoding, bool) {
+	if ctx.StringEncoding == "" {
+		return nil, true
+	}
+	enc, ok := m.Encodings[ctx.StringEncoding]
+	return enc, ok
+}
+
+type hexEncoding struct{}
+
+func (hexEncoding) EncodeToString(b []byte) string {
+	return hex.EncodeToString(b)
+}
+
+func (hexEncoding) DecodeString(s string) ([]byte, error) {
+	return hex.DecodeString(s)
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var errInvalidBase58 = errors.New("invalid base58 string")
+
+type base58Encoding struct{}
+
+func (base58Encoding) EncodeToString(b []byte) string {
+	zeros := 0
+	for zeros < len(b) && b[zeros] == 0 {
+		zeros++
+	}
+	// Convert the number from base 256 to base 58, digits are stored in
+	// reverse order.
+	var digits []byte
+	for _, c := range b[zeros:] {
+		carry := int(c)
+		for i := range digits {
+			carry += int(digits[i]) << 8
+			digits[i] = byte(carry % 58)
+			carry /= 58
+		}
+		for carry > 0 {
+			digits = append(digits, byte(carry%58))
+			carry /= 58
+		}
+	}
+	s := make([]byte, zeros+len(digits))
+	for i := 0; i < zeros; i++ {
+		s[i] = base58Alphabet[0]
+	}
+	for i, d := range digits {
+		s[len(s)-1-i] = base58Alphabet[d]
+	}
+	return string(s)
+}
+
+func (base58Encoding) DecodeString(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+	// Convert the number from base 58 to base 256, bytes are stored in
+	// reverse order.
+	var rev []byte
+	for i := zeros; i < len(s); i++ {
+		carry := strings.IndexByte(base58Alphabet, s[i])
+		if carry < 0 {
+			return nil, errInvalidBase58
+		}
+		for j := range rev {
+			carry += int(rev[j]) * 58
+			rev[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			rev = append(rev, byte(carry))
+			carry >>= 8
+		}
+	}
+	b := make([]byte, zeros+len(rev))
+	for i, c := range rev {
+		b[len(b)-1-i] = c
+	}
+	return b, nil
+}
@@ -0,0 +1,85 @@
+package anymapper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringEncoding(t *testing.T) {
+	tests := []struct {
+		encoding string
+		bytes    []byte
+		str      string
+	}{
+		{encoding: EncodingHex, bytes: []byte{0xde, 0xad, 0xbe, 0xef}, str: "deadbeef"},
+		{encoding: EncodingBase32, bytes: []byte("foo"), str: "MZXW6==="},
+		{encoding: EncodingBase58, bytes: []byte("Hello World!"), str: "2NEpo7TZRRrLZSi2U"},
+		{encoding: EncodingBase58, bytes: []byte{0, 0, 1}, str: "112"},
+		{encoding: EncodingBase58, bytes: []byte{}, str: ""},
+		{encoding: EncodingBase64, bytes: []byte{0xfb, 0xff}, str: "+/8="},
+		{encoding: EncodingBase64URL, bytes: []byte{0xfb, 0xff}, str: "-_8="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.encoding+"/"+tt.str, func(t *testing.T) {
+			ctx := Default.Context.WithStringEncoding(tt.encoding)
+			var str string
+			require.NoError(t, MapContext(ctx, tt.bytes, &str))
+			assert.Equal(t, tt.str, str)
+			var b []byte
+			require.NoError(t, MapContext(ctx, tt.str, &b))
+			assert.Equal(t, tt.bytes, b)
+		})
+	}
+	t.Run("array", func(t *testing.T) {
+		ctx := Default.Context.WithStringEncoding(EncodingHex)
+		var str string
+		require.NoError(t, MapContext(ctx, [2]byte{0xab, 0xcd}, &str))
+		assert.Equal(t, "abcd", str)
+		var arr [2]byte
+		require.NoError(t, MapContext(ctx, "abcd", &arr))
+		assert.Equal(t, [2]byte{0xab, 0xcd}, arr)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		var b []byte
+		assert.Error(t, MapContext(Default.Context.WithStringEncoding(EncodingHex), "zz", &b))
+		assert.Error(t, MapContext(Default.Context.WithStringEncoding(EncodingBase58), "0OIl", &b))
+	})
+	t.Run("unknown", func(t *testing.T) {
+		var s string
+		err := MapContext(Default.Context.WithStringEncoding("foo"), []byte{1}, &s)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown string encoding: foo")
+	})
+	t.Run("tag", func(t *testing.T) {
+		type Tx struct {
+			Hash []byte `map:"hash,encoding=hex"`
+			Data []byte `map:"data"`
+		}
+		var dst map[string]string
+		require.NoError(t, Map(Tx{Hash: []byte{0x01, 0x02}, Data: []byte("foo")}, &dst))
+		assert.Equal(t, map[string]string{"hash": "0102", "data": "foo"}, dst)
+		var tx Tx
+		require.NoError(t, Map(dst, &tx))
+		assert.Equal(t, Tx{Hash: []byte{0x01, 0x02}, Data: []byte("foo")}, tx)
+	})
+	t.Run("custom", func(t *testing.T) {
+		m := New()
+		m.Encodings["upper"] = upperEncoding{}
+		var s string
+		require.NoError(t, m.MapContext(m.Context.WithStringEncoding("upper"), []byte("foo"), &s))
+		assert.Equal(t, "FOO", s)
+	})
+}
+
+type upperEncoding struct{}
+
+func (upperEncoding) EncodeToString(b []byte) string {
+	return strings.ToUpper(string(b))
+}
+
+func (upperEncoding) DecodeString(s string) ([]byte, error) {
+	return []byte(strings.ToLower(s)), nil
+}
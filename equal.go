@@ -0,0 +1,155 @@
+package anymapper
+
+import (
+	"math/big"
+	"reflect"
+	"time"
+)
+
+// Equal reports whether the values are equal after normalizing them using
+// the mapping rules.
+//
+// It is shorthand for Default.Equal(a, b).
+func Equal(a, b any) (bool, error) {
+	return Default.Equal(a, b)
+}
+
+// EqualContext reports whether the values are equal after normalizing them
+// using the mapping rules and the given context.
+//
+// It is shorthand for Default.EqualContext(ctx, a, b).
+func EqualContext(ctx *Context, a, b any) (bool, error) {
+	return Default.EqualContext(ctx, a, b)
+}
+
+// Equal reports whether the values are equal after normalizing them using
+// the mapping rules. The value b is mapped to the type of a and compared
+// with a. If they differ, a is mapped to the type of b and compared with b.
+// For example, 1, "1" and big.NewInt(1) are all equal, unless the strict
+// mode is enabled. Custom mapping functions define the equivalence of
+// their types.
+//
+// Values are mapped with Context.Lossless enabled, so values like 2.5 and
+// 2 are not equal because of truncation. Mapped values are compared
+// deeply. Big numbers are compared by their values, times are compared
+// using time.Time.Equal, and nil slices and maps are equal to empty ones.
+// Cyclic values are not supported.
+//
+// If neither value can be mapped to the type of the other one, Equal
+// returns false and the mapping error.
+func (m *Mapper) Equal(a, b any) (bool, error) {
+	return m.EqualContext(m.Context, a, b)
+}
+
+// EqualContext reports whether the values are equal after normalizing them
+// using the mapping rules and the given context. See Equal for details.
+func (m *Mapper) EqualContext(ctx *Context, a, b any) (bool, error) {
+	if a == nil || b == nil {
+		return a == nil && b == nil, nil
+	}
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	if av.Type() == bv.Type() {
+		return valuesEqual(av, bv), nil
+	}
+	if ctx == nil {
+		ctx = m.Context
+	}
+	if !ctx.Lossless {
+		ctx = ctx.WithLossless(true)
+	}
+	eq, errA := m.equalAs(ctx, av, bv)
+	if eq {
+		return true, nil
+	}
+	eq, errB := m.equalAs(ctx, bv, av)
+	if eq {
+		return true, nil
+	}
+	if errA != nil && errB != nil {
+		return false, errA
+	}
+	return false, nil
+}
+
+// equalAs maps src to the type of v and compares the result with v.
+func (m *Mapper) equalAs(ctx *Context, v, src reflect.Value) (bool, error) {
+	dst := reflect.New(v.Type())
+	if err := m.MapReflContext(ctx, src, dst); err != nil {
+		return false, err
+	}
+	return valuesEqual(v, dst.Elem()), nil
+}
+
+// valuesEqual reports whether the values of the same type are deeply equal.
+func valuesEqual(a, b reflect.Value) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() == b.IsValid()
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+	if a.CanInterface() {
+		switch a.Type() {
+		case bigIntTy:
+			x, y := a.Interface().(big.Int), b.Interface().(big.Int)
+			return x.Cmp(&y) == 0
+		case bigFloatTy:
+			x, y := a.Interface().(big.Float), b.Interface().(big.Float)
+			return x.Cmp(&y) == 0
+		case bigRatTy:
+			x, y := a.Interface().(big.Rat), b.Interface().(big.Rat)
+			return x.Cmp(&y) == 0
+		case timeTy:
+			return a.Interface().(time.Time).Equal(b.Interface().(time.Time))
+		}
+	}
+	switch a.Kind() {
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() == b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() == b.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return a.Complex() == b.Complex()
+	case reflect.String:
+		return a.String() == b.String()
+	case reflect.Pointer, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() && b.IsNil()
+		}
+		return valuesEqual(a.Elem(), b.Elem())
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !valuesEqual(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for it := a.MapRange(); it.Next(); {
+			if !valuesEqual(it.Value(), b.MapIndex(it.Key())) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !valuesEqual(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	// Functions, channels and unsafe pointers are equal only if they are
+	// the same.
+	return a.Pointer() == b.Pointer()
+}
@@ -0,0 +1,72 @@
+package anymapper
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEqual(t *testing.T) {
+	type user struct {
+		ID   int
+		Name string
+		Tags []string
+	}
+	tm := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		a, b any
+		want bool
+	}{
+		{name: "int-string", a: 1, b: "1", want: true},
+		{name: "string-int", a: "1", b: 1, want: true},
+		{name: "int-big-int", a: 1, b: big.NewInt(1), want: true},
+		{name: "big-int-string", a: big.NewInt(1), b: "1", want: true},
+		{name: "big-int-different", a: big.NewInt(1), b: big.NewInt(2), want: false},
+		{name: "int-float", a: 2, b: 2.0, want: true},
+		{name: "float-int-fraction", a: 2.5, b: 2, want: false},
+		{name: "string-different", a: "1", b: 2, want: false},
+		{name: "time-location", a: tm, b: tm.In(time.FixedZone("X", 3600)), want: true},
+		{name: "time-string", a: tm, b: "2024-01-01T00:00:00Z", want: true},
+		{name: "struct-map", a: user{ID: 1, Name: "foo"}, b: map[string]any{"ID": "1", "Name": "foo"}, want: true},
+		{name: "struct-map-different", a: user{ID: 1, Name: "foo"}, b: map[string]any{"ID": 2, "Name": "foo"}, want: false},
+		{name: "nil-slice", a: user{Tags: nil}, b: user{Tags: []string{}}, want: true},
+		{name: "slices", a: []int{1, 2}, b: []string{"1", "2"}, want: true},
+		{name: "pointers", a: &user{ID: 1}, b: &user{ID: 1}, want: true},
+		{name: "nil", a: nil, b: nil, want: true},
+		{name: "nil-value", a: nil, b: 1, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eq, err := Equal(tt.a, tt.b)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, eq)
+		})
+	}
+	t.Run("strict", func(t *testing.T) {
+		eq, err := EqualContext(Default.Context.WithStrictTypes(true), 1, "1")
+		assert.Error(t, err)
+		assert.False(t, eq)
+	})
+	t.Run("custom", func(t *testing.T) {
+		type celsius float64
+		type fahrenheit float64
+		m := New()
+		m.Mappers[reflect.TypeOf(celsius(0))] = func(m *Mapper, src, dst reflect.Type) MapFunc {
+			if dst != reflect.TypeOf(fahrenheit(0)) {
+				return nil
+			}
+			return func(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+				dst.SetFloat(src.Float()*9/5 + 32)
+				return nil
+			}
+		}
+		eq, err := m.Equal(fahrenheit(212), celsius(100))
+		require.NoError(t, err)
+		assert.True(t, eq)
+	})
+}
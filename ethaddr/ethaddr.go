@@ -0,0 +1,244 @@
+// Package ethaddr provides mapping functions for Ethereum addresses.
+//
+// The Register and RegisterType functions add a mapper provider for a
+// 20-byte array type to the Mapper. Once registered, the address can be
+// mapped to and from 0x-prefixed hex strings, byte slices, big.Int values
+// and other 20-byte arrays.
+package ethaddr
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/defiweb/go-anymapper"
+)
+
+// AddressLength is the length of an Ethereum address in bytes.
+const AddressLength = 20
+
+// Address is an Ethereum address.
+type Address [AddressLength]byte
+
+// String returns the address as a hex string with EIP-55 checksum casing.
+func (a Address) String() string {
+	return checksumHex(a[:])
+}
+
+// Bytes returns the address as a byte slice.
+func (a Address) Bytes() []byte {
+	return a[:]
+}
+
+// Parse parses a hex encoded address. The 0x prefix is optional. If the
+// address uses mixed casing, its EIP-55 checksum is validated.
+func Parse(s string) (Address, error) {
+	var a Address
+	if err := parse(a[:], s, false); err != nil {
+		return Address{}, err
+	}
+	return a, nil
+}
+
+// ParseStrict parses a hex encoded address and requires it to have a valid
+// EIP-55 checksum.
+func ParseStrict(s string) (Address, error) {
+	var a Address
+	if err := parse(a[:], s, true); err != nil {
+		return Address{}, err
+	}
+	return a, nil
+}
+
+// Errors returned when parsing or mapping an address.
+var (
+	ErrInvalidLength   = errors.New("ethaddr: invalid address length")
+	ErrInvalidHex      = errors.New("ethaddr: invalid hex string")
+	ErrInvalidChecksum = errors.New("ethaddr: invalid address checksum")
+	ErrOutOfRange      = errors.New("ethaddr: big.Int value out of address range")
+)
+
+// Register adds the mapper provider for the Address type to the Mapper.
+// It should be called before the Mapper is used, because mapping functions
+// resolved earlier may be cached.
+//
+// If strict is true, strings must have a valid EIP-55 checksum. Otherwise,
+// the checksum is validated only for strings that use mixed casing.
+func Register(m *anymapper.Mapper, strict bool) {
+	RegisterType(m, reflect.TypeOf(Address{}), strict)
+}
+
+// RegisterType adds the mapper provider for the given address type to the
+// Mapper. The type must be a 20-byte array, which allows to use address
+// types from other packages.
+//
+// If strict is true, strings must have a valid EIP-55 checksum. Otherwise,
+// the checksum is validated only for strings that use mixed casing.
+func RegisterType(m *anymapper.Mapper, typ reflect.Type, strict bool) {
+	if typ.Kind() != reflect.Array || typ.Len() != AddressLength || typ.Elem().Kind() != reflect.Uint8 {
+		panic("ethaddr: address type must be a 20-byte array")
+	}
+	m.Mappers[typ] = TypeMapper(typ, strict)
+}
+
+// TypeMapper returns the mapper provider for the given address type.
+func TypeMapper(typ reflect.Type, strict bool) anymapper.MapFuncProvider {
+	return func(_ *anymapper.Mapper, src, dst reflect.Type) anymapper.MapFunc {
+		if src == dst {
+			return mapAddressToAddress
+		}
+		switch {
+		case src == typ:
+			switch {
+			case dst.Kind() == reflect.String:
+				return mapAddressToString
+			case dst.Kind() == reflect.Slice && dst.Elem().Kind() == reflect.Uint8:
+				return mapAddressToBytes
+			case dst.Kind() == reflect.Array && dst.Len() == AddressLength && dst.Elem().Kind() == reflect.Uint8:
+				return mapAddressToAddress
+			case dst == bigIntTy:
+				return mapAddressToBigInt
+			}
+		case dst == typ:
+			switch {
+			case src.Kind() == reflect.String:
+				return func(_ *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+					return mapStringToAddress(ctx, src, dst, strict)
+				}
+			case src.Kind() == reflect.Slice && src.Elem().Kind() == reflect.Uint8:
+				return mapBytesToAddress
+			case src.Kind() == reflect.Array && src.Len() == AddressLength && src.Elem().Kind() == reflect.Uint8:
+				return mapAddressToAddress
+			case src == bigIntTy:
+				return mapBigIntToAddress
+			}
+		}
+		return nil
+	}
+}
+
+var bigIntTy = reflect.TypeOf((*big.Int)(nil)).Elem()
+
+func mapAddressToAddress(_ *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes && src.Type() != dst.Type() {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	reflect.Copy(dst, src)
+	return nil
+}
+
+func mapAddressToString(_ *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	dst.SetString(checksumHex(addressBytes(src)))
+	return nil
+}
+
+func mapAddressToBytes(_ *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	dst.SetBytes(addressBytes(src))
+	return nil
+}
+
+func mapAddressToBigInt(_ *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	dst.Set(reflect.ValueOf(new(big.Int).SetBytes(addressBytes(src))).Elem())
+	return nil
+}
+
+func mapStringToAddress(ctx *anymapper.Context, src, dst reflect.Value, strict bool) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	var a Address
+	if err := parse(a[:], src.String(), strict); err != nil {
+		return anymapper.WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	reflect.Copy(dst, reflect.ValueOf(a))
+	return nil
+}
+
+func mapBytesToAddress(_ *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	if src.Len() != AddressLength {
+		return anymapper.WrapInvalidMappingError(src.Type(), dst.Type(), ErrInvalidLength)
+	}
+	reflect.Copy(dst, src)
+	return nil
+}
+
+func mapBigIntToAddress(_ *anymapper.Mapper, ctx *anymapper.Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return anymapper.NewStrictMappingError(src.Type(), dst.Type())
+	}
+	v := src.Addr().Interface().(*big.Int)
+	if v.Sign() < 0 || v.BitLen() > AddressLength*8 {
+		return anymapper.WrapInvalidMappingError(src.Type(), dst.Type(), ErrOutOfRange)
+	}
+	var a Address
+	v.FillBytes(a[:])
+	reflect.Copy(dst, reflect.ValueOf(a))
+	return nil
+}
+
+// addressBytes returns the bytes of an address array value.
+func addressBytes(v reflect.Value) []byte {
+	b := make([]byte, AddressLength)
+	reflect.Copy(reflect.ValueOf(b), v)
+	return b
+}
+
+// parse decodes a hex encoded address into dst.
+func parse(dst []byte, s string, strict bool) error {
+	h := s
+	if strings.HasPrefix(h, "0x") || strings.HasPrefix(h, "0X") {
+		h = h[2:]
+	}
+	if len(h) != AddressLength*2 {
+		return ErrInvalidLength
+	}
+	if _, err := hex.Decode(dst, []byte(h)); err != nil {
+		return ErrInvalidHex
+	}
+	if (strict || isMixedCase(h)) && checksumHex(dst)[2:] != h {
+		return ErrInvalidChecksum
+	}
+	return nil
+}
+
+// checksumHex returns the 0x-prefixed hex encoding of the address with
+// EIP-55 checksum casing.
+func checksumHex(b []byte) string {
+	buf := []byte(hex.EncodeToString(b))
+	hash := keccak256(buf)
+	for i, c := range buf {
+		if c < 'a' {
+			continue
+		}
+		// Letters are uppercased if the corresponding nibble of the hash
+		// of the lowercase hex string is 8 or more.
+		nibble := hash[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if nibble&0xf >= 8 {
+			buf[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(buf)
+}
+
+// isMixedCase returns true if the string contains both lowercase and
+// uppercase letters.
+func isMixedCase(s string) bool {
+	return strings.ToLower(s) != s && strings.ToUpper(s) != s
+}
@@ -0,0 +1,130 @@
+package ethaddr
+
+import (
+	"encoding/hex"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defiweb/go-anymapper"
+)
+
+// Test vectors from EIP-55.
+var checksumAddresses = []string{
+	"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+	"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+	"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+	"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+}
+
+func TestKeccak256(t *testing.T) {
+	h := keccak256(nil)
+	assert.Equal(t, "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470", hex.EncodeToString(h[:]))
+	h = keccak256([]byte(strings.Repeat("a", 200)))
+	assert.Len(t, h, 32)
+}
+
+func TestParse(t *testing.T) {
+	for _, s := range checksumAddresses {
+		t.Run(s, func(t *testing.T) {
+			a, err := Parse(s)
+			require.NoError(t, err)
+			assert.Equal(t, s, a.String())
+
+			a, err = Parse(strings.ToLower(s))
+			require.NoError(t, err)
+			assert.Equal(t, s, a.String())
+
+			a, err = ParseStrict(s)
+			require.NoError(t, err)
+			assert.Equal(t, s, a.String())
+
+			_, err = ParseStrict(strings.ToLower(s))
+			assert.ErrorIs(t, err, ErrInvalidChecksum)
+		})
+	}
+	_, err := Parse("0x5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+	assert.ErrorIs(t, err, ErrInvalidChecksum)
+	_, err = Parse("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA")
+	assert.ErrorIs(t, err, ErrInvalidLength)
+	_, err = Parse("0xzzAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+	assert.ErrorIs(t, err, ErrInvalidHex)
+}
+
+func TestMapper(t *testing.T) {
+	m := anymapper.New()
+	Register(m, false)
+
+	s := checksumAddresses[0]
+	exp, err := Parse(s)
+	require.NoError(t, err)
+
+	t.Run("string", func(t *testing.T) {
+		var a Address
+		require.NoError(t, m.Map(strings.ToLower(s), &a))
+		assert.Equal(t, exp, a)
+
+		var str string
+		require.NoError(t, m.Map(a, &str))
+		assert.Equal(t, s, str)
+	})
+	t.Run("bytes", func(t *testing.T) {
+		var a Address
+		require.NoError(t, m.Map(exp.Bytes(), &a))
+		assert.Equal(t, exp, a)
+
+		var b []byte
+		require.NoError(t, m.Map(a, &b))
+		assert.Equal(t, exp.Bytes(), b)
+
+		assert.Error(t, m.Map([]byte{1, 2, 3}, &a))
+	})
+	t.Run("big.Int", func(t *testing.T) {
+		var a Address
+		require.NoError(t, m.Map(new(big.Int).SetBytes(exp.Bytes()), &a))
+		assert.Equal(t, exp, a)
+
+		var b *big.Int
+		require.NoError(t, m.Map(a, &b))
+		assert.Equal(t, new(big.Int).SetBytes(exp.Bytes()), b)
+
+		assert.Error(t, m.Map(big.NewInt(-1), &a))
+		assert.Error(t, m.Map(new(big.Int).Lsh(big.NewInt(1), 160), &a))
+	})
+	t.Run("array", func(t *testing.T) {
+		var b [20]byte
+		require.NoError(t, m.Map(exp, &b))
+		assert.Equal(t, [20]byte(exp), b)
+
+		var a Address
+		require.NoError(t, m.Map(b, &a))
+		assert.Equal(t, exp, a)
+	})
+	t.Run("struct", func(t *testing.T) {
+		var dst struct{ Owner Address }
+		require.NoError(t, m.Map(map[string]any{"Owner": s}, &dst))
+		assert.Equal(t, exp, dst.Owner)
+	})
+	t.Run("strict-types", func(t *testing.T) {
+		var a Address
+		assert.Error(t, m.MapContext(m.Context.WithStrictTypes(true), s, &a))
+	})
+}
+
+func TestRegisterType(t *testing.T) {
+	type OtherAddress [20]byte
+	m := anymapper.New()
+	RegisterType(m, reflect.TypeOf(OtherAddress{}), true)
+
+	var a OtherAddress
+	require.NoError(t, m.Map(checksumAddresses[1], &a))
+	assert.Error(t, m.Map(strings.ToLower(checksumAddresses[1]), &a))
+
+	assert.Panics(t, func() {
+		RegisterType(m, reflect.TypeOf([32]byte{}), false)
+	})
+}
@@ -0,0 +1,87 @@
+package ethaddr
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// keccakRC are the round constants of the Keccak-f[1600] permutation.
+var keccakRC = [24]uint64{
+	0x0000000000000001, 0x0000000000008082, 0x800000000000808a, 0x8000000080008000,
+	0x000000000000808b, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+	0x000000000000008a, 0x0000000000000088, 0x0000000080008009, 0x000000008000000a,
+	0x000000008000808b, 0x800000000000008b, 0x8000000000008089, 0x8000000000008003,
+	0x8000000000008002, 0x8000000000000080, 0x000000000000800a, 0x800000008000000a,
+	0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+}
+
+// keccakRotc are the rotation offsets of the rho step, in the order of
+// the pi step lanes.
+var keccakRotc = [24]int{
+	1, 3, 6, 10, 15, 21, 28, 36, 45, 55, 2, 14, 27, 41, 56, 8, 25, 43, 62, 18, 39, 61, 20, 44,
+}
+
+// keccakPiln are the lane indices of the pi step.
+var keccakPiln = [24]int{
+	10, 7, 11, 17, 18, 3, 5, 16, 8, 21, 24, 4, 15, 23, 19, 13, 12, 2, 20, 14, 22, 9, 6, 1,
+}
+
+// keccakF1600 applies the Keccak-f[1600] permutation to the state.
+func keccakF1600(a *[25]uint64) {
+	var bc [5]uint64
+	for r := 0; r < 24; r++ {
+		// Theta
+		for i := 0; i < 5; i++ {
+			bc[i] = a[i] ^ a[i+5] ^ a[i+10] ^ a[i+15] ^ a[i+20]
+		}
+		for i := 0; i < 5; i++ {
+			t := bc[(i+4)%5] ^ bits.RotateLeft64(bc[(i+1)%5], 1)
+			for j := 0; j < 25; j += 5 {
+				a[j+i] ^= t
+			}
+		}
+		// Rho and pi
+		t := a[1]
+		for i := 0; i < 24; i++ {
+			j := keccakPiln[i]
+			t, a[j] = a[j], bits.RotateLeft64(t, keccakRotc[i])
+		}
+		// Chi
+		for j := 0; j < 25; j += 5 {
+			for i := 0; i < 5; i++ {
+				bc[i] = a[j+i]
+			}
+			for i := 0; i < 5; i++ {
+				a[j+i] ^= ^bc[(i+1)%5] & bc[(i+2)%5]
+			}
+		}
+		// Iota
+		a[0] ^= keccakRC[r]
+	}
+}
+
+// keccak256 returns the legacy Keccak-256 hash of the data, as used by
+// Ethereum. It differs from SHA3-256 only in the padding byte.
+func keccak256(data []byte) (h [32]byte) {
+	const rate = 136
+	var a [25]uint64
+	for len(data) >= rate {
+		for i := 0; i < rate/8; i++ {
+			a[i] ^= binary.LittleEndian.Uint64(data[i*8:])
+		}
+		keccakF1600(&a)
+		data = data[rate:]
+	}
+	var block [rate]byte
+	copy(block[:], data)
+	block[len(data)] ^= 0x01
+	block[rate-1] ^= 0x80
+	for i := 0; i < rate/8; i++ {
+		a[i] ^= binary.LittleEndian.Uint64(block[i*8:])
+	}
+	keccakF1600(&a)
+	for i := 0; i < 4; i++ {
+		binary.LittleEndian.PutUint64(h[i*8:], a[i])
+	}
+	return h
+}
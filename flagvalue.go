@@ -0,0 +1,50 @@
+package anymapper
+
+import (
+	"flag"
+	"reflect"
+)
+
+var flagValueTy = reflect.TypeOf((*flag.Value)(nil)).Elem()
+
+// implFlagValue returns true if the type or a pointer to it implements the
+// flag.Value interface.
+func implFlagValue(t reflect.Type) bool {
+	return t.Kind() != reflect.Interface && reflect.PointerTo(t).Implements(flagValueTy)
+}
+
+// flagValueFunc returns a MapFunc that maps strings to types implementing
+// the flag.Value interface using their Set method, and such types to
+// strings using their String method, if Context.FlagValues is enabled.
+// Otherwise, and for other types, the next MapFunc is returned, which may
+// be nil.
+func flagValueFunc(ctx *Context, src, dst reflect.Type, next MapFunc) MapFunc {
+	if src == dst || !ctx.FlagValues {
+		return next
+	}
+	switch {
+	case src.Kind() == reflect.String && implFlagValue(dst):
+		return mapStringToFlagValue
+	case dst.Kind() == reflect.String && implFlagValue(src):
+		return mapFlagValueToString
+	}
+	return next
+}
+
+func mapStringToFlagValue(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	if err := dst.Addr().Interface().(flag.Value).Set(src.String()); err != nil {
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	return nil
+}
+
+func mapFlagValueToString(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	dst.SetString(addr(src).Interface().(flag.Value).String())
+	return nil
+}
@@ -0,0 +1,86 @@
+package anymapper
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testLevel int
+
+func (l *testLevel) Set(s string) error {
+	switch s {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return errors.New("unknown level")
+	}
+	return nil
+}
+
+func (l *testLevel) String() string {
+	if *l == 2 {
+		return "high"
+	}
+	return "low"
+}
+
+type testList struct {
+	items []string
+}
+
+func (l *testList) Set(s string) error {
+	l.items = strings.Split(s, ",")
+	return nil
+}
+
+func (l *testList) String() string {
+	return strings.Join(l.items, ",")
+}
+
+func TestFlagValues(t *testing.T) {
+	ctx := Default.Context.WithFlagValues(true)
+	t.Run("string-to-value", func(t *testing.T) {
+		var l testLevel
+		require.NoError(t, MapContext(ctx, "high", &l))
+		assert.Equal(t, testLevel(2), l)
+		assert.Error(t, MapContext(ctx, "medium", &l))
+		var list testList
+		require.NoError(t, MapContext(ctx, "a,b", &list))
+		assert.Equal(t, []string{"a", "b"}, list.items)
+	})
+	t.Run("value-to-string", func(t *testing.T) {
+		var s string
+		require.NoError(t, MapContext(ctx, testLevel(2), &s))
+		assert.Equal(t, "high", s)
+		require.NoError(t, MapContext(ctx, testList{items: []string{"x", "y"}}, &s))
+		assert.Equal(t, "x,y", s)
+	})
+	t.Run("struct-fields", func(t *testing.T) {
+		type Config struct {
+			Level testLevel
+			Tags  *testList
+		}
+		var cfg Config
+		require.NoError(t, MapContext(ctx, map[string]string{"Level": "low", "Tags": "a"}, &cfg))
+		assert.Equal(t, testLevel(1), cfg.Level)
+		assert.Equal(t, []string{"a"}, cfg.Tags.items)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		var l testLevel
+		require.NoError(t, Map("2", &l))
+		assert.Equal(t, testLevel(2), l)
+		assert.Error(t, Map("high", &l))
+		var list testList
+		assert.Error(t, Map("a,b", &list))
+		// The pair cannot be mapped without FlagValues, so no mapping
+		// function is resolved for it.
+		assert.Nil(t, Default.mapperFor(Default.Context, stringTy, reflect.TypeOf(list)).MapFunc)
+	})
+}
@@ -0,0 +1,216 @@
+package anymapper
+
+import (
+	"math"
+	"reflect"
+)
+
+// Float16 is an IEEE 754 half-precision floating-point number stored as its
+// binary representation.
+//
+// The mapper converts Float16 values to and from other numbers, strings and
+// big numbers using their floating-point values. Byte slices and arrays are
+// mapped to and from the binary representation, using Context.ByteOrder.
+//
+// Fields of the uint16 kind can be mapped as Float16 values using the
+// float16 tag option.
+type Float16 uint16
+
+var float16Ty = reflect.TypeOf((*Float16)(nil)).Elem()
+
+// NewFloat16 returns the half-precision number nearest to f. Values too
+// large to be represented are converted to infinities.
+func NewFloat16(f float64) Float16 {
+	b := math.Float64bits(f)
+	sign := uint16(b>>48) & 0x8000
+	exp := int(b>>52) & 0x7ff
+	mant := b & (1<<52 - 1)
+	if exp == 0x7ff {
+		if mant != 0 {
+			return Float16(sign | 0x7e00) // NaN
+		}
+		return Float16(sign | 0x7c00) // Inf
+	}
+	e := exp - 1023 + 15
+	switch {
+	case e >= 0x1f:
+		return Float16(sign | 0x7c00)
+	case e < -10:
+		return Float16(sign)
+	case e <= 0:
+		// Subnormal numbers. If rounding results in the smallest normal
+		// number, the carry sets the exponent bits.
+		return Float16(sign | uint16(roundShift(mant|1<<52, uint(43-e))))
+	}
+	// If rounding overflows the mantissa, the carry increments the exponent,
+	// which also correctly produces infinity for the largest exponent.
+	return Float16(sign | (uint16(e)<<10 + uint16(roundShift(mant, 42))))
+}
+
+// Float64 returns the value of h as float64. The conversion is exact.
+func (h Float16) Float64() float64 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1
+	}
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	switch exp {
+	case 0:
+		return sign * math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant != 0 {
+			return math.NaN()
+		}
+		return math.Inf(int(sign))
+	}
+	return sign * math.Ldexp(mant+0x400, exp-25)
+}
+
+// roundShift shifts m right by s bits, rounding half to even.
+func roundShift(m uint64, s uint) uint64 {
+	r := m >> s
+	rem := m & (1<<s - 1)
+	half := uint64(1) << (s - 1)
+	if rem > half || (rem == half && r&1 == 1) {
+		r++
+	}
+	return r
+}
+
+// float16TypeMapper maps Float16 values using their floating-point values,
+// except for byte slices and arrays, which are mapped to and from the binary
+// representation.
+func float16TypeMapper(m *Mapper, src, dst reflect.Type) MapFunc {
+	if src == dst {
+		return mapDirect
+	}
+	switch {
+	case src == float16Ty && isFloatKind(dst.Kind()):
+		return mapFloat16ToFloat
+	case dst == float16Ty && isFloatKind(src.Kind()):
+		return mapFloatToFloat16
+	case dst == anyTy:
+		return mapAny
+	case isByteSliceOrArray(src) || isByteSliceOrArray(dst):
+		return mapFloat16Bytes(builtInTypesMapper(m, src, dst))
+	case src == float16Ty:
+		return mapFloat16ViaFloat64
+	case dst == float16Ty:
+		return mapToFloat16ViaFloat64
+	}
+	return nil
+}
+
+// mapFloat16Bytes returns a function that maps Float16 values to and from
+// byte slices using mapFunc, or using their floating-point values if the
+// byte slice is treated as text.
+func mapFloat16Bytes(mapFunc MapFunc) MapFunc {
+	if mapFunc == nil {
+		return nil
+	}
+	return func(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+		switch {
+		case isTextBytes(ctx, src):
+			return mapToFloat16ViaFloat64(m, ctx, src, dst)
+		case isTextBytes(ctx, dst):
+			return mapFloat16ViaFloat64(m, ctx, src, dst)
+		}
+		return mapFunc(m, ctx, src, dst)
+	}
+}
+
+func mapFloat16ToFloat(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	dst.SetFloat(Float16(src.Uint()).Float64())
+	return nil
+}
+
+func mapFloatToFloat16(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	if ctx.Lossless && !exactFloat16(src.Float()) {
+		return newLossError(src.Type(), dst.Type())
+	}
+	if !setFloat16(dst, src.Float()) {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "overflow")
+	}
+	return nil
+}
+
+func mapFloat16ViaFloat64(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	return m.MapReflContext(ctx, reflect.ValueOf(Float16(src.Uint()).Float64()), dst)
+}
+
+func mapToFloat16ViaFloat64(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	var f float64
+	if err := m.MapReflContext(ctx, src, reflect.ValueOf(&f)); err != nil {
+		return err
+	}
+	if ctx.Lossless && !exactFloat16(f) {
+		return newLossError(src.Type(), dst.Type())
+	}
+	if !setFloat16(dst, f) {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "overflow")
+	}
+	return nil
+}
+
+// setFloat16 sets dst to the half-precision number nearest to f. It returns
+// false if f is finite, but too large to be represented.
+func setFloat16(dst reflect.Value, f float64) bool {
+	h := NewFloat16(f)
+	if math.IsInf(h.Float64(), 0) && !math.IsInf(f, 0) {
+		return false
+	}
+	dst.SetUint(uint64(h))
+	return true
+}
+
+// exactFloat16 reports whether f can be stored as Float16 without rounding.
+// Values too large to be represented are reported as exact, because they
+// are rejected as overflows.
+func exactFloat16(f float64) bool {
+	h := NewFloat16(f).Float64()
+	return h == f || math.IsNaN(f) || math.IsInf(h, 0)
+}
+
+// asFloat16 returns v as a Float16 value if it has the uint16 kind. The
+// returned value is settable if v is addressable.
+func asFloat16(v reflect.Value) reflect.Value {
+	if v.Kind() != reflect.Uint16 || v.Type() == float16Ty {
+		return v
+	}
+	if v.CanAddr() {
+		return v.Addr().Convert(reflect.PointerTo(float16Ty)).Elem()
+	}
+	return v.Convert(float16Ty)
+}
+
+// float16Source returns the source value of a field with the float16 tag
+// option. Values mapped to interfaces are converted to float64, so the
+// destination does not hold the binary representation.
+func float16Source(src, dst reflect.Value) reflect.Value {
+	src = asFloat16(src)
+	if src.IsValid() && src.Type() == float16Ty && dst.IsValid() && dst.Kind() == reflect.Interface {
+		return reflect.ValueOf(Float16(src.Uint()).Float64())
+	}
+	return src
+}
+
+func isFloatKind(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+func isByteSliceOrArray(t reflect.Type) bool {
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() == reflect.Uint8
+}
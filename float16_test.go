@@ -0,0 +1,95 @@
+package anymapper
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFloat16(t *testing.T) {
+	tests := []struct {
+		f float64
+		h Float16
+	}{
+		{f: 0, h: 0x0000},
+		{f: math.Copysign(0, -1), h: 0x8000},
+		{f: 1, h: 0x3c00},
+		{f: -2, h: 0xc000},
+		{f: 0.5, h: 0x3800},
+		{f: 65504, h: 0x7bff},                 // largest normal number
+		{f: math.Ldexp(1, -14), h: 0x0400},    // smallest normal number
+		{f: math.Ldexp(1, -24), h: 0x0001},    // smallest subnormal number
+		{f: math.Ldexp(1023, -24), h: 0x03ff}, // largest subnormal number
+		{f: math.Inf(1), h: 0x7c00},
+		{f: math.Inf(-1), h: 0xfc00},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.h, NewFloat16(tt.f), "NewFloat16(%v)", tt.f)
+		assert.Equal(t, tt.f, tt.h.Float64(), "Float16(%#04x).Float64()", uint16(tt.h))
+	}
+	t.Run("rounding", func(t *testing.T) {
+		assert.Equal(t, Float16(0x3c00), NewFloat16(1+math.Ldexp(1, -11)))   // tie, rounds to even
+		assert.Equal(t, Float16(0x3c02), NewFloat16(1+3*math.Ldexp(1, -11))) // tie, rounds to even
+		assert.Equal(t, Float16(0x3c01), NewFloat16(1+math.Ldexp(1, -10)))
+		assert.Equal(t, Float16(0x0000), NewFloat16(math.Ldexp(1, -25)))
+		assert.Equal(t, Float16(0x0001), NewFloat16(math.Ldexp(1.5, -25)))
+		assert.Equal(t, Float16(0x7c00), NewFloat16(65520))
+	})
+	t.Run("nan", func(t *testing.T) {
+		assert.True(t, math.IsNaN(NewFloat16(math.NaN()).Float64()))
+	})
+}
+
+func TestFloat16Mapping(t *testing.T) {
+	t.Run("float", func(t *testing.T) {
+		var h Float16
+		require.NoError(t, Map(1.5, &h))
+		assert.Equal(t, Float16(0x3e00), h)
+		var f float32
+		require.NoError(t, Map(h, &f))
+		assert.Equal(t, float32(1.5), f)
+	})
+	t.Run("overflow", func(t *testing.T) {
+		var h Float16
+		assert.Error(t, Map(1e6, &h))
+	})
+	t.Run("string", func(t *testing.T) {
+		var h Float16
+		require.NoError(t, Map("-2", &h))
+		assert.Equal(t, Float16(0xc000), h)
+		var s string
+		require.NoError(t, Map(Float16(0x3800), &s))
+		assert.Equal(t, "0.5", s)
+	})
+	t.Run("int", func(t *testing.T) {
+		var n int
+		require.NoError(t, Map(Float16(0x4900), &n))
+		assert.Equal(t, 10, n)
+	})
+	t.Run("bytes", func(t *testing.T) {
+		var b [2]byte
+		require.NoError(t, Map(Float16(0x3c00), &b))
+		assert.Equal(t, [2]byte{0x3c, 0x00}, b)
+		var h Float16
+		require.NoError(t, Map([]byte{0xc0, 0x00}, &h))
+		assert.Equal(t, Float16(0xc000), h)
+	})
+	t.Run("strict", func(t *testing.T) {
+		var f float64
+		assert.Error(t, MapContext(Default.Context.WithStrictTypes(true), Float16(0x3c00), &f))
+	})
+	t.Run("tag", func(t *testing.T) {
+		type Reading struct {
+			Value uint16 `map:"value,float16"`
+			Raw   uint16 `map:"raw"`
+		}
+		var r Reading
+		require.NoError(t, Map(map[string]any{"value": 0.25, "raw": 2}, &r))
+		assert.Equal(t, Reading{Value: 0x3400, Raw: 2}, r)
+		var m map[string]any
+		require.NoError(t, Map(r, &m))
+		assert.Equal(t, map[string]any{"value": 0.25, "raw": uint16(2)}, m)
+	})
+}
@@ -0,0 +1,45 @@
+package anymapper
+
+import "reflect"
+
+// foldedKeys indexes the string keys of the map src by their case-folded
+// forms, see FoldCase. If multiple keys fold to the same string, the one
+// that is the lowest in the sort order is used, so the result does not
+// depend on the map iteration order.
+func foldedKeys(src reflect.Value) map[string]reflect.Value {
+	keys := src.MapKeys()
+	sortKeys(keys)
+	folded := make(map[string]reflect.Value, len(keys))
+	for _, key := range keys {
+		k := key
+		if k.Kind() == reflect.Interface {
+			k = k.Elem()
+		}
+		if k.Kind() != reflect.String {
+			continue
+		}
+		f := FoldCase(k.String())
+		if _, ok := folded[f]; !ok {
+			folded[f] = key
+		}
+	}
+	return folded
+}
+
+// foldedIndex returns the value of the map src stored under a key that is
+// equal to the given key under case folding, and the matched key. The
+// folded index is built on the first use.
+func foldedIndex(src reflect.Value, folded *map[string]reflect.Value, key string) (reflect.Value, string) {
+	if *folded == nil {
+		*folded = foldedKeys(src)
+	}
+	srcKey, ok := (*folded)[FoldCase(key)]
+	if !ok {
+		return reflect.Value{}, ""
+	}
+	k := srcKey
+	if k.Kind() == reflect.Interface {
+		k = k.Elem()
+	}
+	return src.MapIndex(srcKey), k.String()
+}
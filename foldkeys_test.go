@@ -0,0 +1,65 @@
+package anymapper
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaseInsensitiveKeys(t *testing.T) {
+	type user struct {
+		Name  string `map:"name"`
+		Email string `map:"email|mail"`
+		Age   int
+	}
+	ctx := Default.Context.WithCaseInsensitiveKeys(true)
+
+	t.Run("disabled", func(t *testing.T) {
+		var dst user
+		require.NoError(t, Map(map[string]any{"NAME": "a", "age": 1}, &dst))
+		assert.Equal(t, user{}, dst)
+	})
+	t.Run("any-case", func(t *testing.T) {
+		for _, key := range []string{"name", "Name", "NAME", "nAmE"} {
+			var dst user
+			require.NoError(t, MapContext(ctx, map[string]any{key: "a", "age": "2"}, &dst))
+			assert.Equal(t, user{Name: "a", Age: 2}, dst, key)
+		}
+	})
+	t.Run("exact-match-preferred", func(t *testing.T) {
+		var dst user
+		require.NoError(t, MapContext(ctx, map[string]any{"NAME": "a", "name": "b", "Name": "c"}, &dst))
+		assert.Equal(t, user{Name: "b"}, dst)
+	})
+	t.Run("deterministic", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			var dst user
+			require.NoError(t, MapContext(ctx, map[string]any{"NAME": "a", "nAME": "b", "Name": "c"}, &dst))
+			assert.Equal(t, user{Name: "a"}, dst)
+		}
+	})
+	t.Run("alias", func(t *testing.T) {
+		var dst user
+		require.NoError(t, MapContext(ctx, map[string]any{"MAIL": "a@b"}, &dst))
+		assert.Equal(t, user{Email: "a@b"}, dst)
+	})
+	t.Run("interface-keys", func(t *testing.T) {
+		var dst user
+		require.NoError(t, MapContext(ctx, map[any]any{"Name": "a", 1: "b"}, &dst))
+		assert.Equal(t, user{Name: "a"}, dst)
+	})
+	t.Run("unmapped-keys", func(t *testing.T) {
+		var keys []string
+		m := Default.Copy()
+		m.Hooks.UnmappedKeyHook = func(_ *Mapper, _ *Context, key string, _ reflect.Value) error {
+			keys = append(keys, key)
+			return nil
+		}
+		var dst user
+		require.NoError(t, m.MapContext(ctx.WithSortMapKeys(true), map[string]any{"NAME": "a", "Other": 1}, &dst))
+		assert.Equal(t, user{Name: "a"}, dst)
+		assert.Equal(t, []string{"Other"}, keys)
+	})
+}
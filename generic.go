@@ -0,0 +1,54 @@
+package anymapper
+
+// Convert maps the source value to a new value of type T.
+//
+// It is shorthand for Default.Map(src, &dst).
+func Convert[T any](src any) (T, error) {
+	var dst T
+	err := Default.Map(src, &dst)
+	return dst, err
+}
+
+// ConvertWith maps the source value to a new value of type T using the
+// given mapper. The options are applied only to this call.
+func ConvertWith[T any](m *Mapper, src any, opts ...Option) (T, error) {
+	var dst T
+	err := m.MapContext(applyOptions(m.Context, opts), src, &dst)
+	return dst, err
+}
+
+// MustConvert is like Convert but panics if the mapping fails. It is meant
+// for tests and initialization code, e.g. package-level variables.
+func MustConvert[T any](src any) T {
+	dst, err := Convert[T](src)
+	if err != nil {
+		panic(err)
+	}
+	return dst
+}
+
+// MustMapTo is an alias for MustConvert.
+func MustMapTo[T any](src any) T {
+	return MustConvert[T](src)
+}
+
+// ConvertContext maps the source value to a new value of type T using the
+// given context.
+//
+// It is shorthand for Default.MapContext(ctx, src, &dst).
+func ConvertContext[T any](ctx *Context, src any) (T, error) {
+	var dst T
+	err := Default.MapContext(ctx, src, &dst)
+	return dst, err
+}
+
+// Remap maps the source value of type S to a new value of type D using
+// the Default mapper. The options are applied only to this call.
+//
+// It is meant for translations between two struct types, for example
+// between a DTO and a domain model.
+func Remap[S, D any](src S, opts ...Option) (D, error) {
+	var dst D
+	err := Default.MapContext(applyOptions(Default.Context, opts), src, &dst)
+	return dst, err
+}
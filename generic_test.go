@@ -0,0 +1,90 @@
+package anymapper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	t.Run("simple", func(t *testing.T) {
+		v, err := Convert[int]("42")
+		require.NoError(t, err)
+		assert.Equal(t, 42, v)
+	})
+	t.Run("struct", func(t *testing.T) {
+		type Dst struct {
+			A int
+		}
+		v, err := Convert[Dst](map[string]any{"A": "1"})
+		require.NoError(t, err)
+		assert.Equal(t, Dst{A: 1}, v)
+	})
+	t.Run("pointer", func(t *testing.T) {
+		v, err := Convert[*int]("42")
+		require.NoError(t, err)
+		assert.Equal(t, 42, *v)
+	})
+	t.Run("error", func(t *testing.T) {
+		_, err := Convert[int]("foo")
+		assert.Error(t, err)
+	})
+}
+
+func TestConvertWith(t *testing.T) {
+	type Config struct {
+		Port uint16 `json:"port"`
+	}
+	m := Default.Copy()
+	m.Context.Tag = "json"
+	v, err := ConvertWith[Config](m, map[string]any{"port": "8080"})
+	require.NoError(t, err)
+	assert.Equal(t, Config{Port: 8080}, v)
+	_, err = ConvertWith[Config](m, map[string]any{"port": "8080"}, WithStrictTypes(true))
+	assert.Error(t, err)
+}
+
+func TestMustConvert(t *testing.T) {
+	assert.Equal(t, 42, MustConvert[int]("42"))
+	assert.Panics(t, func() { MustConvert[int]("foo") })
+}
+
+func TestMustMapTo(t *testing.T) {
+	assert.Equal(t, 42, MustMapTo[int]("42"))
+	assert.Panics(t, func() { MustMapTo[int]("foo") })
+}
+
+func TestConvertContext(t *testing.T) {
+	_, err := ConvertContext[int](Default.Context.WithStrictTypes(true), "42")
+	assert.Error(t, err)
+	v, err := ConvertContext[int](Default.Context, "42")
+	require.NoError(t, err)
+	assert.Equal(t, 42, v)
+}
+
+func TestRemap(t *testing.T) {
+	type DTO struct {
+		ID   string
+		Name string
+	}
+	type Model struct {
+		ID   int
+		Name string
+	}
+	t.Run("default", func(t *testing.T) {
+		v, err := Remap[DTO, Model](DTO{ID: "1", Name: "foo"})
+		require.NoError(t, err)
+		assert.Equal(t, Model{ID: 1, Name: "foo"}, v)
+	})
+	t.Run("options", func(t *testing.T) {
+		v, err := Remap[Model, map[string]string](Model{ID: 1, Name: "foo"}, WithFieldMapper(strings.ToLower))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"id": "1", "name": "foo"}, v)
+	})
+	t.Run("strict", func(t *testing.T) {
+		_, err := Remap[DTO, Model](DTO{ID: "1"}, WithStrictTypes(true))
+		assert.Error(t, err)
+	})
+}
@@ -55,3 +55,19 @@ func TestCustomType(t *testing.T) {
 		assert.Equal(t, "foo", dst.foo)
 	})
 }
+
+func TestCustomTypeContextHooks(t *testing.T) {
+	m := New()
+	ctx := m.Context.WithHooks(&MappingInterfaceHooks)
+
+	var dst customType
+	require.NoError(t, m.MapContext(ctx, "foo", &dst))
+	assert.Equal(t, "foo", dst.foo)
+
+	var str string
+	require.NoError(t, m.MapContext(ctx, &customType{foo: "bar"}, &str))
+	assert.Equal(t, "bar", str)
+
+	// The hooks resolved for the call are not cached in the mapper.
+	assert.Error(t, m.Map("foo", &dst))
+}
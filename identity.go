@@ -0,0 +1,95 @@
+package anymapper
+
+import "reflect"
+
+// mapState holds the state of a single mapping call.
+type mapState struct {
+	// nodes maps source pointers to the destination pointers they were
+	// mapped to. It is set only if Context.PreserveIdentity is enabled.
+	nodes map[nodeKey]reflect.Value
+
+	// elements is the number of slice, array and map elements mapped so
+	// far, counted only if Context.MaxElements is set.
+	elements int
+
+	// cache holds the type mappers resolved using the per-call providers
+	// and hooks stored in mappers and hooks. It is set only if the context
+	// of the call resolves mapping functions per call.
+	cache   *typeCache
+	mappers map[reflect.Type]MapFuncProvider
+	hooks   *Hooks
+}
+
+// nodeKey identifies a source pointer mapped to a destination pointer type.
+// Pointer types are part of the key, because a pointer to a struct and a
+// pointer to its first field have the same address.
+type nodeKey struct {
+	ptr uintptr
+	src reflect.Type
+	dst reflect.Type
+}
+
+// withState returns a copy of the context with a new mapping state, if the
+// context needs one and does not have it yet. The root source and
+// destination pointers are recorded, so cycles that lead back to the root
+// are mapped to the root destination.
+func (c *Context) withState(src, dst reflect.Value) *Context {
+	if c.state != nil || (!c.PreserveIdentity && c.MaxElements <= 0 && !c.resolvesPerCall()) {
+		return c
+	}
+	cpy := *c
+	cpy.state = &mapState{}
+	if c.resolvesPerCall() {
+		cpy.state.cache = newTypeCache()
+		cpy.state.mappers = c.Mappers
+		cpy.state.hooks = c.Hooks
+	}
+	if !c.PreserveIdentity {
+		return &cpy
+	}
+	cpy.state.nodes = map[nodeKey]reflect.Value{}
+	src = unwrapInterface(src)
+	if src.Kind() == reflect.Pointer && !src.IsNil() && dst.Kind() == reflect.Pointer && !dst.IsNil() {
+		cpy.state.nodes[nodeKey{ptr: src.Pointer(), src: src.Type(), dst: dst.Type()}] = dst
+	}
+	return &cpy
+}
+
+// resolvesFor reports whether the type mappers cached in the state were
+// resolved using the same per-call providers and hooks as the context uses.
+func (s *mapState) resolvesFor(ctx *Context) bool {
+	return s.hooks == ctx.Hooks && reflect.ValueOf(s.mappers).Pointer() == reflect.ValueOf(ctx.Mappers).Pointer()
+}
+
+// sharedNode reuses the destination pointer that the src pointer was mapped
+// to earlier in the same mapping call. It returns true if dst was set to
+// the reused pointer and there is nothing left to map. If the src pointer
+// is seen for the first time, dst is initialized and recorded before it is
+// mapped, so later occurrences and cycles point to the same value.
+func (m *Mapper) sharedNode(ctx *Context, src, dst reflect.Value) bool {
+	if ctx.state == nil || ctx.state.nodes == nil {
+		return false
+	}
+	src = unwrapInterface(src)
+	if src.Kind() != reflect.Pointer || src.IsNil() || dst.Kind() != reflect.Pointer || !dst.CanSet() {
+		return false
+	}
+	key := nodeKey{ptr: src.Pointer(), src: src.Type(), dst: dst.Type()}
+	if ptr, ok := ctx.state.nodes[key]; ok {
+		dst.Set(ptr)
+		return true
+	}
+	if dst.IsNil() {
+		dst.Set(reflect.New(dst.Type().Elem()))
+	}
+	ctx.state.nodes[key] = reflect.ValueOf(dst.Interface())
+	return false
+}
+
+// unwrapInterface returns the value stored in a non-nil interface.
+func unwrapInterface(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
@@ -0,0 +1,69 @@
+package anymapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreserveIdentity(t *testing.T) {
+	type SrcNode struct {
+		Name string
+		Next *SrcNode
+	}
+	type DstNode struct {
+		Name string
+		Next *DstNode
+	}
+	type SrcGraph struct {
+		A, B  *SrcNode
+		Nodes []*SrcNode
+		ByKey map[string]*SrcNode
+	}
+	type DstGraph struct {
+		A, B  *DstNode
+		Nodes []*DstNode
+		ByKey map[string]*DstNode
+	}
+	shared := &SrcNode{Name: "shared"}
+	src := SrcGraph{
+		A:     shared,
+		B:     shared,
+		Nodes: []*SrcNode{shared, {Name: "other"}},
+		ByKey: map[string]*SrcNode{"x": shared},
+	}
+	t.Run("shared", func(t *testing.T) {
+		var dst DstGraph
+		ctx := Default.Context.WithPreserveIdentity(true)
+		require.NoError(t, MapContext(ctx, src, &dst))
+		assert.Equal(t, "shared", dst.A.Name)
+		assert.Same(t, dst.A, dst.B)
+		assert.Same(t, dst.A, dst.Nodes[0])
+		assert.Same(t, dst.A, dst.ByKey["x"])
+		assert.NotSame(t, dst.A, dst.Nodes[1])
+	})
+	t.Run("disabled", func(t *testing.T) {
+		var dst DstGraph
+		require.NoError(t, Map(src, &dst))
+		assert.Equal(t, dst.A, dst.B)
+		assert.NotSame(t, dst.A, dst.B)
+	})
+	t.Run("cycle", func(t *testing.T) {
+		a := &SrcNode{Name: "a"}
+		b := &SrcNode{Name: "b", Next: a}
+		a.Next = b
+		var dst DstNode
+		ctx := Default.Context.WithPreserveIdentity(true)
+		require.NoError(t, MapContext(ctx, a, &dst))
+		assert.Equal(t, "a", dst.Name)
+		assert.Equal(t, "b", dst.Next.Name)
+		assert.Same(t, &dst, dst.Next.Next)
+	})
+	t.Run("map-to-struct", func(t *testing.T) {
+		var dst DstGraph
+		ctx := Default.Context.WithPreserveIdentity(true)
+		require.NoError(t, MapContext(ctx, map[string]any{"A": shared, "B": shared}, &dst))
+		assert.Same(t, dst.A, dst.B)
+	})
+}
@@ -0,0 +1,36 @@
+package anymapper
+
+import "reflect"
+
+// IgnoreTypes adds the types to the list of ignored types. Struct fields of
+// ignored types, or pointers to them, are skipped on both sides of the
+// mapping, as if they had the "-" tag. It is useful for fields that must
+// not be copied, like sync.Mutex, or that do not carry data, like
+// context.Context.
+//
+// Types must be added before the mapper is used, the method is not safe for
+// concurrent use with the mapping methods.
+func (m *Mapper) IgnoreTypes(types ...reflect.Type) {
+	if m.IgnoredTypes == nil {
+		m.IgnoredTypes = make(map[reflect.Type]bool, len(types))
+	}
+	for _, t := range types {
+		m.IgnoredTypes[t] = true
+	}
+}
+
+// mappedField reports whether the struct field takes part in the mapping,
+// that is, whether it is exported and its type is not ignored.
+func (m *Mapper) mappedField(f reflect.StructField) bool {
+	if !f.IsExported() {
+		return false
+	}
+	if len(m.IgnoredTypes) == 0 {
+		return true
+	}
+	t := f.Type
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return !m.IgnoredTypes[t]
+}
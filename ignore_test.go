@@ -0,0 +1,69 @@
+package anymapper
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnoreTypes(t *testing.T) {
+	type counter struct {
+		sync.Mutex
+		Ctx   context.Context
+		Count int
+	}
+	type dto struct {
+		Mutex string
+		Ctx   string
+		Count string
+	}
+	m := New()
+	m.IgnoreTypes(reflect.TypeOf(sync.Mutex{}), reflect.TypeOf((*context.Context)(nil)).Elem())
+
+	t.Run("same-type", func(t *testing.T) {
+		src := &counter{Ctx: context.Background(), Count: 1}
+		src.Lock()
+		defer src.Unlock()
+		var dst counter
+		require.NoError(t, m.Map(src, &dst))
+		assert.Equal(t, 1, dst.Count)
+		assert.Nil(t, dst.Ctx)
+		// The mutex must not be copied in the locked state.
+		assert.True(t, dst.TryLock())
+	})
+	t.Run("struct-to-map", func(t *testing.T) {
+		var dst map[string]any
+		require.NoError(t, m.Map(&counter{Ctx: context.Background(), Count: 1}, &dst))
+		assert.Equal(t, map[string]any{"Count": 1}, dst)
+	})
+	t.Run("map-to-struct", func(t *testing.T) {
+		var missing []string
+		cpy := m.Copy()
+		cpy.Hooks.MissingFieldHook = func(m *Mapper, ctx *Context, field reflect.StructField, key string, dst reflect.Value) error {
+			missing = append(missing, key)
+			return nil
+		}
+		var dst counter
+		require.NoError(t, cpy.Map(map[string]any{"Count": "2", "Ctx": "foo"}, &dst))
+		assert.Equal(t, 2, dst.Count)
+		assert.Empty(t, missing)
+	})
+	t.Run("struct-to-struct", func(t *testing.T) {
+		var dst dto
+		require.NoError(t, m.Map(&counter{Count: 3}, &dst))
+		assert.Equal(t, dto{Count: "3"}, dst)
+	})
+	t.Run("pointer", func(t *testing.T) {
+		type src struct {
+			M *sync.Mutex
+			N int
+		}
+		var dst map[string]any
+		require.NoError(t, m.Map(src{M: &sync.Mutex{}, N: 1}, &dst))
+		assert.Equal(t, map[string]any{"N": 1}, dst)
+	})
+}
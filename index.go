@@ -0,0 +1,154 @@
+package anymapper
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// DefaultMaxIndex is the largest index of integer-keyed map entries mapped
+// to slices, used if Context.MaxIndex is zero. It protects against sparse
+// maps with huge keys, which would require huge slices.
+const DefaultMaxIndex = 1<<16 - 1
+
+// maxIndex returns the largest index of integer-keyed map entries that can
+// be mapped to slices.
+func (c *Context) maxIndex() int {
+	if c.MaxIndex > 0 {
+		return c.MaxIndex
+	}
+	return DefaultMaxIndex
+}
+
+// mapIndexMapToSlice maps an integer-keyed map to a slice, placing every
+// value at the index equal to its key. The length of the destination slice
+// is set to the largest key plus one, and elements at the indexes missing
+// in the source are set to zero values.
+func mapIndexMapToSlice(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if err := checkLimits(ctx, src); err != nil {
+		return err
+	}
+	var (
+		keys    = ctx.mapKeys(src)
+		indexes = make([]int, len(keys))
+		length  = 0
+	)
+	for n, key := range keys {
+		i, ok := keyIndex(key)
+		if !ok || i > ctx.maxIndex() {
+			return NewInvalidMappingError(
+				src.Type(),
+				dst.Type(),
+				fmt.Sprintf("index out of range: %v", key.Interface()),
+			)
+		}
+		indexes[n] = i
+		if i >= length {
+			length = i + 1
+		}
+	}
+	// Elements are already counted by checkLimits.
+	if err := checkCount(ctx, dst.Type(), false, length, 0); err != nil {
+		return err
+	}
+	prev := dst.Len()
+	switch {
+	case length <= dst.Cap():
+		dst.SetLen(length)
+	default:
+		dst.Set(reflect.AppendSlice(dst, reflect.MakeSlice(dst.Type(), length-dst.Len(), length-dst.Len())))
+	}
+	if prev > length {
+		prev = length
+	}
+	// Reset the reused elements that are not present in the source.
+	set := make([]bool, prev)
+	for _, i := range indexes {
+		if i < prev {
+			set[i] = true
+		}
+	}
+	for i := 0; i < prev; i++ {
+		if !set[i] {
+			dst.Index(i).Set(reflect.Zero(dst.Type().Elem()))
+		}
+	}
+	var (
+		mapper = m.mapperFor(ctx, src.Type().Elem(), dst.Type().Elem())
+		errs   []error
+	)
+	for n, key := range keys {
+		srcRaw := src.MapIndex(key)
+		dstElem := dst.Index(indexes[n])
+		if m.sharedNode(ctx, srcRaw, dstElem) {
+			continue
+		}
+		srcVal := m.srcValue(ctx, srcRaw)
+		if !srcVal.IsValid() && m.mapNil(ctx, dstElem) {
+			continue
+		}
+		dstVal := m.dstValue(ctx, dstElem)
+		if err := m.mapValue(ctx.enterIndex(indexes[n]), &mapper, srcVal, dstVal); err != nil {
+			if err := collectError(ctx, &errs, dstElem, indexPathError(err, indexes[n])); err != nil {
+				return err
+			}
+		}
+	}
+	return joinErrors(errs)
+}
+
+// mapSliceToIndexMap maps a slice to an integer-keyed map, using the
+// indexes of the elements as keys.
+func mapSliceToIndexMap(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	// Elements are counted when the map is mapped to the destination.
+	if err := checkCount(ctx, src.Type(), false, src.Len(), 0); err != nil {
+		return err
+	}
+	var (
+		keyTyp = dst.Type().Key()
+		idx    = reflect.MakeMapWithSize(reflect.MapOf(keyTyp, src.Type().Elem()), src.Len())
+	)
+	for i := 0; i < src.Len(); i++ {
+		key := reflect.New(keyTyp).Elem()
+		if !setKeyIndex(key, i) {
+			return NewInvalidMappingError(
+				src.Type(),
+				dst.Type(),
+				fmt.Sprintf("index %d overflows %v", i, keyTyp),
+			)
+		}
+		idx.SetMapIndex(key, src.Index(i))
+	}
+	return mapMapToMap(m, ctx, idx, dst)
+}
+
+// keyIndex returns the slice index equal to the integer map key. It returns
+// false if the key is negative or does not fit in an int.
+func keyIndex(key reflect.Value) (int, bool) {
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := key.Int()
+		return int(i), i >= 0 && i <= math.MaxInt
+	default:
+		u := key.Uint()
+		return int(u), u <= math.MaxInt
+	}
+}
+
+// setKeyIndex sets the integer map key to the given index. It returns false
+// if the index overflows the key type.
+func setKeyIndex(key reflect.Value, i int) bool {
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if key.OverflowInt(int64(i)) {
+			return false
+		}
+		key.SetInt(int64(i))
+	default:
+		if key.OverflowUint(uint64(i)) {
+			return false
+		}
+		key.SetUint(uint64(i))
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package anymapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexMap(t *testing.T) {
+	t.Run("map-to-slice", func(t *testing.T) {
+		var dst []string
+		require.NoError(t, Map(map[int]any{0: "a", 3: 4}, &dst))
+		assert.Equal(t, []string{"a", "", "", "4"}, dst)
+	})
+	t.Run("map-to-existing-slice", func(t *testing.T) {
+		dst := []int{1, 2, 3, 4, 5}
+		require.NoError(t, Map(map[uint8]string{2: "7"}, &dst))
+		assert.Equal(t, []int{0, 0, 7}, dst)
+	})
+	t.Run("empty-map", func(t *testing.T) {
+		dst := []int{1}
+		require.NoError(t, Map(map[int]int{}, &dst))
+		assert.Empty(t, dst)
+	})
+	t.Run("slice-to-map", func(t *testing.T) {
+		var dst map[int]int
+		require.NoError(t, Map([]string{"1", "2"}, &dst))
+		assert.Equal(t, map[int]int{0: 1, 1: 2}, dst)
+	})
+	t.Run("slice-to-existing-map", func(t *testing.T) {
+		dst := map[uint]string{5: "x"}
+		require.NoError(t, Map([]int{1}, &dst))
+		assert.Equal(t, map[uint]string{0: "1", 5: "x"}, dst)
+	})
+	t.Run("kv-elements", func(t *testing.T) {
+		// Integer keys are indexes, even if the elements are key/value
+		// pairs.
+		var dst map[int]KV
+		require.NoError(t, Map([]KV{{Key: "a", Value: 1}}, &dst))
+		assert.Equal(t, map[int]KV{0: {Key: "a", Value: 1}}, dst)
+
+		var kv []KV
+		require.NoError(t, Map(map[int]KV{1: {Key: "b", Value: 2}}, &kv))
+		assert.Equal(t, []KV{{}, {Key: "b", Value: 2}}, kv)
+	})
+	t.Run("negative-index", func(t *testing.T) {
+		var dst []int
+		assert.Error(t, Map(map[int]int{-1: 1}, &dst))
+	})
+	t.Run("max-index", func(t *testing.T) {
+		var dst []int
+		assert.Error(t, Map(map[int]int{DefaultMaxIndex + 1: 1}, &dst))
+		ctx := Default.Context.WithMaxIndex(2)
+		require.NoError(t, MapContext(ctx, map[int]int{2: 1}, &dst))
+		assert.Error(t, MapContext(ctx, map[int]int{3: 1}, &dst))
+	})
+	t.Run("max-length", func(t *testing.T) {
+		var dst []int
+		ctx := Default.Context.WithMaxLength(2)
+		assert.ErrorIs(t, MapContext(ctx, map[int]int{2: 1}, &dst), LimitExceededErr)
+	})
+	t.Run("key-overflow", func(t *testing.T) {
+		var dst map[int8]int
+		assert.Error(t, Map(make([]int, 200), &dst))
+	})
+}
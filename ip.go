@@ -0,0 +1,305 @@
+package anymapper
+
+import (
+	"net"
+	"net/netip"
+	"reflect"
+)
+
+var (
+	ipTy    = reflect.TypeOf((*net.IP)(nil)).Elem()
+	ipNetTy = reflect.TypeOf((*net.IPNet)(nil)).Elem()
+	netipTy = reflect.TypeOf((*netip.Addr)(nil)).Elem()
+)
+
+const ipLenErr = "IP address must be 4 or 16 bytes long"
+
+// ipTypeMapper maps net.IP addresses to and from strings, and to and from
+// netip.Addr values. Byte slices and arrays mapped to addresses must be 4 or
+// 16 bytes long, unless they are treated as text, see Context.TextBytes.
+// Other conversions use the built-in rules.
+func ipTypeMapper(m *Mapper, src, dst reflect.Type) MapFunc {
+	switch {
+	case src == dst:
+		return builtInTypesMapper(m, src, dst)
+	case src == ipTy && dst.Kind() == reflect.String:
+		return mapIPToString
+	case dst == ipTy && src.Kind() == reflect.String:
+		return mapStringToIP
+	case src == ipTy && dst == netipTy:
+		return mapIPToNetip
+	case dst == ipTy && src == netipTy:
+		return mapNetipToIP
+	case dst == anyTy:
+		return mapAny
+	case isByteSliceOrArray(src) || isByteSliceOrArray(dst):
+		return mapIPBytes(builtInTypesMapper(m, src, dst))
+	}
+	return builtInTypesMapper(m, src, dst)
+}
+
+// ipNetTypeMapper maps net.IPNet networks to and from strings in the CIDR
+// notation, e.g. "10.0.0.0/8", and to and from byte slices containing the
+// IP address followed by the mask. Other conversions, like to and from
+// maps, use the built-in rules.
+func ipNetTypeMapper(m *Mapper, src, dst reflect.Type) MapFunc {
+	switch {
+	case src == ipNetTy && dst.Kind() == reflect.String:
+		return mapIPNetToString
+	case dst == ipNetTy && src.Kind() == reflect.String:
+		return mapStringToIPNet
+	case src == ipNetTy && isByteSlice(dst):
+		return mapIPNetToBytes
+	case dst == ipNetTy && isByteSlice(src):
+		return mapBytesToIPNet
+	case dst == anyTy:
+		return mapAny
+	}
+	return builtInTypesMapper(m, src, dst)
+}
+
+// netipTypeMapper maps netip.Addr addresses to and from strings, byte
+// slices in the format of netip.Addr.MarshalBinary, and net.IP addresses.
+// The zero address is mapped to and from empty strings and slices.
+func netipTypeMapper(_ *Mapper, src, dst reflect.Type) MapFunc {
+	if src == dst {
+		return mapDirect
+	}
+	switch {
+	case src == netipTy && dst.Kind() == reflect.String:
+		return mapNetipToString
+	case dst == netipTy && src.Kind() == reflect.String:
+		return mapStringToNetip
+	case src == netipTy && dst == ipTy:
+		return mapNetipToIP
+	case dst == netipTy && src == ipTy:
+		return mapIPToNetip
+	case src == netipTy && isByteSlice(dst):
+		return mapNetipToBytes
+	case dst == netipTy && isByteSlice(src):
+		return mapBytesToNetip
+	case dst == anyTy:
+		return mapAny
+	}
+	return nil
+}
+
+func isByteSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// mapIPBytes returns a function that maps IP addresses to and from byte
+// slices and arrays using mapFunc, or using their string representations if
+// the byte slice is treated as text.
+func mapIPBytes(mapFunc MapFunc) MapFunc {
+	if mapFunc == nil {
+		return nil
+	}
+	return func(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+		switch {
+		case dst.Type() == ipTy && isTextBytes(ctx, src):
+			return textError(src, mapStringToIP(m, ctx, reflect.ValueOf(string(src.Bytes())), dst))
+		case src.Type() == ipTy && isTextBytes(ctx, dst):
+			return mapViaText(m, ctx, src, dst, mapIPToString)
+		case dst.Type() == ipTy && src.Len() != net.IPv4len && src.Len() != net.IPv6len:
+			return NewInvalidMappingError(src.Type(), dst.Type(), ipLenErr)
+		}
+		return mapFunc(m, ctx, src, dst)
+	}
+}
+
+// mapViaText maps src to the byte slice dst treated as text, using the
+// mapFunc that maps src to strings.
+func mapViaText(m *Mapper, ctx *Context, src, dst reflect.Value, mapFunc MapFunc) error {
+	text := reflect.New(stringTy).Elem()
+	if err := mapFunc(m, ctx, src, text); err != nil {
+		return err
+	}
+	dst.SetBytes([]byte(text.String()))
+	return nil
+}
+
+func mapIPToString(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	ip := net.IP(src.Bytes())
+	if len(ip) == 0 {
+		dst.SetString("")
+		return nil
+	}
+	if len(ip) != net.IPv4len && len(ip) != net.IPv6len {
+		return NewInvalidMappingError(src.Type(), dst.Type(), ipLenErr)
+	}
+	dst.SetString(ip.String())
+	return nil
+}
+
+func mapStringToIP(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	var ip net.IP
+	if err := ip.UnmarshalText([]byte(ctx.parseInput(src.String()))); err != nil {
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	dst.SetBytes(ip)
+	return nil
+}
+
+func mapIPToNetip(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	if src.Len() == 0 {
+		dst.Set(reflect.ValueOf(netip.Addr{}))
+		return nil
+	}
+	addr, ok := netip.AddrFromSlice(src.Bytes())
+	if !ok {
+		return NewInvalidMappingError(src.Type(), dst.Type(), ipLenErr)
+	}
+	dst.Set(reflect.ValueOf(addr))
+	return nil
+}
+
+func mapNetipToIP(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	addr := src.Interface().(netip.Addr)
+	if !addr.IsValid() {
+		dst.SetBytes(nil)
+		return nil
+	}
+	dst.SetBytes(addr.AsSlice())
+	return nil
+}
+
+func mapIPNetToString(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	ipNet := src.Addr().Interface().(*net.IPNet)
+	if len(ipNet.IP) == 0 {
+		dst.SetString("")
+		return nil
+	}
+	dst.SetString(ipNet.String())
+	return nil
+}
+
+// mapStringToIPNet parses networks in the CIDR notation. Host bits of the
+// address are cleared, as in net.ParseCIDR.
+func mapStringToIPNet(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	s := ctx.parseInput(src.String())
+	if s == "" {
+		dst.Set(reflect.ValueOf(net.IPNet{}))
+		return nil
+	}
+	_, ipNet, err := net.ParseCIDR(s)
+	if err != nil {
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	dst.Set(reflect.ValueOf(ipNet).Elem())
+	return nil
+}
+
+func mapIPNetToBytes(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	if isTextBytes(ctx, dst) {
+		return mapViaText(m, ctx, src, dst, mapIPNetToString)
+	}
+	ipNet := src.Addr().Interface().(*net.IPNet)
+	if len(ipNet.IP) == 0 {
+		dst.SetBytes(nil)
+		return nil
+	}
+	if len(ipNet.IP) != len(ipNet.Mask) {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "IP address and mask lengths differ")
+	}
+	b := make([]byte, 0, len(ipNet.IP)+len(ipNet.Mask))
+	dst.SetBytes(append(append(b, ipNet.IP...), ipNet.Mask...))
+	return nil
+}
+
+func mapBytesToIPNet(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	if isTextBytes(ctx, src) {
+		return textError(src, mapStringToIPNet(m, ctx, reflect.ValueOf(string(src.Bytes())), dst))
+	}
+	b := src.Bytes()
+	if len(b) == 0 {
+		dst.Set(reflect.ValueOf(net.IPNet{}))
+		return nil
+	}
+	if len(b) != 2*net.IPv4len && len(b) != 2*net.IPv6len {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "IP network must be 8 or 32 bytes long")
+	}
+	n := len(b) / 2
+	mask := net.IPMask(append([]byte(nil), b[n:]...))
+	if _, bits := mask.Size(); bits == 0 {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "non-canonical IP mask")
+	}
+	ip := net.IP(append([]byte(nil), b[:n]...)).Mask(mask)
+	dst.Set(reflect.ValueOf(net.IPNet{IP: ip, Mask: mask}))
+	return nil
+}
+
+func mapNetipToString(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	text, _ := src.Interface().(netip.Addr).MarshalText()
+	dst.SetString(string(text))
+	return nil
+}
+
+func mapStringToNetip(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	var addr netip.Addr
+	if err := addr.UnmarshalText([]byte(ctx.parseInput(src.String()))); err != nil {
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	dst.Set(reflect.ValueOf(addr))
+	return nil
+}
+
+func mapNetipToBytes(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	addr := src.Interface().(netip.Addr)
+	var b []byte
+	if isTextBytes(ctx, dst) {
+		b, _ = addr.MarshalText()
+	} else {
+		b, _ = addr.MarshalBinary()
+	}
+	dst.SetBytes(b)
+	return nil
+}
+
+func mapBytesToNetip(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	if isTextBytes(ctx, src) {
+		return textError(src, mapStringToNetip(m, ctx, reflect.ValueOf(string(src.Bytes())), dst))
+	}
+	var addr netip.Addr
+	if err := addr.UnmarshalBinary(src.Bytes()); err != nil {
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	dst.Set(reflect.ValueOf(addr))
+	return nil
+}
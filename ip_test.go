@@ -0,0 +1,154 @@
+package anymapper
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIP(t *testing.T) {
+	ip4 := net.IPv4(192, 168, 0, 1).To4()
+	ip6 := net.ParseIP("2001:db8::1")
+
+	t.Run("string", func(t *testing.T) {
+		var s string
+		require.NoError(t, Map(ip4, &s))
+		assert.Equal(t, "192.168.0.1", s)
+		require.NoError(t, Map(ip6, &s))
+		assert.Equal(t, "2001:db8::1", s)
+		require.NoError(t, Map(net.IP(nil), &s))
+		assert.Equal(t, "", s)
+		var dst net.IP
+		require.NoError(t, Map("192.168.0.1", &dst))
+		assert.True(t, ip4.Equal(dst))
+		require.NoError(t, Map("", &dst))
+		assert.Nil(t, dst)
+	})
+	t.Run("bytes", func(t *testing.T) {
+		var b []byte
+		require.NoError(t, Map(ip4, &b))
+		assert.Equal(t, []byte{192, 168, 0, 1}, b)
+		var dst net.IP
+		require.NoError(t, Map([]byte{10, 0, 0, 1}, &dst))
+		assert.Equal(t, net.IP{10, 0, 0, 1}, dst)
+		var arr [4]byte
+		require.NoError(t, Map(ip4, &arr))
+		assert.Equal(t, [4]byte{192, 168, 0, 1}, arr)
+		require.NoError(t, Map(arr, &dst))
+		assert.Equal(t, ip4, dst)
+	})
+	t.Run("text-bytes", func(t *testing.T) {
+		ctx := Default.Context.WithTextBytes(true)
+		var b []byte
+		require.NoError(t, MapContext(ctx, ip4, &b))
+		assert.Equal(t, []byte("192.168.0.1"), b)
+		var dst net.IP
+		require.NoError(t, MapContext(ctx, []byte("10.0.0.1"), &dst))
+		assert.True(t, net.IPv4(10, 0, 0, 1).Equal(dst))
+	})
+	t.Run("struct-field", func(t *testing.T) {
+		var dst struct {
+			Addr net.IP `map:"addr"`
+		}
+		require.NoError(t, Map(map[string]any{"addr": "::1"}, &dst))
+		assert.Equal(t, net.IPv6loopback, dst.Addr)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		var dst net.IP
+		assert.Error(t, Map("300.0.0.1", &dst))
+		assert.Error(t, Map([]byte{1, 2, 3}, &dst))
+		var s string
+		assert.Error(t, Map(net.IP{1, 2, 3}, &s))
+	})
+}
+
+func TestIPNet(t *testing.T) {
+	_, ipNet, _ := net.ParseCIDR("10.1.0.0/16")
+
+	t.Run("string", func(t *testing.T) {
+		var s string
+		require.NoError(t, Map(*ipNet, &s))
+		assert.Equal(t, "10.1.0.0/16", s)
+		var dst net.IPNet
+		require.NoError(t, Map("10.1.2.3/16", &dst))
+		assert.Equal(t, *ipNet, dst)
+		require.NoError(t, Map(net.IPNet{}, &s))
+		assert.Equal(t, "", s)
+	})
+	t.Run("pointer", func(t *testing.T) {
+		var dst *net.IPNet
+		require.NoError(t, Map("10.1.0.0/16", &dst))
+		assert.Equal(t, ipNet, dst)
+	})
+	t.Run("bytes", func(t *testing.T) {
+		var b []byte
+		require.NoError(t, Map(*ipNet, &b))
+		assert.Equal(t, []byte{10, 1, 0, 0, 255, 255, 0, 0}, b)
+		var dst net.IPNet
+		require.NoError(t, Map(b, &dst))
+		assert.Equal(t, *ipNet, dst)
+	})
+	t.Run("text-bytes", func(t *testing.T) {
+		ctx := Default.Context.WithTextBytes(true)
+		var b []byte
+		require.NoError(t, MapContext(ctx, *ipNet, &b))
+		assert.Equal(t, []byte("10.1.0.0/16"), b)
+		var dst net.IPNet
+		require.NoError(t, MapContext(ctx, b, &dst))
+		assert.Equal(t, *ipNet, dst)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		var dst net.IPNet
+		assert.Error(t, Map("10.1.0.0", &dst))
+		assert.Error(t, Map([]byte{10, 1, 0, 0}, &dst))
+		assert.Error(t, Map([]byte{10, 1, 0, 0, 255, 0, 255, 0}, &dst))
+	})
+}
+
+func TestNetipAddr(t *testing.T) {
+	addr := netip.MustParseAddr("192.168.0.1")
+
+	t.Run("string", func(t *testing.T) {
+		var s string
+		require.NoError(t, Map(addr, &s))
+		assert.Equal(t, "192.168.0.1", s)
+		require.NoError(t, Map(netip.Addr{}, &s))
+		assert.Equal(t, "", s)
+		var dst netip.Addr
+		require.NoError(t, Map("fe80::1%eth0", &dst))
+		assert.Equal(t, netip.MustParseAddr("fe80::1%eth0"), dst)
+		require.NoError(t, Map("", &dst))
+		assert.Equal(t, netip.Addr{}, dst)
+	})
+	t.Run("bytes", func(t *testing.T) {
+		var b []byte
+		require.NoError(t, Map(addr, &b))
+		assert.Equal(t, []byte{192, 168, 0, 1}, b)
+		var dst netip.Addr
+		require.NoError(t, Map(b, &dst))
+		assert.Equal(t, addr, dst)
+	})
+	t.Run("net-ip", func(t *testing.T) {
+		var ip net.IP
+		require.NoError(t, Map(addr, &ip))
+		assert.Equal(t, net.IP{192, 168, 0, 1}, ip)
+		var dst netip.Addr
+		require.NoError(t, Map(ip, &dst))
+		assert.Equal(t, addr, dst)
+	})
+	t.Run("any", func(t *testing.T) {
+		var dst any
+		require.NoError(t, Map(addr, &dst))
+		assert.Equal(t, addr, dst)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		var dst netip.Addr
+		assert.Error(t, Map("192.168.0", &dst))
+		assert.Error(t, Map([]byte{1, 2, 3}, &dst))
+		assert.Error(t, Map(net.IP{1, 2, 3}, &dst))
+		assert.Error(t, Map(1, &dst))
+	})
+}
@@ -0,0 +1,26 @@
+//go:build go1.23
+
+package anymapper
+
+import "iter"
+
+// Iter returns an iterator that maps the values of seq to values of type D
+// using the Default mapper. Values are mapped lazily, one at a time, when
+// the iterator is consumed. Every mapped value is yielded together with
+// the mapping error, if any. The options are applied only to this call.
+func Iter[S, D any](seq iter.Seq[S], opts ...Option) iter.Seq2[D, error] {
+	return IterWith[S, D](Default, seq, opts...)
+}
+
+// IterWith returns an iterator that maps the values of seq to values of
+// type D using the given mapper. See Iter for details.
+func IterWith[S, D any](m *Mapper, seq iter.Seq[S], opts ...Option) iter.Seq2[D, error] {
+	ctx := applyOptions(m.Context, opts)
+	return func(yield func(D, error) bool) {
+		seq(func(v S) bool {
+			var dst D
+			err := m.MapContext(ctx, v, &dst)
+			return yield(dst, err)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+//go:build go1.23
+
+package anymapper
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIterSources(t *testing.T) {
+	var dst []string
+	require.NoError(t, Map(slices.Values([]int{1, 2}), &dst))
+	assert.Equal(t, []string{"1", "2"}, dst)
+
+	var m map[string]string
+	require.NoError(t, Map(maps.All(map[string]int{"a": 1}), &m))
+	assert.Equal(t, map[string]string{"a": "1"}, m)
+}
+
+func TestIter(t *testing.T) {
+	var (
+		vals []int
+		errs []error
+	)
+	Iter[string, int](slices.Values([]string{"1", "x", "3"}))(func(v int, err error) bool {
+		vals = append(vals, v)
+		errs = append(errs, err)
+		return true
+	})
+	assert.Equal(t, []int{1, 0, 3}, vals)
+	assert.NoError(t, errs[0])
+	assert.Error(t, errs[1])
+	assert.NoError(t, errs[2])
+
+	// Stops when the consumer stops.
+	var n int
+	IterWith[string, int](Default, slices.Values([]string{"1", "2", "3"}))(func(int, error) bool {
+		n++
+		return false
+	})
+	assert.Equal(t, 1, n)
+}
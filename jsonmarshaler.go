@@ -0,0 +1,94 @@
+package anymapper
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+var (
+	jsonMarshalerTy   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshalerTy = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// implJSONMarshaler returns true if the type or a pointer to it implements
+// the json.Marshaler interface.
+func implJSONMarshaler(t reflect.Type) bool {
+	return t.Kind() != reflect.Interface && reflect.PointerTo(t).Implements(jsonMarshalerTy)
+}
+
+// implJSONUnmarshaler returns true if a pointer to the type implements the
+// json.Unmarshaler interface.
+func implJSONUnmarshaler(t reflect.Type) bool {
+	return t.Kind() != reflect.Interface && reflect.PointerTo(t).Implements(jsonUnmarshalerTy)
+}
+
+// jsonMarshalerFunc returns a MapFunc that maps values through their JSON
+// representations, if the source type implements the json.Marshaler
+// interface or the destination type implements the json.Unmarshaler
+// interface, and Context.JSONMarshalers is enabled. Otherwise, and for
+// other types, the next MapFunc is returned, which may be nil.
+func jsonMarshalerFunc(ctx *Context, src, dst reflect.Type, next MapFunc) MapFunc {
+	if src == dst || dst.Kind() == reflect.Interface || !ctx.JSONMarshalers {
+		return next
+	}
+	switch srcImpl, dstImpl := implJSONMarshaler(src), implJSONUnmarshaler(dst); {
+	case srcImpl && dstImpl:
+		return mapJSONMarshalerToJSONUnmarshaler
+	case srcImpl:
+		return mapFromJSONMarshaler
+	case dstImpl:
+		return mapToJSONUnmarshaler
+	}
+	return next
+}
+
+func mapJSONMarshalerToJSONUnmarshaler(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	data, err := addr(src).Interface().(json.Marshaler).MarshalJSON()
+	if err != nil {
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	if err := dst.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(data); err != nil {
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	return nil
+}
+
+// mapFromJSONMarshaler decodes the JSON representation of src into generic
+// values, which are then mapped to dst. Numbers are decoded as json.Number
+// values, so they do not lose precision.
+func mapFromJSONMarshaler(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	data, err := addr(src).Interface().(json.Marshaler).MarshalJSON()
+	if err != nil {
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var aux any
+	if err := dec.Decode(&aux); err != nil {
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	return m.MapReflContext(ctx, reflect.ValueOf(&aux).Elem(), dst)
+}
+
+// mapToJSONUnmarshaler encodes src as JSON using the encoding/json package
+// and decodes it into dst.
+func mapToJSONUnmarshaler(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	data, err := json.Marshal(src.Interface())
+	if err != nil {
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	if err := dst.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(data); err != nil {
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	return nil
+}
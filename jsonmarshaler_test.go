@@ -0,0 +1,107 @@
+package anymapper
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testMoney is encoded in JSON as an object with the amount as a string.
+type testMoney struct {
+	cents    int64
+	currency string
+}
+
+func (m testMoney) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"amount":   big.NewRat(m.cents, 100).FloatString(2),
+		"currency": m.currency,
+	})
+}
+
+func (m *testMoney) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Amount   json.Number `json:"amount"`
+		Currency string      `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r, ok := new(big.Rat).SetString(aux.Amount.String())
+	if !ok {
+		return errors.New("invalid amount")
+	}
+	r.Mul(r, big.NewRat(100, 1))
+	m.cents = r.Num().Int64()
+	m.currency = aux.Currency
+	return nil
+}
+
+// testSymbol is encoded in JSON as an upper case string.
+type testSymbol struct {
+	name string
+}
+
+func (s testSymbol) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strings.ToUpper(s.name))
+}
+
+func TestJSONMarshalers(t *testing.T) {
+	ctx := Default.Context.WithJSONMarshalers(true)
+
+	t.Run("from-marshaler", func(t *testing.T) {
+		var dst struct {
+			Amount   float64 `map:"amount"`
+			Currency string  `map:"currency"`
+		}
+		require.NoError(t, MapContext(ctx, testMoney{cents: 150, currency: "EUR"}, &dst))
+		assert.Equal(t, 1.5, dst.Amount)
+		assert.Equal(t, "EUR", dst.Currency)
+		var s string
+		require.NoError(t, MapContext(ctx, testSymbol{name: "eth"}, &s))
+		assert.Equal(t, "ETH", s)
+	})
+	t.Run("to-unmarshaler", func(t *testing.T) {
+		var dst testMoney
+		require.NoError(t, MapContext(ctx, map[string]any{"amount": "2.25", "currency": "USD"}, &dst))
+		assert.Equal(t, testMoney{cents: 225, currency: "USD"}, dst)
+		assert.Error(t, MapContext(ctx, map[string]any{"amount": "x"}, &dst))
+	})
+	t.Run("marshaler-to-unmarshaler", func(t *testing.T) {
+		var raw json.RawMessage
+		require.NoError(t, MapContext(ctx, &testMoney{cents: 1, currency: "PLN"}, &raw))
+		assert.JSONEq(t, `{"amount":"0.01","currency":"PLN"}`, string(raw))
+		var dst testMoney
+		require.NoError(t, MapContext(ctx, raw, &dst))
+		assert.Equal(t, testMoney{cents: 1, currency: "PLN"}, dst)
+	})
+	t.Run("struct-fields", func(t *testing.T) {
+		type Order struct {
+			Price testMoney `map:"price"`
+		}
+		var order Order
+		src := map[string]any{"price": map[string]any{"amount": 3, "currency": "GBP"}}
+		require.NoError(t, MapContext(ctx, src, &order))
+		assert.Equal(t, testMoney{cents: 300, currency: "GBP"}, order.Price)
+		var m map[string]map[string]string
+		require.NoError(t, MapContext(ctx, order, &m))
+		assert.Equal(t, map[string]map[string]string{"price": {"amount": "3.00", "currency": "GBP"}}, m)
+	})
+	t.Run("any", func(t *testing.T) {
+		var dst any
+		require.NoError(t, MapContext(ctx, testSymbol{name: "a"}, &dst))
+		assert.Equal(t, testSymbol{name: "a"}, dst)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		var s string
+		assert.Error(t, Map(testSymbol{name: "eth"}, &s))
+		var dst testMoney
+		require.NoError(t, Map(map[string]any{"amount": "2.25"}, &dst))
+		assert.Equal(t, testMoney{}, dst)
+	})
+}
@@ -0,0 +1,157 @@
+package anymapper
+
+import (
+	"reflect"
+	"strings"
+)
+
+// KV is a key/value pair. Slices of KV can be used instead of maps where
+// the order of entries matters, e.g. in canonical payloads that are signed.
+//
+// Structs and maps can be mapped to and from []KV, or slices of any other
+// struct type with exactly two exported fields, where the first one is a
+// string named Key and the second one is named Value. Instead of the names,
+// the fields may be tagged as `map:"key"` and `map:"value"`. Structs are mapped to key/value pairs in the order of their
+// fields, and maps in the order of their sorted keys. The previous content
+// of the destination slice is replaced. If a key occurs multiple times in
+// the source slice, the last value is used.
+type KV struct {
+	Key   string
+	Value any
+}
+
+var kvTy = reflect.TypeOf(KV{})
+
+// isKVSlice reports whether t is a slice of key/value pairs.
+func isKVSlice(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice {
+		return false
+	}
+	e := t.Elem()
+	if e == kvTy {
+		return true
+	}
+	return e.Kind() == reflect.Struct &&
+		e.NumField() == 2 &&
+		isKVField(e.Field(0), "Key") &&
+		isKVField(e.Field(1), "Value") &&
+		e.Field(0).Type.Kind() == reflect.String
+}
+
+// isKVField reports whether f is an exported field with the given name, or
+// with the lower-cased name in the map tag.
+func isKVField(f reflect.StructField, name string) bool {
+	if !f.IsExported() {
+		return false
+	}
+	tag, _, _ := strings.Cut(f.Tag.Get("map"), ",")
+	return f.Name == name || tag == strings.ToLower(name)
+}
+
+// kvMapType returns the type of the map equivalent to the given slice of
+// key/value pairs.
+func kvMapType(t reflect.Type) reflect.Type {
+	return reflect.MapOf(t.Elem().Field(0).Type, t.Elem().Field(1).Type)
+}
+
+// mapKVToStruct maps a slice of key/value pairs to a struct, as if it was
+// a map.
+func mapKVToStruct(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	kv, err := kvToMap(ctx, src)
+	if err != nil {
+		return err
+	}
+	return mapMapToStruct(m, ctx, kv, dst)
+}
+
+// mapKVToMap maps a slice of key/value pairs to a map.
+func mapKVToMap(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	kv, err := kvToMap(ctx, src)
+	if err != nil {
+		return err
+	}
+	return mapMapToMap(m, ctx, kv, dst)
+}
+
+// mapStructToKV maps a struct to a slice of key/value pairs, in the order
+// of the struct fields.
+func mapStructToKV(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	kv := reflect.MakeMap(kvMapType(dst.Type()))
+	err := mapStructToMap(m, ctx, src, kv)
+	if _, ok := err.(MappingErrors); err != nil && !ok {
+		return err
+	}
+	var (
+		srcTyp = src.Type()
+		fields = m.structFields(ctx, srcTyp)
+		keys   = make([]reflect.Value, 0, kv.Len())
+		seen   = make(map[string]bool, kv.Len())
+	)
+	for i := 0; i < numFields(srcTyp, fields); i++ {
+		srcFld := fieldAt(srcTyp, fields, i)
+		if !m.mappedField(srcFld) {
+			continue
+		}
+		tag := m.parseTag(ctx, srcFld)
+		if tag.Skip {
+			continue
+		}
+		srcField := fieldByIndex(src, srcFld.Index, false)
+		if !srcField.IsValid() {
+			continue
+		}
+		key := m.fieldKey(ctx, srcFld, tag.Name, srcField)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, reflect.ValueOf(key).Convert(kv.Type().Key()))
+	}
+	setKV(dst, kv, keys)
+	return err
+}
+
+// mapMapToKV maps a map to a slice of key/value pairs, in the order of the
+// sorted keys.
+func mapMapToKV(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	kv := reflect.MakeMap(kvMapType(dst.Type()))
+	err := mapMapToMap(m, ctx, src, kv)
+	if _, ok := err.(MappingErrors); err != nil && !ok {
+		return err
+	}
+	keys := kv.MapKeys()
+	sortKeys(keys)
+	setKV(dst, kv, keys)
+	return err
+}
+
+// kvToMap converts a slice of key/value pairs to a map.
+func kvToMap(ctx *Context, src reflect.Value) (reflect.Value, error) {
+	// Elements are counted when the map is mapped to the destination.
+	if err := checkCount(ctx, src.Type(), true, src.Len(), 0); err != nil {
+		return reflect.Value{}, err
+	}
+	kv := reflect.MakeMapWithSize(kvMapType(src.Type()), src.Len())
+	for i := 0; i < src.Len(); i++ {
+		e := src.Index(i)
+		kv.SetMapIndex(e.Field(0), e.Field(1))
+	}
+	return kv, nil
+}
+
+// setKV replaces the content of dst with the entries of kv for the given
+// keys. Keys that are not in kv are skipped.
+func setKV(dst, kv reflect.Value, keys []reflect.Value) {
+	out := reflect.MakeSlice(dst.Type(), 0, len(keys))
+	for _, key := range keys {
+		val := kv.MapIndex(key)
+		if !val.IsValid() {
+			continue
+		}
+		e := reflect.New(dst.Type().Elem()).Elem()
+		e.Field(0).Set(key)
+		e.Field(1).Set(val)
+		out = reflect.Append(out, e)
+	}
+	dst.Set(out)
+}
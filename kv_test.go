@@ -0,0 +1,82 @@
+package anymapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKV(t *testing.T) {
+	type payload struct {
+		Nonce  uint64 `map:"nonce"`
+		Amount string `map:"amount"`
+		From   string `map:"from"`
+		Skip   string `map:"-"`
+	}
+	type pair struct {
+		Name  string `map:"key"`
+		Value int
+	}
+	type point struct {
+		X string
+		Y int
+	}
+
+	t.Run("struct-to-kv", func(t *testing.T) {
+		dst := []KV{{Key: "old"}}
+		require.NoError(t, Map(payload{Nonce: 1, Amount: "10", From: "a"}, &dst))
+		assert.Equal(t, []KV{
+			{Key: "nonce", Value: uint64(1)},
+			{Key: "amount", Value: "10"},
+			{Key: "from", Value: "a"},
+		}, dst)
+	})
+	t.Run("kv-to-struct", func(t *testing.T) {
+		var dst payload
+		src := []KV{{Key: "from", Value: "a"}, {Key: "nonce", Value: "2"}, {Key: "nonce", Value: 3}}
+		require.NoError(t, Map(src, &dst))
+		assert.Equal(t, payload{Nonce: 3, From: "a"}, dst)
+	})
+	t.Run("map-to-kv", func(t *testing.T) {
+		var dst []KV
+		require.NoError(t, Map(map[string]int{"b": 2, "a": 1, "c": 3}, &dst))
+		assert.Equal(t, []KV{{Key: "a", Value: 1}, {Key: "b", Value: 2}, {Key: "c", Value: 3}}, dst)
+	})
+	t.Run("kv-to-map", func(t *testing.T) {
+		var dst map[string]string
+		require.NoError(t, Map([]KV{{Key: "a", Value: 1}}, &dst))
+		assert.Equal(t, map[string]string{"a": "1"}, dst)
+	})
+	t.Run("custom-pair", func(t *testing.T) {
+		var dst []pair
+		require.NoError(t, Map(map[string]string{"x": "1", "y": "2"}, &dst))
+		assert.Equal(t, []pair{{Name: "x", Value: 1}, {Name: "y", Value: 2}}, dst)
+		var out payload
+		require.NoError(t, Map([]pair{{Name: "nonce", Value: 5}}, &out))
+		assert.Equal(t, payload{Nonce: 5}, out)
+	})
+	t.Run("not-a-pair", func(t *testing.T) {
+		// Structs with other field names are mapped as structs.
+		var dst []point
+		require.NoError(t, Map([]map[string]any{{"X": "a", "Y": 1}}, &dst))
+		assert.Equal(t, []point{{X: "a", Y: 1}}, dst)
+		assert.Error(t, Map(map[string]int{"a": 1}, &dst))
+	})
+	t.Run("invalid-value", func(t *testing.T) {
+		var dst []pair
+		assert.Error(t, Map(map[string]string{"x": "a"}, &dst))
+	})
+	t.Run("strict-kinds", func(t *testing.T) {
+		var dst []KV
+		ctx := Default.Context.WithStrictKinds(true)
+		require.NoError(t, MapContext(ctx, payload{Nonce: 1}, &dst))
+		assert.Len(t, dst, 3)
+	})
+	t.Run("max-map-size", func(t *testing.T) {
+		var dst map[string]any
+		ctx := Default.Context.WithMaxMapSize(1)
+		err := MapContext(ctx, []KV{{Key: "a"}, {Key: "b"}}, &dst)
+		assert.ErrorIs(t, err, LimitExceededErr)
+	})
+}
@@ -0,0 +1,45 @@
+package anymapper
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// LimitExceededErr is returned when the source value exceeds one of the
+// limits set in Context.MaxLength, Context.MaxMapSize or
+// Context.MaxElements.
+var LimitExceededErr = errors.New("mapper: limit exceeded")
+
+// checkLimits returns an error if the source slice, array or map exceeds the
+// limits set in the context. The elements are added to the total number of
+// elements mapped in the current mapping call. Values of other kinds are
+// ignored.
+func checkLimits(ctx *Context, src reflect.Value) error {
+	switch src.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return checkCount(ctx, src.Type(), src.Kind() == reflect.Map, src.Len(), src.Len())
+	}
+	return nil
+}
+
+// checkCount returns an error if a source value of the given type with the
+// given number of elements exceeds the limits set in the context, and adds
+// n elements to the total number of mapped elements. Map entries are
+// limited by Context.MaxMapSize, and other elements by Context.MaxLength.
+// Sequences are checked after every element, with n equal to 1.
+func checkCount(ctx *Context, typ reflect.Type, isMap bool, length, n int) error {
+	if isMap && ctx.MaxMapSize > 0 && length > ctx.MaxMapSize {
+		return fmt.Errorf("%w: %v has %d entries, maximum map size is %d", LimitExceededErr, typ, length, ctx.MaxMapSize)
+	}
+	if !isMap && ctx.MaxLength > 0 && length > ctx.MaxLength {
+		return fmt.Errorf("%w: %v has %d elements, maximum length is %d", LimitExceededErr, typ, length, ctx.MaxLength)
+	}
+	if ctx.MaxElements > 0 && ctx.state != nil {
+		ctx.state.elements += n
+		if ctx.state.elements > ctx.MaxElements {
+			return fmt.Errorf("%w: more than %d elements mapped", LimitExceededErr, ctx.MaxElements)
+		}
+	}
+	return nil
+}
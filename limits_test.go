@@ -0,0 +1,68 @@
+package anymapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimits(t *testing.T) {
+	t.Run("max-length", func(t *testing.T) {
+		ctx := Default.Context.WithMaxLength(2)
+		var dst []int
+		require.NoError(t, MapContext(ctx, []string{"1", "2"}, &dst))
+		assert.ErrorIs(t, MapContext(ctx, []string{"1", "2", "3"}, &dst), LimitExceededErr)
+		assert.ErrorIs(t, MapContext(ctx, [3]int{1, 2, 3}, &dst), LimitExceededErr)
+	})
+	t.Run("max-length-same-type", func(t *testing.T) {
+		ctx := Default.Context.WithMaxLength(2)
+		var dst struct{ A []int }
+		require.NoError(t, MapContext(ctx, struct{ A []int }{A: []int{1, 2, 3}}, &dst))
+		assert.Equal(t, []int{1, 2, 3}, dst.A)
+		require.NoError(t, MapContext(ctx, map[string]any{"A": []int{1, 2, 3}}, &dst))
+		assert.Equal(t, []int{1, 2, 3}, dst.A)
+	})
+	t.Run("max-map-size", func(t *testing.T) {
+		ctx := Default.Context.WithMaxMapSize(1)
+		var dst map[string]int
+		require.NoError(t, MapContext(ctx, map[string]any{"a": 1}, &dst))
+		assert.ErrorIs(t, MapContext(ctx, map[string]any{"a": 1, "b": 2}, &dst), LimitExceededErr)
+		var st struct{ A int }
+		assert.ErrorIs(t, MapContext(ctx, map[string]int{"A": 1, "B": 2}, &st), LimitExceededErr)
+	})
+	t.Run("max-elements", func(t *testing.T) {
+		ctx := Default.Context.WithMaxElements(5)
+		src := map[string]any{
+			"a": []any{1, 2},
+			"b": []any{3},
+		}
+		var dst map[string][]int
+		// 2 map entries and 3 slice elements.
+		require.NoError(t, MapContext(ctx, src, &dst))
+		src["c"] = []any{4}
+		assert.ErrorIs(t, MapContext(ctx, src, &dst), LimitExceededErr)
+	})
+	t.Run("nested", func(t *testing.T) {
+		ctx := Default.Context.WithMaxLength(2)
+		var dst struct{ A [][]int }
+		err := MapContext(ctx, map[string]any{"A": []any{[]any{1, 2, 3}}}, &dst)
+		assert.ErrorIs(t, err, LimitExceededErr)
+	})
+	t.Run("best-effort", func(t *testing.T) {
+		ctx := Default.Context.WithBestEffort(true).WithMaxLength(2)
+		var dst struct {
+			A []int
+			B []int
+		}
+		err := MapContext(ctx, map[string]any{"A": []any{1, 2, 3}, "B": []any{1}}, &dst)
+		assert.ErrorIs(t, err, LimitExceededErr)
+		_, ok := err.(MappingErrors)
+		assert.False(t, ok)
+	})
+	t.Run("batch", func(t *testing.T) {
+		ctx := Default.Context.WithMaxLength(2)
+		var dst []int
+		assert.ErrorIs(t, MapBatchContext(ctx, []string{"1", "2", "3"}, &dst), LimitExceededErr)
+	})
+}
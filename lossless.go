@@ -0,0 +1,45 @@
+package anymapper
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+)
+
+// newLossError returns an error for a conversion that cannot represent the
+// source value exactly, used if Context.Lossless is enabled.
+func newLossError(src, dst reflect.Type) error {
+	return NewInvalidMappingError(src, dst, "precision loss")
+}
+
+// exactFloat reports whether f can be stored in a float of the given kind
+// without rounding. NaN and infinities are always exact.
+func exactFloat(f float64, k reflect.Kind) bool {
+	return k != reflect.Float32 || float64(float32(f)) == f || math.IsNaN(f)
+}
+
+// exactIntToFloat reports whether v can be stored in a float of the given
+// kind without rounding.
+func exactIntToFloat(v int64, k reflect.Kind) bool {
+	f := float64(v)
+	return f < math.MaxInt64 && int64(f) == v && exactFloat(f, k)
+}
+
+// exactUintToFloat reports whether v can be stored in a float of the given
+// kind without rounding.
+func exactUintToFloat(v uint64, k reflect.Kind) bool {
+	f := float64(v)
+	return f < math.MaxUint64 && uint64(f) == v && exactFloat(f, k)
+}
+
+// isFraction reports whether f has a fractional part.
+func isFraction(f float64) bool {
+	return f != math.Trunc(f)
+}
+
+// exactBigFloat reports whether a big.Float conversion with the accuracy a
+// produced the exact value f, which is then stored in a float of the given
+// kind.
+func exactBigFloat(f float64, a big.Accuracy, k reflect.Kind) bool {
+	return a == big.Exact && exactFloat(f, k)
+}
@@ -0,0 +1,56 @@
+package anymapper
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLossless(t *testing.T) {
+	ctx := Default.Context.WithLossless(true)
+	tests := []struct {
+		name  string
+		src   any
+		dst   any
+		lossy bool
+	}{
+		{name: "float-to-int", src: 1.0, dst: new(int)},
+		{name: "fraction-to-int", src: 1.5, dst: new(int), lossy: true},
+		{name: "fraction-to-uint", src: 1.5, dst: new(uint), lossy: true},
+		{name: "float64-to-float32", src: 0.5, dst: new(float32)},
+		{name: "rounded-float32", src: 0.1, dst: new(float32), lossy: true},
+		{name: "nan-to-float32", src: math.NaN(), dst: new(float32)},
+		{name: "int-to-float64", src: int64(1 << 53), dst: new(float64)},
+		{name: "large-int-to-float64", src: int64(1<<53 + 1), dst: new(float64), lossy: true},
+		{name: "max-int-to-float64", src: int64(math.MaxInt64), dst: new(float64), lossy: true},
+		{name: "large-int-to-float32", src: int32(1<<24 + 1), dst: new(float32), lossy: true},
+		{name: "large-uint-to-float64", src: uint64(math.MaxUint64), dst: new(float64), lossy: true},
+		{name: "float-to-float16", src: 0.5, dst: new(Float16)},
+		{name: "rounded-float16", src: 0.1, dst: new(Float16), lossy: true},
+		{name: "big-int-to-float64", src: big.NewInt(1 << 53), dst: new(float64)},
+		{name: "large-big-int-to-float64", src: big.NewInt(1<<53 + 1), dst: new(float64), lossy: true},
+		{name: "big-float-to-int", src: big.NewFloat(2), dst: new(int)},
+		{name: "big-float-fraction-to-int", src: big.NewFloat(2.5), dst: new(int), lossy: true},
+		{name: "big-float-fraction-to-uint", src: big.NewFloat(2.5), dst: new(uint), lossy: true},
+		{name: "big-float-fraction-to-big-int", src: big.NewFloat(2.5), dst: new(big.Int), lossy: true},
+		{name: "big-float-to-float32", src: big.NewFloat(0.1), dst: new(float32), lossy: true},
+		{name: "fraction-to-big-int", src: 2.5, dst: new(big.Int), lossy: true},
+		{name: "big-rat-to-big-float", src: big.NewRat(1, 4), dst: new(big.Float)},
+		{name: "periodic-big-rat-to-big-float", src: big.NewRat(1, 3), dst: new(big.Float), lossy: true},
+		{name: "periodic-big-rat-to-float", src: big.NewRat(1, 3), dst: new(float64), lossy: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Without the Lossless option, all conversions succeed.
+			assert.NoError(t, Map(tt.src, tt.dst))
+			err := MapContext(ctx, tt.src, tt.dst)
+			if tt.lossy {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
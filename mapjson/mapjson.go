@@ -0,0 +1,86 @@
+// Package mapjson decodes JSON documents into Go values using the mapper.
+//
+// Documents are decoded into generic values first, which are then mapped to
+// the destination using the mapping rules of the mapper, so struct fields
+// are matched using the mapper tags, and registered mapper providers are
+// used, like for any other mapping. Numbers are decoded as json.Number
+// values and parsed directly into the destination type, so large integers
+// do not lose precision by being converted to float64 first.
+package mapjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/defiweb/go-anymapper"
+)
+
+// ErrTrailingData is returned by Unmarshal if the data contains more than
+// one JSON value.
+var ErrTrailingData = errors.New("mapjson: invalid data after top-level value")
+
+// Unmarshal decodes the JSON document and maps it to the destination value
+// using the Default mapper. A JSON null leaves the destination unchanged.
+func Unmarshal(data []byte, dst any, opts ...anymapper.Option) error {
+	return UnmarshalWith(anymapper.Default, data, dst, opts...)
+}
+
+// UnmarshalWith decodes the JSON document and maps it to the destination
+// value using the given mapper.
+func UnmarshalWith(m *anymapper.Mapper, data []byte, dst any, opts ...anymapper.Option) error {
+	d := NewDecoderWith(m, bytes.NewReader(data), opts...)
+	if err := d.Decode(dst); err != nil {
+		return err
+	}
+	if _, err := d.dec.Token(); err != io.EOF {
+		return ErrTrailingData
+	}
+	return nil
+}
+
+// Decoder reads successive JSON values from an input stream and maps them
+// to destination values.
+type Decoder struct {
+	m   *anymapper.Mapper
+	ctx *anymapper.Context
+	dec *json.Decoder
+}
+
+// NewDecoder returns a new decoder that reads from r and maps values using
+// the Default mapper.
+func NewDecoder(r io.Reader, opts ...anymapper.Option) *Decoder {
+	return NewDecoderWith(anymapper.Default, r, opts...)
+}
+
+// NewDecoderWith returns a new decoder that reads from r and maps values
+// using the given mapper.
+func NewDecoderWith(m *anymapper.Mapper, r io.Reader, opts ...anymapper.Option) *Decoder {
+	ctx := *m.Context
+	for _, opt := range opts {
+		opt(&ctx)
+	}
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	return &Decoder{m: m, ctx: &ctx, dec: dec}
+}
+
+// Decode reads the next JSON value from the input and maps it to the
+// destination value. A JSON null leaves the destination unchanged. At the
+// end of the input, it returns io.EOF.
+func (d *Decoder) Decode(dst any) error {
+	var v any
+	if err := d.dec.Decode(&v); err != nil {
+		return err
+	}
+	if v == nil {
+		return nil
+	}
+	return d.m.MapContext(d.ctx, v, dst)
+}
+
+// More reports whether there is another value in the input.
+func (d *Decoder) More() bool {
+	return d.dec.More()
+}
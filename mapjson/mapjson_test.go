@@ -0,0 +1,79 @@
+package mapjson
+
+import (
+	"encoding/json"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defiweb/go-anymapper"
+)
+
+type event struct {
+	ID      int64     `map:"id"`
+	Name    string    `map:"name"`
+	Amount  *big.Int  `map:"amount"`
+	Price   float64   `map:"price"`
+	Created time.Time `map:"created"`
+	Tags    []string  `map:"tags"`
+	Meta    map[string]any
+}
+
+func TestUnmarshal(t *testing.T) {
+	data := `{
+		"id": 9007199254740993,
+		"name": "foo",
+		"amount": 123456789012345678901234567890,
+		"price": 1.5,
+		"created": "2024-01-01T00:00:00Z",
+		"tags": ["a", "b"],
+		"Meta": {"n": 1}
+	}`
+	var e event
+	require.NoError(t, Unmarshal([]byte(data), &e))
+	amount, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	assert.Equal(t, int64(9007199254740993), e.ID)
+	assert.Equal(t, "foo", e.Name)
+	assert.Equal(t, amount, e.Amount)
+	assert.Equal(t, 1.5, e.Price)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), e.Created.UTC())
+	assert.Equal(t, []string{"a", "b"}, e.Tags)
+	assert.Equal(t, map[string]any{"n": json.Number("1")}, e.Meta)
+}
+
+func TestUnmarshal_Options(t *testing.T) {
+	var e event
+	err := Unmarshal([]byte(`{"id": "1"}`), &e, anymapper.WithStrictTypes(true))
+	assert.Error(t, err)
+}
+
+func TestUnmarshal_Null(t *testing.T) {
+	e := event{Name: "foo"}
+	require.NoError(t, Unmarshal([]byte(`null`), &e))
+	assert.Equal(t, "foo", e.Name)
+}
+
+func TestUnmarshal_Invalid(t *testing.T) {
+	var e event
+	assert.Error(t, Unmarshal([]byte(`{"id": `), &e))
+	assert.ErrorIs(t, Unmarshal([]byte(`{"id": 1} {}`), &e), ErrTrailingData)
+	assert.Error(t, Unmarshal([]byte(`{"id": 1.5}`), &e))
+}
+
+func TestDecoder(t *testing.T) {
+	d := NewDecoder(strings.NewReader(`{"id": 1} {"id": 2}`))
+	var ids []int64
+	for d.More() {
+		var e event
+		require.NoError(t, d.Decode(&e))
+		ids = append(ids, e.ID)
+	}
+	assert.Equal(t, []int64{1, 2}, ids)
+	var e event
+	assert.Equal(t, io.EOF, d.Decode(&e))
+}
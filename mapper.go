@@ -12,12 +12,128 @@ import (
 // MapFunc is a function that maps a src value to a dst value. It returns an
 // error if the mapping is not possible. The src and dst values are never
 // pointers.
+//
+// If MapFunc needs to map values recursively, it should use the
+// Mapper.MapReflContext method to propagate the context.
 type MapFunc func(m *Mapper, ctx *Context, src, dst reflect.Value) error
 
 // MapFuncProvider is a function that returns a MapFunc for given src and dst
 // types. If mapping is not supported, it returns nil.
 type MapFuncProvider func(m *Mapper, src, dst reflect.Type) MapFunc
 
+// MapFuncProviderErr is a variant of MapFuncProvider that can explain why
+// the mapping is not possible. If mapping is not supported, it returns nil
+// and nil. If the types are supported, but the mapping cannot be performed,
+// e.g. because of a missing registration or a bad configuration, it
+// returns an error. It must be converted to MapFuncProvider using
+// ProviderWithError before it can be used.
+type MapFuncProviderErr func(m *Mapper, src, dst reflect.Type) (MapFunc, error)
+
+// ProviderWithError converts MapFuncProviderErr to MapFuncProvider. If the
+// provider returns an error, the returned MapFunc always fails with an
+// InvalidMappingErr that wraps the error. Other providers are not
+// consulted in that case.
+func ProviderWithError(p MapFuncProviderErr) MapFuncProvider {
+	return func(m *Mapper, src, dst reflect.Type) MapFunc {
+		fn, err := p(m, src, dst)
+		if err != nil {
+			return func(_ *Mapper, _ *Context, _, _ reflect.Value) error {
+				return WrapInvalidMappingError(src, dst, err)
+			}
+		}
+		return fn
+	}
+}
+
+// MapFuncProviderCtx is a variant of MapFuncProvider that also receives the
+// context of the mapping call, so it can return different mapping functions
+// depending on per-call options, like strictness, encodings or the Custom
+// field. It must be converted to MapFuncProvider using ProviderWithContext
+// before it can be used.
+type MapFuncProviderCtx func(m *Mapper, ctx *Context, src, dst reflect.Type) MapFunc
+
+// ProviderWithContext converts MapFuncProviderCtx to MapFuncProvider.
+//
+// Whether the types are supported is decided when they are resolved, using
+// the default context of the mapper. The mapping function for the context
+// of a call is resolved when it is first needed and cached under the value
+// returned by the key function, which should include all context fields the
+// provider depends on. The cached functions are stored together with the
+// resolved type mapper, so they are dropped by Mapper.InvalidateCache.
+//
+// The key must be comparable, e.g. a string or a struct of comparable
+// fields. If it is not, or if key is nil, the provider is called for every
+// mapped value. If the provider returns nil for the context of a call, the
+// mapping fails.
+func ProviderWithContext(p MapFuncProviderCtx, key func(ctx *Context) any) MapFuncProvider {
+	return func(m *Mapper, src, dst reflect.Type) MapFunc {
+		fn := p(m, m.Context, src, dst)
+		if fn == nil {
+			return nil
+		}
+		var cache sync.Map // key(ctx) -> MapFunc
+		if key != nil {
+			if k := key(m.Context); isComparable(k) {
+				cache.Store(k, fn)
+			}
+		}
+		return func(m *Mapper, ctx *Context, srcVal, dstVal reflect.Value) error {
+			var fn MapFunc
+			if key == nil {
+				fn = p(m, ctx, src, dst)
+			} else if k := key(ctx); !isComparable(k) {
+				fn = p(m, ctx, src, dst)
+			} else if v, ok := cache.Load(k); ok {
+				fn = v.(MapFunc)
+			} else {
+				fn = p(m, ctx, src, dst)
+				cache.Store(k, fn)
+			}
+			if fn == nil {
+				return NewInvalidMappingError(src, dst, "not supported in this context")
+			}
+			return fn(m, ctx, srcVal, dstVal)
+		}
+	}
+}
+
+// isComparable reports whether v can be used as a map key without a panic,
+// including values of interfaces nested in arrays and structs.
+func isComparable(v any) bool {
+	return isComparableValue(reflect.ValueOf(v))
+}
+
+func isComparableValue(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	if !v.Type().Comparable() {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		return isComparableValue(v.Elem())
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !isComparableValue(v.Index(i)) {
+				return false
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !isComparableValue(v.Field(i)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Middleware is a function that wraps a MapFunc. It can be used to add
+// behavior that is common to all mapping functions, such as logging or
+// timing, without modifying the mapping functions themselves.
+type Middleware func(next MapFunc) MapFunc
+
 // Default is the default Mapper used by the Map and MapRefl functions.
 // It also provides additional mapping rules for time.Time, big.Int, big.Float
 // and big.Rat. It can be modified to change the default behavior, but if the
@@ -38,6 +154,29 @@ type Context struct {
 	// will be assigned to it regardless of the strict type check setting.
 	StrictTypes bool
 
+	// StrictKinds enables strict kind checking. It is less strict than
+	// StrictTypes: the source and destination types may be different named
+	// types, like `type MyInt int` and `int`, but they must have the same
+	// kind, so values are never parsed from strings or converted between
+	// numeric kinds. As with StrictTypes, mapping between structs and maps
+	// is always allowed. Custom mapping functions returned by MapFuncHook,
+	// like the ones for the MapTo and MapFrom interfaces, are not checked.
+	StrictKinds bool
+
+	// StrictExceptions lists the type pairs that can be mapped even if
+	// StrictTypes or StrictKinds is enabled, e.g. string to time.Time. The
+	// exception applies only to the listed pair, strict checks are still
+	// performed for values mapped inside it, like slice elements.
+	StrictExceptions []TypePair
+
+	// Lossless enables detection of lossy numeric conversions. If enabled,
+	// conversions that cannot represent the source value exactly fail
+	// instead of rounding or truncating it, e.g. a float with a fractional
+	// part mapped to an integer, an integer above 2^53 mapped to float64,
+	// a float64 that cannot be represented as float32 or a big.Float
+	// truncated to an integer.
+	Lossless bool
+
 	// Tag is the name of the struct tag that is used by the mapper to
 	// determine the name of the field to map to.
 	Tag string
@@ -49,12 +188,316 @@ type Context struct {
 	DisableCache bool
 
 	// FieldMapper is a function that maps a struct field name to another name,
-	// it is used only when the tag is not present.
+	// it is used only when the tag is not present. Field names are resolved
+	// for every mapping call and are not cached, so a single call can use a
+	// different function, set with WithFieldMapper, than the mapper's
+	// default context without copying the mapper.
 	FieldMapper func(string) string
 
+	// BestEffort enables the best-effort mode. In this mode, if mapping of a
+	// struct field, slice element or map value fails, the destination value is
+	// set to its zero value and the mapping continues. All errors are
+	// returned as MappingErrors after the mapping is finished.
+	BestEffort bool
+
+	// SkipSecrets enables skipping of fields marked with the "secret" tag
+	// option when mapping a struct to a map.
+	SkipSecrets bool
+
+	// OmitEmpty enables omitting of empty struct fields when mapping a struct
+	// to a map, as if all fields had the "omitempty" tag option.
+	OmitEmpty bool
+
+	// FlattenSeparator, if not empty, makes the Encode method flatten nested
+	// maps into a single map. Keys of nested maps are joined with their
+	// parent keys using the separator.
+	FlattenSeparator string
+
+	// NormalizeAny enables normalization of values mapped to destinations
+	// of the any type. Instead of being stored as is, values are converted
+	// to plain built-in types: structs and maps to map[string]any, slices
+	// and arrays to []any, named types to their underlying types, integers
+	// to int64, or *big.Int if they do not fit in it, and floats to
+	// float64. Complex numbers and types with registered mapper providers
+	// are mapped to strings, except big.Int. Nil slices and maps become nil.
+	// The result can be encoded by encoding/json regardless of the source
+	// types.
+	NormalizeAny bool
+
+	// MinimalBytes enables the minimal big-endian encoding of integers
+	// mapped to and from byte slices. Integers are encoded without leading
+	// zero bytes, like big.Int.Bytes does, and zero is encoded as an empty
+	// slice. Decoding accepts input of any length as long as the value fits
+	// in the destination type. The ByteOrder field is ignored for integers
+	// in this mode. Negative integers cannot be encoded.
+	MinimalBytes bool
+
+	// NumberCodec, if set, is used to encode and decode integers mapped to
+	// and from byte slices and arrays instead of the ByteOrder and
+	// MinimalBytes fields.
+	NumberCodec NumberCodec
+
+	// Bits enables bit-level views of integers and byte slices. Integers
+	// and byte slices are mapped to and from strings of binary digits, like
+	// "00000110", instead of decimal numbers and raw strings, and integers
+	// and byte slices are mapped to and from []bool bit slices.
+	Bits bool
+
+	// BitOrder is the order of bits used by the bit-level conversions.
+	BitOrder BitOrder
+
+	// TextBytes enables treating byte slices as UTF-8 text. Byte slices are
+	// mapped to and from numbers and big integers using their decimal
+	// representations, like strings, and to and from time.Time using the
+	// RFC 3339 format, instead of their binary representations. It is
+	// useful for text columns returned by database drivers as []byte. Byte
+	// arrays are not affected.
+	TextBytes bool
+
+	// TrimStrings enables trimming of surrounding whitespace and a single
+	// pair of matching double or single quotes from strings before they are
+	// parsed into numbers, bools, times and big numbers. It is useful for
+	// data from CSV files or fixed-width exports that carry padding.
+	TrimStrings bool
+
+	// Suffixes enables unit suffixes in numbers mapped to and from strings.
+	// If set, strings like "1.5k", "2M" or "512Mi" are parsed into
+	// integers, floats and big integers. Both SI and binary suffixes are
+	// accepted, regardless of the chosen set. Numbers are formatted using
+	// the largest unit of the chosen set that divides them exactly, e.g.
+	// 2000000 as "2M", while other numbers are formatted as usual. Both
+	// "k" and "K" are accepted for thousands. Suffixes are not applied to
+	// the keys of maps.
+	Suffixes Suffixes
+
+	// InputTransforms are applied, in order, to source strings before they
+	// are mapped, e.g. to normalize user input before it is stored or
+	// parsed. Strings in slices, arrays and map values are transformed one
+	// by one, but map keys are not transformed.
+	InputTransforms []StringTransform
+
+	// OutputTransforms are applied, in order, to strings produced by mapping
+	// values other than strings to strings.
+	OutputTransforms []StringTransform
+
+	// SortMapKeys enables iteration over source map keys in sorted order,
+	// instead of the random order of Go maps, when maps are mapped to maps,
+	// structs or slices. It makes the order of errors, hook calls and values
+	// appended to destinations reproducible. Numbers are sorted by their
+	// values and strings lexically.
+	SortMapKeys bool
+
+	// SyncMaps enables deletion of destination map keys that are not present
+	// in the source when maps or structs are mapped to maps, so the
+	// destination mirrors the source exactly. Keys of struct fields that are
+	// skipped, e.g. because of the omitempty option, are deleted as well.
+	// Keys excluded using Fields or ExcludeFields are kept.
+	SyncMaps bool
+
+	// NoOverwrite enables the merge mode. In this mode, struct fields and
+	// map values are mapped only if the destination value is zero, so the
+	// values already present in the destination are preserved. Nested
+	// structs and maps are merged recursively, unless their types have
+	// registered mapper providers, like time.Time.
+	NoOverwrite bool
+
+	// SkipZeroSource enables skipping of struct fields and map values that
+	// are zero in the source, so they never overwrite the values in the
+	// destination. It is useful for partial updates, where only the fields
+	// that are set in the source should be applied. Values of interfaces
+	// are checked, so zero values in map[string]any are skipped as well.
+	SkipZeroSource bool
+
+	// NilElements is the policy for nil elements of source slices, arrays
+	// and maps, e.g. nil values in []any or map[string]any. By default,
+	// such elements cause the mapping to fail, except for map values mapped
+	// to struct fields, which are skipped.
+	NilElements NilPolicy
+
+	// Fields, if not empty, limits the mapping to the listed destination
+	// fields. Nested fields are specified using paths, e.g. "Address.City".
+	// Path elements are the keys used by the mapper, that is, tag names or
+	// field names for untagged fields. Listing a field includes all of its
+	// nested fields. Elements of slices and arrays, and values of maps with
+	// non-string keys, share the path of their parent.
+	Fields []string
+
+	// ExcludeFields lists the destination fields that are skipped during
+	// the mapping. Paths are specified in the same way as for the Fields
+	// field. Excluding a field excludes all of its nested fields.
+	ExcludeFields []string
+
+	// TagPaths enables dot-separated paths in the names of struct fields,
+	// e.g. `map:"meta.created_at"`. Such fields are read from and written
+	// to nested maps and structs, instead of the keys containing dots. Keys
+	// containing dots are still used if the source has them.
+	TagPaths bool
+
+	// CaseInsensitiveKeys enables case-insensitive matching of map keys to
+	// struct fields when maps are mapped to structs, so the "foo", "Foo"
+	// and "FOO" keys are all mapped to the same field. A key that matches
+	// exactly is preferred. If multiple keys differ only in case, the
+	// lowest one in the sort order is used.
+	CaseInsensitiveKeys bool
+
+	// Renames maps struct field names to the names used as map keys,
+	// overriding both the tag and the FieldMapper function for these fields.
+	Renames map[string]string
+
+	// FieldConverters maps struct field names to the names of converters,
+	// registered in Mapper.Converters, applied to these fields as if they
+	// had the conv tag option. It overrides the conv option of the tags.
+	FieldConverters map[string]string
+
+	// StringEncoding is the name of the encoding, registered in
+	// Mapper.Encodings, used to map byte slices and arrays to and from
+	// strings. If empty, bytes are mapped to strings as is. It can be
+	// overridden for struct fields with the encoding tag option.
+	StringEncoding string
+
+	// FlagValues enables mapping of strings to and from types implementing
+	// the flag.Value interface using their Set and String methods, instead
+	// of the built-in rules for their kinds. Types with registered mapper
+	// providers are not affected.
+	FlagValues bool
+
+	// TextMarshalers enables mapping of strings to types implementing the
+	// encoding.TextUnmarshaler interface, and of types implementing the
+	// encoding.TextMarshaler interface to strings, instead of the built-in
+	// rules for their kinds. Types with registered mapper providers are not
+	// affected, and FlagValues takes precedence for types implementing
+	// both interfaces.
+	TextMarshalers bool
+
+	// JSONMarshalers enables mapping of values through their JSON
+	// representations if the source type implements the json.Marshaler
+	// interface or the destination type implements the json.Unmarshaler
+	// interface, instead of the built-in rules for their kinds. The JSON
+	// representation of the source is decoded into generic values, which
+	// are then mapped to the destination. Types with registered mapper
+	// providers are not affected, and FlagValues and TextMarshalers take
+	// precedence.
+	JSONMarshalers bool
+
+	// Stringers enables mapping of types implementing the fmt.Stringer
+	// interface to strings using their String method, if there is no other
+	// way to map them.
+	Stringers bool
+
+	// TimeLayout is the layout, as defined by the time package, used to
+	// format time.Time values mapped to strings. It is also tried first when
+	// strings are parsed into time.Time values. If empty, time.RFC3339 is
+	// used. It can be overridden for struct fields with the layout tag
+	// option.
+	TimeLayout string
+
+	// ValueSnapshotLen, if greater than zero, enables value snapshots in
+	// errors. The InvalidMappingErr.Value field is set to a printable
+	// representation of the source value, truncated to the given number
+	// of characters. Values of secret fields are never included.
+	ValueSnapshotLen int
+
+	// BigFloatPrec is the precision, in bits, of big.Float values converted
+	// from big.Rat values. If zero, the precision is chosen as in the
+	// big.Float.SetRat method, that is, it is at least 64 bits.
+	BigFloatPrec uint
+
+	// PreserveIdentity enables preservation of shared nodes. If the same
+	// source pointer is found multiple times during a single mapping, it is
+	// mapped only once and all destination pointers of the same type point
+	// to the same mapped value. This also allows to map cyclic graphs.
+	PreserveIdentity bool
+
+	// IdenticalLayouts enables a fast path for mapping between different
+	// struct types with identical layouts, where the fields at the same
+	// positions have the same types. Such structs are copied as a whole,
+	// instead of being mapped field by field. Fields are matched by their
+	// positions, and their names, tags, hooks and other per-field options
+	// are ignored.
+	IdenticalLayouts bool
+
+	// MaxLength, if greater than zero, is the maximum length of slices and
+	// arrays mapped element by element. Longer values cause the mapping to
+	// fail with LimitExceededErr. Values assigned directly, because the
+	// source and destination types are the same, are not limited.
+	MaxLength int
+
+	// MaxMapSize, if greater than zero, is the maximum number of entries of
+	// source maps mapped entry by entry, including maps mapped to structs.
+	// Larger maps cause the mapping to fail with LimitExceededErr.
+	MaxMapSize int
+
+	// MaxElements, if greater than zero, is the maximum total number of
+	// slice, array and map elements mapped in a single mapping call. It
+	// protects against deeply nested untrusted input, where every single
+	// value is within the other limits.
+	MaxElements int
+
+	// MaxIndex, if greater than zero, is the largest key of integer-keyed
+	// map entries mapped to slices. Larger or negative keys cause the mapping
+	// to fail. If zero, DefaultMaxIndex is used.
+	MaxIndex int
+
+	// Mappers is a map of mapper providers used in addition to the ones
+	// registered in Mapper.Mappers, e.g. request-scoped providers that
+	// resolve values using a database. Providers for the same type take
+	// precedence over the ones of the mapper. Mapping functions resolved
+	// while these providers are set are cached only for the duration of a
+	// single mapping call. See WithMapper.
+	Mappers map[reflect.Type]MapFuncProvider
+
+	// Hooks, if set, are hooks used in addition to the Mapper.Hooks for a
+	// single mapping call, e.g. to filter fields per tenant. The MapFuncHook,
+	// SourceValueHook and DestinationValueHook are consulted before the hooks
+	// of the mapper, which are used if they return nil or an invalid value.
+	// The MissingFieldHook and UnmappedKeyHook are called after the hooks of
+	// the mapper, and the KeyHook receives the key returned by the KeyHook
+	// of the mapper. As with Mappers, mapping functions resolved while the
+	// MapFuncHook is set are cached only for the duration of a single call.
+	// See WithHooks.
+	Hooks *Hooks
+
 	// Custom is a custom value that can be used to pass additional information
 	// to the mapping functions.
 	Custom any
+
+	// path is the path of the currently mapped destination value, in the
+	// format used by InvalidMappingErr.Path. It is tracked only if it is
+	// needed by other fields.
+	path string
+
+	// fieldPath is the path without the indexes of slice elements and the
+	// non-string keys of maps. It is matched against the Fields and
+	// ExcludeFields paths and the paths of path mapping functions.
+	fieldPath string
+
+	// pathMappers indicates that paths are tracked, because the mapper has
+	// mapping functions registered for paths.
+	pathMappers bool
+
+	// state is the state of a single mapping call. It is set only if it is
+	// needed by other fields.
+	state *mapState
+
+	// keepAny indicates that values of types with mapper providers are
+	// assigned to the any type as is. It is set by Encode.
+	keepAny bool
+
+	// suspended holds the strict mode settings disabled for a type pair
+	// listed in StrictExceptions. They are restored for nested mappings.
+	suspended *strictMode
+}
+
+// TypePair is a pair of source and destination types.
+type TypePair struct {
+	Src reflect.Type
+	Dst reflect.Type
+}
+
+// strictMode holds the strict mode settings of a context.
+type strictMode struct {
+	types bool
+	kinds bool
 }
 
 // WithStrictTypes returns a copy of the context with the StrictTypes field
@@ -65,6 +508,30 @@ func (c *Context) WithStrictTypes(strictTypes bool) *Context {
 	return &cpy
 }
 
+// WithStrictKinds returns a copy of the context with the StrictKinds field
+// set to the given value.
+func (c *Context) WithStrictKinds(strictKinds bool) *Context {
+	cpy := *c
+	cpy.StrictKinds = strictKinds
+	return &cpy
+}
+
+// WithStrictExceptions returns a copy of the context with the
+// StrictExceptions field set to the given value.
+func (c *Context) WithStrictExceptions(pairs ...TypePair) *Context {
+	cpy := *c
+	cpy.StrictExceptions = pairs
+	return &cpy
+}
+
+// WithLossless returns a copy of the context with the Lossless field set to
+// the given value.
+func (c *Context) WithLossless(lossless bool) *Context {
+	cpy := *c
+	cpy.Lossless = lossless
+	return &cpy
+}
+
 // WithTag returns a copy of the context with the Tag field set to the given
 // value.
 func (c *Context) WithTag(tag string) *Context {
@@ -97,6 +564,344 @@ func (c *Context) WithFieldMapper(fieldMapper func(string) string) *Context {
 	return &cpy
 }
 
+// WithBestEffort returns a copy of the context with the BestEffort field set
+// to the given value.
+func (c *Context) WithBestEffort(bestEffort bool) *Context {
+	cpy := *c
+	cpy.BestEffort = bestEffort
+	return &cpy
+}
+
+// WithSkipSecrets returns a copy of the context with the SkipSecrets field
+// set to the given value.
+func (c *Context) WithSkipSecrets(skipSecrets bool) *Context {
+	cpy := *c
+	cpy.SkipSecrets = skipSecrets
+	return &cpy
+}
+
+// WithOmitEmpty returns a copy of the context with the OmitEmpty field set
+// to the given value.
+func (c *Context) WithOmitEmpty(omitEmpty bool) *Context {
+	cpy := *c
+	cpy.OmitEmpty = omitEmpty
+	return &cpy
+}
+
+// WithFlattenSeparator returns a copy of the context with the
+// FlattenSeparator field set to the given value.
+func (c *Context) WithFlattenSeparator(sep string) *Context {
+	cpy := *c
+	cpy.FlattenSeparator = sep
+	return &cpy
+}
+
+// WithNormalizeAny returns a copy of the context with the NormalizeAny
+// field set to the given value.
+func (c *Context) WithNormalizeAny(normalizeAny bool) *Context {
+	cpy := *c
+	cpy.NormalizeAny = normalizeAny
+	return &cpy
+}
+
+// WithMinimalBytes returns a copy of the context with the MinimalBytes
+// field set to the given value.
+func (c *Context) WithMinimalBytes(minimalBytes bool) *Context {
+	cpy := *c
+	cpy.MinimalBytes = minimalBytes
+	return &cpy
+}
+
+// WithNumberCodec returns a copy of the context with the NumberCodec field
+// set to the given value.
+func (c *Context) WithNumberCodec(codec NumberCodec) *Context {
+	cpy := *c
+	cpy.NumberCodec = codec
+	return &cpy
+}
+
+// WithTextBytes returns a copy of the context with the TextBytes field set
+// to the given value.
+func (c *Context) WithTextBytes(textBytes bool) *Context {
+	cpy := *c
+	cpy.TextBytes = textBytes
+	return &cpy
+}
+
+// WithTrimStrings returns a copy of the context with the TrimStrings field
+// set to the given value.
+func (c *Context) WithTrimStrings(trimStrings bool) *Context {
+	cpy := *c
+	cpy.TrimStrings = trimStrings
+	return &cpy
+}
+
+// WithSuffixes returns a copy of the context with the Suffixes field set to
+// the given value.
+func (c *Context) WithSuffixes(suffixes Suffixes) *Context {
+	cpy := *c
+	cpy.Suffixes = suffixes
+	return &cpy
+}
+
+// WithInputTransforms returns a copy of the context with the
+// InputTransforms field set to the given value.
+func (c *Context) WithInputTransforms(transforms ...StringTransform) *Context {
+	cpy := *c
+	cpy.InputTransforms = transforms
+	return &cpy
+}
+
+// WithOutputTransforms returns a copy of the context with the
+// OutputTransforms field set to the given value.
+func (c *Context) WithOutputTransforms(transforms ...StringTransform) *Context {
+	cpy := *c
+	cpy.OutputTransforms = transforms
+	return &cpy
+}
+
+// WithSortMapKeys returns a copy of the context with the SortMapKeys field
+// set to the given value.
+func (c *Context) WithSortMapKeys(sortMapKeys bool) *Context {
+	cpy := *c
+	cpy.SortMapKeys = sortMapKeys
+	return &cpy
+}
+
+// WithSyncMaps returns a copy of the context with the SyncMaps field set
+// to the given value.
+func (c *Context) WithSyncMaps(syncMaps bool) *Context {
+	cpy := *c
+	cpy.SyncMaps = syncMaps
+	return &cpy
+}
+
+// WithNoOverwrite returns a copy of the context with the NoOverwrite field
+// set to the given value.
+func (c *Context) WithNoOverwrite(noOverwrite bool) *Context {
+	cpy := *c
+	cpy.NoOverwrite = noOverwrite
+	return &cpy
+}
+
+// WithSkipZeroSource returns a copy of the context with the SkipZeroSource
+// field set to the given value.
+func (c *Context) WithSkipZeroSource(skipZeroSource bool) *Context {
+	cpy := *c
+	cpy.SkipZeroSource = skipZeroSource
+	return &cpy
+}
+
+// WithNilElements returns a copy of the context with the NilElements field
+// set to the given value.
+func (c *Context) WithNilElements(policy NilPolicy) *Context {
+	cpy := *c
+	cpy.NilElements = policy
+	return &cpy
+}
+
+// WithFields returns a copy of the context with the Fields field set to the
+// given value.
+func (c *Context) WithFields(fields ...string) *Context {
+	cpy := *c
+	cpy.Fields = fields
+	return &cpy
+}
+
+// WithoutFields returns a copy of the context with the ExcludeFields field
+// set to the given value.
+func (c *Context) WithoutFields(fields ...string) *Context {
+	cpy := *c
+	cpy.ExcludeFields = fields
+	return &cpy
+}
+
+// WithTagPaths returns a copy of the context with the TagPaths field set to
+// the given value.
+func (c *Context) WithTagPaths(tagPaths bool) *Context {
+	cpy := *c
+	cpy.TagPaths = tagPaths
+	return &cpy
+}
+
+// WithCaseInsensitiveKeys returns a copy of the context with the
+// CaseInsensitiveKeys field set to the given value.
+func (c *Context) WithCaseInsensitiveKeys(caseInsensitiveKeys bool) *Context {
+	cpy := *c
+	cpy.CaseInsensitiveKeys = caseInsensitiveKeys
+	return &cpy
+}
+
+// WithRenames returns a copy of the context with the Renames field set to
+// the given value.
+func (c *Context) WithRenames(renames map[string]string) *Context {
+	cpy := *c
+	cpy.Renames = renames
+	return &cpy
+}
+
+// WithFieldConverters returns a copy of the context with the
+// FieldConverters field set to the given value.
+func (c *Context) WithFieldConverters(converters map[string]string) *Context {
+	cpy := *c
+	cpy.FieldConverters = converters
+	return &cpy
+}
+
+// WithStringEncoding returns a copy of the context with the StringEncoding
+// field set to the given value.
+func (c *Context) WithStringEncoding(name string) *Context {
+	cpy := *c
+	cpy.StringEncoding = name
+	return &cpy
+}
+
+// WithFlagValues returns a copy of the context with the FlagValues field
+// set to the given value.
+func (c *Context) WithFlagValues(flagValues bool) *Context {
+	cpy := *c
+	cpy.FlagValues = flagValues
+	return &cpy
+}
+
+// WithTextMarshalers returns a copy of the context with the TextMarshalers
+// field set to the given value.
+func (c *Context) WithTextMarshalers(textMarshalers bool) *Context {
+	cpy := *c
+	cpy.TextMarshalers = textMarshalers
+	return &cpy
+}
+
+// WithJSONMarshalers returns a copy of the context with the JSONMarshalers
+// field set to the given value.
+func (c *Context) WithJSONMarshalers(jsonMarshalers bool) *Context {
+	cpy := *c
+	cpy.JSONMarshalers = jsonMarshalers
+	return &cpy
+}
+
+// WithStringers returns a copy of the context with the Stringers field set
+// to the given value.
+func (c *Context) WithStringers(stringers bool) *Context {
+	cpy := *c
+	cpy.Stringers = stringers
+	return &cpy
+}
+
+// WithTimeLayout returns a copy of the context with the TimeLayout field
+// set to the given value.
+func (c *Context) WithTimeLayout(layout string) *Context {
+	cpy := *c
+	cpy.TimeLayout = layout
+	return &cpy
+}
+
+// WithValueSnapshotLen returns a copy of the context with the
+// ValueSnapshotLen field set to the given value.
+func (c *Context) WithValueSnapshotLen(n int) *Context {
+	cpy := *c
+	cpy.ValueSnapshotLen = n
+	return &cpy
+}
+
+// WithBigFloatPrec returns a copy of the context with the BigFloatPrec
+// field set to the given value.
+func (c *Context) WithBigFloatPrec(prec uint) *Context {
+	cpy := *c
+	cpy.BigFloatPrec = prec
+	return &cpy
+}
+
+// WithPreserveIdentity returns a copy of the context with the
+// PreserveIdentity field set to the given value.
+func (c *Context) WithPreserveIdentity(preserveIdentity bool) *Context {
+	cpy := *c
+	cpy.PreserveIdentity = preserveIdentity
+	return &cpy
+}
+
+// WithIdenticalLayouts returns a copy of the context with the
+// IdenticalLayouts field set to the given value.
+func (c *Context) WithIdenticalLayouts(identicalLayouts bool) *Context {
+	cpy := *c
+	cpy.IdenticalLayouts = identicalLayouts
+	return &cpy
+}
+
+// WithBits returns a copy of the context with the Bits field set to the
+// given value.
+func (c *Context) WithBits(bits bool) *Context {
+	cpy := *c
+	cpy.Bits = bits
+	return &cpy
+}
+
+// WithBitOrder returns a copy of the context with the BitOrder field set to
+// the given value.
+func (c *Context) WithBitOrder(order BitOrder) *Context {
+	cpy := *c
+	cpy.BitOrder = order
+	return &cpy
+}
+
+// WithMaxLength returns a copy of the context with the MaxLength field set
+// to the given value.
+func (c *Context) WithMaxLength(maxLength int) *Context {
+	cpy := *c
+	cpy.MaxLength = maxLength
+	return &cpy
+}
+
+// WithMaxMapSize returns a copy of the context with the MaxMapSize field set
+// to the given value.
+func (c *Context) WithMaxMapSize(maxMapSize int) *Context {
+	cpy := *c
+	cpy.MaxMapSize = maxMapSize
+	return &cpy
+}
+
+// WithMaxElements returns a copy of the context with the MaxElements field
+// set to the given value.
+func (c *Context) WithMaxElements(maxElements int) *Context {
+	cpy := *c
+	cpy.MaxElements = maxElements
+	return &cpy
+}
+
+// WithMaxIndex returns a copy of the context with the MaxIndex field set
+// to the given value.
+func (c *Context) WithMaxIndex(maxIndex int) *Context {
+	cpy := *c
+	cpy.MaxIndex = maxIndex
+	return &cpy
+}
+
+// WithMapper returns a copy of the context with the provider added to the
+// Mappers field for the given type. If the provider is nil, the type is
+// removed instead. The Mappers map of the context is copied, so the
+// original context is not modified.
+func (c *Context) WithMapper(typ reflect.Type, provider MapFuncProvider) *Context {
+	cpy := *c
+	cpy.Mappers = make(map[reflect.Type]MapFuncProvider, len(c.Mappers)+1)
+	for k, v := range c.Mappers {
+		cpy.Mappers[k] = v
+	}
+	if provider == nil {
+		delete(cpy.Mappers, typ)
+	} else {
+		cpy.Mappers[typ] = provider
+	}
+	return &cpy
+}
+
+// WithHooks returns a copy of the context with the Hooks field set to the
+// given value.
+func (c *Context) WithHooks(hooks *Hooks) *Context {
+	cpy := *c
+	cpy.Hooks = hooks
+	return &cpy
+}
+
 // WithCustom returns a copy of the context with the Custom field set to the
 // given value.
 func (c *Context) WithCustom(custom any) *Context {
@@ -105,6 +910,14 @@ func (c *Context) WithCustom(custom any) *Context {
 	return &cpy
 }
 
+// Path returns the path of the currently mapped destination value, in the
+// format used by InvalidMappingErr.Path, e.g. "Items[0].Price". Paths are
+// tracked only if Fields, ExcludeFields or path mapping functions are used,
+// otherwise the path is empty.
+func (c *Context) Path() string {
+	return c.path
+}
+
 // Mapper hold the mapper configuration.
 type Mapper struct {
 	// Context is the default context used by the mapper.
@@ -118,15 +931,59 @@ type Mapper struct {
 	// If both source and destination types have defined providers, then
 	// the provider for source value is used first, and if it returns nil,
 	// then the provider for destination value is used.
+	//
+	// Mapping functions are resolved once and cached, so if the map is
+	// modified after the mapper is used, InvalidateCache must be called.
 	Mappers map[reflect.Type]MapFuncProvider
 
+	// Encodings is a map of string encodings that can be selected using
+	// Context.StringEncoding or the encoding tag option.
+	Encodings map[string]StringEncoding
+
+	// Converters is a map of named converters that can be applied to struct
+	// fields using the conv tag option. See RegisterNamedConverter.
+	Converters map[string]Converter
+
+	// FieldRules is a map of rules that derive destination struct fields
+	// from source struct fields, for pairs of struct types. See
+	// AddFieldRule.
+	FieldRules map[TypePair][]FieldRule
+
+	// Migrations is a map of migrations of versioned types to their next
+	// versions. See AddMigration.
+	Migrations map[reflect.Type]Migration
+
+	// IgnoredTypes is a set of types of struct fields that are skipped by
+	// the mapper. See IgnoreTypes.
+	IgnoredTypes map[reflect.Type]bool
+
+	// PathMappers is a map of mapping functions used for destination
+	// values at specific paths. See AddPathMapFunc.
+	PathMappers map[string]MapFunc
+
 	// Hooks are functions that are called during the mapping process. They
 	// can modify the behavior of the mapper. See Hooks for more information.
 	Hooks Hooks
 
-	// Cache:
-	cacheMu  sync.Mutex
-	cacheMap map[typePair]*typeMapper
+	// middlewares is a list of middlewares that wrap every resolved MapFunc.
+	middlewares []Middleware
+
+	// cache holds the resolved type mappers. It may be shared with copies
+	// created by CopySharingCache.
+	cache *typeCache
+}
+
+// typeCache is a cache of resolved type mappers. Lookups do not take the
+// lock, so concurrent mapping calls do not block each other once the type
+// mappers are resolved. The lock is taken only to resolve missing ones.
+type typeCache struct {
+	mu     sync.Mutex
+	m      sync.Map // typePair -> *typeMapper
+	fields sync.Map // fieldsKey -> []reflect.StructField
+}
+
+func newTypeCache() *typeCache {
+	return &typeCache{}
 }
 
 // Hooks are functions that are called during the mapping process. They can
@@ -156,6 +1013,32 @@ type Hooks struct {
 	// By default, mapper unpacks pointers and dereferences interfaces. This
 	// hook can be used to change this behavior.
 	DestinationValueHook func(reflect.Value) reflect.Value
+
+	// MissingFieldHook is called when a field of the destination struct does
+	// not have a corresponding value in the source map or struct. The key is
+	// the name under which the value was looked up and dst is the field value.
+	//
+	// The hook can be used to assign a value to the field, record the missing
+	// field or return an error, in which case the mapping fails.
+	MissingFieldHook func(m *Mapper, ctx *Context, field reflect.StructField, key string, dst reflect.Value) error
+
+	// UnmappedKeyHook is called for every key of the source map, or field of
+	// the source struct, that was not mapped to any field of the destination
+	// struct. The key is the name of the map key or the struct field and src
+	// is its value.
+	//
+	// The hook can be used to log or collect extra data, or to reject it by
+	// returning an error, in which case the mapping fails.
+	UnmappedKeyHook func(m *Mapper, ctx *Context, key string, src reflect.Value) error
+
+	// KeyHook returns the map key for the given struct field. It is called
+	// with the key resolved from the tag or field name and the field value.
+	//
+	// For struct → map mappings, the returned key is used as the destination
+	// key and the value is the source field. For map → struct mappings, the
+	// returned key is used to look up the source value and the value is the
+	// destination field.
+	KeyHook func(m *Mapper, ctx *Context, field reflect.StructField, key string, val reflect.Value) string
 }
 
 // New returns a new Mapper with default configuration.
@@ -170,8 +1053,22 @@ func New() *Mapper {
 			bigIntTy:   bigIntTypeMapper,
 			bigFloatTy: bigFloatTypeMapper,
 			bigRatTy:   bigRatTypeMapper,
+			monthTy:    monthEnum.typeMapper,
+			weekdayTy:  weekdayEnum.typeMapper,
+			fileModeTy: fileModeTypeMapper,
+			regexpTy:   regexpTypeMapper,
+			macTy:      macTypeMapper,
+			mailAddrTy: mailAddrTypeMapper,
+			locationTy: locationTypeMapper,
+			float16Ty:  float16TypeMapper,
+			rawTy:      rawTypeMapper,
+			durationTy: durationTypeMapper,
+			ipTy:       ipTypeMapper,
+			ipNetTy:    ipNetTypeMapper,
+			netipTy:    netipTypeMapper,
 		},
-		cacheMap: make(map[typePair]*typeMapper, 0),
+		Encodings: defaultEncodings(),
+		cache:     newTypeCache(),
 	}
 }
 
@@ -203,6 +1100,21 @@ func MapReflContext(ctx *Context, src, dst reflect.Value) error {
 	return Default.MapReflContext(ctx, src, dst)
 }
 
+// MustMap is like Map but panics if the mapping fails. It is meant for
+// tests and initialization code.
+//
+// It is shorthand for Default.MustMap(src, dst).
+func MustMap(src, dst any) {
+	Default.MustMap(src, dst)
+}
+
+// MustMapRefl is like MapRefl but panics if the mapping fails.
+//
+// It is shorthand for Default.MustMapRefl(src, dst).
+func MustMapRefl(src, dst reflect.Value) {
+	Default.MustMapRefl(src, dst)
+}
+
 // Map maps the source value to the destination value.
 func (m *Mapper) Map(src, dst any) error {
 	return m.MapRefl(reflect.ValueOf(src), reflect.ValueOf(dst))
@@ -218,13 +1130,28 @@ func (m *Mapper) MapRefl(src, dst reflect.Value) error {
 	return m.MapReflContext(m.Context, src, dst)
 }
 
+// MustMap is like Map but panics if the mapping fails.
+func (m *Mapper) MustMap(src, dst any) {
+	if err := m.Map(src, dst); err != nil {
+		panic(err)
+	}
+}
+
+// MustMapRefl is like MapRefl but panics if the mapping fails.
+func (m *Mapper) MustMapRefl(src, dst reflect.Value) {
+	if err := m.MapRefl(src, dst); err != nil {
+		panic(err)
+	}
+}
+
 // MapReflContext maps the source value to the destination value.
 func (m *Mapper) MapReflContext(ctx *Context, src, dst reflect.Value) error {
 	if ctx == nil {
 		ctx = m.Context
 	}
-	srcVal := m.srcValue(src)
-	dstVal := m.dstValue(dst)
+	ctx = ctx.withState(src, dst).withPathMappers(m)
+	srcVal := m.srcValue(ctx, src)
+	dstVal := m.dstValue(ctx, dst)
 	if !srcVal.IsValid() {
 		return InvalidSrcErr
 	}
@@ -235,18 +1162,24 @@ func (m *Mapper) MapReflContext(ctx *Context, src, dst reflect.Value) error {
 }
 
 // Copy creates a copy of the current Mapper with the same configuration.
+// All Context fields are copied, so the copy behaves exactly like the
+// original mapper. Slices and maps in the context are shared, while the
+// Mappers, Encodings, Converters, FieldRules, Migrations, IgnoredTypes and
+// PathMappers maps are copied, so they can be modified without affecting
+// the original mapper.
 func (m *Mapper) Copy() *Mapper {
+	ctx := *m.Context
+	ctx.path = ""
+	ctx.fieldPath = ""
+	ctx.pathMappers = false
+	ctx.state = nil
+	ctx.keepAny = false
+	ctx.suspended = nil
 	cpy := &Mapper{
-		Context: &Context{
-			StrictTypes:  m.Context.StrictTypes,
-			Tag:          m.Context.Tag,
-			ByteOrder:    m.Context.ByteOrder,
-			DisableCache: m.Context.DisableCache,
-			FieldMapper:  m.Context.FieldMapper,
-			Custom:       m.Context.Custom,
-		},
-		Hooks:    m.Hooks,
-		cacheMap: make(map[typePair]*typeMapper, 0),
+		Context:     &ctx,
+		Hooks:       m.Hooks,
+		middlewares: append([]Middleware(nil), m.middlewares...),
+		cache:       newTypeCache(),
 	}
 	if m.Mappers != nil {
 		cpy.Mappers = make(map[reflect.Type]MapFuncProvider)
@@ -254,37 +1187,187 @@ func (m *Mapper) Copy() *Mapper {
 			cpy.Mappers[k] = v
 		}
 	}
+	if m.Encodings != nil {
+		cpy.Encodings = make(map[string]StringEncoding)
+		for k, v := range m.Encodings {
+			cpy.Encodings[k] = v
+		}
+	}
+	if m.Converters != nil {
+		cpy.Converters = make(map[string]Converter)
+		for k, v := range m.Converters {
+			cpy.Converters[k] = v
+		}
+	}
+	if m.FieldRules != nil {
+		cpy.FieldRules = make(map[TypePair][]FieldRule)
+		for k, v := range m.FieldRules {
+			cpy.FieldRules[k] = append([]FieldRule(nil), v...)
+		}
+	}
+	if m.Migrations != nil {
+		cpy.Migrations = make(map[reflect.Type]Migration)
+		for k, v := range m.Migrations {
+			cpy.Migrations[k] = v
+		}
+	}
+	if m.IgnoredTypes != nil {
+		cpy.IgnoredTypes = make(map[reflect.Type]bool)
+		for k, v := range m.IgnoredTypes {
+			cpy.IgnoredTypes[k] = v
+		}
+	}
+	if m.PathMappers != nil {
+		cpy.PathMappers = make(map[string]MapFunc)
+		for k, v := range m.PathMappers {
+			cpy.PathMappers[k] = v
+		}
+	}
 	return cpy
 }
 
+// CopySharingCache creates a copy of the current Mapper, like Copy does,
+// that shares the cache of resolved type mappers with the original mapper,
+// so the copy does not have to resolve them again.
+//
+// Cached mappers depend on the Mappers, Hooks and middlewares of the mapper,
+// but not on its Context, so the copy may use a different context, e.g.
+// a different tag or field mapper. The Mappers and Hooks fields of the copy
+// must not be modified, otherwise both mappers may use mapping functions
+// resolved by the other one. Calling Use on the copy detaches it from the
+// shared cache.
+func (m *Mapper) CopySharingCache() *Mapper {
+	cpy := m.Copy()
+	cpy.cache = m.cache
+	return cpy
+}
+
+// Use adds middlewares that wrap every MapFunc resolved by the mapper.
+// Middlewares are applied in the order in which they were added, hence the
+// first middleware is the outermost one.
+//
+// Because the wrapped functions are cached, Use replaces the cache with
+// an empty one. Like modifying the Mappers map, it is not safe to call Use
+// concurrently with the mapping methods of the mapper.
+func (m *Mapper) Use(mw ...Middleware) {
+	m.middlewares = append(m.middlewares, mw...)
+	m.cache = newTypeCache()
+}
+
+// InvalidateCache removes all resolved type mappers from the cache, so they
+// are resolved again when they are needed. It must be called after the
+// Mappers, Hooks or Migrations of a mapper that has already been used are
+// modified, otherwise the previously resolved mapping functions are still
+// used. If the cache is shared with copies created by CopySharingCache, it
+// is cleared for them too.
+//
+// InvalidateCache may be called concurrently with the mapping methods, but
+// modifying the Mappers map itself is not safe for concurrent use.
+func (m *Mapper) InvalidateCache() {
+	c := m.cache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m.Range(func(k, _ any) bool {
+		c.m.Delete(k)
+		return true
+	})
+	c.fields.Range(func(k, _ any) bool {
+		c.fields.Delete(k)
+		return true
+	})
+}
+
 // mapperFor returns the typeMapper that can map values of the given types.
 // If mapping is not possible, the returned typeMapper has a nil MapFunc.
 func (m *Mapper) mapperFor(ctx *Context, src, dst reflect.Type) (tm *typeMapper) {
-	if !ctx.DisableCache {
-		m.cacheMu.Lock()
-		if v, ok := m.cacheMap[typePair{src: src, dst: dst}]; ok {
-			m.cacheMu.Unlock()
-			return v
+	if c := m.cacheFor(ctx); c != nil {
+		key := typePair{src: src, dst: dst, flags: ctx.resolveFlags()}
+		if v, ok := c.m.Load(key); ok {
+			return v.(*typeMapper)
+		}
+		c.mu.Lock()
+		if v, ok := c.m.Load(key); ok {
+			c.mu.Unlock()
+			return v.(*typeMapper)
 		}
 		defer func() {
-			m.cacheMap[typePair{src: src, dst: dst}] = tm
-			m.cacheMu.Unlock()
+			c.m.Store(key, tm)
+			c.mu.Unlock()
 		}()
 	}
-
 	tm = &typeMapper{
 		SrcType: src,
 		DstType: dst,
 	}
-
 	// If MapFuncHook is set, then use it to get the mapping function.
-	if m.Hooks.MapFuncHook != nil {
-		if fn := m.Hooks.MapFuncHook(m, src, dst); fn != nil {
-			tm.MapFunc = fn
-			return
+	if ctx.Hooks != nil && ctx.Hooks.MapFuncHook != nil {
+		tm.MapFunc = ctx.Hooks.MapFuncHook(m, src, dst)
+		tm.Custom = tm.MapFunc != nil
+	}
+	if tm.MapFunc == nil && m.Hooks.MapFuncHook != nil {
+		tm.MapFunc = m.Hooks.MapFuncHook(m, src, dst)
+		tm.Custom = tm.MapFunc != nil
+	}
+	// Migrations between versions of a type take precedence over providers
+	// and built-in rules.
+	if tm.MapFunc == nil {
+		tm.MapFunc = m.migrationFor(ctx, src, dst)
+		tm.Custom = tm.MapFunc != nil
+	}
+	if tm.MapFunc == nil {
+		tm.MapFunc = m.mapFuncFor(ctx, src, dst)
+	}
+	if tm.MapFunc != nil {
+		for i := len(m.middlewares) - 1; i >= 0; i-- {
+			tm.MapFunc = m.middlewares[i](tm.MapFunc)
 		}
 	}
+	return tm
+}
+
+// cacheFor returns the cache of type mappers that can be used with the
+// context, or nil if type mappers must not be cached. Type mappers resolved
+// using the per-call providers from Context.Mappers, or the per-call
+// Context.Hooks.MapFuncHook, are stored in the state of the mapping call,
+// so they do not leak to other calls.
+func (m *Mapper) cacheFor(ctx *Context) *typeCache {
+	switch {
+	case ctx.DisableCache:
+		return nil
+	case !ctx.resolvesPerCall():
+		return m.cache
+	case ctx.state != nil && ctx.state.cache != nil && ctx.state.resolvesFor(ctx):
+		return ctx.state.cache
+	}
+	return nil
+}
+
+// resolvesPerCall reports whether the context changes the way mapping
+// functions are resolved, so they cannot be stored in the mapper's cache.
+func (c *Context) resolvesPerCall() bool {
+	return len(c.Mappers) > 0 || (c.Hooks != nil && c.Hooks.MapFuncHook != nil)
+}
 
+// provider returns the mapper provider for the given type. Providers from
+// Context.Mappers take precedence over the ones of the mapper.
+func (m *Mapper) provider(ctx *Context, t reflect.Type) (MapFuncProvider, bool) {
+	if p, ok := ctx.Mappers[t]; ok {
+		return p, true
+	}
+	p, ok := m.Mappers[t]
+	return p, ok
+}
+
+// hasProvider reports whether there is a non-nil mapper provider for the
+// given type in the context or in the mapper.
+func (m *Mapper) hasProvider(ctx *Context, t reflect.Type) bool {
+	p, _ := m.provider(ctx, t)
+	return p != nil
+}
+
+// mapFuncFor returns the MapFunc that can map values of the given types.
+// If mapping is not possible, it returns nil.
+func (m *Mapper) mapFuncFor(ctx *Context, src, dst reflect.Type) MapFunc {
 	var isSrcSimple, isDstSimple, sameTypes bool
 	if src == dst {
 		isSrcSimple = isSimpleType(src)
@@ -298,8 +1381,17 @@ func (m *Mapper) mapperFor(ctx *Context, src, dst reflect.Type) (tm *typeMapper)
 	// If both types are simple, e.g. int, string, etc. map the value directly
 	// using reflect.Set.
 	if sameTypes && isSrcSimple {
-		tm.MapFunc = mapDirect
-		return
+		if src.Kind() == reflect.Map {
+			return mapSimpleMap
+		}
+		return mapDirect
+	}
+
+	// Values mapped to the any type are normalized if Context.NormalizeAny
+	// is enabled, and assigned as is by Encode, even if their types have
+	// mapper providers.
+	if dst == anyTy && (ctx.NormalizeAny || ctx.keepAny) {
+		return mapAny
 	}
 
 	// Try to find a mapper using mapper providers. It looks for providers
@@ -309,46 +1401,65 @@ func (m *Mapper) mapperFor(ctx *Context, src, dst reflect.Type) (tm *typeMapper)
 	var srcMapper, dstMapper MapFuncProvider
 	var hasSrcMapper, hasDstMapper bool
 	if !isSrcSimple {
-		srcMapper, hasSrcMapper = m.Mappers[src]
+		srcMapper, hasSrcMapper = m.provider(ctx, src)
 	}
 	if hasSrcMapper {
-		tm.MapFunc = srcMapper(m, src, dst)
-		if tm.MapFunc != nil {
-			return
+		if fn := srcMapper(m, src, dst); fn != nil {
+			return fn
 		}
 	}
 	if !sameTypes && !isDstSimple {
-		dstMapper, hasDstMapper = m.Mappers[dst]
+		dstMapper, hasDstMapper = m.provider(ctx, dst)
 	}
 	if hasDstMapper {
-		tm.MapFunc = dstMapper(m, src, dst)
-		if tm.MapFunc != nil {
-			return
+		if fn := dstMapper(m, src, dst); fn != nil {
+			return fn
 		}
 	}
+
 	if hasSrcMapper || hasDstMapper {
-		return
+		return nil
 	}
 
 	// If destination type is an any interface, map the value directly using
 	// reflect.Set, if the destination interface is not nil, map the value
 	// to the same type as the value in the interface.
 	if dst == anyTy {
-		tm.MapFunc = mapAny
-		return
+		return mapAny
+	}
+
+	// Structs with binary layouts are packed into and unpacked from bytes.
+	if fn := binaryLayoutFunc(src, dst); fn != nil {
+		return fn
+	}
+
+	// Collection types are mapped using their interfaces, even if they are
+	// slices or arrays themselves.
+	if fn := collectionFunc(src, dst); fn != nil {
+		return fn
 	}
 
 	// If there are no custom mappers and hooks, use the default mappers.
-	tm.MapFunc = builtInTypesMapper(m, src, dst)
-	return
+	// Interfaces enabled in the context take precedence over them, except
+	// sql.Scanner, driver.Valuer and fmt.Stringer, which are fallbacks.
+	next := sameLayoutFunc(ctx, src, dst, builtInTypesMapper(m, src, dst))
+	next = stringerFunc(ctx, src, dst, sqlFunc(src, dst, next))
+	next = jsonMarshalerFunc(ctx, src, dst, next)
+	next = textMarshalerFunc(ctx, src, dst, next)
+	return flagValueFunc(ctx, src, dst, next)
 }
 
 // srcValue unpacks values from pointers and interfaces until it reaches a
 // non-pointer or non-interface value, or a type that has a custom mapper.
-func (m *Mapper) srcValue(v reflect.Value) reflect.Value {
+func (m *Mapper) srcValue(ctx *Context, v reflect.Value) reflect.Value {
 	if !v.IsValid() {
 		return v
 	}
+	if ctx.Hooks != nil && ctx.Hooks.SourceValueHook != nil {
+		if v := ctx.Hooks.SourceValueHook(v); v.IsValid() {
+			return v
+		}
+	}
 	if m.Hooks.SourceValueHook != nil {
 		if v := m.Hooks.SourceValueHook(v); v.IsValid() {
 			return v
@@ -360,6 +1471,16 @@ func (m *Mapper) srcValue(v reflect.Value) reflect.Value {
 		}
 		v = v.Elem()
 	}
+	if v.Kind() == reflect.Struct && !v.CanAddr() {
+		// Mapping functions for types such as big.Int need to take the
+		// address of the value, so unaddressable values, e.g. those stored
+		// in interfaces, are copied.
+		if m.hasProvider(ctx, v.Type()) {
+			cpy := reflect.New(v.Type()).Elem()
+			cpy.Set(v)
+			return cpy
+		}
+	}
 	return v
 }
 
@@ -368,10 +1489,15 @@ func (m *Mapper) srcValue(v reflect.Value) reflect.Value {
 // or a value that is a map, slice or array. It returns an invalid value if it
 // cannot find a value that meets these conditions. If the value is a pointer,
 // map or slice, it will be initialized if needed.
-func (m *Mapper) dstValue(v reflect.Value) reflect.Value {
+func (m *Mapper) dstValue(ctx *Context, v reflect.Value) reflect.Value {
 	if !v.IsValid() {
 		return v
 	}
+	if ctx.Hooks != nil && ctx.Hooks.DestinationValueHook != nil {
+		if v := ctx.Hooks.DestinationValueHook(v); v.IsValid() {
+			return v
+		}
+	}
 	if m.Hooks.DestinationValueHook != nil {
 		if v := m.Hooks.DestinationValueHook(v); v.IsValid() {
 			return v
@@ -389,7 +1515,7 @@ func (m *Mapper) dstValue(v reflect.Value) reflect.Value {
 		if v.CanSet() && isSimpleType(v.Type()) {
 			return v
 		}
-		if m.Mappers[v.Type()] != nil {
+		if m.hasProvider(ctx, v.Type()) {
 			return v
 		}
 		if v.Kind() == reflect.Map && !v.IsNil() {
@@ -421,23 +1547,6 @@ func (m *Mapper) initValue(v reflect.Value) {
 	}
 }
 
-// parseTag parses the tag of the given field and returns the tag name and
-// whether the field should be skipped.
-func (m *Mapper) parseTag(ctx *Context, f reflect.StructField) (fields string, skip bool) {
-	tag, ok := f.Tag.Lookup(ctx.Tag)
-	if !ok {
-		if ctx.FieldMapper != nil {
-			return ctx.FieldMapper(f.Name), false
-		} else {
-			return f.Name, false
-		}
-	}
-	if tag == "-" {
-		return "", true
-	}
-	return tag, false
-}
-
 // isSimpleType indicates whether a type is simple type.
 //
 // A type is considered simple if it is a built-in type, or it is a slice,
@@ -486,15 +1595,18 @@ func isSimpleType(p reflect.Type) bool {
 }
 
 // mapAny map src to dst assuming dst is an empty interface.
-func mapAny(m *Mapper, _ *Context, src, dst reflect.Value) error {
+func mapAny(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.NormalizeAny {
+		return m.mapNormalized(ctx, src, dst)
+	}
 	if !dst.IsNil() && !dst.Elem().CanSet() {
 		// Mapper always tries to reuse the destination value if possible, but
 		// if destination value is not settable, we need to cheat a little and
 		// create a new value of the same type and then set it back to the
 		// destination.
 		auxVal := reflect.New(dst.Elem().Type())
-		auxDst := m.dstValue(auxVal)
-		if err := m.MapRefl(src, auxDst); err != nil {
+		auxDst := m.dstValue(ctx, auxVal)
+		if err := m.MapReflContext(ctx, src, auxDst); err != nil {
 			return NewInvalidMappingError(src.Type(), dst.Type(), "")
 		}
 		dst.Set(auxVal.Elem())
@@ -504,8 +1616,15 @@ func mapAny(m *Mapper, _ *Context, src, dst reflect.Value) error {
 	return nil
 }
 
-// mapDirect maps src to dst using a direct assignment.
-func mapDirect(_ *Mapper, _ *Context, src, dst reflect.Value) error {
+// mapDirect maps src to dst using a direct assignment. If
+// Context.InputTransforms are set, slices, arrays and maps of strings are
+// mapped element by element instead, so the strings are transformed.
+func mapDirect(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if len(ctx.InputTransforms) > 0 && src.Kind() != reflect.String && containsStrings(src.Type()) {
+		if fn := builtInTypesMapper(m, src.Type(), dst.Type()); fn != nil {
+			return fn(m, ctx, src, dst)
+		}
+	}
 	dst.Set(src)
 	return nil
 }
@@ -514,6 +1633,7 @@ type typeMapper struct {
 	SrcType reflect.Type
 	DstType reflect.Type
 	MapFunc MapFunc
+	Custom  bool // MapFunc was returned by MapFuncHook or is a migration
 }
 
 func (tm *typeMapper) match(src, dst reflect.Type) bool {
@@ -523,13 +1643,91 @@ func (tm *typeMapper) match(src, dst reflect.Type) bool {
 	return tm.SrcType == src && tm.DstType == dst
 }
 
-func (tm *typeMapper) mapRefl(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+// violatesStrictKinds reports whether mapping between the given types is
+// not allowed in the StrictKinds mode. Types must have the same kind, with
+// the exception of structs, maps and slices of key/value pairs, which can be
+// mapped to each other.
+// Struct types with mapper providers, such as time.Time or big.Int, must
+// be identical.
+func (m *Mapper) violatesStrictKinds(ctx *Context, src, dst reflect.Type) bool {
+	if src == dst || dst.Kind() == reflect.Interface {
+		return false
+	}
+	_, hasSrcMapper := m.provider(ctx, src)
+	_, hasDstMapper := m.provider(ctx, dst)
+	if (src.Kind() == reflect.Struct && hasSrcMapper) || (dst.Kind() == reflect.Struct && hasDstMapper) {
+		return true
+	}
+	if (isStructOrMap(src.Kind()) || isKVSlice(src)) && (isStructOrMap(dst.Kind()) || isKVSlice(dst)) {
+		return false
+	}
+	return src.Kind() != dst.Kind()
+}
+
+// isStrictException reports whether the strict mode is enabled and the
+// given types are listed in StrictExceptions.
+func (c *Context) isStrictException(src, dst reflect.Type) bool {
+	if !c.StrictTypes && !c.StrictKinds {
+		return false
+	}
+	for _, p := range c.StrictExceptions {
+		if p.Src == src && p.Dst == dst {
+			return true
+		}
+	}
+	return false
+}
+
+// suspendStrict returns a copy of the context with the strict mode
+// disabled. The settings are restored by restoreStrict.
+func (c *Context) suspendStrict() *Context {
+	cpy := *c
+	cpy.suspended = &strictMode{types: c.StrictTypes, kinds: c.StrictKinds}
+	cpy.StrictTypes = false
+	cpy.StrictKinds = false
+	return &cpy
+}
+
+// restoreStrict returns a copy of the context with the strict mode
+// settings disabled by suspendStrict restored.
+func (c *Context) restoreStrict() *Context {
+	cpy := *c
+	cpy.StrictTypes = c.suspended.types
+	cpy.StrictKinds = c.suspended.kinds
+	cpy.suspended = nil
+	return &cpy
+}
+
+func isStructOrMap(k reflect.Kind) bool {
+	return k == reflect.Struct || k == reflect.Map
+}
+
+func (tm *typeMapper) mapRefl(m *Mapper, ctx *Context, src, dst reflect.Value) (err error) {
+	if ctx.ValueSnapshotLen > 0 {
+		defer func() { err = snapshotValue(ctx, err, src) }()
+	}
 	if tm == nil {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "unknown mapper")
 	}
 	if tm.MapFunc == nil {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "")
 	}
+	if ctx.suspended != nil {
+		ctx = ctx.restoreStrict()
+	}
+	if ctx.isStrictException(src.Type(), dst.Type()) {
+		return tm.MapFunc(m, ctx.suspendStrict(), src, dst)
+	}
+	if ctx.StrictKinds && !tm.Custom && m.violatesStrictKinds(ctx, src.Type(), dst.Type()) {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	if len(ctx.InputTransforms) > 0 || len(ctx.OutputTransforms) > 0 {
+		if err := tm.MapFunc(m, ctx, ctx.transformInput(src), dst); err != nil {
+			return err
+		}
+		ctx.transformOutput(src, dst)
+		return nil
+	}
 	return tm.MapFunc(m, ctx, src, dst)
 }
 
@@ -545,6 +1743,25 @@ var InvalidDstErr = errors.New("mapper: invalid destination value")
 type InvalidMappingErr struct {
 	From, To reflect.Type
 	Reason   string
+
+	// Path is the path to the value that could not be mapped, relative to
+	// the mapped value, e.g. "Servers[2].Port". Struct fields and map keys
+	// are separated by dots, using the same keys as Context.Fields, and
+	// slice and array indexes, as well as non-string map keys, are in
+	// brackets. It is empty if the mapped value itself failed.
+	Path string
+
+	// Err is the underlying error, such as *strconv.NumError or
+	// *time.ParseError, if the mapping failed because of it.
+	Err error
+
+	// Value is a printable representation of the source value, set only
+	// if Context.ValueSnapshotLen is greater than zero.
+	Value string
+
+	// redacted indicates that the error was created for a secret value, so
+	// the value must not be added to it.
+	redacted bool
 }
 
 func NewStrictMappingError(from, to reflect.Type) *InvalidMappingErr {
@@ -555,32 +1772,179 @@ func NewInvalidMappingError(from, to reflect.Type, reason string) *InvalidMappin
 	return &InvalidMappingErr{From: from, To: to, Reason: reason}
 }
 
+// WrapInvalidMappingError returns an InvalidMappingErr caused by the given
+// error. The error message is used as the reason, and the error is
+// available through errors.Is and errors.As.
+func WrapInvalidMappingError(from, to reflect.Type, err error) *InvalidMappingErr {
+	return &InvalidMappingErr{From: from, To: to, Reason: err.Error(), Err: err}
+}
+
 func (e *InvalidMappingErr) Error() string {
-	if len(e.Reason) == 0 {
-		return fmt.Sprintf("mapper: cannot map %v to %v", e.From, e.To)
+	msg := fmt.Sprintf("mapper: cannot map %v to %v", e.From, e.To)
+	if len(e.Path) > 0 {
+		msg += " at " + e.Path
+	}
+	if len(e.Reason) > 0 {
+		msg += ": " + e.Reason
+	}
+	if len(e.Value) > 0 {
+		msg += " (value: " + e.Value + ")"
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error.
+func (e *InvalidMappingErr) Unwrap() error {
+	return e.Err
+}
+
+// PrependErrorPath prepends the path element to the paths of the
+// InvalidMappingErr errors contained in err, including the ones in
+// MappingErrors, and returns the updated error. The element is a struct
+// field or map key, or an index in brackets, e.g. "[2]". It is used by
+// mapping functions that map nested values, like the ones generated by
+// anymapper-gen, so errors point to the values that failed. Other errors
+// are returned as is.
+//
+// The errors in err are not modified, so it is safe to pass errors that are
+// shared, e.g. stored in package-level variables.
+func PrependErrorPath(err error, elem string) error {
+	switch terr := err.(type) {
+	case MappingErrors:
+		errs := make(MappingErrors, len(terr))
+		for i, err := range terr {
+			errs[i] = PrependErrorPath(err, elem)
+		}
+		return errs
+	case *InvalidMappingErr:
+		cpy := *terr
+		switch {
+		case cpy.Path == "":
+			cpy.Path = elem
+		case cpy.Path[0] == '[':
+			cpy.Path = elem + cpy.Path
+		default:
+			cpy.Path = elem + "." + cpy.Path
+		}
+		return &cpy
+	}
+	return err
+}
+
+// MappingErrors is returned in the best-effort mode when mapping of some
+// values failed. It contains all errors that occurred during the mapping.
+type MappingErrors []error
+
+func (e MappingErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	var b strings.Builder
+	for i, err := range e {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(err.Error())
 	}
-	return fmt.Sprintf("mapper: cannot map %v to %v: %s", e.From, e.To, e.Reason)
+	return b.String()
+}
+
+// Unwrap returns the errors contained in MappingErrors.
+func (e MappingErrors) Unwrap() []error {
+	return e
 }
 
+// Is reports whether any of the contained errors matches target. It makes
+// errors.Is inspect the contained errors also in Go versions that do not
+// support the Unwrap() []error method.
+func (e MappingErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first contained error that matches target, and if one is
+// found, sets target to that error value and returns true. It makes
+// errors.As inspect the contained errors also in Go versions that do not
+// support the Unwrap() []error method.
+func (e MappingErrors) As(target any) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// typePair is the key of a resolved type mapper. Besides the types, it
+// holds the context options that change the way mapping functions are
+// resolved, so the mappers resolved for different options are cached
+// separately.
 type typePair struct {
-	src reflect.Type
-	dst reflect.Type
+	src   reflect.Type
+	dst   reflect.Type
+	flags resolveFlags
+}
+
+// resolveFlags is a set of context options that change the way mapping
+// functions are resolved.
+type resolveFlags uint8
+
+const (
+	resolveFlagValues resolveFlags = 1 << iota
+	resolveTextMarshalers
+	resolveJSONMarshalers
+	resolveStringers
+	resolveIdenticalLayouts
+	resolveNormalizeAny
+	resolveKeepAny
+)
+
+// resolveFlags returns the options of the context that change the way
+// mapping functions are resolved.
+func (c *Context) resolveFlags() (f resolveFlags) {
+	if c.FlagValues {
+		f |= resolveFlagValues
+	}
+	if c.TextMarshalers {
+		f |= resolveTextMarshalers
+	}
+	if c.JSONMarshalers {
+		f |= resolveJSONMarshalers
+	}
+	if c.Stringers {
+		f |= resolveStringers
+	}
+	if c.IdenticalLayouts {
+		f |= resolveIdenticalLayouts
+	}
+	if c.NormalizeAny {
+		f |= resolveNormalizeAny
+	}
+	if c.keepAny {
+		f |= resolveKeepAny
+	}
+	return f
 }
 
 var (
-	anyTy     = reflect.TypeOf((*any)(nil)).Elem()
-	boolTy    = reflect.TypeOf((*bool)(nil)).Elem()
-	intTy     = reflect.TypeOf((*int)(nil)).Elem()
-	int8Ty    = reflect.TypeOf((*int8)(nil)).Elem()
-	int16Ty   = reflect.TypeOf((*int16)(nil)).Elem()
-	int32Ty   = reflect.TypeOf((*int32)(nil)).Elem()
-	int64Ty   = reflect.TypeOf((*int64)(nil)).Elem()
-	uintTy    = reflect.TypeOf((*uint)(nil)).Elem()
-	uint8Ty   = reflect.TypeOf((*uint8)(nil)).Elem()
-	uint16Ty  = reflect.TypeOf((*uint16)(nil)).Elem()
-	uint32Ty  = reflect.TypeOf((*uint32)(nil)).Elem()
-	uint64Ty  = reflect.TypeOf((*uint64)(nil)).Elem()
-	float32Ty = reflect.TypeOf((*float32)(nil)).Elem()
-	float64Ty = reflect.TypeOf((*float64)(nil)).Elem()
-	stringTy  = reflect.TypeOf((*string)(nil)).Elem()
+	anyTy          = reflect.TypeOf((*any)(nil)).Elem()
+	mapStringAnyTy = reflect.TypeOf(map[string]any(nil))
+	boolTy         = reflect.TypeOf((*bool)(nil)).Elem()
+	intTy          = reflect.TypeOf((*int)(nil)).Elem()
+	int8Ty         = reflect.TypeOf((*int8)(nil)).Elem()
+	int16Ty        = reflect.TypeOf((*int16)(nil)).Elem()
+	int32Ty        = reflect.TypeOf((*int32)(nil)).Elem()
+	int64Ty        = reflect.TypeOf((*int64)(nil)).Elem()
+	uintTy         = reflect.TypeOf((*uint)(nil)).Elem()
+	uint8Ty        = reflect.TypeOf((*uint8)(nil)).Elem()
+	uint16Ty       = reflect.TypeOf((*uint16)(nil)).Elem()
+	uint32Ty       = reflect.TypeOf((*uint32)(nil)).Elem()
+	uint64Ty       = reflect.TypeOf((*uint64)(nil)).Elem()
+	float32Ty      = reflect.TypeOf((*float32)(nil)).Elem()
+	float64Ty      = reflect.TypeOf((*float64)(nil)).Elem()
+	stringTy       = reflect.TypeOf((*string)(nil)).Elem()
 )
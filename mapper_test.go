@@ -1,9 +1,16 @@
 package anymapper
 
 import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -72,6 +79,152 @@ func TestCustomMapFunc(t *testing.T) {
 	})
 }
 
+func TestProviderWithError(t *testing.T) {
+	type customType struct {
+		Foo string
+	}
+	typ := reflect.TypeOf(customType{})
+	errNotConfigured := errors.New("codec not configured")
+	m := Default.Copy()
+	m.Mappers[typ] = ProviderWithError(func(m *Mapper, src, dst reflect.Type) (MapFunc, error) {
+		if src.Kind() == reflect.Int {
+			return nil, errNotConfigured
+		}
+		return nil, nil
+	})
+	t.Run("error", func(t *testing.T) {
+		var dst customType
+		err := m.Map(1, &dst)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, errNotConfigured)
+		var mErr *InvalidMappingErr
+		require.ErrorAs(t, err, &mErr)
+		assert.Equal(t, typ, mErr.To)
+		assert.Equal(t, "mapper: cannot map int to anymapper.customType: codec not configured", err.Error())
+	})
+	t.Run("unsupported", func(t *testing.T) {
+		var dst customType
+		err := m.Map("foo", &dst)
+		require.Error(t, err)
+		var mErr *InvalidMappingErr
+		require.ErrorAs(t, err, &mErr)
+		assert.Nil(t, mErr.Err)
+	})
+}
+
+func TestProviderWithContext(t *testing.T) {
+	type customType struct {
+		Foo string
+	}
+	typ := reflect.TypeOf(customType{})
+	var resolved int
+	provider := func(m *Mapper, ctx *Context, src, dst reflect.Type) MapFunc {
+		if dst != typ || src.Kind() != reflect.String {
+			return nil
+		}
+		resolved++
+		if ctx.StrictTypes {
+			return nil
+		}
+		upper, _ := ctx.Custom.(bool)
+		return func(m *Mapper, _ *Context, src, dst reflect.Value) error {
+			s := src.String()
+			if upper {
+				s = strings.ToUpper(s)
+			}
+			dst.Set(reflect.ValueOf(customType{Foo: s}))
+			return nil
+		}
+	}
+	m := Default.Copy()
+	m.Mappers[typ] = ProviderWithContext(provider, func(ctx *Context) any {
+		return [2]any{ctx.StrictTypes, ctx.Custom}
+	})
+
+	var dst customType
+	require.NoError(t, m.Map("foo", &dst))
+	assert.Equal(t, "foo", dst.Foo)
+	require.NoError(t, m.MapContext(m.Context.WithCustom(true), "foo", &dst))
+	assert.Equal(t, "FOO", dst.Foo)
+	require.NoError(t, m.MapContext(m.Context.WithCustom(true), "bar", &dst))
+	assert.Equal(t, "BAR", dst.Foo)
+	assert.Error(t, m.MapContext(m.Context.WithStrictTypes(true), "foo", &dst))
+	assert.Error(t, m.Map(1, &dst))
+
+	// The function resolved for the default context is reused.
+	assert.Equal(t, 3, resolved)
+
+	// Keys that are not comparable are not cached.
+	require.NoError(t, m.MapContext(m.Context.WithCustom([]int{1}), "foo", &dst))
+	require.NoError(t, m.MapContext(m.Context.WithCustom([]int{1}), "foo", &dst))
+	assert.Equal(t, 5, resolved)
+
+	// Invalidating the cache drops the functions resolved for contexts.
+	m.InvalidateCache()
+	require.NoError(t, m.MapContext(m.Context.WithCustom(true), "foo", &dst))
+	assert.Equal(t, 7, resolved)
+}
+
+func TestContextMappers(t *testing.T) {
+	type user struct {
+		ID   int
+		Name string
+	}
+	type order struct {
+		User user
+	}
+	typ := reflect.TypeOf(user{})
+	users := map[int]string{1: "alice"}
+	provider := func(m *Mapper, src, dst reflect.Type) MapFunc {
+		if dst == typ && src.Kind() == reflect.Int {
+			return func(m *Mapper, _ *Context, src, dst reflect.Value) error {
+				dst.Set(reflect.ValueOf(user{ID: int(src.Int()), Name: users[int(src.Int())]}))
+				return nil
+			}
+		}
+		return nil
+	}
+	m := Default.Copy()
+	ctx := m.Context.WithMapper(typ, provider)
+	assert.Nil(t, m.Context.Mappers)
+
+	var dst order
+	require.NoError(t, m.MapContext(ctx, map[string]any{"User": 1}, &dst))
+	assert.Equal(t, user{ID: 1, Name: "alice"}, dst.User)
+
+	// The provider is not used by other calls, even though they map the
+	// same types.
+	assert.Error(t, m.Map(map[string]any{"User": 1}, &dst))
+
+	// The mapper's providers are still used.
+	var tm time.Time
+	require.NoError(t, m.MapContext(ctx, int64(1), &tm))
+	assert.Equal(t, int64(1), tm.Unix())
+
+	// A nil provider removes the type from the context.
+	require.Error(t, m.MapContext(ctx.WithMapper(typ, nil), map[string]any{"User": 1}, &dst))
+
+	// Providers for pointer types stop the unpacking of destination values,
+	// as the ones registered in the mapper do.
+	ptrTyp := reflect.TypeOf(&user{})
+	ptrProvider := func(m *Mapper, src, dst reflect.Type) MapFunc {
+		if dst == ptrTyp && src.Kind() == reflect.String {
+			return func(m *Mapper, _ *Context, src, dst reflect.Value) error {
+				dst.Set(reflect.ValueOf(&user{Name: src.String()}))
+				return nil
+			}
+		}
+		return nil
+	}
+	ptrCtx := m.Context.WithMapper(ptrTyp, ptrProvider)
+	var ptr *user
+	require.NoError(t, m.MapContext(ptrCtx, "bob", &ptr))
+	assert.Equal(t, &user{Name: "bob"}, ptr)
+	var ptrDst struct{ User *user }
+	require.NoError(t, m.MapContext(ptrCtx, map[string]any{"User": "bob"}, &ptrDst))
+	assert.Equal(t, &user{Name: "bob"}, ptrDst.User)
+}
+
 func TestCustomMapFuncAny(t *testing.T) {
 	type customType struct {
 		Foo string
@@ -93,6 +246,298 @@ func TestCustomMapFuncAny(t *testing.T) {
 	assert.Equal(t, "foo", dst.(string))
 }
 
+func TestUse(t *testing.T) {
+	m := Default.Copy()
+	var calls []string
+	m.Use(
+		func(next MapFunc) MapFunc {
+			return func(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+				calls = append(calls, "outer")
+				return next(m, ctx, src, dst)
+			}
+		},
+		func(next MapFunc) MapFunc {
+			return func(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+				calls = append(calls, "inner")
+				if src.Kind() == reflect.String {
+					src = reflect.ValueOf(strings.TrimSpace(src.String()))
+				}
+				return next(m, ctx, src, dst)
+			}
+		},
+	)
+	var dst int
+	require.NoError(t, m.Map(" 42 ", &dst))
+	assert.Equal(t, 42, dst)
+	assert.Equal(t, []string{"outer", "inner"}, calls)
+
+	// Middlewares must not affect the original mapper.
+	require.Error(t, Default.Map(" 42 ", &dst))
+}
+
+func TestMissingFieldHook(t *testing.T) {
+	type Src struct {
+		A int
+	}
+	type Dst struct {
+		A int
+		B string
+	}
+	m := Default.Copy()
+	var missing []string
+	m.Hooks.MissingFieldHook = func(m *Mapper, ctx *Context, field reflect.StructField, key string, dst reflect.Value) error {
+		missing = append(missing, key)
+		return m.MapReflContext(ctx, reflect.ValueOf("default"), dst)
+	}
+	t.Run("map->struct", func(t *testing.T) {
+		missing = nil
+		var dst Dst
+		require.NoError(t, m.Map(map[string]any{"A": 1}, &dst))
+		assert.Equal(t, Dst{A: 1, B: "default"}, dst)
+		assert.Equal(t, []string{"B"}, missing)
+	})
+	t.Run("struct->struct", func(t *testing.T) {
+		missing = nil
+		var dst Dst
+		require.NoError(t, m.Map(Src{A: 1}, &dst))
+		assert.Equal(t, Dst{A: 1, B: "default"}, dst)
+		assert.Equal(t, []string{"B"}, missing)
+	})
+	t.Run("error", func(t *testing.T) {
+		m := m.Copy()
+		m.Hooks.MissingFieldHook = func(_ *Mapper, _ *Context, _ reflect.StructField, key string, _ reflect.Value) error {
+			return fmt.Errorf("missing %s", key)
+		}
+		var dst Dst
+		assert.EqualError(t, m.Map(Src{A: 1}, &dst), "missing B")
+	})
+}
+
+func TestUnmappedKeyHook(t *testing.T) {
+	type Src struct {
+		A int
+		B string
+		C bool
+	}
+	type Dst struct {
+		A int
+	}
+	m := Default.Copy()
+	var unmapped []string
+	m.Hooks.UnmappedKeyHook = func(_ *Mapper, _ *Context, key string, _ reflect.Value) error {
+		unmapped = append(unmapped, key)
+		return nil
+	}
+	t.Run("map->struct", func(t *testing.T) {
+		unmapped = nil
+		var dst Dst
+		require.NoError(t, m.Map(map[string]any{"A": 1, "B": "b", "C": true}, &dst))
+		assert.Equal(t, Dst{A: 1}, dst)
+		assert.ElementsMatch(t, []string{"B", "C"}, unmapped)
+	})
+	t.Run("struct->struct", func(t *testing.T) {
+		unmapped = nil
+		var dst Dst
+		require.NoError(t, m.Map(Src{A: 1, B: "b", C: true}, &dst))
+		assert.Equal(t, Dst{A: 1}, dst)
+		assert.Equal(t, []string{"B", "C"}, unmapped)
+	})
+	t.Run("error", func(t *testing.T) {
+		m := m.Copy()
+		m.Hooks.UnmappedKeyHook = func(_ *Mapper, _ *Context, key string, _ reflect.Value) error {
+			return fmt.Errorf("unexpected %s", key)
+		}
+		var dst Dst
+		assert.EqualError(t, m.Map(map[string]any{"A": 1, "B": "b"}, &dst), "unexpected B")
+	})
+}
+
+func TestKeyHook(t *testing.T) {
+	type Data struct {
+		Name    string
+		Version int `map:"version"`
+	}
+	m := Default.Copy()
+	m.Hooks.KeyHook = func(_ *Mapper, _ *Context, field reflect.StructField, key string, val reflect.Value) string {
+		if field.Name == "Name" {
+			return fmt.Sprintf("%s_%s", key, "en")
+		}
+		return key
+	}
+	t.Run("struct->map", func(t *testing.T) {
+		var dst map[string]any
+		require.NoError(t, m.Map(Data{Name: "foo", Version: 2}, &dst))
+		assert.Equal(t, map[string]any{"Name_en": "foo", "version": 2}, dst)
+	})
+	t.Run("map->struct", func(t *testing.T) {
+		var dst Data
+		require.NoError(t, m.Map(map[string]any{"Name_en": "foo", "Name": "bar", "version": 2}, &dst))
+		assert.Equal(t, Data{Name: "foo", Version: 2}, dst)
+	})
+}
+
+func TestContextHooks(t *testing.T) {
+	type Data struct {
+		Name    string
+		Version int `map:"version"`
+	}
+	m := Default.Copy()
+	m.Hooks.KeyHook = func(_ *Mapper, _ *Context, _ reflect.StructField, key string, _ reflect.Value) string {
+		return strings.ToLower(key)
+	}
+	var unmapped []string
+	ctx := m.Context.WithHooks(&Hooks{
+		KeyHook: func(_ *Mapper, _ *Context, field reflect.StructField, key string, _ reflect.Value) string {
+			if field.Name == "Name" {
+				return "tenant_" + key
+			}
+			return key
+		},
+		UnmappedKeyHook: func(_ *Mapper, _ *Context, key string, _ reflect.Value) error {
+			unmapped = append(unmapped, key)
+			return nil
+		},
+	})
+	t.Run("struct->map", func(t *testing.T) {
+		var dst map[string]any
+		require.NoError(t, m.MapContext(ctx, Data{Name: "foo", Version: 2}, &dst))
+		assert.Equal(t, map[string]any{"tenant_name": "foo", "version": 2}, dst)
+	})
+	t.Run("map->struct", func(t *testing.T) {
+		unmapped = nil
+		var dst Data
+		require.NoError(t, m.MapContext(ctx, map[string]any{"tenant_name": "foo", "name": "bar"}, &dst))
+		assert.Equal(t, Data{Name: "foo"}, dst)
+		assert.Equal(t, []string{"name"}, unmapped)
+	})
+	t.Run("other-calls", func(t *testing.T) {
+		var dst map[string]any
+		require.NoError(t, m.Map(Data{Name: "foo", Version: 2}, &dst))
+		assert.Equal(t, map[string]any{"name": "foo", "version": 2}, dst)
+	})
+}
+
+func TestBestEffort(t *testing.T) {
+	type Inner struct {
+		X int
+		Y int
+	}
+	type Dst struct {
+		A int
+		B int
+		C Inner
+		D []int
+	}
+	ctx := Default.Context.WithBestEffort(true)
+	src := map[string]any{
+		"A": "1",
+		"B": "foo",
+		"C": map[string]any{"X": "2", "Y": "bar"},
+		"D": []any{"3", "baz", "4"},
+	}
+	dst := Dst{B: 42}
+	err := MapContext(ctx, src, &dst)
+	require.Error(t, err)
+	assert.Len(t, err.(MappingErrors), 3)
+	assert.Equal(t, Dst{A: 1, C: Inner{X: 2}, D: []int{3, 0, 4}}, dst)
+
+	// Structs of different types are mapped in the same way.
+	type Src struct {
+		A string
+		B string
+		C map[string]any
+		D []string
+	}
+	dst = Dst{B: 42}
+	err = MapContext(ctx, Src{A: "1", B: "foo", C: src["C"].(map[string]any), D: []string{"x", "5"}}, &dst)
+	var errs MappingErrors
+	require.ErrorAs(t, err, &errs)
+	assert.Len(t, errs, 3)
+	assert.Equal(t, Dst{A: 1, C: Inner{X: 2}, D: []int{0, 5}}, dst)
+
+	// Contained errors can be inspected using errors.Is and errors.As.
+	var mErr *InvalidMappingErr
+	require.True(t, errs.As(&mErr))
+	assert.Equal(t, errs[0], mErr)
+	assert.True(t, errs.Is(mErr))
+	assert.False(t, errs.Is(InvalidSrcErr))
+
+	// Without the best-effort mode, the first error is returned.
+	err = Map(src, &Dst{})
+	require.Error(t, err)
+	assert.IsType(t, &InvalidMappingErr{}, err)
+}
+
+func TestMustMap(t *testing.T) {
+	var dst int
+	MustMap("42", &dst)
+	assert.Equal(t, 42, dst)
+	MustMapRefl(reflect.ValueOf("7"), reflect.ValueOf(&dst))
+	assert.Equal(t, 7, dst)
+	assert.PanicsWithError(t, `mapper: cannot map string to int: strconv.ParseInt: parsing "foo": invalid syntax`, func() {
+		MustMap("foo", &dst)
+	})
+	assert.Panics(t, func() { MustMapRefl(reflect.ValueOf("foo"), reflect.ValueOf(&dst)) })
+}
+
+func TestMapReflContextPropagation(t *testing.T) {
+	// Mapping functions that map values recursively must use the context
+	// passed by the caller.
+	tm := time.Unix(1, 0).UTC()
+	var dst []byte
+	ctx := Default.Context.WithByteOrder(binary.LittleEndian)
+	require.NoError(t, MapReflContext(ctx, reflect.ValueOf(tm), reflect.ValueOf(&dst)))
+	assert.Equal(t, []byte{1, 0, 0, 0, 0, 0, 0, 0}, dst)
+}
+
+func TestMinimalBytes(t *testing.T) {
+	ctx := Default.Context.WithMinimalBytes(true)
+	tests := []struct {
+		src  any
+		want []byte
+	}{
+		{src: 0, want: []byte{}},
+		{src: 1, want: []byte{1}},
+		{src: uint16(256), want: []byte{1, 0}},
+		{src: int64(math.MaxInt64), want: []byte{0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{src: uint64(math.MaxUint64), want: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%T(%v)", tt.src, tt.src), func(t *testing.T) {
+			var dst []byte
+			require.NoError(t, MapContext(ctx, tt.src, &dst))
+			assert.Equal(t, tt.want, dst)
+		})
+	}
+	t.Run("negative", func(t *testing.T) {
+		var dst []byte
+		assert.Error(t, MapContext(ctx, -1, &dst))
+	})
+	t.Run("decode", func(t *testing.T) {
+		var u8 uint8
+		require.NoError(t, MapContext(ctx, []byte{0, 0, 0xff}, &u8))
+		assert.Equal(t, uint8(0xff), u8)
+		assert.Error(t, MapContext(ctx, []byte{1, 0}, &u8))
+
+		var i64 int64
+		require.NoError(t, MapContext(ctx, []byte{}, &i64))
+		assert.Equal(t, int64(0), i64)
+		require.NoError(t, MapContext(ctx, [2]byte{1, 0}, &i64))
+		assert.Equal(t, int64(256), i64)
+		assert.Error(t, MapContext(ctx, []byte{0x80, 0, 0, 0, 0, 0, 0, 0}, &i64))
+		assert.Error(t, MapContext(ctx, []byte{1, 0, 0, 0, 0, 0, 0, 0, 0}, &i64))
+
+		var u64 uint64
+		require.NoError(t, MapContext(ctx, []byte{0, 0x80, 0, 0, 0, 0, 0, 0, 0}, &u64))
+		assert.Equal(t, uint64(1)<<63, u64)
+	})
+	t.Run("float", func(t *testing.T) {
+		var dst []byte
+		require.NoError(t, MapContext(ctx, float32(1), &dst))
+		assert.Len(t, dst, 4)
+	})
+}
+
 func TestFieldMapper(t *testing.T) {
 	m := Default.Copy()
 	m.Context.FieldMapper = func(name string) string {
@@ -114,6 +559,29 @@ func TestFieldMapper(t *testing.T) {
 	}, dst)
 }
 
+func TestFieldMapperContext(t *testing.T) {
+	type Src struct {
+		Foo string
+		Bar string `map:"BAR"`
+	}
+	m := Default.Copy()
+	ctx := m.Context.WithFieldMapper(strings.ToLower)
+
+	var dst map[string]any
+	require.NoError(t, m.MapContext(ctx, Src{Foo: "foo", Bar: "bar"}, &dst))
+	assert.Equal(t, map[string]any{"foo": "foo", "BAR": "bar"}, dst)
+
+	var src Src
+	require.NoError(t, m.MapContext(ctx, map[string]any{"foo": "foo", "Foo": "x"}, &src))
+	assert.Equal(t, "foo", src.Foo)
+
+	// The per-call field mapper does not affect other calls, even though
+	// they share the cached type mappers.
+	dst = nil
+	require.NoError(t, m.Map(Src{Foo: "foo", Bar: "bar"}, &dst))
+	assert.Equal(t, map[string]any{"Foo": "foo", "BAR": "bar"}, dst)
+}
+
 func TestEmptyTag(t *testing.T) {
 	m := Default.Copy()
 	m.Context.Tag = ""
@@ -150,6 +618,218 @@ func TestInvalidMappingErr_WithoutReason(t *testing.T) {
 	assert.Equal(t, "mapper: cannot map int to string", err.Error())
 }
 
+func TestInvalidMappingErr_Path(t *testing.T) {
+	type Server struct {
+		Host string `map:"host"`
+		Port uint16 `map:"port"`
+	}
+	type Config struct {
+		Servers []Server       `map:"servers"`
+		Limits  map[int]uint8  `map:"limits"`
+		Labels  map[string]int `map:"labels"`
+	}
+	t.Run("map-to-struct", func(t *testing.T) {
+		var dst Config
+		err := Map(map[string]any{
+			"servers": []any{
+				map[string]any{"host": "a", "port": 80},
+				map[string]any{"host": "b", "port": "http"},
+			},
+		}, &dst)
+		var merr *InvalidMappingErr
+		require.ErrorAs(t, err, &merr)
+		assert.Equal(t, "servers[1].port", merr.Path)
+		assert.Equal(t, `mapper: cannot map string to uint16 at servers[1].port: strconv.ParseUint: parsing "http": invalid syntax`, err.Error())
+	})
+	t.Run("map-keys", func(t *testing.T) {
+		var dst Config
+		err := Map(map[string]any{"limits": map[int]int{7: 300}}, &dst)
+		var merr *InvalidMappingErr
+		require.ErrorAs(t, err, &merr)
+		assert.Equal(t, "limits[7]", merr.Path)
+		err = Map(map[string]any{"labels": map[string]any{"a": "b"}}, &dst)
+		require.ErrorAs(t, err, &merr)
+		assert.Equal(t, "labels.a", merr.Path)
+	})
+	t.Run("struct-to-struct", func(t *testing.T) {
+		type ServerDTO struct {
+			Host string `map:"host"`
+			Port string `map:"port"`
+		}
+		var dst []Server
+		err := Map([]ServerDTO{{Port: "1"}, {Port: "-1"}}, &dst)
+		var merr *InvalidMappingErr
+		require.ErrorAs(t, err, &merr)
+		assert.Equal(t, "[1].port", merr.Path)
+	})
+	t.Run("best-effort", func(t *testing.T) {
+		var dst Config
+		ctx := Default.Context.WithBestEffort(true)
+		err := MapContext(ctx, map[string]any{
+			"servers": []any{map[string]any{"port": "x"}, map[string]any{"port": "y"}},
+			"limits":  map[int]int{1: -1},
+		}, &dst)
+		var errs MappingErrors
+		require.ErrorAs(t, err, &errs)
+		var paths []string
+		for _, err := range errs {
+			paths = append(paths, err.(*InvalidMappingErr).Path)
+		}
+		assert.ElementsMatch(t, []string{"servers[0].port", "servers[1].port", "limits[1]"}, paths)
+	})
+	t.Run("top-level", func(t *testing.T) {
+		var dst int
+		err := Map("x", &dst)
+		var merr *InvalidMappingErr
+		require.ErrorAs(t, err, &merr)
+		assert.Empty(t, merr.Path)
+	})
+	t.Run("prepend", func(t *testing.T) {
+		err := PrependErrorPath(NewInvalidMappingError(reflect.TypeOf(1), reflect.TypeOf(""), ""), "[0]")
+		err = PrependErrorPath(err, "a")
+		assert.Equal(t, "mapper: cannot map int to string at a[0]", err.Error())
+		assert.Equal(t, InvalidSrcErr, PrependErrorPath(InvalidSrcErr, "a"))
+	})
+	t.Run("shared-error", func(t *testing.T) {
+		type item struct {
+			N int `map:"n"`
+		}
+		shared := NewInvalidMappingError(reflect.TypeOf(""), reflect.TypeOf(0), "")
+		m := New()
+		m.Mappers[reflect.TypeOf(item{})] = func(m *Mapper, src, dst reflect.Type) MapFunc {
+			return func(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+				return shared
+			}
+		}
+		var dst []item
+		for i := 0; i < 2; i++ {
+			err := m.Map([]string{"a"}, &dst)
+			assert.Equal(t, "mapper: cannot map string to int at [0]", err.Error())
+		}
+		assert.Empty(t, shared.Path)
+	})
+}
+
+func TestInvalidMappingErr_Unwrap(t *testing.T) {
+	t.Run("strconv", func(t *testing.T) {
+		var dst int
+		err := Map("foo", &dst)
+		var numErr *strconv.NumError
+		require.True(t, errors.As(err, &numErr))
+		assert.Equal(t, "foo", numErr.Num)
+		assert.Equal(t, `mapper: cannot map string to int: strconv.ParseInt: parsing "foo": invalid syntax`, err.Error())
+	})
+	t.Run("time", func(t *testing.T) {
+		var dst time.Time
+		err := Map("2024-13-01", &dst)
+		var parseErr *time.ParseError
+		assert.True(t, errors.As(err, &parseErr))
+	})
+	t.Run("best-effort", func(t *testing.T) {
+		var dst struct{ A, B int }
+		ctx := Default.Context.WithBestEffort(true)
+		err := MapContext(ctx, map[string]any{"A": "1", "B": "foo"}, &dst)
+		var numErr *strconv.NumError
+		assert.True(t, errors.As(err, &numErr))
+	})
+	t.Run("secret", func(t *testing.T) {
+		var dst struct {
+			A int `map:",secret"`
+		}
+		err := Map(map[string]any{"A": "hunter2"}, &dst)
+		var numErr *strconv.NumError
+		require.Error(t, err)
+		assert.False(t, errors.As(err, &numErr))
+	})
+}
+
+func TestValueSnapshot(t *testing.T) {
+	ctx := Default.Context.WithValueSnapshotLen(8)
+	t.Run("string", func(t *testing.T) {
+		var dst int
+		err := MapContext(ctx, "foo", &dst)
+		var mapErr *InvalidMappingErr
+		require.True(t, errors.As(err, &mapErr))
+		assert.Equal(t, `"foo"`, mapErr.Value)
+		assert.Contains(t, err.Error(), `(value: "foo")`)
+	})
+	t.Run("truncated", func(t *testing.T) {
+		var dst int
+		err := MapContext(ctx, "abcdefghij", &dst)
+		var mapErr *InvalidMappingErr
+		require.True(t, errors.As(err, &mapErr))
+		assert.Equal(t, `"abcdefg...`, mapErr.Value)
+	})
+	t.Run("nested", func(t *testing.T) {
+		var dst struct{ A []int }
+		err := MapContext(ctx, map[string]any{"A": []any{1, true, "x"}}, &dst)
+		var mapErr *InvalidMappingErr
+		require.True(t, errors.As(err, &mapErr))
+		assert.Equal(t, `"x"`, mapErr.Value)
+	})
+	t.Run("secret", func(t *testing.T) {
+		var dst struct {
+			A int `map:",secret"`
+		}
+		err := MapContext(ctx, map[string]any{"A": "hunter2"}, &dst)
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "hunter2")
+		assert.NotContains(t, err.Error(), "value:")
+	})
+	t.Run("disabled", func(t *testing.T) {
+		var dst int
+		err := Map("foo", &dst)
+		var mapErr *InvalidMappingErr
+		require.True(t, errors.As(err, &mapErr))
+		assert.Empty(t, mapErr.Value)
+	})
+	t.Run("shared-error", func(t *testing.T) {
+		shared := NewInvalidMappingError(reflect.TypeOf(""), reflect.TypeOf(0), "")
+		m := New()
+		m.Context = ctx
+		m.Mappers[reflect.TypeOf(0)] = func(m *Mapper, src, dst reflect.Type) MapFunc {
+			return func(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+				return shared
+			}
+		}
+		var dst int
+		for _, src := range []string{"a", "b"} {
+			err := m.Map(src, &dst)
+			var mapErr *InvalidMappingErr
+			require.True(t, errors.As(err, &mapErr))
+			assert.Equal(t, strconv.Quote(src), mapErr.Value)
+		}
+		assert.Empty(t, shared.Value)
+	})
+}
+
+func TestMapToMapAllocs(t *testing.T) {
+	// Disabled options, like NoOverwrite, SkipZeroSource and
+	// PreserveIdentity, must not cost additional allocations.
+	type Src struct {
+		A int
+		B int
+		C int
+		D int
+	}
+	t.Run("struct->map", func(t *testing.T) {
+		dst := map[string]string{}
+		srcVal, dstVal := reflect.ValueOf(Src{A: 1, B: 2, C: 3, D: 4}), reflect.ValueOf(&dst)
+		allocs := testing.AllocsPerRun(100, func() {
+			_ = MapRefl(srcVal, dstVal)
+		})
+		assert.LessOrEqual(t, allocs, float64(12))
+	})
+	t.Run("map->map", func(t *testing.T) {
+		dst := map[string]string{}
+		srcVal, dstVal := reflect.ValueOf(map[string]int{"A": 1, "B": 2, "C": 3, "D": 4}), reflect.ValueOf(&dst)
+		allocs := testing.AllocsPerRun(100, func() {
+			_ = MapRefl(srcVal, dstVal)
+		})
+		assert.LessOrEqual(t, allocs, float64(17))
+	})
+}
+
 func Benchmark(b *testing.B) {
 	b.Run("struct->struct", func(b *testing.B) {
 		type Src struct {
@@ -176,6 +856,31 @@ func Benchmark(b *testing.B) {
 			_ = Map(src, &dst)
 		}
 	})
+	b.Run("struct->struct#large", func(b *testing.B) {
+		type Src struct {
+			A int
+			B uint
+			C float64
+			D float64
+		}
+		type Dst struct {
+			A string
+			B string
+			C string
+			D string
+		}
+		src := Src{
+			A: -123456789,
+			B: 123456789,
+			C: 3.14159,
+			D: 1e21,
+		}
+		dst := Dst{}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_ = Map(src, &dst)
+		}
+	})
 	b.Run("struct->map", func(b *testing.B) {
 		type Src struct {
 			A int
@@ -252,6 +957,67 @@ func Benchmark(b *testing.B) {
 			_ = Map(src, &dst)
 		}
 	})
+	b.Run("struct->struct#parallel", func(b *testing.B) {
+		type Src struct {
+			A int
+			B int
+		}
+		type Dst struct {
+			A string
+			B string
+		}
+		src := Src{A: 1, B: 2}
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			var dst Dst
+			for pb.Next() {
+				_ = Map(src, &dst)
+			}
+		})
+	})
+}
+
+func TestMapper_InvalidateCache(t *testing.T) {
+	type custom int
+	m := Default.Copy()
+	var dst custom
+	require.NoError(t, m.Map("1", &dst))
+	assert.Equal(t, custom(1), dst)
+
+	m.Mappers[reflect.TypeOf(custom(0))] = func(m *Mapper, src, dst reflect.Type) MapFunc {
+		return func(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+			dst.SetInt(42)
+			return nil
+		}
+	}
+	require.NoError(t, m.Map("1", &dst))
+	assert.Equal(t, custom(1), dst)
+
+	m.InvalidateCache()
+	require.NoError(t, m.Map("1", &dst))
+	assert.Equal(t, custom(42), dst)
+
+	delete(m.Mappers, reflect.TypeOf(custom(0)))
+	m.InvalidateCache()
+	require.NoError(t, m.Map("2", &dst))
+	assert.Equal(t, custom(2), dst)
+}
+
+func TestMapper_Concurrent(t *testing.T) {
+	m := Default.Copy()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				var dst string
+				assert.NoError(t, m.Map(i*j, &dst))
+				assert.Equal(t, strconv.Itoa(i*j), dst)
+			}
+		}(i)
+	}
+	wg.Wait()
 }
 
 func ptr(v any) any {
@@ -282,3 +1048,130 @@ func dst(v any) any {
 func anySlice() any {
 	return []any{}
 }
+
+func TestMapper_Copy(t *testing.T) {
+	m := New()
+	m.Context = &Context{
+		StrictTypes:         true,
+		StrictKinds:         true,
+		StrictExceptions:    []TypePair{{Src: reflect.TypeOf(""), Dst: reflect.TypeOf(0)}},
+		Lossless:            true,
+		Tag:                 "json",
+		ByteOrder:           binary.LittleEndian,
+		DisableCache:        true,
+		FieldMapper:         strings.ToLower,
+		BestEffort:          true,
+		SkipSecrets:         true,
+		OmitEmpty:           true,
+		FlattenSeparator:    ".",
+		NormalizeAny:        true,
+		MinimalBytes:        true,
+		NumberCodec:         VarintCodec,
+		Bits:                true,
+		BitOrder:            LSBFirst,
+		TextBytes:           true,
+		TrimStrings:         true,
+		Suffixes:            BinarySuffixes,
+		InputTransforms:     []StringTransform{nil},
+		OutputTransforms:    []StringTransform{nil},
+		SortMapKeys:         true,
+		SyncMaps:            true,
+		NoOverwrite:         true,
+		SkipZeroSource:      true,
+		NilElements:         NilZero,
+		Fields:              []string{"A"},
+		ExcludeFields:       []string{"B"},
+		TagPaths:            true,
+		CaseInsensitiveKeys: true,
+		Renames:             map[string]string{"A": "a"},
+		FieldConverters:     map[string]string{"A": "conv"},
+		StringEncoding:      EncodingHex,
+		FlagValues:          true,
+		TextMarshalers:      true,
+		JSONMarshalers:      true,
+		Stringers:           true,
+		TimeLayout:          DateOnly,
+		ValueSnapshotLen:    32,
+		BigFloatPrec:        128,
+		PreserveIdentity:    true,
+		IdenticalLayouts:    true,
+		MaxLength:           10,
+		MaxMapSize:          20,
+		MaxElements:         30,
+		MaxIndex:            40,
+		Mappers:             map[reflect.Type]MapFuncProvider{timeTy: nil},
+		Hooks:               &Hooks{},
+		Custom:              42,
+	}
+	m.Hooks = Hooks{
+		MapFuncHook:          func(m *Mapper, src, dst reflect.Type) MapFunc { return nil },
+		SourceValueHook:      func(v reflect.Value) reflect.Value { return v },
+		DestinationValueHook: func(v reflect.Value) reflect.Value { return v },
+		MissingFieldHook: func(m *Mapper, ctx *Context, field reflect.StructField, key string, dst reflect.Value) error {
+			return nil
+		},
+		UnmappedKeyHook: func(m *Mapper, ctx *Context, key string, src reflect.Value) error {
+			return nil
+		},
+		KeyHook: func(m *Mapper, ctx *Context, field reflect.StructField, key string, val reflect.Value) string {
+			return key
+		},
+	}
+	m.Use(func(next MapFunc) MapFunc { return next })
+	cpy := m.Copy()
+
+	// Every exported field must be set above, so the test fails if a new
+	// field is added but not copied.
+	assertFieldsCopied(t, reflect.ValueOf(*m.Context), reflect.ValueOf(*cpy.Context))
+	assertFieldsCopied(t, reflect.ValueOf(m.Hooks), reflect.ValueOf(cpy.Hooks))
+	assert.NotSame(t, m.Context, cpy.Context)
+	assert.Equal(t, len(m.middlewares), len(cpy.middlewares))
+	assert.Equal(t, len(m.Mappers), len(cpy.Mappers))
+	assert.Equal(t, m.Encodings, cpy.Encodings)
+
+	// Modifying the copy must not affect the original.
+	cpy.Context.Tag = "other"
+	delete(cpy.Mappers, reflect.TypeOf(time.Time{}))
+	delete(cpy.Encodings, EncodingHex)
+	assert.Equal(t, "json", m.Context.Tag)
+	assert.Contains(t, m.Mappers, reflect.TypeOf(time.Time{}))
+	assert.Contains(t, m.Encodings, EncodingHex)
+}
+
+func assertFieldsCopied(t *testing.T, src, cpy reflect.Value) {
+	for i := 0; i < src.NumField(); i++ {
+		fld := src.Type().Field(i)
+		if !fld.IsExported() {
+			continue
+		}
+		require.False(t, src.Field(i).IsZero(), "field %s is not set in the test", fld.Name)
+		if fld.Type.Kind() == reflect.Func {
+			assert.Equal(t, src.Field(i).Pointer(), cpy.Field(i).Pointer(), "field %s is not copied", fld.Name)
+			continue
+		}
+		assert.Equal(t, src.Field(i).Interface(), cpy.Field(i).Interface(), "field %s is not copied", fld.Name)
+	}
+}
+
+func TestMapper_CopySharingCache(t *testing.T) {
+	m := Default.Copy()
+	var dst int
+	require.NoError(t, m.Map("1", &dst))
+
+	cpy := m.CopySharingCache()
+	assert.Same(t, m.cache, cpy.cache)
+	_, ok := cpy.cache.m.Load(typePair{src: reflect.TypeOf(""), dst: reflect.TypeOf(0)})
+	assert.True(t, ok)
+
+	// The copy may use a different context.
+	cpy.Context = cpy.Context.WithStrictTypes(true)
+	assert.Error(t, cpy.Map("1", &dst))
+	require.NoError(t, m.Map("2", &dst))
+	assert.Equal(t, 2, dst)
+
+	// Use detaches the copy from the shared cache.
+	cpy.Use(func(next MapFunc) MapFunc { return next })
+	assert.NotSame(t, m.cache, cpy.cache)
+	_, ok = m.cache.m.Load(typePair{src: reflect.TypeOf(""), dst: reflect.TypeOf(0)})
+	assert.True(t, ok)
+}
@@ -0,0 +1,133 @@
+// Package maprules configures mappers using rules loaded at runtime, for
+// example from a JSON document, so field mappings can be changed without
+// recompiling the program.
+//
+// A Config describes renames of struct fields, the fields to map or to
+// exclude, named converters applied to fields and default values of fields
+// missing in the source. Fields are referenced by their Go names, except
+// for the Fields and Exclude lists, which use the field paths described in
+// anymapper.Context.Fields. The rules are applied to a copy of a mapper, so
+// many configurations, e.g. one per customer, can be derived from a single
+// base mapper.
+//
+// The Config type also has yaml tags, so it can be decoded from YAML
+// documents using a YAML package.
+package maprules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/defiweb/go-anymapper"
+)
+
+// Config is a set of mapping rules.
+type Config struct {
+	// Tag is the name of the struct tag used by the mapper. If empty, the
+	// tag of the mapper is not changed.
+	Tag string `json:"tag,omitempty" yaml:"tag,omitempty"`
+
+	// Renames maps struct field names to the keys used for them. See
+	// anymapper.Context.Renames.
+	Renames map[string]string `json:"renames,omitempty" yaml:"renames,omitempty"`
+
+	// Fields lists the paths of the only fields that are mapped. See
+	// anymapper.Context.Fields.
+	Fields []string `json:"fields,omitempty" yaml:"fields,omitempty"`
+
+	// Exclude lists the paths of the fields that are not mapped. See
+	// anymapper.Context.ExcludeFields.
+	Exclude []string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+
+	// Converters maps struct field names to the names of converters
+	// registered in the mapper. See anymapper.Context.FieldConverters.
+	Converters map[string]string `json:"converters,omitempty" yaml:"converters,omitempty"`
+
+	// Defaults maps struct field names to the values assigned to them if
+	// the source does not have a value for the field. Values are mapped to
+	// the fields using the usual mapping rules.
+	Defaults map[string]any `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+}
+
+// Parse parses the rules from a JSON document. Unknown fields are rejected,
+// so typos in the document are reported. Numbers in default values are
+// decoded as json.Number, so they are not rounded.
+func Parse(data []byte) (*Config, error) {
+	return Load(bytes.NewReader(data))
+}
+
+// Load reads the rules from a JSON document. See Parse for details.
+func Load(r io.Reader) (*Config, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	dec.DisallowUnknownFields()
+	var c Config
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("maprules: %w", err)
+	}
+	return &c, nil
+}
+
+// Apply returns a copy of the mapper configured using the rules. The
+// original mapper is not modified. Converters must be registered in the
+// mapper before Apply is called.
+//
+// If the mapper has a MissingFieldHook, it is still called for fields
+// that do not have a default value.
+func (c *Config) Apply(m *anymapper.Mapper) (*anymapper.Mapper, error) {
+	for field, name := range c.Converters {
+		if _, ok := m.Converters[name]; !ok {
+			return nil, fmt.Errorf("maprules: unknown converter %q for field %q", name, field)
+		}
+	}
+	cpy := m.Copy()
+	ctx := cpy.Context
+	if c.Tag != "" {
+		ctx.Tag = c.Tag
+	}
+	if len(c.Renames) > 0 {
+		ctx.Renames = merge(ctx.Renames, c.Renames)
+	}
+	if len(c.Fields) > 0 {
+		ctx.Fields = append(append([]string(nil), ctx.Fields...), c.Fields...)
+	}
+	if len(c.Exclude) > 0 {
+		ctx.ExcludeFields = append(append([]string(nil), ctx.ExcludeFields...), c.Exclude...)
+	}
+	if len(c.Converters) > 0 {
+		ctx.FieldConverters = merge(ctx.FieldConverters, c.Converters)
+	}
+	if len(c.Defaults) > 0 {
+		defaults := c.Defaults
+		next := cpy.Hooks.MissingFieldHook
+		cpy.Hooks.MissingFieldHook = func(m *anymapper.Mapper, ctx *anymapper.Context, field reflect.StructField, key string, dst reflect.Value) error {
+			if def, ok := defaults[field.Name]; ok {
+				if def == nil {
+					return nil
+				}
+				return m.MapReflContext(ctx, reflect.ValueOf(def), dst)
+			}
+			if next != nil {
+				return next(m, ctx, field, key, dst)
+			}
+			return nil
+		}
+	}
+	return cpy, nil
+}
+
+// merge returns a new map with the entries of both maps. Entries of b take
+// precedence.
+func merge(a, b map[string]string) map[string]string {
+	r := make(map[string]string, len(a)+len(b))
+	for k, v := range a {
+		r[k] = v
+	}
+	for k, v := range b {
+		r[k] = v
+	}
+	return r
+}
@@ -0,0 +1,91 @@
+package maprules
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defiweb/go-anymapper"
+)
+
+type customer struct {
+	ID      int
+	Name    string
+	Country string
+	Email   string
+	Secret  string
+}
+
+func TestApply(t *testing.T) {
+	base := anymapper.New()
+	base.RegisterNamedConverter("upper", func(src any) (any, error) {
+		return strings.ToUpper(src.(string)), nil
+	})
+	cfg, err := Parse([]byte(`{
+		"renames": {"ID": "customer_id", "Name": "full_name"},
+		"exclude": ["Secret"],
+		"converters": {"Country": "upper"},
+		"defaults": {"Email": "unknown@example.com", "ID": 9007199254740993}
+	}`))
+	require.NoError(t, err)
+	m, err := cfg.Apply(base)
+	require.NoError(t, err)
+
+	t.Run("decode", func(t *testing.T) {
+		var dst customer
+		src := map[string]any{"customer_id": 1, "full_name": "foo", "Country": "pl", "Secret": "x"}
+		require.NoError(t, m.Map(src, &dst))
+		assert.Equal(t, customer{ID: 1, Name: "foo", Country: "PL", Email: "unknown@example.com"}, dst)
+	})
+	t.Run("defaults", func(t *testing.T) {
+		var dst customer
+		require.NoError(t, m.Map(map[string]any{}, &dst))
+		assert.Equal(t, 9007199254740993, dst.ID)
+		assert.Equal(t, "unknown@example.com", dst.Email)
+	})
+	t.Run("encode", func(t *testing.T) {
+		var dst map[string]any
+		require.NoError(t, m.Map(customer{ID: 1, Name: "foo", Country: "pl", Secret: "x"}, &dst))
+		assert.Equal(t, map[string]any{"customer_id": 1, "full_name": "foo", "Country": "PL", "Email": ""}, dst)
+	})
+	t.Run("base-unchanged", func(t *testing.T) {
+		var dst customer
+		require.NoError(t, base.Map(map[string]any{"Secret": "x"}, &dst))
+		assert.Equal(t, customer{Secret: "x"}, dst)
+	})
+}
+
+func TestApply_MissingFieldHook(t *testing.T) {
+	base := anymapper.New()
+	missing := errors.New("missing")
+	base.Hooks.MissingFieldHook = func(m *anymapper.Mapper, ctx *anymapper.Context, field reflect.StructField, key string, dst reflect.Value) error {
+		return missing
+	}
+	cfg := &Config{Defaults: map[string]any{"Email": "foo"}}
+	m, err := cfg.Apply(base)
+	require.NoError(t, err)
+	var dst struct{ Email string }
+	require.NoError(t, m.Map(map[string]any{}, &dst))
+	assert.Equal(t, "foo", dst.Email)
+	var dst2 struct{ Name string }
+	assert.ErrorIs(t, m.Map(map[string]any{}, &dst2), missing)
+}
+
+func TestApply_UnknownConverter(t *testing.T) {
+	cfg := &Config{Converters: map[string]string{"Name": "unknown"}}
+	_, err := cfg.Apply(anymapper.New())
+	assert.Error(t, err)
+}
+
+func TestLoad(t *testing.T) {
+	cfg, err := Load(strings.NewReader(`{"tag": "json", "fields": ["A", "B.C"]}`))
+	require.NoError(t, err)
+	assert.Equal(t, &Config{Tag: "json", Fields: []string{"A", "B.C"}}, cfg)
+
+	_, err = Parse([]byte(`{"renamed": {}}`))
+	assert.Error(t, err)
+}
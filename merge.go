@@ -0,0 +1,49 @@
+package anymapper
+
+import "reflect"
+
+// keepsValue reports whether the destination struct field or map value
+// must not be overwritten, because Context.NoOverwrite is enabled and the
+// value is not zero. Structs and maps are merged instead, so they are
+// never kept as a whole, unless their types have mapper providers.
+func (m *Mapper) keepsValue(ctx *Context, dst reflect.Value) bool {
+	if !ctx.NoOverwrite || !dst.IsValid() {
+		return false
+	}
+	for dst.Kind() == reflect.Pointer || dst.Kind() == reflect.Interface {
+		if dst.IsNil() {
+			return false
+		}
+		dst = dst.Elem()
+	}
+	switch dst.Kind() {
+	case reflect.Struct, reflect.Map:
+		if _, ok := m.provider(ctx, dst.Type()); !ok {
+			return false
+		}
+	}
+	return !dst.IsZero()
+}
+
+// mapSimpleMap maps maps of simple types directly, unless
+// Context.NoOverwrite or Context.SkipZeroSource is enabled, in which case
+// the source map is merged into the destination map.
+func mapSimpleMap(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if (ctx.NoOverwrite || ctx.SkipZeroSource) && !dst.IsNil() {
+		return mapMapToMap(m, ctx, src, dst)
+	}
+	return mapDirect(m, ctx, src, dst)
+}
+
+// skipsValue reports whether the source struct field or map value must be
+// skipped, because Context.SkipZeroSource is enabled and the value, or the
+// value of the interface, is zero.
+func skipsValue(ctx *Context, src reflect.Value) bool {
+	if !ctx.SkipZeroSource || !src.IsValid() {
+		return false
+	}
+	for src.Kind() == reflect.Interface && !src.IsNil() {
+		src = src.Elem()
+	}
+	return src.IsZero()
+}
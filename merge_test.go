@@ -0,0 +1,139 @@
+package anymapper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoOverwrite(t *testing.T) {
+	type DB struct {
+		Host string
+		Port int
+	}
+	type Config struct {
+		Name    string
+		Debug   bool
+		Tags    []string
+		Created time.Time
+		DB      DB
+		Cache   *DB
+		Labels  map[string]string
+	}
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	ctx := Default.Context.WithNoOverwrite(true)
+
+	t.Run("struct", func(t *testing.T) {
+		dst := Config{Name: "app", DB: DB{Port: 5432}, Cache: &DB{Host: "cache"}, Labels: map[string]string{"env": "dev"}}
+		src := Config{
+			Name:    "other",
+			Debug:   true,
+			Tags:    []string{"a"},
+			Created: created,
+			DB:      DB{Host: "db", Port: 3306},
+			Cache:   &DB{Host: "other", Port: 6379},
+			Labels:  map[string]string{"env": "prod", "team": "x"},
+		}
+		require.NoError(t, MapContext(ctx, src, &dst))
+		assert.Equal(t, Config{
+			Name:    "app",
+			Debug:   true,
+			Tags:    []string{"a"},
+			Created: created,
+			DB:      DB{Host: "db", Port: 5432},
+			Cache:   &DB{Host: "cache", Port: 6379},
+			Labels:  map[string]string{"env": "dev", "team": "x"},
+		}, dst)
+	})
+	t.Run("provider-types", func(t *testing.T) {
+		dst := Config{Created: created}
+		src := map[string]any{"Created": "2021-01-01T00:00:00Z"}
+		require.NoError(t, MapContext(ctx, src, &dst))
+		assert.Equal(t, created, dst.Created)
+	})
+	t.Run("map-to-struct", func(t *testing.T) {
+		dst := Config{Name: "app"}
+		src := map[string]any{"Name": "other", "Debug": true, "DB": map[string]any{"Host": "db"}}
+		require.NoError(t, MapContext(ctx, src, &dst))
+		assert.Equal(t, Config{Name: "app", Debug: true, DB: DB{Host: "db"}}, dst)
+	})
+	t.Run("struct-to-map", func(t *testing.T) {
+		dst := map[string]any{"Host": "db"}
+		require.NoError(t, MapContext(ctx, DB{Host: "other", Port: 1}, &dst))
+		assert.Equal(t, map[string]any{"Host": "db", "Port": 1}, dst)
+	})
+	t.Run("map-to-map", func(t *testing.T) {
+		dst := map[string]int{"a": 1, "b": 0}
+		require.NoError(t, MapContext(ctx, map[string]int{"a": 2, "b": 2, "c": 2}, &dst))
+		assert.Equal(t, map[string]int{"a": 1, "b": 2, "c": 2}, dst)
+	})
+	t.Run("default-tag", func(t *testing.T) {
+		var dst struct {
+			Port int `map:"port,default=8080"`
+		}
+		dst.Port = 9090
+		require.NoError(t, MapContext(ctx, map[string]any{}, &dst))
+		assert.Equal(t, 9090, dst.Port)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		dst := Config{Name: "app"}
+		require.NoError(t, Map(Config{Name: "other"}, &dst))
+		assert.Equal(t, "other", dst.Name)
+	})
+}
+
+func TestSkipZeroSource(t *testing.T) {
+	type Address struct {
+		City string
+		Zip  string
+	}
+	type User struct {
+		Name    string
+		Age     int
+		Admin   *bool
+		Tags    []string
+		Address Address
+		Extra   map[string]int
+	}
+	ctx := Default.Context.WithSkipZeroSource(true)
+	admin := false
+
+	t.Run("struct", func(t *testing.T) {
+		dst := User{Name: "foo", Age: 30, Tags: []string{"a"}, Address: Address{City: "x", Zip: "1"}}
+		src := User{Age: 31, Admin: &admin, Address: Address{Zip: "2"}}
+		require.NoError(t, MapContext(ctx, src, &dst))
+		assert.Equal(t, User{Name: "foo", Age: 31, Admin: &admin, Tags: []string{"a"}, Address: Address{City: "x", Zip: "2"}}, dst)
+	})
+	t.Run("different-types", func(t *testing.T) {
+		type Patch struct {
+			Name string
+			Age  int
+		}
+		dst := User{Name: "foo", Age: 30}
+		require.NoError(t, MapContext(ctx, Patch{Name: "bar"}, &dst))
+		assert.Equal(t, User{Name: "bar", Age: 30}, dst)
+	})
+	t.Run("map-to-struct", func(t *testing.T) {
+		dst := User{Name: "foo", Age: 30}
+		require.NoError(t, MapContext(ctx, map[string]any{"Name": "", "Age": 0, "Tags": []string{"b"}}, &dst))
+		assert.Equal(t, User{Name: "foo", Age: 30, Tags: []string{"b"}}, dst)
+	})
+	t.Run("struct-to-map", func(t *testing.T) {
+		dst := map[string]any{"City": "x"}
+		c := ctx.WithSyncMaps(true)
+		require.NoError(t, MapContext(c, Address{Zip: "1"}, &dst))
+		assert.Equal(t, map[string]any{"City": "x", "Zip": "1"}, dst)
+	})
+	t.Run("map-to-map", func(t *testing.T) {
+		dst := map[string]int{"a": 1, "b": 1}
+		require.NoError(t, MapContext(ctx, map[string]int{"a": 0, "b": 2}, &dst))
+		assert.Equal(t, map[string]int{"a": 1, "b": 2}, dst)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		dst := User{Name: "foo"}
+		require.NoError(t, Map(User{Age: 1}, &dst))
+		assert.Equal(t, User{Age: 1}, dst)
+	})
+}
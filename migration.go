@@ -0,0 +1,81 @@
+package anymapper
+
+import "reflect"
+
+// Migration describes the migration of a versioned struct type to its next
+// version. Migrations are registered using Mapper.AddMigration.
+type Migration struct {
+	// To is the next version of the type.
+	To reflect.Type
+
+	// Func migrates a value to the next version. If nil, the value is
+	// mapped using the usual mapping rules.
+	Func MapFunc
+}
+
+// AddMigration registers the migration of the from type to its next
+// version, the to type. Pointer types are dereferenced. Every type may have
+// only one next version, registering another migration for the same type
+// replaces the previous one.
+//
+// Migrations form chains, like V1 → V2 → V3. When a value of a type is
+// mapped to any later version of the type, it is migrated through all
+// versions between them, so only migrations between consecutive versions
+// have to be written. If fn is nil, the value is mapped to the next version
+// using the usual mapping rules.
+//
+// Migrations must be registered before the mapper is used, the method is
+// not safe for concurrent use with the mapping methods.
+func (m *Mapper) AddMigration(from, to reflect.Type, fn MapFunc) {
+	for from.Kind() == reflect.Pointer {
+		from = from.Elem()
+	}
+	for to.Kind() == reflect.Pointer {
+		to = to.Elem()
+	}
+	if m.Migrations == nil {
+		m.Migrations = make(map[reflect.Type]Migration)
+	}
+	m.Migrations[from] = Migration{To: to, Func: fn}
+}
+
+// migrationFor returns a MapFunc that migrates values of the src type to
+// the dst type, or nil if dst is not a later version of src.
+func (m *Mapper) migrationFor(ctx *Context, src, dst reflect.Type) MapFunc {
+	if len(m.Migrations) == 0 || src == dst {
+		return nil
+	}
+	var steps []MapFunc
+	var types []reflect.Type
+	visited := map[reflect.Type]bool{}
+	for typ := src; typ != dst; {
+		mig, ok := m.Migrations[typ]
+		if !ok || visited[typ] {
+			return nil
+		}
+		visited[typ] = true
+		fn := mig.Func
+		if fn == nil {
+			if fn = m.mapFuncFor(ctx, typ, mig.To); fn == nil {
+				return nil
+			}
+		}
+		steps = append(steps, fn)
+		types = append(types, mig.To)
+		typ = mig.To
+	}
+	return func(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+		cur := src
+		for i, step := range steps {
+			next := dst
+			if i < len(steps)-1 {
+				next = reflect.New(types[i]).Elem()
+			}
+			if err := step(m, ctx, cur, next); err != nil {
+				return err
+			}
+			cur = next
+		}
+		return nil
+	}
+}
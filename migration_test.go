@@ -0,0 +1,72 @@
+package anymapper
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrations(t *testing.T) {
+	type configV1 struct {
+		Name string
+		Port string
+	}
+	type configV2 struct {
+		Name string
+		Port int
+	}
+	type configV3 struct {
+		Name    string
+		Port    int
+		Address string
+	}
+	v1, v2, v3 := reflect.TypeOf(configV1{}), reflect.TypeOf(configV2{}), reflect.TypeOf(configV3{})
+
+	m := New()
+	m.AddMigration(v1, v2, nil)
+	m.AddMigration(v2, reflect.PointerTo(v3), func(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+		s := src.Interface().(configV2)
+		if s.Port == 0 {
+			return errors.New("missing port")
+		}
+		dst.Set(reflect.ValueOf(configV3{
+			Name:    strings.ToLower(s.Name),
+			Port:    s.Port,
+			Address: "localhost",
+		}))
+		return nil
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		var dst configV3
+		require.NoError(t, m.Map(configV1{Name: "Foo", Port: "80"}, &dst))
+		assert.Equal(t, configV3{Name: "foo", Port: 80, Address: "localhost"}, dst)
+	})
+	t.Run("intermediate", func(t *testing.T) {
+		var dst configV2
+		require.NoError(t, m.Map(&configV1{Name: "Foo", Port: "80"}, &dst))
+		assert.Equal(t, configV2{Name: "Foo", Port: 80}, dst)
+	})
+	t.Run("error", func(t *testing.T) {
+		var dst configV3
+		assert.Error(t, m.Map(configV1{Name: "Foo", Port: "x"}, &dst))
+		assert.EqualError(t, m.Map(configV1{Name: "Foo", Port: "0"}, &dst), "missing port")
+	})
+	t.Run("backwards", func(t *testing.T) {
+		// Values are not migrated backwards, the usual rules are used.
+		var dst configV1
+		require.NoError(t, m.Map(configV3{Name: "foo", Port: 80}, &dst))
+		assert.Equal(t, configV1{Name: "foo", Port: "80"}, dst)
+	})
+	t.Run("cycle", func(t *testing.T) {
+		cpy := m.Copy()
+		cpy.AddMigration(v3, v1, nil)
+		var dst map[string]any
+		require.NoError(t, cpy.Map(configV1{Name: "foo"}, &dst))
+		assert.Len(t, m.Migrations, 2)
+	})
+}
@@ -0,0 +1,61 @@
+package anymapper
+
+import "reflect"
+
+// NilPolicy defines how nil elements of source slices, arrays and maps,
+// e.g. nil values in []any or map[string]any, are mapped.
+type NilPolicy int
+
+const (
+	// NilDefault fails the mapping of nil slice, array and map elements with
+	// InvalidSrcErr. Nil map values mapped to struct fields are skipped.
+	NilDefault NilPolicy = iota
+
+	// NilSkip skips nil elements. Destination elements are left unchanged
+	// and no entries are added to destination maps.
+	NilSkip
+
+	// NilZero sets destination elements to their zero values. Nil
+	// destination pointers are initialized to point to a zero value, and
+	// values pointed to by existing pointers are set to zero.
+	NilZero
+
+	// NilAsNil sets destination pointers, interfaces, maps and slices to
+	// nil, and other destination elements to their zero values.
+	NilAsNil
+)
+
+// mapNil applies Context.NilElements to the destination of a nil source
+// element. It reports whether the element was handled. If not, the element
+// must be mapped as usual.
+func (m *Mapper) mapNil(ctx *Context, dst reflect.Value) bool {
+	switch ctx.NilElements {
+	case NilSkip:
+	case NilZero:
+		if v := m.dstValue(ctx, dst); v.IsValid() && v.CanSet() {
+			v.Set(reflect.Zero(v.Type()))
+		}
+	case NilAsNil:
+		if dst.CanSet() {
+			dst.Set(reflect.Zero(dst.Type()))
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// mapNilEntry applies Context.NilElements to the destination map entry of
+// a nil source element. It reports whether the entry was handled.
+func (m *Mapper) mapNilEntry(ctx *Context, dst, key reflect.Value) bool {
+	switch ctx.NilElements {
+	case NilDefault:
+		return false
+	case NilSkip:
+		return true
+	}
+	val := reflect.New(dst.Type().Elem()).Elem()
+	m.mapNil(ctx, val)
+	dst.SetMapIndex(key, val)
+	return true
+}
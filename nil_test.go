@@ -0,0 +1,66 @@
+package anymapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNilElements(t *testing.T) {
+	type Item struct {
+		A *int
+		B int
+	}
+	one := 1
+	t.Run("default", func(t *testing.T) {
+		var dst []int
+		assert.ErrorIs(t, Map([]any{1, nil}, &dst), InvalidSrcErr)
+		var item Item
+		require.NoError(t, Map(map[string]any{"A": nil, "B": 2}, &item))
+		assert.Equal(t, Item{B: 2}, item)
+	})
+	t.Run("skip", func(t *testing.T) {
+		ctx := Default.Context.WithNilElements(NilSkip)
+		dst := []int{5, 6, 7}
+		require.NoError(t, MapContext(ctx, []any{1, nil, 3}, &dst))
+		assert.Equal(t, []int{1, 6, 3}, dst)
+		m := map[string]int{"b": 2}
+		require.NoError(t, MapContext(ctx, map[string]any{"a": nil, "b": nil, "c": 3}, &m))
+		assert.Equal(t, map[string]int{"b": 2, "c": 3}, m)
+		item := Item{A: &one, B: 2}
+		require.NoError(t, MapContext(ctx, map[string]any{"A": nil, "B": nil}, &item))
+		assert.Equal(t, Item{A: &one, B: 2}, item)
+	})
+	t.Run("zero", func(t *testing.T) {
+		ctx := Default.Context.WithNilElements(NilZero)
+		dst := []int{5, 6}
+		require.NoError(t, MapContext(ctx, []any{1, nil}, &dst))
+		assert.Equal(t, []int{1, 0}, dst)
+		var ptrs []*int
+		require.NoError(t, MapContext(ctx, []any{nil}, &ptrs))
+		require.Len(t, ptrs, 1)
+		assert.Equal(t, 0, *ptrs[0])
+		m := map[string]int{"b": 2}
+		require.NoError(t, MapContext(ctx, map[string]any{"a": nil, "b": nil}, &m))
+		assert.Equal(t, map[string]int{"a": 0, "b": 0}, m)
+		two := 2
+		item := Item{A: &two, B: 2}
+		require.NoError(t, MapContext(ctx, map[string]any{"A": nil, "B": nil}, &item))
+		assert.Same(t, &two, item.A)
+		assert.Equal(t, Item{A: new(int)}, item)
+	})
+	t.Run("nil", func(t *testing.T) {
+		ctx := Default.Context.WithNilElements(NilAsNil)
+		ptrs := []*int{&one, &one}
+		require.NoError(t, MapContext(ctx, []any{nil, 2}, &ptrs))
+		assert.Nil(t, ptrs[0])
+		assert.Equal(t, 2, *ptrs[1])
+		m := map[string]*int{"a": &one}
+		require.NoError(t, MapContext(ctx, map[string]any{"a": nil, "b": nil}, &m))
+		assert.Equal(t, map[string]*int{"a": nil, "b": nil}, m)
+		item := Item{A: &one, B: 2}
+		require.NoError(t, MapContext(ctx, map[string]any{"A": nil, "B": nil}, &item))
+		assert.Equal(t, Item{}, item)
+	})
+}
@@ -0,0 +1,115 @@
+package anymapper
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+)
+
+// mapNormalized maps src to dst, which is an empty interface, converting
+// src to plain built-in types. See Context.NormalizeAny.
+func (m *Mapper) mapNormalized(ctx *Context, src, dst reflect.Value) error {
+	v, err := m.normalize(ctx, src)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	dst.Set(reflect.ValueOf(v))
+	return nil
+}
+
+// normalize converts v to plain built-in types.
+func (m *Mapper) normalize(ctx *Context, v reflect.Value) (any, error) {
+	v = m.srcValue(ctx, v)
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if v.Type() == bigIntTy {
+		var n *big.Int
+		if err := m.mapNested(ctx, v, &n); err != nil {
+			return nil, err
+		}
+		return n, nil
+	}
+	if _, ok := m.Mappers[v.Type()]; ok {
+		var s string
+		if err := m.mapNested(ctx, v, &s); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n := v.Uint(); n > math.MaxInt64 {
+			return new(big.Int).SetUint64(n), nil
+		}
+		return int64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.Complex64, reflect.Complex128:
+		// Complex numbers cannot be encoded as JSON.
+		return strconv.FormatComplex(v.Complex(), 'g', -1, v.Type().Bits()), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			// Byte slices are kept, so they are encoded as base64 strings.
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return b, nil
+		}
+		res := make([]any, v.Len())
+		for i := range res {
+			n, err := m.normalize(ctx, v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			res[i] = n
+		}
+		return res, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		res := make(map[string]any, v.Len())
+		for _, key := range v.MapKeys() {
+			var k string
+			if err := m.mapNested(ctx, key, &k); err != nil {
+				return nil, err
+			}
+			n, err := m.normalize(ctx, v.MapIndex(key))
+			if err != nil {
+				return nil, err
+			}
+			res[k] = n
+		}
+		return res, nil
+	case reflect.Struct:
+		// Field values are mapped to the any type, so they are normalized
+		// by the mapper.
+		res := make(map[string]any)
+		if err := m.mapNested(ctx, v, &res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+	return v.Interface(), nil
+}
+
+// mapNested maps src to the value pointed to by dst as a part of the
+// current mapping call, so the state of the call is preserved.
+func (m *Mapper) mapNested(ctx *Context, src reflect.Value, dst any) error {
+	tm := &typeMapper{}
+	return m.mapValue(ctx, &tm, src, m.dstValue(ctx, reflect.ValueOf(dst)))
+}
@@ -0,0 +1,120 @@
+package anymapper
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeAny(t *testing.T) {
+	type status string
+	type address struct {
+		City string `map:"city"`
+	}
+	type user struct {
+		Name    string            `map:"name"`
+		Status  status            `map:"status"`
+		Age     uint8             `map:"age"`
+		Score   float32           `map:"score"`
+		Big     uint64            `map:"big"`
+		Balance *big.Int          `map:"balance"`
+		Created time.Time         `map:"created"`
+		Tags    []status          `map:"tags"`
+		Hash    [2]byte           `map:"hash"`
+		Addr    *address          `map:"addr"`
+		Extra   map[int]address   `map:"extra"`
+		Meta    map[string]any    `map:"meta"`
+		Nil     []any             `map:"nil"`
+		Labels  map[status]status `map:"labels"`
+	}
+	src := user{
+		Name:    "alice",
+		Status:  "active",
+		Age:     30,
+		Score:   1.5,
+		Big:     math.MaxUint64,
+		Balance: big.NewInt(100),
+		Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Tags:    []status{"a", "b"},
+		Hash:    [2]byte{1, 2},
+		Addr:    &address{City: "Paris"},
+		Extra:   map[int]address{1: {City: "Rome"}},
+		Meta:    map[string]any{"s": status("x")},
+		Nil:     []any{nil},
+		Labels:  map[status]status{"k": "v"},
+	}
+	expected := map[string]any{
+		"name":    "alice",
+		"status":  "active",
+		"age":     int64(30),
+		"score":   float64(1.5),
+		"big":     new(big.Int).SetUint64(math.MaxUint64),
+		"balance": big.NewInt(100),
+		"created": "2024-01-02T03:04:05Z",
+		"tags":    []any{"a", "b"},
+		"hash":    []byte{1, 2},
+		"addr":    map[string]any{"city": "Paris"},
+		"extra":   map[string]any{"1": map[string]any{"city": "Rome"}},
+		"meta":    map[string]any{"s": "x"},
+		"nil":     []any{nil},
+		"labels":  map[string]any{"k": "v"},
+	}
+
+	t.Run("any", func(t *testing.T) {
+		var dst any
+		require.NoError(t, MapContext(Default.Context.WithNormalizeAny(true), src, &dst))
+		assert.Equal(t, expected, dst)
+		_, err := json.Marshal(dst)
+		require.NoError(t, err)
+	})
+	t.Run("map", func(t *testing.T) {
+		var dst map[string]any
+		require.NoError(t, MapContext(Default.Context.WithNormalizeAny(true), src, &dst))
+		assert.Equal(t, expected, dst)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		// Without normalization, big.Int cannot be mapped to any.
+		src := src
+		src.Balance = nil
+		var dst map[string]any
+		require.NoError(t, Map(src, &dst))
+		assert.Equal(t, status("active"), dst["status"])
+		assert.Equal(t, *src.Addr, dst["addr"])
+		assert.Equal(t, src.Created.Unix(), dst["created"])
+	})
+}
+
+func TestNormalizeAnyValues(t *testing.T) {
+	ctx := Default.Context.WithNormalizeAny(true)
+	t.Run("complex", func(t *testing.T) {
+		var dst any
+		require.NoError(t, MapContext(ctx, []any{complex(1, 2)}, &dst))
+		assert.Equal(t, []any{"(1+2i)"}, dst)
+		_, err := json.Marshal(dst)
+		require.NoError(t, err)
+	})
+	t.Run("nil", func(t *testing.T) {
+		var dst any
+		require.NoError(t, MapContext(ctx, map[string]any{"s": []int(nil), "m": map[string]int(nil)}, &dst))
+		assert.Equal(t, map[string]any{"s": nil, "m": nil}, dst)
+	})
+	t.Run("fields", func(t *testing.T) {
+		type address struct {
+			City string `map:"city"`
+			Zip  string `map:"zip"`
+		}
+		type user struct {
+			Name string   `map:"name"`
+			Addr *address `map:"addr"`
+		}
+		var dst any
+		src := user{Name: "a", Addr: &address{City: "Paris", Zip: "75001"}}
+		require.NoError(t, MapContext(ctx.WithFields("addr.city"), src, &dst))
+		assert.Equal(t, map[string]any{"addr": map[string]any{"city": "Paris"}}, dst)
+	})
+}
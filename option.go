@@ -0,0 +1,398 @@
+package anymapper
+
+import (
+	"encoding/binary"
+	"reflect"
+)
+
+// Option modifies the context used for a single mapping operation.
+type Option func(ctx *Context)
+
+// WithContext returns an Option that replaces the whole context with a copy
+// of the given one. It should be used as the first option, because it
+// overrides all previous options.
+func WithContext(ctx *Context) Option {
+	return func(c *Context) {
+		*c = *ctx
+	}
+}
+
+// WithStrictTypes returns an Option that sets the Context.StrictTypes field.
+func WithStrictTypes(strictTypes bool) Option {
+	return func(c *Context) {
+		c.StrictTypes = strictTypes
+	}
+}
+
+// WithStrictKinds returns an Option that sets the Context.StrictKinds
+// field.
+func WithStrictKinds(strictKinds bool) Option {
+	return func(c *Context) {
+		c.StrictKinds = strictKinds
+	}
+}
+
+// WithStrictExceptions returns an Option that sets the
+// Context.StrictExceptions field.
+func WithStrictExceptions(pairs ...TypePair) Option {
+	return func(c *Context) {
+		c.StrictExceptions = pairs
+	}
+}
+
+// WithLossless returns an Option that sets the Context.Lossless field.
+func WithLossless(lossless bool) Option {
+	return func(c *Context) {
+		c.Lossless = lossless
+	}
+}
+
+// WithTag returns an Option that sets the Context.Tag field.
+func WithTag(tag string) Option {
+	return func(c *Context) {
+		c.Tag = tag
+	}
+}
+
+// WithByteOrder returns an Option that sets the Context.ByteOrder field.
+func WithByteOrder(byteOrder binary.ByteOrder) Option {
+	return func(c *Context) {
+		c.ByteOrder = byteOrder
+	}
+}
+
+// WithFieldMapper returns an Option that sets the Context.FieldMapper field.
+func WithFieldMapper(fieldMapper func(string) string) Option {
+	return func(c *Context) {
+		c.FieldMapper = fieldMapper
+	}
+}
+
+// WithBestEffort returns an Option that sets the Context.BestEffort field.
+func WithBestEffort(bestEffort bool) Option {
+	return func(c *Context) {
+		c.BestEffort = bestEffort
+	}
+}
+
+// WithSkipSecrets returns an Option that sets the Context.SkipSecrets field.
+func WithSkipSecrets(skipSecrets bool) Option {
+	return func(c *Context) {
+		c.SkipSecrets = skipSecrets
+	}
+}
+
+// WithOmitEmpty returns an Option that sets the Context.OmitEmpty field.
+func WithOmitEmpty(omitEmpty bool) Option {
+	return func(c *Context) {
+		c.OmitEmpty = omitEmpty
+	}
+}
+
+// WithFlattenSeparator returns an Option that sets the
+// Context.FlattenSeparator field.
+func WithFlattenSeparator(sep string) Option {
+	return func(c *Context) {
+		c.FlattenSeparator = sep
+	}
+}
+
+// WithNormalizeAny returns an Option that sets the Context.NormalizeAny
+// field.
+func WithNormalizeAny(normalizeAny bool) Option {
+	return func(c *Context) {
+		c.NormalizeAny = normalizeAny
+	}
+}
+
+// WithMinimalBytes returns an Option that sets the Context.MinimalBytes
+// field.
+func WithMinimalBytes(minimalBytes bool) Option {
+	return func(c *Context) {
+		c.MinimalBytes = minimalBytes
+	}
+}
+
+// WithNumberCodec returns an Option that sets the Context.NumberCodec field.
+func WithNumberCodec(codec NumberCodec) Option {
+	return func(c *Context) {
+		c.NumberCodec = codec
+	}
+}
+
+// WithBits returns an Option that sets the Context.Bits field.
+func WithBits(bits bool) Option {
+	return func(c *Context) {
+		c.Bits = bits
+	}
+}
+
+// WithBitOrder returns an Option that sets the Context.BitOrder field.
+func WithBitOrder(order BitOrder) Option {
+	return func(c *Context) {
+		c.BitOrder = order
+	}
+}
+
+// WithTextBytes returns an Option that sets the Context.TextBytes field.
+func WithTextBytes(textBytes bool) Option {
+	return func(c *Context) {
+		c.TextBytes = textBytes
+	}
+}
+
+// WithTrimStrings returns an Option that sets the Context.TrimStrings field.
+func WithTrimStrings(trimStrings bool) Option {
+	return func(c *Context) {
+		c.TrimStrings = trimStrings
+	}
+}
+
+// WithSuffixes returns an Option that sets the Context.Suffixes field.
+func WithSuffixes(suffixes Suffixes) Option {
+	return func(c *Context) {
+		c.Suffixes = suffixes
+	}
+}
+
+// WithInputTransforms returns an Option that sets the
+// Context.InputTransforms field.
+func WithInputTransforms(transforms ...StringTransform) Option {
+	return func(c *Context) {
+		c.InputTransforms = transforms
+	}
+}
+
+// WithOutputTransforms returns an Option that sets the
+// Context.OutputTransforms field.
+func WithOutputTransforms(transforms ...StringTransform) Option {
+	return func(c *Context) {
+		c.OutputTransforms = transforms
+	}
+}
+
+// WithSortMapKeys returns an Option that sets the Context.SortMapKeys
+// field.
+func WithSortMapKeys(sortMapKeys bool) Option {
+	return func(c *Context) {
+		c.SortMapKeys = sortMapKeys
+	}
+}
+
+// WithSyncMaps returns an Option that sets the Context.SyncMaps field.
+func WithSyncMaps(syncMaps bool) Option {
+	return func(c *Context) {
+		c.SyncMaps = syncMaps
+	}
+}
+
+// WithNoOverwrite returns an Option that sets the Context.NoOverwrite
+// field.
+func WithNoOverwrite(noOverwrite bool) Option {
+	return func(c *Context) {
+		c.NoOverwrite = noOverwrite
+	}
+}
+
+// WithSkipZeroSource returns an Option that sets the Context.SkipZeroSource
+// field.
+func WithSkipZeroSource(skipZeroSource bool) Option {
+	return func(c *Context) {
+		c.SkipZeroSource = skipZeroSource
+	}
+}
+
+// WithNilElements returns an Option that sets the Context.NilElements
+// field.
+func WithNilElements(policy NilPolicy) Option {
+	return func(c *Context) {
+		c.NilElements = policy
+	}
+}
+
+// WithFields returns an Option that sets the Context.Fields field.
+func WithFields(fields ...string) Option {
+	return func(c *Context) {
+		c.Fields = fields
+	}
+}
+
+// WithoutFields returns an Option that sets the Context.ExcludeFields
+// field.
+func WithoutFields(fields ...string) Option {
+	return func(c *Context) {
+		c.ExcludeFields = fields
+	}
+}
+
+// WithTagPaths returns an Option that sets the Context.TagPaths field.
+func WithTagPaths(tagPaths bool) Option {
+	return func(c *Context) {
+		c.TagPaths = tagPaths
+	}
+}
+
+// WithCaseInsensitiveKeys returns an Option that sets the
+// Context.CaseInsensitiveKeys field.
+func WithCaseInsensitiveKeys(caseInsensitiveKeys bool) Option {
+	return func(c *Context) {
+		c.CaseInsensitiveKeys = caseInsensitiveKeys
+	}
+}
+
+// WithRenames returns an Option that sets the Context.Renames field.
+func WithRenames(renames map[string]string) Option {
+	return func(c *Context) {
+		c.Renames = renames
+	}
+}
+
+// WithFieldConverters returns an Option that sets the
+// Context.FieldConverters field.
+func WithFieldConverters(converters map[string]string) Option {
+	return func(c *Context) {
+		c.FieldConverters = converters
+	}
+}
+
+// WithStringEncoding returns an Option that sets the
+// Context.StringEncoding field.
+func WithStringEncoding(name string) Option {
+	return func(c *Context) {
+		c.StringEncoding = name
+	}
+}
+
+// WithFlagValues returns an Option that sets the Context.FlagValues field.
+func WithFlagValues(flagValues bool) Option {
+	return func(c *Context) {
+		c.FlagValues = flagValues
+	}
+}
+
+// WithTextMarshalers returns an Option that sets the Context.TextMarshalers
+// field.
+func WithTextMarshalers(textMarshalers bool) Option {
+	return func(c *Context) {
+		c.TextMarshalers = textMarshalers
+	}
+}
+
+// WithJSONMarshalers returns an Option that sets the Context.JSONMarshalers
+// field.
+func WithJSONMarshalers(jsonMarshalers bool) Option {
+	return func(c *Context) {
+		c.JSONMarshalers = jsonMarshalers
+	}
+}
+
+// WithStringers returns an Option that sets the Context.Stringers field.
+func WithStringers(stringers bool) Option {
+	return func(c *Context) {
+		c.Stringers = stringers
+	}
+}
+
+// WithTimeLayout returns an Option that sets the Context.TimeLayout field.
+func WithTimeLayout(layout string) Option {
+	return func(c *Context) {
+		c.TimeLayout = layout
+	}
+}
+
+// WithValueSnapshotLen returns an Option that sets the
+// Context.ValueSnapshotLen field.
+func WithValueSnapshotLen(n int) Option {
+	return func(c *Context) {
+		c.ValueSnapshotLen = n
+	}
+}
+
+// WithBigFloatPrec returns an Option that sets the Context.BigFloatPrec
+// field.
+func WithBigFloatPrec(prec uint) Option {
+	return func(c *Context) {
+		c.BigFloatPrec = prec
+	}
+}
+
+// WithPreserveIdentity returns an Option that sets the
+// Context.PreserveIdentity field.
+func WithPreserveIdentity(preserveIdentity bool) Option {
+	return func(c *Context) {
+		c.PreserveIdentity = preserveIdentity
+	}
+}
+
+// WithIdenticalLayouts returns an Option that sets the
+// Context.IdenticalLayouts field.
+func WithIdenticalLayouts(identicalLayouts bool) Option {
+	return func(c *Context) {
+		c.IdenticalLayouts = identicalLayouts
+	}
+}
+
+// WithMaxLength returns an Option that sets the Context.MaxLength field.
+func WithMaxLength(maxLength int) Option {
+	return func(c *Context) {
+		c.MaxLength = maxLength
+	}
+}
+
+// WithMaxMapSize returns an Option that sets the Context.MaxMapSize field.
+func WithMaxMapSize(maxMapSize int) Option {
+	return func(c *Context) {
+		c.MaxMapSize = maxMapSize
+	}
+}
+
+// WithMaxElements returns an Option that sets the Context.MaxElements
+// field.
+func WithMaxElements(maxElements int) Option {
+	return func(c *Context) {
+		c.MaxElements = maxElements
+	}
+}
+
+// WithMaxIndex returns an Option that sets the Context.MaxIndex field.
+func WithMaxIndex(maxIndex int) Option {
+	return func(c *Context) {
+		c.MaxIndex = maxIndex
+	}
+}
+
+// WithMapper returns an Option that adds the provider to the
+// Context.Mappers field for the given type, or removes the type if the
+// provider is nil. See Context.WithMapper.
+func WithMapper(typ reflect.Type, provider MapFuncProvider) Option {
+	return func(c *Context) {
+		*c = *c.WithMapper(typ, provider)
+	}
+}
+
+// WithHooks returns an Option that sets the Context.Hooks field.
+func WithHooks(hooks *Hooks) Option {
+	return func(c *Context) {
+		c.Hooks = hooks
+	}
+}
+
+// WithCustom returns an Option that sets the Context.Custom field.
+func WithCustom(custom any) Option {
+	return func(c *Context) {
+		c.Custom = custom
+	}
+}
+
+// applyOptions returns a copy of the context with the given options applied.
+// If there are no options, the context is returned as is.
+func applyOptions(ctx *Context, opts []Option) *Context {
+	if len(opts) == 0 {
+		return ctx
+	}
+	cpy := *ctx
+	for _, opt := range opts {
+		opt(&cpy)
+	}
+	return &cpy
+}
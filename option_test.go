@@ -0,0 +1,135 @@
+package anymapper
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyOptions(t *testing.T) {
+	ctx := &Context{Tag: "map", ByteOrder: binary.BigEndian}
+	assert.Same(t, ctx, applyOptions(ctx, nil))
+	hooks := &Hooks{}
+
+	cpy := applyOptions(ctx, []Option{
+		WithStrictTypes(true),
+		WithStrictKinds(true),
+		WithStrictExceptions(TypePair{Src: reflect.TypeOf(""), Dst: reflect.TypeOf(0)}),
+		WithLossless(true),
+		WithTag("json"),
+		WithByteOrder(binary.LittleEndian),
+		WithBestEffort(true),
+		WithSkipSecrets(true),
+		WithOmitEmpty(true),
+		WithFlattenSeparator("."),
+		WithNormalizeAny(true),
+		WithMinimalBytes(true),
+		WithNumberCodec(VarintCodec),
+		WithBits(true),
+		WithBitOrder(LSBFirst),
+		WithTextBytes(true),
+		WithTrimStrings(true),
+		WithSuffixes(BinarySuffixes),
+		WithSortMapKeys(true),
+		WithSyncMaps(true),
+		WithNoOverwrite(true),
+		WithSkipZeroSource(true),
+		WithNilElements(NilZero),
+		WithFields("A", "B.C"),
+		WithoutFields("B.D"),
+		WithTagPaths(true),
+		WithCaseInsensitiveKeys(true),
+		WithRenames(map[string]string{"A": "a"}),
+		WithFieldConverters(map[string]string{"A": "conv"}),
+		WithStringEncoding("hex"),
+		WithFlagValues(true),
+		WithTextMarshalers(true),
+		WithJSONMarshalers(true),
+		WithStringers(true),
+		WithTimeLayout(DateOnly),
+		WithValueSnapshotLen(32),
+		WithBigFloatPrec(128),
+		WithPreserveIdentity(true),
+		WithIdenticalLayouts(true),
+		WithMaxLength(10),
+		WithMaxMapSize(20),
+		WithMaxElements(30),
+		WithMaxIndex(40),
+		WithHooks(hooks),
+		WithCustom(42),
+	})
+	assert.Equal(t, &Context{
+		StrictTypes:         true,
+		StrictKinds:         true,
+		StrictExceptions:    []TypePair{{Src: reflect.TypeOf(""), Dst: reflect.TypeOf(0)}},
+		Lossless:            true,
+		Tag:                 "json",
+		ByteOrder:           binary.LittleEndian,
+		BestEffort:          true,
+		SkipSecrets:         true,
+		OmitEmpty:           true,
+		FlattenSeparator:    ".",
+		NormalizeAny:        true,
+		MinimalBytes:        true,
+		NumberCodec:         VarintCodec,
+		Bits:                true,
+		BitOrder:            LSBFirst,
+		TextBytes:           true,
+		TrimStrings:         true,
+		Suffixes:            BinarySuffixes,
+		SortMapKeys:         true,
+		SyncMaps:            true,
+		NoOverwrite:         true,
+		SkipZeroSource:      true,
+		NilElements:         NilZero,
+		Fields:              []string{"A", "B.C"},
+		ExcludeFields:       []string{"B.D"},
+		TagPaths:            true,
+		CaseInsensitiveKeys: true,
+		Renames:             map[string]string{"A": "a"},
+		FieldConverters:     map[string]string{"A": "conv"},
+		StringEncoding:      "hex",
+		FlagValues:          true,
+		TextMarshalers:      true,
+		JSONMarshalers:      true,
+		Stringers:           true,
+		TimeLayout:          DateOnly,
+		ValueSnapshotLen:    32,
+		BigFloatPrec:        128,
+		PreserveIdentity:    true,
+		IdenticalLayouts:    true,
+		MaxLength:           10,
+		MaxMapSize:          20,
+		MaxElements:         30,
+		MaxIndex:            40,
+		Hooks:               hooks,
+		Custom:              42,
+	}, cpy)
+	assert.Equal(t, &Context{Tag: "map", ByteOrder: binary.BigEndian}, ctx)
+
+	other := &Context{Tag: "other"}
+	cpy = applyOptions(ctx, []Option{WithContext(other), WithStrictTypes(true)})
+	assert.Equal(t, &Context{Tag: "other", StrictTypes: true}, cpy)
+}
+
+func TestWithTransforms(t *testing.T) {
+	cpy := applyOptions(&Context{}, []Option{
+		WithInputTransforms(FoldCase, TrimControl),
+		WithOutputTransforms(RemoveControl),
+	})
+	assert.Len(t, cpy.InputTransforms, 2)
+	assert.Len(t, cpy.OutputTransforms, 1)
+}
+
+func TestWithMapper(t *testing.T) {
+	provider := func(m *Mapper, src, dst reflect.Type) MapFunc { return nil }
+	ctx := &Context{Mappers: map[reflect.Type]MapFuncProvider{timeTy: provider}}
+	cpy := applyOptions(ctx, []Option{WithMapper(bigIntTy, provider), WithMapper(timeTy, nil)})
+	assert.Len(t, cpy.Mappers, 1)
+	assert.Contains(t, cpy.Mappers, bigIntTy)
+	// The map of the original context is not modified.
+	assert.Len(t, ctx.Mappers, 1)
+	assert.Contains(t, ctx.Mappers, timeTy)
+}
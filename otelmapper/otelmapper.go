@@ -0,0 +1,145 @@
+// Package otelmapper provides OpenTelemetry instrumentation for the mapper.
+//
+// The Mapper type wraps anymapper.Mapper and records a span and metrics for
+// every top-level mapping call. Spans and metrics have the source and
+// destination types as attributes, and failed mappings are recorded as
+// span errors. Nested mappings of struct fields, slice elements and map
+// values are not recorded separately.
+//
+// The package is a separate module, so OpenTelemetry is not required by
+// the main module.
+package otelmapper
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/defiweb/go-anymapper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope name used for the tracer and the
+// meter.
+const ScopeName = "github.com/defiweb/go-anymapper/otelmapper"
+
+// Attribute keys used for spans and metrics.
+const (
+	SrcTypeKey = attribute.Key("anymapper.src.type")
+	DstTypeKey = attribute.Key("anymapper.dst.type")
+)
+
+// Option configures the Mapper created by New.
+type Option func(*config)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+// WithTracerProvider sets the tracer provider. The global provider is used
+// by default.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider sets the meter provider. The global provider is used
+// by default.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+// Mapper is an instrumented mapper. It is safe for concurrent use if the
+// wrapped mapper is.
+type Mapper struct {
+	mapper   *anymapper.Mapper
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	errors   metric.Int64Counter
+}
+
+// New returns an instrumented mapper that wraps the given mapper. If m is
+// nil, anymapper.Default is used.
+func New(m *anymapper.Mapper, opts ...Option) (*Mapper, error) {
+	cfg := config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if m == nil {
+		m = anymapper.Default
+	}
+	meter := cfg.meterProvider.Meter(ScopeName)
+	duration, err := meter.Float64Histogram(
+		"anymapper.map.duration",
+		metric.WithDescription("Duration of mapping calls."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	errs, err := meter.Int64Counter(
+		"anymapper.map.errors",
+		metric.WithDescription("Number of failed mapping calls."),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Mapper{
+		mapper:   m,
+		tracer:   cfg.tracerProvider.Tracer(ScopeName),
+		duration: duration,
+		errors:   errs,
+	}, nil
+}
+
+// Mapper returns the wrapped mapper.
+func (m *Mapper) Mapper() *anymapper.Mapper {
+	return m.mapper
+}
+
+// Map maps the source value to the destination value using the default
+// context of the wrapped mapper. See anymapper.Mapper.Map for details.
+func (m *Mapper) Map(ctx context.Context, src, dst any) error {
+	return m.MapContext(ctx, nil, src, dst)
+}
+
+// MapContext maps the source value to the destination value using the
+// given mapper context. If mctx is nil, the default context of the wrapped
+// mapper is used. See anymapper.Mapper.MapContext for details.
+func (m *Mapper) MapContext(ctx context.Context, mctx *anymapper.Context, src, dst any) error {
+	attrs := []attribute.KeyValue{
+		SrcTypeKey.String(typeName(src)),
+		DstTypeKey.String(typeName(dst)),
+	}
+	ctx, span := m.tracer.Start(ctx, "anymapper.Map", trace.WithAttributes(attrs...))
+	defer span.End()
+	start := time.Now()
+	err := m.mapper.MapContext(mctx, src, dst)
+	set := metric.WithAttributes(attrs...)
+	m.duration.Record(ctx, time.Since(start).Seconds(), set)
+	if err != nil {
+		m.errors.Add(ctx, 1, set)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// typeName returns the name of the type of v used in attributes.
+func typeName(v any) string {
+	if v == nil {
+		return "nil"
+	}
+	return reflect.TypeOf(v).String()
+}
@@ -0,0 +1,57 @@
+package otelmapper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/defiweb/go-anymapper"
+)
+
+func TestMapper(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	m, err := New(
+		anymapper.Default,
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)
+	require.NoError(t, err)
+
+	var dst struct{ A int }
+	require.NoError(t, m.Map(context.Background(), map[string]any{"A": "1"}, &dst))
+	assert.Equal(t, 1, dst.A)
+	var n int
+	require.Error(t, m.Map(context.Background(), "foo", &n))
+
+	ended := spans.Ended()
+	require.Len(t, ended, 2)
+	assert.Equal(t, "anymapper.Map", ended[0].Name())
+	assert.Contains(t, ended[0].Attributes(), SrcTypeKey.String("map[string]interface {}"))
+	assert.Contains(t, ended[0].Attributes(), DstTypeKey.String("*struct { A int }"))
+	assert.Equal(t, codes.Unset, ended[0].Status().Code)
+	assert.Equal(t, codes.Error, ended[1].Status().Code)
+	assert.Len(t, ended[1].Events(), 1)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	metrics := map[string]metricdata.Metrics{}
+	for _, md := range rm.ScopeMetrics[0].Metrics {
+		metrics[md.Name] = md
+	}
+	duration := metrics["anymapper.map.duration"].Data.(metricdata.Histogram[float64])
+	assert.Len(t, duration.DataPoints, 2)
+	errs := metrics["anymapper.map.errors"].Data.(metricdata.Sum[int64])
+	require.Len(t, errs.DataPoints, 1)
+	assert.Equal(t, int64(1), errs.DataPoints[0].Value)
+	assert.Equal(t, attribute.NewSet(SrcTypeKey.String("string"), DstTypeKey.String("*int")), errs.DataPoints[0].Attributes)
+}
@@ -0,0 +1,234 @@
+package anymapper
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// InvalidPathErr is returned by MapPath when a path does not exist in the
+// source value or cannot be created in the destination value.
+var InvalidPathErr = errors.New("mapper: invalid path")
+
+// MapPath maps the value at srcPath in the source value to the value at
+// dstPath in the destination value.
+//
+// It is shorthand for Default.MapPath(src, srcPath, dst, dstPath).
+func MapPath(src any, srcPath string, dst any, dstPath string) error {
+	return Default.MapPath(src, srcPath, dst, dstPath)
+}
+
+// MapPathContext maps the value at srcPath in the source value to the value
+// at dstPath in the destination value.
+//
+// It is shorthand for Default.MapPathContext(ctx, src, srcPath, dst, dstPath).
+func MapPathContext(ctx *Context, src any, srcPath string, dst any, dstPath string) error {
+	return Default.MapPathContext(ctx, src, srcPath, dst, dstPath)
+}
+
+// MapPath maps the value at srcPath in the source value to the value at
+// dstPath in the destination value.
+//
+// Paths are dot-separated lists of struct field keys, map keys and slice or
+// array indices, e.g. "payload.users.0.name". Struct fields are resolved
+// using the same tag rules as the mapper. An empty path refers to the
+// whole value.
+//
+// Nil pointers and maps on the destination path are initialized. Missing
+// keys in maps of the any type are created as map[string]any values.
+func (m *Mapper) MapPath(src any, srcPath string, dst any, dstPath string) error {
+	return m.MapPathContext(m.Context, src, srcPath, dst, dstPath)
+}
+
+// MapPathContext maps the value at srcPath in the source value to the value
+// at dstPath in the destination value. See MapPath for details.
+func (m *Mapper) MapPathContext(ctx *Context, src any, srcPath string, dst any, dstPath string) error {
+	if ctx == nil {
+		ctx = m.Context
+	}
+	srcVal, secret, err := m.lookupPath(ctx, reflect.ValueOf(src), srcPath)
+	if err != nil {
+		return err
+	}
+	return m.mapToPath(ctx, srcVal, reflect.ValueOf(dst), splitPath(dstPath), dstPath, secret)
+}
+
+// lookupPath returns the value at the given path in v. The returned bool
+// indicates whether the value is a secret struct field.
+func (m *Mapper) lookupPath(ctx *Context, v reflect.Value, path string) (reflect.Value, bool, error) {
+	secret := false
+	for _, seg := range splitPath(path) {
+		v = m.srcValue(ctx, v)
+		if !v.IsValid() {
+			return v, false, fmt.Errorf("%w: %s", InvalidPathErr, path)
+		}
+		var ok bool
+		switch v.Kind() {
+		case reflect.Struct:
+			v, secret, ok = m.pathField(ctx, v, seg, false)
+		case reflect.Map:
+			var key reflect.Value
+			if key, ok = m.pathKey(ctx, v.Type().Key(), seg); ok {
+				v = v.MapIndex(key)
+				ok = v.IsValid()
+			}
+		case reflect.Slice, reflect.Array:
+			var i int
+			if i, ok = pathIndex(v, seg); ok {
+				v = v.Index(i)
+			}
+		}
+		if !ok {
+			return v, false, fmt.Errorf("%w: %s", InvalidPathErr, path)
+		}
+	}
+	return v, secret, nil
+}
+
+// mapToPath maps src to the value at the given path segments in dst. If
+// secret is true, mapping errors are redacted.
+func (m *Mapper) mapToPath(ctx *Context, src, dst reflect.Value, segs []string, path string, secret bool) error {
+	return m.setPath(ctx, dst, segs, path, secret, func(dst reflect.Value, secret bool) error {
+		err := m.MapReflContext(ctx, src, dst)
+		if err != nil && secret && src.IsValid() && dst.IsValid() {
+			return redactError(src.Type(), dst.Type(), err)
+		}
+		return err
+	})
+}
+
+// setPath calls set with the value at the given path segments in dst, and
+// whether any of the struct fields on the path is a secret. Nil pointers
+// and maps on the path are initialized, and map elements are stored back
+// in their maps after set returns.
+func (m *Mapper) setPath(ctx *Context, dst reflect.Value, segs []string, path string, secret bool, set func(dst reflect.Value, secret bool) error) error {
+	if len(segs) == 0 {
+		return set(dst, secret)
+	}
+	for dst.Kind() == reflect.Pointer || dst.Kind() == reflect.Interface {
+		if dst.IsNil() {
+			if !dst.CanSet() {
+				return InvalidDstErr
+			}
+			if dst.Kind() == reflect.Pointer {
+				dst.Set(reflect.New(dst.Type().Elem()))
+			} else if mapStringAnyTy.AssignableTo(dst.Type()) {
+				dst.Set(reflect.MakeMap(mapStringAnyTy))
+			} else {
+				return fmt.Errorf("%w: %s", InvalidPathErr, path)
+			}
+		}
+		dst = dst.Elem()
+	}
+	seg := segs[0]
+	switch dst.Kind() {
+	case reflect.Struct:
+		fld, fldSecret, ok := m.pathField(ctx, dst, seg, true)
+		if !ok {
+			return fmt.Errorf("%w: %s", InvalidPathErr, path)
+		}
+		return m.setPath(ctx, fld, segs[1:], path, secret || fldSecret, set)
+	case reflect.Map:
+		key, ok := m.pathKey(ctx, dst.Type().Key(), seg)
+		if !ok {
+			return fmt.Errorf("%w: %s", InvalidPathErr, path)
+		}
+		if dst.IsNil() {
+			if !dst.CanSet() {
+				return InvalidDstErr
+			}
+			dst.Set(reflect.MakeMap(dst.Type()))
+		}
+		// Map elements are not addressable, so the element is copied,
+		// updated and stored back in the map.
+		elem := reflect.New(dst.Type().Elem()).Elem()
+		if cur := dst.MapIndex(key); cur.IsValid() {
+			elem.Set(cur)
+		}
+		if err := m.setPath(ctx, elem, segs[1:], path, secret, set); err != nil {
+			return err
+		}
+		dst.SetMapIndex(key, elem)
+		return nil
+	case reflect.Slice, reflect.Array:
+		i, ok := pathIndex(dst, seg)
+		if !ok {
+			return fmt.Errorf("%w: %s", InvalidPathErr, path)
+		}
+		return m.setPath(ctx, dst.Index(i), segs[1:], path, secret, set)
+	}
+	return fmt.Errorf("%w: %s", InvalidPathErr, path)
+}
+
+// isTagPath reports whether the key of a struct field is a path, that is,
+// if Context.TagPaths is enabled and the key contains a dot.
+func (c *Context) isTagPath(key string) bool {
+	return c.TagPaths && strings.Contains(key, ".")
+}
+
+// pathRoot returns the first segment of a path.
+func pathRoot(path string) string {
+	root, _, _ := strings.Cut(path, ".")
+	return root
+}
+
+// pathField returns the struct field whose key is equal to seg, and
+// whether the field is marked as secret. If init is true, nil pointers to
+// embedded structs are initialized, otherwise their fields are skipped.
+func (m *Mapper) pathField(ctx *Context, v reflect.Value, seg string, init bool) (reflect.Value, bool, bool) {
+	typ := v.Type()
+	fields := m.structFields(ctx, typ)
+	for i := 0; i < numFields(typ, fields); i++ {
+		fld := fieldAt(typ, fields, i)
+		if !m.mappedField(fld) {
+			continue
+		}
+		tag := m.parseTag(ctx, fld)
+		if tag.Skip {
+			continue
+		}
+		val := fieldByIndex(v, fld.Index, false)
+		if !val.IsValid() && !init {
+			continue
+		}
+		if m.fieldKey(ctx, fld, tag.Name, val) != seg {
+			continue
+		}
+		if !val.IsValid() {
+			if val = fieldByIndex(v, fld.Index, true); !val.IsValid() {
+				continue
+			}
+		}
+		return val, tag.Secret, true
+	}
+	return reflect.Value{}, false, false
+}
+
+// pathKey converts the path segment to a map key of the given type.
+func (m *Mapper) pathKey(ctx *Context, typ reflect.Type, seg string) (reflect.Value, bool) {
+	key := reflect.New(typ).Elem()
+	if err := m.MapReflContext(ctx, reflect.ValueOf(seg), key); err != nil {
+		return reflect.Value{}, false
+	}
+	return key, true
+}
+
+// pathIndex parses the path segment as an index of the slice or array.
+func pathIndex(v reflect.Value, seg string) (int, bool) {
+	i, err := strconv.Atoi(seg)
+	if err != nil || i < 0 || i >= v.Len() {
+		return 0, false
+	}
+	return i, true
+}
+
+// splitPath splits the dot-separated path into segments. An empty path has
+// no segments.
+func splitPath(path string) []string {
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, ".")
+}
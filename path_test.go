@@ -0,0 +1,139 @@
+package anymapper
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapPath(t *testing.T) {
+	type User struct {
+		ID   int    `map:"id"`
+		Name string `map:"name"`
+	}
+	type Payload struct {
+		Users []*User `map:"users"`
+	}
+	type Envelope struct {
+		Payload Payload `map:"payload"`
+	}
+	doc := map[string]any{
+		"payload": map[string]any{
+			"user":  map[string]any{"id": "1", "name": "foo"},
+			"users": []any{map[string]any{"id": 2, "name": "bar"}},
+		},
+	}
+	t.Run("map-source", func(t *testing.T) {
+		var dst User
+		require.NoError(t, MapPath(doc, "payload.user", &dst, ""))
+		assert.Equal(t, User{ID: 1, Name: "foo"}, dst)
+	})
+	t.Run("slice-index", func(t *testing.T) {
+		var dst string
+		require.NoError(t, MapPath(doc, "payload.users.0.name", &dst, ""))
+		assert.Equal(t, "bar", dst)
+	})
+	t.Run("struct-source", func(t *testing.T) {
+		var dst int
+		src := Envelope{Payload: Payload{Users: []*User{{ID: 3}}}}
+		require.NoError(t, MapPath(src, "payload.users.0.id", &dst, ""))
+		assert.Equal(t, 3, dst)
+	})
+	t.Run("struct-destination", func(t *testing.T) {
+		dst := Envelope{Payload: Payload{Users: []*User{nil}}}
+		require.NoError(t, MapPath(doc, "payload.user", &dst, "payload.users.0"))
+		assert.Equal(t, &User{ID: 1, Name: "foo"}, dst.Payload.Users[0])
+	})
+	t.Run("map-destination", func(t *testing.T) {
+		dst := map[string]any{"a": map[string]any{"b": 1}}
+		require.NoError(t, MapPath(doc, "payload.user.name", &dst, "a.c.d"))
+		assert.Equal(t, map[string]any{"a": map[string]any{"b": 1, "c": map[string]any{"d": "foo"}}}, dst)
+	})
+	t.Run("typed-map-destination", func(t *testing.T) {
+		var dst map[int]User
+		require.NoError(t, MapPath(doc, "payload.user", &dst, "5"))
+		assert.Equal(t, map[int]User{5: {ID: 1, Name: "foo"}}, dst)
+	})
+	t.Run("missing-source", func(t *testing.T) {
+		var dst User
+		err := MapPath(doc, "payload.missing", &dst, "")
+		assert.True(t, errors.Is(err, InvalidPathErr))
+	})
+	t.Run("missing-destination", func(t *testing.T) {
+		var dst User
+		err := MapPath(doc, "payload.user.id", &dst, "missing")
+		assert.True(t, errors.Is(err, InvalidPathErr))
+	})
+	t.Run("index-out-of-range", func(t *testing.T) {
+		var dst User
+		err := MapPath(doc, "payload.users.1", &dst, "")
+		assert.True(t, errors.Is(err, InvalidPathErr))
+	})
+}
+
+func TestTagPaths(t *testing.T) {
+	type dto struct {
+		ID      int    `map:"id"`
+		Created string `map:"meta.created_at"`
+		City    string `map:"meta.address.city"`
+		Token   string `map:"auth.token,secret"`
+	}
+	type meta struct {
+		CreatedAt string `map:"created_at"`
+	}
+	type nested struct {
+		ID   int   `map:"id"`
+		Meta *meta `map:"meta"`
+	}
+	ctx := Default.Context.WithTagPaths(true)
+
+	t.Run("map-to-struct", func(t *testing.T) {
+		src := map[string]any{
+			"id":   1,
+			"meta": map[string]any{"created_at": "2023", "address": map[string]any{"city": "x"}},
+		}
+		var dst dto
+		require.NoError(t, MapContext(ctx, src, &dst))
+		assert.Equal(t, dto{ID: 1, Created: "2023", City: "x"}, dst)
+	})
+	t.Run("flat-keys-first", func(t *testing.T) {
+		src := map[string]any{"meta.created_at": "flat", "meta": map[string]any{"created_at": "nested"}}
+		var dst dto
+		require.NoError(t, MapContext(ctx, src, &dst))
+		assert.Equal(t, "flat", dst.Created)
+	})
+	t.Run("struct-to-map", func(t *testing.T) {
+		var dst map[string]any
+		require.NoError(t, MapContext(ctx, dto{ID: 1, Created: "2023", City: "x", Token: "t"}, &dst))
+		assert.Equal(t, map[string]any{
+			"id":   1,
+			"meta": map[string]any{"created_at": "2023", "address": map[string]any{"city": "x"}},
+			"auth": map[string]any{"token": "t"},
+		}, dst)
+	})
+	t.Run("struct-to-nested-struct", func(t *testing.T) {
+		var dst nested
+		require.NoError(t, MapContext(ctx, dto{ID: 1, Created: "2023"}, &dst))
+		require.NotNil(t, dst.Meta)
+		assert.Equal(t, nested{ID: 1, Meta: &meta{CreatedAt: "2023"}}, dst)
+
+		var back dto
+		require.NoError(t, MapContext(ctx, dst, &back))
+		assert.Equal(t, dto{ID: 1, Created: "2023"}, back)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		var dst map[string]any
+		require.NoError(t, Map(dto{ID: 1, Created: "2023"}, &dst))
+		assert.Equal(t, "2023", dst["meta.created_at"])
+	})
+	t.Run("invalid-path", func(t *testing.T) {
+		type city struct {
+			City string `map:"address.city.name"`
+		}
+		var dst map[string]map[string]string
+		err := MapContext(ctx, city{City: "x"}, &dst)
+		assert.True(t, errors.Is(err, InvalidPathErr))
+	})
+}
@@ -0,0 +1,51 @@
+package anymapper
+
+import "strings"
+
+// AddPathMapFunc registers a mapping function used for the destination
+// value at the given path, instead of the function resolved for the types
+// of the values. It allows handling the same type differently in
+// different fields, e.g. using a cents converter only for prices.
+//
+// Paths are specified in the same way as for the Context.Fields field,
+// relative to the root destination value, e.g. "Order.Total". Elements of
+// slices and arrays share the path of their parent, so "Items.Price"
+// refers to the Price field of every element of Items. For readability,
+// such paths may be written as "Items[*].Price". The path of the currently
+// mapped value, including the indexes, is returned by Context.Path.
+//
+// The function is called with the source and destination values already
+// unpacked, like functions returned by MapFuncProvider. Functions must be
+// registered before the mapper is used, the method is not safe for
+// concurrent use with the mapping methods.
+func (m *Mapper) AddPathMapFunc(path string, fn MapFunc) {
+	if m.PathMappers == nil {
+		m.PathMappers = make(map[string]MapFunc)
+	}
+	m.PathMappers[normalizePath(path)] = fn
+}
+
+// normalizePath removes the "[*]" element wildcards from the path.
+func normalizePath(path string) string {
+	return strings.ReplaceAll(path, "[*]", "")
+}
+
+// withPathMappers returns a copy of the context that tracks paths of
+// mapped values, if the mapper has path mapping functions.
+func (c *Context) withPathMappers(m *Mapper) *Context {
+	if c.pathMappers || len(m.PathMappers) == 0 {
+		return c
+	}
+	cpy := *c
+	cpy.pathMappers = true
+	return &cpy
+}
+
+// pathMapFunc returns the mapping function registered for the path of the
+// currently mapped value, or nil if there is none.
+func (m *Mapper) pathMapFunc(ctx *Context) MapFunc {
+	if !ctx.pathMappers || ctx.fieldPath == "" {
+		return nil
+	}
+	return m.PathMappers[ctx.fieldPath]
+}
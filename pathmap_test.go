@@ -0,0 +1,88 @@
+package anymapper
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddPathMapFunc(t *testing.T) {
+	type item struct {
+		Price float64
+		Qty   float64
+	}
+	type order struct {
+		Items []item
+		Total float64
+	}
+	type itemCents struct {
+		Price int64
+		Qty   int64
+	}
+	type orderCents struct {
+		Items []itemCents
+		Total int64
+	}
+	cents := func(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+		dst.SetInt(int64(src.Float()*100 + 0.5))
+		return nil
+	}
+	m := New()
+	m.AddPathMapFunc("Items[*].Price", cents)
+	m.AddPathMapFunc("Total", cents)
+
+	t.Run("struct", func(t *testing.T) {
+		var dst orderCents
+		src := order{Items: []item{{Price: 1.25, Qty: 2}, {Price: 0.5, Qty: 3}}, Total: 4}
+		require.NoError(t, m.Map(src, &dst))
+		assert.Equal(t, orderCents{Items: []itemCents{{Price: 125, Qty: 2}, {Price: 50, Qty: 3}}, Total: 400}, dst)
+	})
+	t.Run("map", func(t *testing.T) {
+		var dst orderCents
+		src := map[string]any{"Items": []any{map[string]any{"Price": 2.5, "Qty": 1}}, "Total": 2.5}
+		require.NoError(t, m.Map(src, &dst))
+		assert.Equal(t, orderCents{Items: []itemCents{{Price: 250, Qty: 1}}, Total: 250}, dst)
+	})
+	t.Run("nested-path", func(t *testing.T) {
+		type wrapper struct{ Order orderCents }
+		var dst wrapper
+		src := map[string]any{"Order": order{Items: []item{{Price: 1, Qty: 1}}, Total: 1}}
+		require.NoError(t, m.Map(src, &dst))
+		// Paths are relative to the root value, so the functions do not
+		// apply to the nested order.
+		assert.Equal(t, wrapper{Order: orderCents{Items: []itemCents{{Price: 1, Qty: 1}}, Total: 1}}, dst)
+	})
+	t.Run("error", func(t *testing.T) {
+		m := m.Copy()
+		m.AddPathMapFunc("Total", func(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+			return errors.New("negative total")
+		})
+		var dst orderCents
+		assert.EqualError(t, m.Map(order{Total: -1}, &dst), "negative total")
+	})
+	t.Run("path", func(t *testing.T) {
+		m := m.Copy()
+		var paths []string
+		m.AddPathMapFunc("Items.Price", func(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+			paths = append(paths, ctx.Path())
+			if src.Float() < 0 {
+				return NewInvalidMappingError(src.Type(), dst.Type(), "negative price")
+			}
+			return cents(m, ctx, src, dst)
+		})
+		var dst orderCents
+		err := m.Map(order{Items: []item{{Price: 1}, {Price: -1}}}, &dst)
+		assert.Equal(t, []string{"Items[0].Price", "Items[1].Price"}, paths)
+		var merr *InvalidMappingErr
+		require.ErrorAs(t, err, &merr)
+		assert.Equal(t, paths[1], merr.Path)
+	})
+	t.Run("copy", func(t *testing.T) {
+		cpy := m.Copy()
+		delete(cpy.PathMappers, "Total")
+		assert.Contains(t, m.PathMappers, "Total")
+	})
+}
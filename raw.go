@@ -0,0 +1,69 @@
+package anymapper
+
+import "reflect"
+
+// Raw captures a source value without mapping it, so it can be mapped
+// later, like json.RawMessage, but regardless of the data format. It is
+// useful for two-phase mapping, where a discriminator field is inspected
+// first, and the rest of the value is mapped to a type chosen based on it.
+//
+// When Raw is used as a destination, it stores the source value as is,
+// after pointers and interfaces are unpacked. When it is used as a source,
+// the captured value is mapped to the destination. The captured value may
+// share memory with the original source.
+type Raw struct {
+	value reflect.Value
+}
+
+var rawTy = reflect.TypeOf((*Raw)(nil)).Elem()
+
+// NewRaw returns a Raw value that captures the given value.
+func NewRaw(v any) Raw {
+	return Raw{value: reflect.ValueOf(v)}
+}
+
+// Value returns the captured value. It is invalid if no value was captured.
+func (r Raw) Value() reflect.Value {
+	return r.value
+}
+
+// Interface returns the captured value as an interface, or nil if no value
+// was captured.
+func (r Raw) Interface() any {
+	if !r.value.IsValid() || !r.value.CanInterface() {
+		return nil
+	}
+	return r.value.Interface()
+}
+
+// IsValid returns true if a value was captured.
+func (r Raw) IsValid() bool {
+	return r.value.IsValid()
+}
+
+// rawTypeMapper captures values mapped to Raw and maps the captured values
+// of Raw to other types.
+func rawTypeMapper(_ *Mapper, src, dst reflect.Type) MapFunc {
+	switch {
+	case src == dst:
+		return mapDirect
+	case dst == rawTy:
+		return mapToRaw
+	case src == rawTy:
+		return mapFromRaw
+	}
+	return nil
+}
+
+func mapToRaw(_ *Mapper, _ *Context, src, dst reflect.Value) error {
+	dst.Set(reflect.ValueOf(Raw{value: src}))
+	return nil
+}
+
+func mapFromRaw(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	raw := src.Interface().(Raw)
+	if !raw.value.IsValid() {
+		return InvalidSrcErr
+	}
+	return m.MapReflContext(ctx, raw.value, dst)
+}
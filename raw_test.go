@@ -0,0 +1,61 @@
+package anymapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRaw(t *testing.T) {
+	type envelope struct {
+		Type string `map:"type"`
+		Data Raw    `map:"data"`
+	}
+	type transfer struct {
+		From   string `map:"from"`
+		Amount int    `map:"amount"`
+	}
+
+	t.Run("two-phase", func(t *testing.T) {
+		src := map[string]any{
+			"type": "transfer",
+			"data": map[string]any{"from": "a", "amount": "10"},
+		}
+		var env envelope
+		require.NoError(t, Map(src, &env))
+		assert.Equal(t, "transfer", env.Type)
+		require.True(t, env.Data.IsValid())
+		assert.Equal(t, src["data"], env.Data.Interface())
+
+		var tr transfer
+		require.NoError(t, Map(env.Data, &tr))
+		assert.Equal(t, transfer{From: "a", Amount: 10}, tr)
+	})
+	t.Run("keeps-type", func(t *testing.T) {
+		var r Raw
+		require.NoError(t, Map(&transfer{From: "b"}, &r))
+		assert.Equal(t, transfer{From: "b"}, r.Interface())
+	})
+	t.Run("new-raw", func(t *testing.T) {
+		var dst string
+		require.NoError(t, Map(NewRaw(42), &dst))
+		assert.Equal(t, "42", dst)
+	})
+	t.Run("raw-to-raw", func(t *testing.T) {
+		var dst Raw
+		require.NoError(t, Map(NewRaw("a"), &dst))
+		assert.Equal(t, "a", dst.Interface())
+	})
+	t.Run("empty", func(t *testing.T) {
+		var dst string
+		assert.False(t, Raw{}.IsValid())
+		assert.Nil(t, Raw{}.Interface())
+		assert.Error(t, Map(Raw{}, &dst))
+	})
+	t.Run("strict-types", func(t *testing.T) {
+		var dst int
+		ctx := Default.Context.WithStrictTypes(true)
+		assert.Error(t, MapContext(ctx, NewRaw("1"), &dst))
+	})
+}
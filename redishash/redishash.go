@@ -0,0 +1,109 @@
+// Package redishash maps structs to and from Redis hashes.
+//
+// Hashes are represented as map[string]string, as returned by the HGETALL
+// command. Struct fields are matched using the same tags as the mapper,
+// and values are converted to and from strings using the same rules as
+// the mapper. Nested structs and maps are flattened into a single hash,
+// their keys are joined with the parent key using the separator from
+// Context.FlattenSeparator, or DefaultSeparator if it is not set.
+package redishash
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/defiweb/go-anymapper"
+)
+
+// DefaultSeparator is the separator used to join keys of nested structs if
+// Context.FlattenSeparator is not set.
+const DefaultSeparator = "."
+
+// ErrConflictingKeys is returned by Decode if a hash contains a key that is
+// used both as a value and as a prefix of a nested key, e.g. "a" and "a.b".
+var ErrConflictingKeys = errors.New("redishash: conflicting keys")
+
+// Encode maps the source struct to a Redis hash using the Default mapper.
+func Encode(src any, opts ...anymapper.Option) (map[string]string, error) {
+	return EncodeWith(anymapper.Default, src, opts...)
+}
+
+// Decode maps a Redis hash to the destination struct using the Default
+// mapper.
+func Decode(hash map[string]string, dst any, opts ...anymapper.Option) error {
+	return DecodeWith(anymapper.Default, hash, dst, opts...)
+}
+
+// EncodeWith maps the source struct to a Redis hash using the given
+// mapper.
+func EncodeWith(m *anymapper.Mapper, src any, opts ...anymapper.Option) (map[string]string, error) {
+	ctx := hashContext(m, opts)
+	flat, err := m.Encode(src, anymapper.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	hash := make(map[string]string, len(flat))
+	for k, v := range flat {
+		if v == nil {
+			continue
+		}
+		var s string
+		if err := m.MapContext(ctx, v, &s); err != nil {
+			return nil, err
+		}
+		hash[k] = s
+	}
+	return hash, nil
+}
+
+// DecodeWith maps a Redis hash to the destination struct using the given
+// mapper.
+func DecodeWith(m *anymapper.Mapper, hash map[string]string, dst any, opts ...anymapper.Option) error {
+	ctx := hashContext(m, opts)
+	nested, err := unflatten(hash, ctx.FlattenSeparator)
+	if err != nil {
+		return err
+	}
+	return m.MapContext(ctx, nested, dst)
+}
+
+// hashContext returns the mapper context with the options applied and the
+// flatten separator set.
+func hashContext(m *anymapper.Mapper, opts []anymapper.Option) *anymapper.Context {
+	ctx := *m.Context
+	for _, opt := range opts {
+		opt(&ctx)
+	}
+	if ctx.FlattenSeparator == "" {
+		ctx.FlattenSeparator = DefaultSeparator
+	}
+	return &ctx
+}
+
+// unflatten converts a flat hash to nested maps by splitting the keys
+// using the separator.
+func unflatten(hash map[string]string, sep string) (map[string]any, error) {
+	res := make(map[string]any, len(hash))
+	for k, v := range hash {
+		parts := strings.Split(k, sep)
+		cur := res
+		for _, p := range parts[:len(parts)-1] {
+			switch n := cur[p].(type) {
+			case nil:
+				next := make(map[string]any)
+				cur[p] = next
+				cur = next
+			case map[string]any:
+				cur = n
+			default:
+				return nil, ErrConflictingKeys
+			}
+		}
+		last := parts[len(parts)-1]
+		if _, ok := cur[last]; ok {
+			return nil, ErrConflictingKeys
+		}
+		cur[last] = v
+	}
+	return res, nil
+}
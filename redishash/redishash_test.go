@@ -0,0 +1,97 @@
+package redishash
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defiweb/go-anymapper"
+)
+
+type address struct {
+	City string `map:"city"`
+	Zip  string `map:"zip,omitempty"`
+}
+
+type user struct {
+	Name     string    `map:"name"`
+	Age      int       `map:"age"`
+	Active   bool      `map:"active"`
+	Balance  *big.Int  `map:"balance"`
+	Created  time.Time `map:"created"`
+	Address  address   `map:"address"`
+	Manager  *user     `map:"manager"`
+	Password string    `map:"-"`
+}
+
+func TestEncode(t *testing.T) {
+	tm := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	hash, err := Encode(user{
+		Name:     "foo",
+		Age:      42,
+		Active:   true,
+		Balance:  big.NewInt(100),
+		Created:  tm,
+		Address:  address{City: "Warsaw"},
+		Password: "secret",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"name":         "foo",
+		"age":          "42",
+		"active":       "true",
+		"balance":      "100",
+		"created":      "2023-01-02T03:04:05Z",
+		"address.city": "Warsaw",
+	}, hash)
+}
+
+func TestEncodeSeparator(t *testing.T) {
+	hash, err := Encode(user{Address: address{City: "Warsaw"}}, anymapper.WithFlattenSeparator(":"))
+	require.NoError(t, err)
+	assert.Equal(t, "Warsaw", hash["address:city"])
+}
+
+func TestDecode(t *testing.T) {
+	var u user
+	err := Decode(map[string]string{
+		"name":                 "foo",
+		"age":                  "42",
+		"active":               "true",
+		"balance":              "100",
+		"created":              "2023-01-02T03:04:05Z",
+		"address.city":         "Warsaw",
+		"manager.name":         "bar",
+		"manager.address.city": "Berlin",
+	}, &u)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", u.Name)
+	assert.Equal(t, 42, u.Age)
+	assert.True(t, u.Active)
+	assert.Equal(t, big.NewInt(100), u.Balance)
+	assert.Equal(t, time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC), u.Created.UTC())
+	assert.Equal(t, "Warsaw", u.Address.City)
+	require.NotNil(t, u.Manager)
+	assert.Equal(t, "bar", u.Manager.Name)
+	assert.Equal(t, "Berlin", u.Manager.Address.City)
+}
+
+func TestDecodeErrors(t *testing.T) {
+	var u user
+	assert.ErrorIs(t, Decode(map[string]string{"address": "foo", "address.city": "bar"}, &u), ErrConflictingKeys)
+	assert.Error(t, Decode(map[string]string{"age": "foo"}, &u))
+}
+
+func TestRoundTrip(t *testing.T) {
+	m := anymapper.New()
+	src := user{Name: "foo", Age: 1, Manager: &user{Name: "bar"}}
+	hash, err := EncodeWith(m, src)
+	require.NoError(t, err)
+	var dst user
+	require.NoError(t, DecodeWith(m, hash, &dst))
+	assert.Equal(t, "foo", dst.Name)
+	assert.Equal(t, "bar", dst.Manager.Name)
+}
@@ -0,0 +1,154 @@
+package anymapper
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FieldRule derives the values of destination struct fields from the values
+// of source struct fields. Rules are registered for a pair of struct types
+// using Mapper.AddFieldRule and are applied when a struct of the source
+// type is mapped to a struct of the destination type.
+//
+// Rules allow to map fields that do not correspond 1:1, such as combining
+// several source fields into a single destination field, or splitting a
+// single source field into several destination fields.
+type FieldRule struct {
+	// From lists the names of the source struct fields.
+	From []string
+
+	// To lists the names of the destination struct fields.
+	To []string
+
+	// Func receives the values of the From fields, in the same order, and
+	// returns the values for the To fields, which are then mapped to the
+	// destination fields using the usual mapping rules. It must return one
+	// value for every To field.
+	Func func(src []any) ([]any, error)
+}
+
+// CombineFields returns a rule that sets the destination field to the value
+// returned by fn for the values of the source fields.
+func CombineFields(to string, from []string, fn func(src ...any) (any, error)) FieldRule {
+	return FieldRule{
+		From: from,
+		To:   []string{to},
+		Func: func(src []any) ([]any, error) {
+			v, err := fn(src...)
+			if err != nil {
+				return nil, err
+			}
+			return []any{v}, nil
+		},
+	}
+}
+
+// SplitField returns a rule that sets the destination fields to the values
+// returned by fn for the value of the source field.
+func SplitField(from string, to []string, fn func(src any) ([]any, error)) FieldRule {
+	return FieldRule{
+		From: []string{from},
+		To:   to,
+		Func: func(src []any) ([]any, error) {
+			return fn(src[0])
+		},
+	}
+}
+
+// AddFieldRule registers a rule for mapping structs of the src type to
+// structs of the dst type. Pointer types are dereferenced. Fields are
+// referenced by their Go names, fields promoted from embedded structs are
+// not supported. Destination fields set by rules are not mapped using the
+// usual rules, and source fields used by rules are not reported by
+// Hooks.UnmappedKeyHook.
+//
+// Rules must be registered before the mapper is used, the method is not
+// safe for concurrent use with the mapping methods.
+func (m *Mapper) AddFieldRule(src, dst reflect.Type, rule FieldRule) {
+	for src.Kind() == reflect.Pointer {
+		src = src.Elem()
+	}
+	for dst.Kind() == reflect.Pointer {
+		dst = dst.Elem()
+	}
+	if m.FieldRules == nil {
+		m.FieldRules = make(map[TypePair][]FieldRule)
+	}
+	pair := TypePair{Src: src, Dst: dst}
+	m.FieldRules[pair] = append(m.FieldRules[pair], rule)
+}
+
+// fieldRules returns the rules registered for the pair of struct types.
+func (m *Mapper) fieldRules(src, dst reflect.Type) []FieldRule {
+	if len(m.FieldRules) == 0 {
+		return nil
+	}
+	return m.FieldRules[TypePair{Src: src, Dst: dst}]
+}
+
+// ruleField reports whether the field name is listed in the From or To
+// field of any of the rules.
+func ruleField(rules []FieldRule, name string, to bool) bool {
+	for _, r := range rules {
+		names := r.From
+		if to {
+			names = r.To
+		}
+		for _, n := range names {
+			if n == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// applyFieldRules applies the rules to the src and dst structs.
+func (m *Mapper) applyFieldRules(ctx *Context, rules []FieldRule, src, dst reflect.Value, errs *[]error) error {
+	for _, r := range rules {
+		if err := m.applyFieldRule(ctx, r, src, dst, errs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Mapper) applyFieldRule(ctx *Context, r FieldRule, src, dst reflect.Value, errs *[]error) error {
+	in := make([]any, len(r.From))
+	for i, name := range r.From {
+		fld, ok := src.Type().FieldByName(name)
+		if !ok || !fld.IsExported() || len(fld.Index) != 1 {
+			return NewInvalidMappingError(src.Type(), dst.Type(), "unknown source field: "+name)
+		}
+		in[i] = src.Field(fld.Index[0]).Interface()
+	}
+	out, err := r.Func(in)
+	if err != nil {
+		return collectError(ctx, errs, reflect.Value{}, WrapInvalidMappingError(src.Type(), dst.Type(), err))
+	}
+	if len(out) != len(r.To) {
+		return NewInvalidMappingError(src.Type(), dst.Type(), fmt.Sprintf("rule returned %d values for %d fields", len(out), len(r.To)))
+	}
+	for i, name := range r.To {
+		fld, ok := dst.Type().FieldByName(name)
+		if !ok || !fld.IsExported() || len(fld.Index) != 1 {
+			return NewInvalidMappingError(src.Type(), dst.Type(), "unknown destination field: "+name)
+		}
+		tag := m.parseTag(ctx, fld)
+		fctx, ok := ctx.enter(tag.Name)
+		if !ok {
+			continue
+		}
+		dstFld := dst.Field(fld.Index[0])
+		if out[i] == nil {
+			dstFld.Set(reflect.Zero(dstFld.Type()))
+			continue
+		}
+		if err := m.MapReflContext(fctx, reflect.ValueOf(out[i]), dstFld); err != nil {
+			if err := collectError(ctx, errs, dstFld, fieldPathError(err, tag.Name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package anymapper
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldRules(t *testing.T) {
+	type person struct {
+		FirstName string
+		LastName  string
+		Age       int
+	}
+	type dto struct {
+		FullName string
+		Age      string
+	}
+	personTy := reflect.TypeOf(person{})
+	dtoTy := reflect.TypeOf(dto{})
+
+	m := New()
+	m.AddFieldRule(personTy, dtoTy, CombineFields("FullName", []string{"FirstName", "LastName"}, func(src ...any) (any, error) {
+		return src[0].(string) + " " + src[1].(string), nil
+	}))
+	m.AddFieldRule(dtoTy, personTy, SplitField("FullName", []string{"FirstName", "LastName"}, func(src any) ([]any, error) {
+		first, last, ok := strings.Cut(src.(string), " ")
+		if !ok {
+			return nil, errors.New("invalid name")
+		}
+		return []any{first, last}, nil
+	}))
+
+	t.Run("combine", func(t *testing.T) {
+		var dst dto
+		require.NoError(t, m.Map(&person{FirstName: "John", LastName: "Doe", Age: 42}, &dst))
+		assert.Equal(t, dto{FullName: "John Doe", Age: "42"}, dst)
+	})
+	t.Run("split", func(t *testing.T) {
+		var dst person
+		require.NoError(t, m.Map(dto{FullName: "John Doe", Age: "42"}, &dst))
+		assert.Equal(t, person{FirstName: "John", LastName: "Doe", Age: 42}, dst)
+	})
+	t.Run("error", func(t *testing.T) {
+		var dst person
+		assert.Error(t, m.Map(dto{FullName: "John"}, &dst))
+	})
+	t.Run("best-effort", func(t *testing.T) {
+		var dst person
+		err := m.MapContext(m.Context.WithBestEffort(true), dto{FullName: "John", Age: "42"}, &dst)
+		var errs MappingErrors
+		require.True(t, errors.As(err, &errs))
+		assert.Len(t, errs, 1)
+		assert.Equal(t, 42, dst.Age)
+	})
+	t.Run("unmapped-keys", func(t *testing.T) {
+		cpy := m.Copy()
+		var keys []string
+		cpy.Hooks.UnmappedKeyHook = func(m *Mapper, ctx *Context, key string, src reflect.Value) error {
+			keys = append(keys, key)
+			return nil
+		}
+		var dst dto
+		require.NoError(t, cpy.Map(person{FirstName: "John", LastName: "Doe"}, &dst))
+		assert.Empty(t, keys)
+	})
+	t.Run("same-type", func(t *testing.T) {
+		cpy := m.Copy()
+		cpy.AddFieldRule(personTy, personTy, CombineFields("LastName", []string{"LastName"}, func(src ...any) (any, error) {
+			return strings.ToUpper(src[0].(string)), nil
+		}))
+		var dst person
+		require.NoError(t, cpy.Map(person{FirstName: "John", LastName: "Doe"}, &dst))
+		assert.Equal(t, person{FirstName: "John", LastName: "DOE"}, dst)
+		assert.Len(t, m.FieldRules, 2)
+	})
+	t.Run("unknown-field", func(t *testing.T) {
+		cpy := m.Copy()
+		cpy.AddFieldRule(personTy, dtoTy, CombineFields("Name", []string{"FirstName"}, func(src ...any) (any, error) {
+			return src[0], nil
+		}))
+		var dst dto
+		assert.Error(t, cpy.Map(person{}, &dst))
+	})
+}
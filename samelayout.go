@@ -0,0 +1,62 @@
+package anymapper
+
+import "reflect"
+
+// sameLayoutFunc returns a MapFunc that copies structs with identical
+// layouts as a whole, if Context.IdenticalLayouts is enabled. Otherwise,
+// and for other types, the next MapFunc is returned, which may be nil.
+func sameLayoutFunc(ctx *Context, src, dst reflect.Type, next MapFunc) MapFunc {
+	if src == dst || !ctx.IdenticalLayouts || !sameLayout(src, dst) {
+		return next
+	}
+	if src.ConvertibleTo(dst) {
+		return convertSameLayout
+	}
+	return copySameLayout
+}
+
+// sameLayout reports whether src and dst are structs with the same number
+// of exported fields, where the fields at the same positions have the same
+// types or are structs with the same layouts. Fields are matched by their
+// positions, so their names and tags may differ.
+func sameLayout(src, dst reflect.Type) bool {
+	if src.Kind() != reflect.Struct || dst.Kind() != reflect.Struct {
+		return false
+	}
+	if src.NumField() != dst.NumField() || src.Size() != dst.Size() {
+		return false
+	}
+	for i := 0; i < src.NumField(); i++ {
+		sf, df := src.Field(i), dst.Field(i)
+		if !sf.IsExported() || !df.IsExported() || sf.Offset != df.Offset {
+			return false
+		}
+		if sf.Type != df.Type && !sameLayout(sf.Type, df.Type) {
+			return false
+		}
+	}
+	return true
+}
+
+// convertSameLayout copies structs that differ only in tags using a
+// type conversion. Structs with different field names are not convertible
+// and are copied by copySameLayout instead.
+func convertSameLayout(_ *Mapper, _ *Context, src, dst reflect.Value) error {
+	dst.Set(src.Convert(dst.Type()))
+	return nil
+}
+
+// copySameLayout copies structs with the same layouts field by field.
+func copySameLayout(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	for i := 0; i < src.NumField(); i++ {
+		sf, df := src.Field(i), dst.Field(i)
+		if sf.Type() == df.Type() {
+			df.Set(sf)
+			continue
+		}
+		if err := copySameLayout(m, ctx, sf, df); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package anymapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdenticalLayouts(t *testing.T) {
+	type inner struct {
+		A int
+	}
+	type innerDTO struct {
+		A int `json:"a"`
+	}
+	type src struct {
+		ID    string `map:"id"`
+		Inner inner
+		Tags  []string
+	}
+	type dst struct {
+		ID    string `json:"id"`
+		Inner innerDTO
+		Tags  []string
+	}
+	type renamed struct {
+		Key   string `map:"key"`
+		Inner inner
+		Tags  []string
+	}
+	type tagged struct {
+		ID    string `json:"id"`
+		Inner inner
+		Tags  []string
+	}
+	type unexported struct {
+		id    string
+		Inner inner
+		Tags  []string
+	}
+	ctx := Default.Context.WithIdenticalLayouts(true)
+
+	t.Run("nested", func(t *testing.T) {
+		var d dst
+		require.NoError(t, MapContext(ctx, src{ID: "a", Inner: inner{A: 1}, Tags: []string{"x"}}, &d))
+		assert.Equal(t, dst{ID: "a", Inner: innerDTO{A: 1}, Tags: []string{"x"}}, d)
+	})
+	t.Run("different-names", func(t *testing.T) {
+		// Fields are matched by their positions, not by their names.
+		var d renamed
+		require.NoError(t, MapContext(ctx, src{ID: "a", Inner: inner{A: 1}}, &d))
+		assert.Equal(t, renamed{Key: "a", Inner: inner{A: 1}}, d)
+	})
+	t.Run("tags-only", func(t *testing.T) {
+		var d tagged
+		require.NoError(t, MapContext(ctx, src{ID: "a", Inner: inner{A: 1}}, &d))
+		assert.Equal(t, tagged{ID: "a", Inner: inner{A: 1}}, d)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		var d dst
+		require.NoError(t, Map(src{ID: "a", Inner: inner{A: 1}, Tags: []string{"x"}}, &d))
+		assert.Equal(t, dst{Inner: innerDTO{A: 1}, Tags: []string{"x"}}, d)
+	})
+	t.Run("unexported", func(t *testing.T) {
+		var d unexported
+		require.NoError(t, MapContext(ctx, src{ID: "a", Inner: inner{A: 1}}, &d))
+		assert.Equal(t, unexported{Inner: inner{A: 1}}, d)
+	})
+	t.Run("different-layout", func(t *testing.T) {
+		type other struct {
+			ID   int `map:"id"`
+			Tags []string
+		}
+		var d other
+		require.NoError(t, MapContext(ctx, src{ID: "1", Tags: []string{"x"}}, &d))
+		assert.Equal(t, other{ID: 1, Tags: []string{"x"}}, d)
+	})
+}
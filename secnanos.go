@@ -0,0 +1,135 @@
+package anymapper
+
+import (
+	"math"
+	"reflect"
+	"time"
+)
+
+var durationTy = reflect.TypeOf((*time.Duration)(nil)).Elem()
+
+// secondsNanosKeys are the map keys of the seconds and nanos fields.
+var secondsNanosKeys = [2]string{"seconds", "nanos"}
+
+// isSecondsNanos reports whether t is a struct with the exported Seconds
+// (int64) and Nanos (int32) fields and no other exported fields, like the
+// Timestamp and Duration messages of protocol buffers.
+func isSecondsNanos(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	exported := 0
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			exported++
+		}
+	}
+	sec, secOK := t.FieldByName("Seconds")
+	nsec, nsecOK := t.FieldByName("Nanos")
+	return exported == 2 &&
+		secOK && len(sec.Index) == 1 && sec.Type.Kind() == reflect.Int64 &&
+		nsecOK && len(nsec.Index) == 1 && nsec.Type.Kind() == reflect.Int32
+}
+
+// isSecondsNanosMap reports whether t is a map that can hold the "seconds"
+// and "nanos" keys.
+func isSecondsNanosMap(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t.Key().Kind() == reflect.String
+}
+
+func mapTimeToSecondsNanos(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	t := src.Interface().(time.Time)
+	return m.writeSecondsNanos(ctx, t.Unix(), int32(t.Nanosecond()), dst)
+}
+
+func mapSecondsNanosToTime(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	sec, nsec, err := m.readSecondsNanos(ctx, src, dst.Type())
+	if err != nil {
+		return err
+	}
+	dst.Set(reflect.ValueOf(time.Unix(sec, int64(nsec)).UTC()))
+	return nil
+}
+
+func mapDurationToSecondsNanos(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	d := src.Int()
+	return m.writeSecondsNanos(ctx, d/int64(time.Second), int32(d%int64(time.Second)), dst)
+}
+
+func mapSecondsNanosToDuration(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	sec, nsec, err := m.readSecondsNanos(ctx, src, dst.Type())
+	if err != nil {
+		return err
+	}
+	if sec > math.MaxInt64/int64(time.Second) || sec < math.MinInt64/int64(time.Second) {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "overflow")
+	}
+	d := sec * int64(time.Second)
+	r := d + int64(nsec)
+	if (nsec > 0 && r < d) || (nsec < 0 && r > d) {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "overflow")
+	}
+	dst.SetInt(r)
+	return nil
+}
+
+// readSecondsNanos reads the seconds and nanos from a struct or a map of
+// the seconds and nanos shape. One of the keys may be missing in a map, in
+// which case it is treated as zero.
+func (m *Mapper) readSecondsNanos(ctx *Context, src reflect.Value, dstTyp reflect.Type) (sec int64, nsec int32, err error) {
+	if src.Kind() == reflect.Struct {
+		sec = src.FieldByName("Seconds").Int()
+		nsec = int32(src.FieldByName("Nanos").Int())
+	} else {
+		// Maps with other keys are not of the seconds and nanos shape.
+		found := 0
+		for i := range secondsNanosKeys {
+			if src.MapIndex(reflect.ValueOf(secondsNanosKeys[i]).Convert(src.Type().Key())).IsValid() {
+				found++
+			}
+		}
+		if found == 0 || found != src.Len() {
+			return 0, 0, NewInvalidMappingError(src.Type(), dstTyp, "seconds and nanos expected")
+		}
+		for i, dst := range [2]any{&sec, &nsec} {
+			v := src.MapIndex(reflect.ValueOf(secondsNanosKeys[i]).Convert(src.Type().Key()))
+			if !v.IsValid() {
+				continue
+			}
+			if err := m.MapReflContext(ctx, v, reflect.ValueOf(dst)); err != nil {
+				return 0, 0, NewInvalidMappingError(src.Type(), dstTyp, "invalid "+secondsNanosKeys[i])
+			}
+		}
+	}
+	if nsec <= -int32(time.Second) || nsec >= int32(time.Second) {
+		return 0, 0, NewInvalidMappingError(src.Type(), dstTyp, "nanos out of range")
+	}
+	return sec, nsec, nil
+}
+
+// writeSecondsNanos writes the seconds and nanos to a struct or a map of
+// the seconds and nanos shape.
+func (m *Mapper) writeSecondsNanos(ctx *Context, sec int64, nsec int32, dst reflect.Value) error {
+	if dst.Kind() == reflect.Struct {
+		dst.FieldByName("Seconds").SetInt(sec)
+		dst.FieldByName("Nanos").SetInt(int64(nsec))
+		return nil
+	}
+	src := reflect.ValueOf(map[string]any{
+		secondsNanosKeys[0]: sec,
+		secondsNanosKeys[1]: nsec,
+	})
+	return mapMapToMap(m, ctx, src, dst)
+}
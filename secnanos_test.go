@@ -0,0 +1,74 @@
+package anymapper
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecondsNanos(t *testing.T) {
+	// Timestamp has the same shape as the protobuf Timestamp message.
+	type Timestamp struct {
+		state   int
+		Seconds int64
+		Nanos   int32
+	}
+	type Other struct {
+		Seconds int64
+		Nanos   int32
+		Extra   int
+	}
+	tm := time.Unix(1700000000, 123).UTC()
+	t.Run("time-to-struct", func(t *testing.T) {
+		var dst Timestamp
+		require.NoError(t, Map(tm, &dst))
+		assert.Equal(t, Timestamp{Seconds: 1700000000, Nanos: 123}, dst)
+	})
+	t.Run("struct-to-time", func(t *testing.T) {
+		var dst time.Time
+		require.NoError(t, Map(&Timestamp{Seconds: 1700000000, Nanos: 123}, &dst))
+		assert.Equal(t, tm, dst)
+	})
+	t.Run("time-to-map", func(t *testing.T) {
+		var dst map[string]any
+		require.NoError(t, Map(tm, &dst))
+		assert.Equal(t, map[string]any{"seconds": int64(1700000000), "nanos": int32(123)}, dst)
+	})
+	t.Run("map-to-time", func(t *testing.T) {
+		var dst time.Time
+		require.NoError(t, Map(map[string]any{"seconds": "1700000000", "nanos": 123}, &dst))
+		assert.Equal(t, tm, dst)
+		require.NoError(t, Map(map[string]int{"seconds": 5}, &dst))
+		assert.Equal(t, time.Unix(5, 0).UTC(), dst)
+	})
+	t.Run("duration-to-struct", func(t *testing.T) {
+		var dst Timestamp
+		require.NoError(t, Map(-1500*time.Millisecond, &dst))
+		assert.Equal(t, Timestamp{Seconds: -1, Nanos: -500000000}, dst)
+	})
+	t.Run("struct-to-duration", func(t *testing.T) {
+		var dst time.Duration
+		require.NoError(t, Map(Timestamp{Seconds: 2, Nanos: 5}, &dst))
+		assert.Equal(t, 2*time.Second+5, dst)
+	})
+	t.Run("duration-map", func(t *testing.T) {
+		var m map[string]int64
+		require.NoError(t, Map(1500*time.Millisecond, &m))
+		assert.Equal(t, map[string]int64{"seconds": 1, "nanos": 500000000}, m)
+		var d time.Duration
+		require.NoError(t, Map(m, &d))
+		assert.Equal(t, 1500*time.Millisecond, d)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		var d time.Duration
+		assert.Error(t, Map(Timestamp{Seconds: math.MaxInt64}, &d))
+		assert.Error(t, Map(Timestamp{Nanos: 1e9}, &d))
+		assert.Error(t, Map(map[string]int{"seconds": 1, "other": 2}, &d))
+		assert.Error(t, Map(map[string]any{"seconds": "x"}, &d))
+		var tm time.Time
+		assert.Error(t, Map(Other{Seconds: 1}, &tm))
+	})
+}
@@ -0,0 +1,123 @@
+package anymapper
+
+import "reflect"
+
+// isSeq reports whether t is a sequence function, such as iter.Seq[V]:
+// func(yield func(V) bool).
+func isSeq(t reflect.Type) bool {
+	return isSeqOf(t, 1)
+}
+
+// isSeq2 reports whether t is a sequence of pairs function, such as
+// iter.Seq2[K, V]: func(yield func(K, V) bool).
+func isSeq2(t reflect.Type) bool {
+	return isSeqOf(t, 2)
+}
+
+func isSeqOf(t reflect.Type, n int) bool {
+	if t.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 0 || t.IsVariadic() {
+		return false
+	}
+	y := t.In(0)
+	return y.Kind() == reflect.Func &&
+		y.NumIn() == n &&
+		y.NumOut() == 1 &&
+		y.Out(0).Kind() == reflect.Bool &&
+		!y.IsVariadic()
+}
+
+// mapSeqToSlice collects the values of a sequence into a new slice. The
+// previous content of the destination slice is replaced.
+func mapSeqToSlice(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	if src.IsNil() {
+		return nil
+	}
+	var (
+		yieldTyp = src.Type().In(0)
+		elemTyp  = dst.Type().Elem()
+		mapper   = m.mapperFor(ctx, yieldTyp.In(0), elemTyp)
+		out      = reflect.MakeSlice(dst.Type(), 0, 0)
+		errs     []error
+		err      error
+	)
+	yield := reflect.MakeFunc(yieldTyp, func(args []reflect.Value) []reflect.Value {
+		if err != nil {
+			return []reflect.Value{reflect.ValueOf(false)}
+		}
+		if err = checkCount(ctx, src.Type(), false, out.Len()+1, 1); err != nil {
+			return []reflect.Value{reflect.ValueOf(false)}
+		}
+		elem := reflect.New(elemTyp).Elem()
+		if e := m.mapValue(ctx, &mapper, m.srcValue(ctx, args[0]), m.dstValue(ctx, elem)); e != nil {
+			if err = collectError(ctx, &errs, elem, indexPathError(e, out.Len())); err != nil {
+				return []reflect.Value{reflect.ValueOf(false)}
+			}
+		}
+		out = reflect.Append(out, elem)
+		return []reflect.Value{reflect.ValueOf(true)}
+	})
+	src.Call([]reflect.Value{yield})
+	if err != nil {
+		return err
+	}
+	dst.Set(out)
+	return joinErrors(errs)
+}
+
+// mapSeq2ToMap adds the pairs of a sequence to the destination map.
+func mapSeq2ToMap(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	if src.IsNil() {
+		return nil
+	}
+	var (
+		yieldTyp   = src.Type().In(0)
+		keyTyp     = dst.Type().Key()
+		elemTyp    = dst.Type().Elem()
+		keyMapper  = m.mapperFor(ctx, yieldTyp.In(0), keyTyp)
+		elemMapper = m.mapperFor(ctx, yieldTyp.In(1), elemTyp)
+		keyCtx     = ctx.keyContext()
+		count      int
+		errs       []error
+		err        error
+	)
+	if dst.IsNil() {
+		dst.Set(reflect.MakeMap(dst.Type()))
+	}
+	yield := reflect.MakeFunc(yieldTyp, func(args []reflect.Value) []reflect.Value {
+		if err != nil {
+			return []reflect.Value{reflect.ValueOf(false)}
+		}
+		count++
+		if err = checkCount(ctx, src.Type(), true, count, 1); err != nil {
+			return []reflect.Value{reflect.ValueOf(false)}
+		}
+		key := reflect.New(keyTyp).Elem()
+		if e := m.mapValue(keyCtx, &keyMapper, m.srcValue(ctx, args[0]), m.dstValue(ctx, key)); e != nil {
+			e = NewInvalidMappingError(args[0].Type(), keyTyp, "unable to map key")
+			if err = collectError(ctx, &errs, reflect.Value{}, keyPathError(e, args[0])); err != nil {
+				return []reflect.Value{reflect.ValueOf(false)}
+			}
+			return []reflect.Value{reflect.ValueOf(true)}
+		}
+		elem := reflect.New(elemTyp).Elem()
+		if e := m.mapValue(ctx, &elemMapper, m.srcValue(ctx, args[1]), m.dstValue(ctx, elem)); e != nil {
+			if err = collectError(ctx, &errs, reflect.Value{}, keyPathError(e, key)); err != nil {
+				return []reflect.Value{reflect.ValueOf(false)}
+			}
+			return []reflect.Value{reflect.ValueOf(true)}
+		}
+		dst.SetMapIndex(key, elem)
+		return []reflect.Value{reflect.ValueOf(true)}
+	})
+	src.Call([]reflect.Value{yield})
+	if err != nil {
+		return err
+	}
+	return joinErrors(errs)
+}
@@ -0,0 +1,90 @@
+package anymapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	intSeq     func(yield func(int) bool)
+	strIntSeq2 func(yield func(string, int) bool)
+)
+
+func seqOf(vs ...int) intSeq {
+	return func(yield func(int) bool) {
+		for _, v := range vs {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func TestSeq(t *testing.T) {
+	t.Run("slice", func(t *testing.T) {
+		dst := []string{"x", "y", "z", "w"}
+		require.NoError(t, Map(seqOf(1, 2, 3), &dst))
+		assert.Equal(t, []string{"1", "2", "3"}, dst)
+	})
+	t.Run("func-literal", func(t *testing.T) {
+		var dst []int
+		src := func(yield func(string) bool) {
+			_ = yield("1") && yield("2")
+		}
+		require.NoError(t, Map(src, &dst))
+		assert.Equal(t, []int{1, 2}, dst)
+	})
+	t.Run("map", func(t *testing.T) {
+		src := strIntSeq2(func(yield func(string, int) bool) {
+			_ = yield("a", 1) && yield("b", 2)
+		})
+		dst := map[string]string{"c": "3"}
+		require.NoError(t, Map(src, &dst))
+		assert.Equal(t, map[string]string{"a": "1", "b": "2", "c": "3"}, dst)
+	})
+	t.Run("error-stops", func(t *testing.T) {
+		var calls int
+		src := func(yield func(string) bool) {
+			for _, s := range []string{"1", "x", "3"} {
+				calls++
+				if !yield(s) {
+					return
+				}
+			}
+		}
+		var dst []int
+		assert.Error(t, Map(src, &dst))
+		assert.Equal(t, 2, calls)
+		assert.Empty(t, dst)
+	})
+	t.Run("best-effort", func(t *testing.T) {
+		src := func(yield func(string) bool) {
+			_ = yield("1") && yield("x") && yield("3")
+		}
+		var dst []int
+		err := MapContext(Default.Context.WithBestEffort(true), src, &dst)
+		assert.Error(t, err)
+		assert.Equal(t, []int{1, 0, 3}, dst)
+	})
+	t.Run("max-length", func(t *testing.T) {
+		var dst []int
+		err := MapContext(Default.Context.WithMaxLength(2), seqOf(1, 2, 3), &dst)
+		assert.ErrorIs(t, err, LimitExceededErr)
+	})
+	t.Run("nil", func(t *testing.T) {
+		var src intSeq
+		dst := []int{1}
+		require.NoError(t, Map(src, &dst))
+		assert.Equal(t, []int{1}, dst)
+	})
+	t.Run("strict", func(t *testing.T) {
+		var dst []int
+		assert.Error(t, MapContext(Default.Context.WithStrictTypes(true), seqOf(1), &dst))
+	})
+	t.Run("not-seq", func(t *testing.T) {
+		var dst []int
+		assert.Error(t, Map(func(int) bool { return true }, &dst))
+	})
+}
@@ -0,0 +1,77 @@
+package anymapper
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// mapKeys returns the keys of the map v. If Context.SortMapKeys is enabled,
+// the keys are sorted, otherwise they are in unspecified order.
+func (c *Context) mapKeys(v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+	if c.SortMapKeys {
+		sortKeys(keys)
+	}
+	return keys
+}
+
+// sortKeys sorts map keys. Numbers are sorted by their values, strings
+// lexically, false before true, and keys of other kinds by their string
+// representations. Keys of different kinds, possible in maps with
+// interface keys, are sorted by their kinds first.
+func sortKeys(keys []reflect.Value) {
+	sort.SliceStable(keys, func(i, j int) bool {
+		return compareKeys(keys[i], keys[j]) < 0
+	})
+}
+
+func compareKeys(a, b reflect.Value) int {
+	if a.Kind() == reflect.Interface {
+		a = a.Elem()
+	}
+	if b.Kind() == reflect.Interface {
+		b = b.Elem()
+	}
+	if !a.IsValid() || !b.IsValid() {
+		return compareInts(boolInt(a.IsValid()), boolInt(b.IsValid()))
+	}
+	if a.Kind() != b.Kind() {
+		return compareInts(int64(a.Kind()), int64(b.Kind()))
+	}
+	switch a.Kind() {
+	case reflect.Bool:
+		return compareInts(boolInt(a.Bool()), boolInt(b.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareInts(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return compareOrdered(a.Uint() < b.Uint(), a.Uint() > b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return compareOrdered(a.Float() < b.Float(), a.Float() > b.Float())
+	case reflect.String:
+		return compareOrdered(a.String() < b.String(), a.String() > b.String())
+	}
+	as, bs := fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface())
+	return compareOrdered(as < bs, as > bs)
+}
+
+func compareInts(a, b int64) int {
+	return compareOrdered(a < b, a > b)
+}
+
+func compareOrdered(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+func boolInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
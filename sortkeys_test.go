@@ -0,0 +1,64 @@
+package anymapper
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortMapKeys(t *testing.T) {
+	ctx := Default.Context.WithSortMapKeys(true).WithBestEffort(true)
+
+	t.Run("map-to-map", func(t *testing.T) {
+		var dst map[string]int
+		err := MapContext(ctx, map[string]string{"c": "x", "a": "y", "b": "1", "d": "z"}, &dst)
+		var errs MappingErrors
+		require.ErrorAs(t, err, &errs)
+		require.Len(t, errs, 3)
+		for i, key := range []string{"a", "c", "d"} {
+			val := map[string]string{"a": "y", "c": "x", "d": "z"}[key]
+			assert.Equal(t, `mapper: cannot map string to int at `+key+`: strconv.ParseInt: parsing "`+val+`": invalid syntax`, errs[i].Error())
+		}
+	})
+	t.Run("map-to-struct", func(t *testing.T) {
+		var keys []string
+		m := Default.Copy()
+		m.Hooks.UnmappedKeyHook = func(_ *Mapper, _ *Context, key string, _ reflect.Value) error {
+			keys = append(keys, key)
+			return nil
+		}
+		var dst struct{ B int }
+		require.NoError(t, m.MapContext(ctx, map[string]int{"d": 1, "B": 2, "a": 3, "c": 4}, &dst))
+		assert.Equal(t, []string{"a", "c", "d"}, keys)
+	})
+	t.Run("map-each", func(t *testing.T) {
+		var keys []any
+		var dst string
+		err := Default.MapEachContext(ctx, map[int]int{10: 1, -1: 2, 2: 3}, &dst, func(any) error {
+			keys = append(keys, dst)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []any{"2", "3", "1"}, keys)
+	})
+}
+
+func TestSortKeys(t *testing.T) {
+	keys := []reflect.Value{
+		reflect.ValueOf("b"),
+		reflect.ValueOf(2),
+		reflect.ValueOf("a"),
+		reflect.ValueOf(-1),
+		reflect.ValueOf(true),
+		reflect.ValueOf(1.5),
+		reflect.ValueOf(false),
+	}
+	sortKeys(keys)
+	var got []any
+	for _, k := range keys {
+		got = append(got, k.Interface())
+	}
+	assert.Equal(t, []any{false, true, -1, 2, 1.5, "a", "b"}, got)
+}
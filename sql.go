@@ -0,0 +1,78 @@
+package anymapper
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+)
+
+var (
+	sqlScannerTy   = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	driverValuerTy = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+// implScanner returns true if a pointer to the type implements the
+// sql.Scanner interface.
+func implScanner(t reflect.Type) bool {
+	return t.Kind() != reflect.Interface && reflect.PointerTo(t).Implements(sqlScannerTy)
+}
+
+// implValuer returns true if the type or a pointer to it implements the
+// driver.Valuer interface.
+func implValuer(t reflect.Type) bool {
+	return t.Kind() != reflect.Interface && reflect.PointerTo(t).Implements(driverValuerTy)
+}
+
+// sqlFunc returns a MapFunc that maps values to types implementing the
+// sql.Scanner interface using their Scan method, and values of types
+// implementing the driver.Valuer interface using the values returned by
+// their Value method, like sql.NullString or sql.NullInt64. They are used
+// only if the next MapFunc is nil, so the built-in rules take precedence.
+func sqlFunc(src, dst reflect.Type, next MapFunc) MapFunc {
+	if next != nil || src == dst {
+		return next
+	}
+	switch {
+	case implScanner(dst):
+		return mapToScanner
+	case implValuer(src):
+		return mapFromValuer
+	}
+	return nil
+}
+
+// mapToScanner scans the source value into dst. If the source implements
+// the driver.Valuer interface, its value is scanned instead.
+func mapToScanner(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	val := src.Interface()
+	if implValuer(src.Type()) {
+		var err error
+		if val, err = addr(src).Interface().(driver.Valuer).Value(); err != nil {
+			return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+		}
+	}
+	if err := dst.Addr().Interface().(sql.Scanner).Scan(val); err != nil {
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	return nil
+}
+
+// mapFromValuer maps the value returned by the Value method of src to dst.
+// A nil value, which represents NULL, sets dst to its zero value.
+func mapFromValuer(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	val, err := addr(src).Interface().(driver.Valuer).Value()
+	if err != nil {
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	if val == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	return m.MapReflContext(ctx, reflect.ValueOf(&val).Elem(), dst)
+}
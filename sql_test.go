@@ -0,0 +1,78 @@
+package anymapper
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTags is stored in a database as a comma-separated string.
+type testTags []string
+
+func (t testTags) Value() (driver.Value, error) {
+	return strings.Join(t, ","), nil
+}
+
+func TestSQL(t *testing.T) {
+	t.Run("scanner", func(t *testing.T) {
+		var ns sql.NullString
+		require.NoError(t, Map("foo", &ns))
+		assert.Equal(t, sql.NullString{String: "foo", Valid: true}, ns)
+		var ni sql.NullInt64
+		require.NoError(t, Map(42, &ni))
+		assert.Equal(t, sql.NullInt64{Int64: 42, Valid: true}, ni)
+		require.NoError(t, Map("7", &ni))
+		assert.Equal(t, sql.NullInt64{Int64: 7, Valid: true}, ni)
+		assert.Error(t, Map("x", &ni))
+	})
+	t.Run("valuer", func(t *testing.T) {
+		var s string
+		require.NoError(t, Map(sql.NullString{String: "foo", Valid: true}, &s))
+		assert.Equal(t, "foo", s)
+		var n uint8
+		require.NoError(t, Map(sql.NullInt64{Int64: 5, Valid: true}, &n))
+		assert.Equal(t, uint8(5), n)
+		assert.Error(t, Map(sql.NullInt64{Int64: 500, Valid: true}, &n))
+	})
+	t.Run("null", func(t *testing.T) {
+		s := "foo"
+		require.NoError(t, Map(sql.NullString{}, &s))
+		assert.Equal(t, "", s)
+		n := 1
+		require.NoError(t, Map(sql.NullInt64{}, &n))
+		assert.Equal(t, 0, n)
+	})
+	t.Run("valuer-to-scanner", func(t *testing.T) {
+		var ns sql.NullString
+		require.NoError(t, Map(testTags{"a", "b"}, &ns))
+		assert.Equal(t, sql.NullString{String: "a,b", Valid: true}, ns)
+	})
+	t.Run("struct-fields", func(t *testing.T) {
+		type Row struct {
+			Name sql.NullString `map:"name"`
+			Age  sql.NullInt64  `map:"age"`
+		}
+		var row Row
+		require.NoError(t, Map(map[string]any{"name": "a", "age": int64(3)}, &row))
+		assert.Equal(t, Row{
+			Name: sql.NullString{String: "a", Valid: true},
+			Age:  sql.NullInt64{Int64: 3, Valid: true},
+		}, row)
+		var dst struct {
+			Name string `map:"name"`
+			Age  int    `map:"age"`
+		}
+		require.NoError(t, Map(row, &dst))
+		assert.Equal(t, "a", dst.Name)
+		assert.Equal(t, 3, dst.Age)
+	})
+	t.Run("built-in-rules-first", func(t *testing.T) {
+		var m map[string]any
+		require.NoError(t, Map(sql.NullString{String: "a", Valid: true}, &m))
+		assert.Equal(t, map[string]any{"String": "a", "Valid": true}, m)
+	})
+}
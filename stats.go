@@ -0,0 +1,167 @@
+package anymapper
+
+import (
+	"math"
+	"math/bits"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats records the number of calls and durations of mapping functions
+// for every pair of source and destination types. It is used as a
+// middleware:
+//
+//	stats := anymapper.NewStats()
+//	m.Use(stats.Middleware)
+//
+// Durations of a type pair include the durations of nested mappings, like
+// struct fields or slice elements, so they show which conversions dominate
+// the total mapping time. Stats is safe for concurrent use.
+type Stats struct {
+	pairs sync.Map // map[TypePair]*pairStats
+}
+
+// TypeStats is a snapshot of the statistics of a single type pair.
+//
+// Percentiles are estimated using a histogram with buckets of power-of-two
+// nanoseconds, so they are accurate within a factor of two.
+type TypeStats struct {
+	TypePair
+	Count  uint64        // number of calls
+	Errors uint64        // number of calls that returned an error
+	Total  time.Duration // cumulative duration of all calls
+	Max    time.Duration // duration of the longest call
+	P50    time.Duration // estimated median duration
+	P90    time.Duration // estimated 90th percentile duration
+	P99    time.Duration // estimated 99th percentile duration
+}
+
+// Mean returns the mean duration of a call.
+func (s TypeStats) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+// pairStats holds the counters of a single type pair. Counters are
+// updated atomically.
+type pairStats struct {
+	count   uint64
+	errors  uint64
+	total   uint64
+	max     uint64
+	buckets [65]uint64 // bucket i counts durations of bits.Len64(ns) == i
+}
+
+// NewStats returns a new, empty Stats.
+func NewStats() *Stats {
+	return &Stats{}
+}
+
+// Middleware records the calls of the wrapped MapFunc.
+func (s *Stats) Middleware(next MapFunc) MapFunc {
+	return func(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+		start := time.Now()
+		err := next(m, ctx, src, dst)
+		s.record(TypePair{Src: src.Type(), Dst: dst.Type()}, time.Since(start), err)
+		return err
+	}
+}
+
+// Snapshot returns the statistics of all type pairs that were mapped,
+// ordered by the total duration, from the longest one.
+func (s *Stats) Snapshot() []TypeStats {
+	var res []TypeStats
+	s.pairs.Range(func(k, v any) bool {
+		res = append(res, v.(*pairStats).snapshot(k.(TypePair)))
+		return true
+	})
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Total > res[j].Total
+	})
+	return res
+}
+
+// Reset removes all recorded statistics.
+func (s *Stats) Reset() {
+	s.pairs.Range(func(k, _ any) bool {
+		s.pairs.Delete(k)
+		return true
+	})
+}
+
+func (s *Stats) record(pair TypePair, d time.Duration, err error) {
+	v, ok := s.pairs.Load(pair)
+	if !ok {
+		v, _ = s.pairs.LoadOrStore(pair, &pairStats{})
+	}
+	ps := v.(*pairStats)
+	ns := uint64(0)
+	if d > 0 {
+		ns = uint64(d)
+	}
+	atomic.AddUint64(&ps.count, 1)
+	atomic.AddUint64(&ps.total, ns)
+	atomic.AddUint64(&ps.buckets[bits.Len64(ns)], 1)
+	if err != nil {
+		atomic.AddUint64(&ps.errors, 1)
+	}
+	for {
+		cur := atomic.LoadUint64(&ps.max)
+		if ns <= cur || atomic.CompareAndSwapUint64(&ps.max, cur, ns) {
+			break
+		}
+	}
+}
+
+func (ps *pairStats) snapshot(pair TypePair) TypeStats {
+	var buckets [65]uint64
+	for i := range buckets {
+		buckets[i] = atomic.LoadUint64(&ps.buckets[i])
+	}
+	st := TypeStats{
+		TypePair: pair,
+		Count:    atomic.LoadUint64(&ps.count),
+		Errors:   atomic.LoadUint64(&ps.errors),
+		Total:    time.Duration(atomic.LoadUint64(&ps.total)),
+		Max:      time.Duration(atomic.LoadUint64(&ps.max)),
+	}
+	st.P50 = percentile(buckets[:], 0.5, st.Max)
+	st.P90 = percentile(buckets[:], 0.9, st.Max)
+	st.P99 = percentile(buckets[:], 0.99, st.Max)
+	return st
+}
+
+// percentile estimates the percentile p of the durations counted in the
+// buckets as the upper bound of the bucket that contains it, limited to
+// the maximum duration.
+func percentile(buckets []uint64, p float64, max time.Duration) time.Duration {
+	var total uint64
+	for _, n := range buckets {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(p * float64(total)))
+	var seen uint64
+	for i, n := range buckets {
+		seen += n
+		if seen < rank {
+			continue
+		}
+		if i >= 63 {
+			return max
+		}
+		// Bucket i contains durations in the range [2^(i-1), 2^i) ns.
+		if d := time.Duration(1<<uint(i) - 1); d < max {
+			return d
+		}
+		return max
+	}
+	return max
+}
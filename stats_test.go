@@ -0,0 +1,69 @@
+package anymapper
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	type src struct {
+		A int
+		B string
+	}
+	type dst struct {
+		A string
+		B int
+	}
+
+	stats := NewStats()
+	m := New()
+	m.Use(stats.Middleware)
+
+	for i := 0; i < 3; i++ {
+		var d dst
+		require.NoError(t, m.Map(src{A: 1, B: "2"}, &d))
+	}
+	var d dst
+	require.Error(t, m.Map(src{B: "x"}, &d))
+
+	snap := stats.Snapshot()
+	byPair := map[TypePair]TypeStats{}
+	for i, s := range snap {
+		byPair[s.TypePair] = s
+		if i > 0 {
+			assert.GreaterOrEqual(t, snap[i-1].Total, s.Total)
+		}
+	}
+	structs := byPair[TypePair{Src: reflect.TypeOf(src{}), Dst: reflect.TypeOf(dst{})}]
+	assert.Equal(t, uint64(4), structs.Count)
+	assert.Equal(t, uint64(1), structs.Errors)
+	assert.LessOrEqual(t, structs.P50, structs.P99)
+	assert.LessOrEqual(t, structs.P99, structs.Max)
+	assert.LessOrEqual(t, structs.Max, structs.Total)
+
+	fields := byPair[TypePair{Src: reflect.TypeOf(""), Dst: reflect.TypeOf(0)}]
+	assert.Equal(t, uint64(4), fields.Count)
+	assert.Equal(t, uint64(1), fields.Errors)
+
+	stats.Reset()
+	assert.Empty(t, stats.Snapshot())
+}
+
+func TestStatsPercentile(t *testing.T) {
+	var buckets [65]uint64
+	assert.Equal(t, time.Duration(0), percentile(buckets[:], 0.5, 0))
+	buckets[0] = 1   // 0ns
+	buckets[4] = 90  // 8-15ns
+	buckets[10] = 9  // 512-1023ns
+	buckets[20] = 10 // 0.5-1ms
+	max := 900 * time.Microsecond
+	assert.Equal(t, 15*time.Nanosecond, percentile(buckets[:], 0.5, max))
+	assert.Equal(t, 1023*time.Nanosecond, percentile(buckets[:], 0.9, max))
+	assert.Equal(t, max, percentile(buckets[:], 0.99, max))
+	assert.Equal(t, TypeStats{Count: 4, Total: 100}.Mean(), time.Duration(25))
+	assert.Equal(t, TypeStats{}.Mean(), time.Duration(0))
+}
@@ -0,0 +1,33 @@
+package anymapper
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var stringerTy = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+// implStringer returns true if the type or a pointer to it implements the
+// fmt.Stringer interface.
+func implStringer(t reflect.Type) bool {
+	return t.Kind() != reflect.Interface && reflect.PointerTo(t).Implements(stringerTy)
+}
+
+// stringerFunc returns a MapFunc that maps types implementing the
+// fmt.Stringer interface to strings using their String method, if
+// Context.Stringers is enabled. It is used only if the next MapFunc is nil,
+// so it is the last resort for such mappings.
+func stringerFunc(ctx *Context, src, dst reflect.Type, next MapFunc) MapFunc {
+	if next != nil || !ctx.Stringers || dst.Kind() != reflect.String || !implStringer(src) {
+		return next
+	}
+	return mapStringerToString
+}
+
+func mapStringerToString(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	dst.SetString(addr(src).Interface().(fmt.Stringer).String())
+	return nil
+}
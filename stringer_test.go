@@ -0,0 +1,58 @@
+package anymapper
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPoint struct {
+	X, Y int
+}
+
+func (p testPoint) String() string {
+	return fmt.Sprintf("(%d, %d)", p.X, p.Y)
+}
+
+type testPriority int
+
+func (p testPriority) String() string {
+	return "priority"
+}
+
+func TestStringers(t *testing.T) {
+	ctx := Default.Context.WithStringers(true)
+
+	t.Run("struct", func(t *testing.T) {
+		var s string
+		require.NoError(t, MapContext(ctx, testPoint{X: 1, Y: 2}, &s))
+		assert.Equal(t, "(1, 2)", s)
+		require.NoError(t, MapContext(ctx, &testPoint{X: 3}, &s))
+		assert.Equal(t, "(3, 0)", s)
+	})
+	t.Run("struct-field", func(t *testing.T) {
+		var dst map[string]string
+		require.NoError(t, MapContext(ctx, struct{ P testPoint }{P: testPoint{X: 1}}, &dst))
+		assert.Equal(t, map[string]string{"P": "(1, 0)"}, dst)
+	})
+	t.Run("built-in-rules-first", func(t *testing.T) {
+		var s string
+		require.NoError(t, MapContext(ctx, testPriority(5), &s))
+		assert.Equal(t, "5", s)
+		var m map[string]int
+		require.NoError(t, MapContext(ctx, testPoint{X: 1, Y: 2}, &m))
+		assert.Equal(t, map[string]int{"X": 1, "Y": 2}, m)
+	})
+	t.Run("strict-types", func(t *testing.T) {
+		var s string
+		assert.Error(t, MapContext(ctx.WithStrictTypes(true), testPoint{}, &s))
+	})
+	t.Run("disabled", func(t *testing.T) {
+		var s string
+		assert.Error(t, Map(testPoint{}, &s))
+		assert.Nil(t, Default.mapperFor(Default.Context, reflect.TypeOf(testPoint{}), stringTy).MapFunc)
+	})
+}
@@ -0,0 +1,198 @@
+package anymapper
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Suffixes defines the set of unit suffixes, like "k" or "Mi", used to
+// map numbers to and from strings.
+type Suffixes int
+
+const (
+	// NoSuffixes disables unit suffixes.
+	NoSuffixes Suffixes = iota
+
+	// SISuffixes formats numbers using the decimal SI suffixes: k, M, G,
+	// T, P and E, that are powers of 1000.
+	SISuffixes
+
+	// BinarySuffixes formats numbers using the binary suffixes: Ki, Mi,
+	// Gi, Ti, Pi and Ei, that are powers of 1024.
+	BinarySuffixes
+)
+
+// unitSuffix is a unit suffix and its multiplier.
+type unitSuffix struct {
+	name string
+	mul  uint64
+}
+
+// siUnits and binaryUnits are ordered from the largest unit. The uppercase
+// "K" is accepted when parsing, but numbers are always formatted with "k",
+// because it comes first.
+var (
+	siUnits = []unitSuffix{
+		{"E", 1e18}, {"P", 1e15}, {"T", 1e12}, {"G", 1e9}, {"M", 1e6}, {"k", 1e3}, {"K", 1e3},
+	}
+	binaryUnits = []unitSuffix{
+		{"Ei", 1 << 60}, {"Pi", 1 << 50}, {"Ti", 1 << 40}, {"Gi", 1 << 30}, {"Mi", 1 << 20}, {"Ki", 1 << 10},
+	}
+)
+
+// units returns the units of the suffix set, ordered from the largest one.
+func (s Suffixes) units() []unitSuffix {
+	switch s {
+	case SISuffixes:
+		return siUnits
+	case BinarySuffixes:
+		return binaryUnits
+	}
+	return nil
+}
+
+var invalidSuffixedErr = errors.New("invalid number with unit suffix")
+
+// keyContext returns the context used to map the keys of maps. Unit
+// suffixes are applied only to values, so the context has them disabled.
+func (c *Context) keyContext() *Context {
+	if c.Suffixes == NoSuffixes {
+		return c
+	}
+	cpy := *c
+	cpy.Suffixes = NoSuffixes
+	return &cpy
+}
+
+// splitSuffix splits a string into a number and the multiplier of its unit
+// suffix. Both SI and binary suffixes are recognized. If the string has no
+// suffix, ok is false.
+func splitSuffix(s string) (num string, mul uint64, ok bool) {
+	for _, units := range [][]unitSuffix{binaryUnits, siUnits} {
+		for _, u := range units {
+			if strings.HasSuffix(s, u.name) {
+				return s[:len(s)-len(u.name)], u.mul, true
+			}
+		}
+	}
+	return s, 1, false
+}
+
+// parseSuffixed parses a decimal number with a unit suffix, like "1.5k".
+// It returns nil if the string has no suffix.
+func parseSuffixed(s string) (*big.Rat, error) {
+	num, mul, ok := splitSuffix(s)
+	if !ok {
+		return nil, nil
+	}
+	// big.Rat also accepts fractions, like "1/2", which are not numbers
+	// with unit suffixes.
+	if num == "" || strings.ContainsAny(num, "/ ") {
+		return nil, invalidSuffixedErr
+	}
+	r, ok := new(big.Rat).SetString(num)
+	if !ok {
+		return nil, invalidSuffixedErr
+	}
+	return r.Mul(r, new(big.Rat).SetInt(new(big.Int).SetUint64(mul))), nil
+}
+
+// mapSuffixedNumber maps a string with a unit suffix to an integer or
+// float destination. It reports whether the string was handled, which is
+// the case only if Context.Suffixes is set and the string has a suffix.
+func mapSuffixedNumber(ctx *Context, s string, src, dst reflect.Value) (bool, error) {
+	if ctx.Suffixes == NoSuffixes {
+		return false, nil
+	}
+	r, err := parseSuffixed(s)
+	if err != nil {
+		return true, WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	if r == nil {
+		return false, nil
+	}
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !r.IsInt() {
+			return true, NewInvalidMappingError(src.Type(), dst.Type(), "fractional value")
+		}
+		if !r.Num().IsInt64() || dst.OverflowInt(r.Num().Int64()) {
+			return true, NewInvalidMappingError(src.Type(), dst.Type(), "overflow")
+		}
+		dst.SetInt(r.Num().Int64())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if !r.IsInt() {
+			return true, NewInvalidMappingError(src.Type(), dst.Type(), "fractional value")
+		}
+		if !r.Num().IsUint64() || dst.OverflowUint(r.Num().Uint64()) {
+			return true, NewInvalidMappingError(src.Type(), dst.Type(), "overflow")
+		}
+		dst.SetUint(r.Num().Uint64())
+	case reflect.Float32, reflect.Float64:
+		f, _ := r.Float64()
+		if dst.OverflowFloat(f) {
+			return true, NewInvalidMappingError(src.Type(), dst.Type(), "overflow")
+		}
+		dst.SetFloat(f)
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// formatSuffixed formats an integer using the largest unit of the suffix
+// set that divides it exactly, e.g. 2000000 as "2M" or 1536 as "1536" for
+// SI suffixes. It returns an empty string if no unit can be used.
+func formatSuffixed(s Suffixes, neg bool, v uint64) string {
+	for _, u := range s.units() {
+		if v != 0 && v%u.mul == 0 {
+			n := strconv.FormatUint(v/u.mul, 10) + u.name
+			if neg {
+				n = "-" + n
+			}
+			return n
+		}
+	}
+	return ""
+}
+
+// formatSuffixedInt is like formatSuffixed, but for signed integers.
+func formatSuffixedInt(s Suffixes, v int64) string {
+	if v < 0 {
+		// The negation of math.MinInt64 overflows, but its conversion to
+		// uint64 is still correct.
+		return formatSuffixed(s, true, uint64(-v))
+	}
+	return formatSuffixed(s, false, uint64(v))
+}
+
+// formatSuffixedBig is like formatSuffixed, but for big integers.
+func formatSuffixedBig(s Suffixes, v *big.Int) string {
+	if v.Sign() == 0 {
+		return ""
+	}
+	q, r := new(big.Int), new(big.Int)
+	for _, u := range s.units() {
+		q.QuoRem(v, new(big.Int).SetUint64(u.mul), r)
+		if r.Sign() == 0 {
+			return q.String() + u.name
+		}
+	}
+	return ""
+}
+
+// formatSuffixedFloat is like formatSuffixed, but for floats. Only floats
+// with integer values that fit in 64 bits are formatted with suffixes.
+func formatSuffixedFloat(s Suffixes, f float64) string {
+	a := f
+	if a < 0 {
+		a = -a
+	}
+	if a != a || a >= 1<<64 || a != float64(uint64(a)) {
+		return ""
+	}
+	return formatSuffixed(s, f < 0, uint64(a))
+}
@@ -0,0 +1,111 @@
+package anymapper
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuffixes(t *testing.T) {
+	si := Default.Context.WithSuffixes(SISuffixes)
+	bin := Default.Context.WithSuffixes(BinarySuffixes)
+
+	t.Run("parse", func(t *testing.T) {
+		tests := []struct {
+			src  string
+			dst  any
+			want any
+		}{
+			{src: "1.5k", dst: new(int), want: 1500},
+			{src: "4K", dst: new(int), want: 4000},
+			{src: "2M", dst: new(uint32), want: uint32(2000000)},
+			{src: "-3G", dst: new(int64), want: int64(-3000000000)},
+			{src: "512Mi", dst: new(uint64), want: uint64(512 << 20)},
+			{src: "3Gi", dst: new(int), want: 3 << 30},
+			{src: "0.5Ki", dst: new(int16), want: int16(512)},
+			{src: "1.25k", dst: new(float64), want: 1250.0},
+			{src: "1E", dst: new(float32), want: float32(1e18)},
+			{src: "100", dst: new(int), want: 100},
+		}
+		for _, tt := range tests {
+			t.Run(tt.src, func(t *testing.T) {
+				require.NoError(t, MapContext(si, tt.src, tt.dst))
+				assert.Equal(t, tt.want, reflect.ValueOf(tt.dst).Elem().Interface())
+			})
+		}
+	})
+	t.Run("parse-big-int", func(t *testing.T) {
+		var dst big.Int
+		require.NoError(t, MapContext(si, "100E", &dst))
+		want, _ := new(big.Int).SetString("100000000000000000000", 10)
+		assert.Equal(t, want, &dst)
+	})
+	t.Run("parse-errors", func(t *testing.T) {
+		tests := []struct {
+			src string
+			dst any
+		}{
+			{src: "1.5", dst: new(int)},
+			{src: "1.0001k", dst: new(int)},
+			{src: "k", dst: new(int)},
+			{src: "1/2k", dst: new(int)},
+			{src: "xk", dst: new(float64)},
+			{src: "1Ki", dst: new(int8)},
+			{src: "-1k", dst: new(uint)},
+			{src: "20E", dst: new(int64)},
+			{src: "0.0005k", dst: new(big.Int)},
+		}
+		for _, tt := range tests {
+			t.Run(tt.src, func(t *testing.T) {
+				assert.Error(t, MapContext(si, tt.src, tt.dst))
+			})
+		}
+	})
+	t.Run("disabled", func(t *testing.T) {
+		var dst int
+		assert.Error(t, Map("1k", &dst))
+	})
+	t.Run("map-keys", func(t *testing.T) {
+		var keys map[string]int
+		require.NoError(t, MapContext(si, map[int]int{2000: 3000}, &keys))
+		assert.Equal(t, map[string]int{"2000": 3000}, keys)
+		var vals map[string]string
+		require.NoError(t, MapContext(si, map[string]int{"a": 3000}, &vals))
+		assert.Equal(t, map[string]string{"a": "3k"}, vals)
+		var ints map[int]int
+		assert.Error(t, MapContext(si, map[string]string{"1k": "2k"}, &ints))
+	})
+	t.Run("format", func(t *testing.T) {
+		tests := []struct {
+			ctx  *Context
+			src  any
+			want string
+		}{
+			{ctx: si, src: 2000000, want: "2M"},
+			{ctx: si, src: -3000, want: "-3k"},
+			{ctx: si, src: 1500, want: "1500"},
+			{ctx: si, src: 0, want: "0"},
+			{ctx: si, src: uint64(5e18), want: "5E"},
+			{ctx: si, src: 4e9, want: "4G"},
+			{ctx: si, src: 1.5e3, want: "1500"},
+			{ctx: si, src: 0.5, want: "0.5"},
+			{ctx: si, src: math.Inf(1), want: "+Inf"},
+			{ctx: si, src: big.NewInt(7e12), want: "7T"},
+			{ctx: bin, src: 512 << 20, want: "512Mi"},
+			{ctx: bin, src: 1536, want: "1536"},
+			{ctx: bin, src: int64(math.MinInt64), want: "-8Ei"},
+			{ctx: bin, src: 1000, want: "1000"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.want, func(t *testing.T) {
+				var dst string
+				require.NoError(t, MapContext(tt.ctx, tt.src, &dst))
+				assert.Equal(t, tt.want, dst)
+			})
+		}
+	})
+}
@@ -0,0 +1,255 @@
+package anymapper
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// structTag is a parsed struct field tag.
+//
+// The tag has the following format: `map:"name,option1,option2"`. The name
+// may be omitted, in which case the field name is used. If the tag is "-",
+// the field is skipped. The name may list alternative source keys separated
+// by "|", e.g. `map:"name|full_name"`; the first one is the name of the
+// field, and the others are used if the source does not have it.
+//
+// Supported options:
+//
+//   - secret - the field value is sensitive and must not be exposed in error
+//     messages. If Context.SkipSecrets is set, the field is also omitted
+//     when mapping a struct to a map.
+//   - omitempty - the field is omitted when mapping a struct to a map if its
+//     value is empty, as defined by the encoding/json package.
+//   - width=N - integers mapped to strings, or to the any type, are
+//     left-padded to N characters, and padding is removed when strings are
+//     mapped to integers.
+//   - pad=C - the padding character used with the width option, "0" by
+//     default. Invalid width and pad values cause the mapping of the field
+//     to fail.
+//   - encoding=NAME - the string encoding used to map bytes to and from
+//     strings, overrides Context.StringEncoding.
+//   - conv=NAME - the named converter, registered in Mapper.Converters, is
+//     applied to the source value before it is mapped.
+//   - float16 - the uint16 field holds an IEEE 754 half-precision number and
+//     is mapped as a Float16 value.
+//   - layout=LAYOUT - the layout used to format and parse times mapped to
+//     and from strings, overrides Context.TimeLayout. Layouts containing
+//     commas cannot be used.
+//   - nested - the embedded struct is mapped as a regular field, instead
+//     of promoting its fields to the outer struct.
+//   - default=VALUE - the value mapped to the field, as if it was a string
+//     in the source, if the source map or struct has no value for it.
+//     Values containing commas cannot be used. The option is not stored
+//     in structTag, see defaultValue.
+//
+// Small fields are grouped together to keep fieldValue small enough to be
+// stored in maps without additional allocations.
+type structTag struct {
+	// Name is the name of the field used as a map key.
+	Name string
+
+	// Aliases are the alternative source keys of the field, used if the
+	// source does not have the Name key.
+	Aliases []string
+
+	// Skip indicates that the field should be skipped.
+	Skip bool
+
+	// Secret indicates that the field value is sensitive.
+	Secret bool
+
+	// OmitEmpty indicates that the field should be omitted if empty.
+	OmitEmpty bool
+
+	// Float16 indicates that the field holds a half-precision number.
+	Float16 bool
+
+	// Pad is the padding character used with Width.
+	Pad byte
+
+	// Width is the width of strings mapped from integers. It is
+	// invalidPadding if the width or pad option is invalid.
+	Width int
+
+	// Encoding is the name of the string encoding used for bytes.
+	Encoding string
+
+	// Conv is the name of the converter applied to the source value.
+	Conv string
+
+	// Layout is the layout of times mapped to and from strings.
+	Layout string
+}
+
+// parseTag parses the tag of the given field.
+func (m *Mapper) parseTag(ctx *Context, f reflect.StructField) (tag structTag) {
+	raw, ok := f.Tag.Lookup(ctx.Tag)
+	if raw == "-" {
+		tag.Skip = true
+		return tag
+	}
+	if ok {
+		name, opts, _ := strings.Cut(raw, ",")
+		if strings.Contains(name, "|") {
+			names := strings.Split(name, "|")
+			name, tag.Aliases = names[0], names[1:]
+		}
+		tag.Name = name
+		for opts != "" {
+			var opt string
+			opt, opts, _ = strings.Cut(opts, ",")
+			opt, val, _ := strings.Cut(opt, "=")
+			switch opt {
+			case "secret":
+				tag.Secret = true
+			case "omitempty":
+				tag.OmitEmpty = true
+			case "width":
+				n, err := strconv.Atoi(val)
+				if err != nil || n < 0 {
+					n = invalidPadding
+				}
+				if tag.Width != invalidPadding {
+					tag.Width = n
+				}
+			case "encoding":
+				tag.Encoding = val
+			case "float16":
+				tag.Float16 = true
+			case "conv":
+				tag.Conv = val
+			case "layout":
+				tag.Layout = val
+			case "pad":
+				if len(val) == 1 {
+					tag.Pad = val[0]
+				} else {
+					tag.Width = invalidPadding
+				}
+			}
+		}
+	}
+	if tag.Width > 0 && tag.Pad == 0 {
+		tag.Pad = '0'
+	}
+	if conv, ok := ctx.FieldConverters[f.Name]; ok {
+		tag.Conv = conv
+	}
+	if name, ok := ctx.Renames[f.Name]; ok {
+		tag.Name = name
+	} else if tag.Name == "" {
+		if ctx.FieldMapper != nil {
+			tag.Name = ctx.FieldMapper(f.Name)
+		} else {
+			tag.Name = f.Name
+		}
+	}
+	return tag
+}
+
+// defaultValue returns the value of the default tag option of the field,
+// if it has one.
+func defaultValue(ctx *Context, f reflect.StructField) (string, bool) {
+	raw, ok := f.Tag.Lookup(ctx.Tag)
+	if !ok {
+		return "", false
+	}
+	_, opts, _ := strings.Cut(raw, ",")
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if name, val, _ := strings.Cut(opt, "="); name == "default" {
+			return val, true
+		}
+	}
+	return "", false
+}
+
+// invalidPadding is the Width of a structTag with an invalid width or pad
+// option.
+const invalidPadding = -1
+
+// padding returns the width and the padding character from the tags. The
+// destination tag takes precedence. Tags may be nil. The width is
+// invalidPadding if the options of any of the tags are invalid.
+func padding(srcTag, dstTag *structTag) (int, byte) {
+	if (dstTag != nil && dstTag.Width == invalidPadding) || (srcTag != nil && srcTag.Width == invalidPadding) {
+		return invalidPadding, 0
+	}
+	if dstTag != nil && dstTag.Width > 0 {
+		return dstTag.Width, dstTag.Pad
+	}
+	if srcTag != nil && srcTag.Width > 0 {
+		return srcTag.Width, srcTag.Pad
+	}
+	return 0, 0
+}
+
+// encoding returns the name of the string encoding from the tags. The
+// destination tag takes precedence. Tags may be nil.
+func encoding(srcTag, dstTag *structTag) string {
+	if dstTag != nil && dstTag.Encoding != "" {
+		return dstTag.Encoding
+	}
+	if srcTag != nil {
+		return srcTag.Encoding
+	}
+	return ""
+}
+
+// layout returns the time layout from the tags. The destination tag takes
+// precedence. Tags may be nil.
+func layout(srcTag, dstTag *structTag) string {
+	if dstTag != nil && dstTag.Layout != "" {
+		return dstTag.Layout
+	}
+	if srcTag != nil {
+		return srcTag.Layout
+	}
+	return ""
+}
+
+// converter returns the name of the converter from the tags. The
+// destination tag takes precedence. Tags may be nil.
+func converter(srcTag, dstTag *structTag) string {
+	if dstTag != nil && dstTag.Conv != "" {
+		return dstTag.Conv
+	}
+	if srcTag != nil {
+		return srcTag.Conv
+	}
+	return ""
+}
+
+// padNumber left-pads the number string to the given width. If the
+// padding character is "0", it is placed after the sign.
+func padNumber(s string, width int, pad byte) string {
+	if len(s) >= width {
+		return s
+	}
+	fill := strings.Repeat(string(pad), width-len(s))
+	if pad == '0' && (s[0] == '-' || s[0] == '+') {
+		return s[:1] + fill + s[1:]
+	}
+	return fill + s
+}
+
+// unpadNumber removes the padding added by padNumber.
+func unpadNumber(s string, pad byte) string {
+	sign := ""
+	if pad == '0' && s != "" && (s[0] == '-' || s[0] == '+') {
+		sign, s = s[:1], s[1:]
+	}
+	s = strings.TrimLeft(s, string(pad))
+	if s == "" {
+		return "0"
+	}
+	return sign + s
+}
+
+// secret returns true if either of the given tags is marked as secret.
+// Tags may be nil.
+func secret(srcTag, dstTag *structTag) bool {
+	return (srcTag != nil && srcTag.Secret) || (dstTag != nil && dstTag.Secret)
+}
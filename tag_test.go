@@ -0,0 +1,268 @@
+package anymapper
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTag(t *testing.T) {
+	type Src struct {
+		A string
+		B string `map:"b"`
+		C string `map:"-"`
+		D string `map:",secret"`
+		E string `map:"e,secret"`
+		F string `map:"f,unknown"`
+		G int    `map:"g,width=6"`
+		H int    `map:",width=4,pad= "`
+		I string `map:"i|j|k"`
+		J string `map:"|j"`
+		K string `map:"k,layout=2006-01-02"`
+	}
+	tests := []struct {
+		field string
+		exp   structTag
+	}{
+		{field: "A", exp: structTag{Name: "A"}},
+		{field: "B", exp: structTag{Name: "b"}},
+		{field: "C", exp: structTag{Skip: true}},
+		{field: "D", exp: structTag{Name: "D", Secret: true}},
+		{field: "E", exp: structTag{Name: "e", Secret: true}},
+		{field: "F", exp: structTag{Name: "f"}},
+		{field: "G", exp: structTag{Name: "g", Width: 6, Pad: '0'}},
+		{field: "H", exp: structTag{Name: "H", Width: 4, Pad: ' '}},
+		{field: "I", exp: structTag{Name: "i", Aliases: []string{"j", "k"}}},
+		{field: "J", exp: structTag{Name: "J", Aliases: []string{"j"}}},
+		{field: "K", exp: structTag{Name: "k", Layout: "2006-01-02"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			fld, _ := reflect.TypeOf(Src{}).FieldByName(tt.field)
+			assert.Equal(t, tt.exp, Default.parseTag(Default.Context, fld))
+		})
+	}
+}
+
+func TestSecretTag(t *testing.T) {
+	type Src struct {
+		User     string
+		Password string `map:",secret"`
+	}
+	type Dst struct {
+		User     string
+		Password int `map:",secret"`
+	}
+	t.Run("error", func(t *testing.T) {
+		var dst Dst
+		err := Map(Src{User: "foo", Password: "hunter2"}, &dst)
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "hunter2")
+	})
+	t.Run("error-best-effort", func(t *testing.T) {
+		var dst Dst
+		ctx := Default.Context.WithBestEffort(true)
+		err := MapContext(ctx, map[string]any{"User": "foo", "Password": "hunter2"}, &dst)
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "hunter2")
+		assert.Equal(t, "foo", dst.User)
+	})
+	t.Run("skip-secrets", func(t *testing.T) {
+		var dst map[string]any
+		ctx := Default.Context.WithSkipSecrets(true)
+		require.NoError(t, MapContext(ctx, Src{User: "foo", Password: "hunter2"}, &dst))
+		assert.Equal(t, map[string]any{"User": "foo"}, dst)
+	})
+	t.Run("no-skip-secrets", func(t *testing.T) {
+		var dst map[string]any
+		require.NoError(t, Map(Src{User: "foo", Password: "hunter2"}, &dst))
+		assert.Equal(t, map[string]any{"User": "foo", "Password": "hunter2"}, dst)
+	})
+}
+
+func TestRenames(t *testing.T) {
+	type User struct {
+		UserID int    `map:"id"`
+		Name   string `map:"name,secret"`
+		Email  string
+	}
+	ctx := Default.Context.WithRenames(map[string]string{"UserID": "uid", "Email": "mail"})
+	t.Run("parse-tag", func(t *testing.T) {
+		fld, _ := reflect.TypeOf(User{}).FieldByName("UserID")
+		assert.Equal(t, structTag{Name: "uid"}, Default.parseTag(ctx, fld))
+	})
+	t.Run("map-to-struct", func(t *testing.T) {
+		var dst User
+		require.NoError(t, MapContext(ctx, map[string]any{"uid": 1, "name": "foo", "mail": "foo@example.com"}, &dst))
+		assert.Equal(t, User{UserID: 1, Name: "foo", Email: "foo@example.com"}, dst)
+	})
+	t.Run("struct-to-map", func(t *testing.T) {
+		var dst map[string]any
+		require.NoError(t, MapContext(ctx, User{UserID: 1, Name: "foo"}, &dst))
+		assert.Equal(t, map[string]any{"uid": 1, "name": "foo", "mail": ""}, dst)
+	})
+}
+
+func TestWidthTag(t *testing.T) {
+	type Invoice struct {
+		Number  int   `map:"number,width=6"`
+		Balance int64 `map:"balance,width=5"`
+		Account uint  `map:"account,width=4,pad= "`
+	}
+	t.Run("struct-to-map", func(t *testing.T) {
+		var dst map[string]string
+		require.NoError(t, Map(Invoice{Number: 42, Balance: -7, Account: 12}, &dst))
+		assert.Equal(t, map[string]string{"number": "000042", "balance": "-0007", "account": "  12"}, dst)
+	})
+	t.Run("map-to-struct", func(t *testing.T) {
+		var dst Invoice
+		require.NoError(t, Map(map[string]string{"number": "000042", "balance": "-0007", "account": "  12"}, &dst))
+		assert.Equal(t, Invoice{Number: 42, Balance: -7, Account: 12}, dst)
+	})
+	t.Run("zero", func(t *testing.T) {
+		var dst Invoice
+		require.NoError(t, Map(map[string]string{"number": "000000"}, &dst))
+		assert.Equal(t, 0, dst.Number)
+	})
+	t.Run("too-long", func(t *testing.T) {
+		var dst map[string]string
+		require.NoError(t, Map(Invoice{Number: 1234567}, &dst))
+		assert.Equal(t, "1234567", dst["number"])
+	})
+	t.Run("struct-to-struct", func(t *testing.T) {
+		type Dst struct {
+			Number string `map:"number"`
+		}
+		var dst Dst
+		require.NoError(t, Map(Invoice{Number: 42}, &dst))
+		assert.Equal(t, Dst{Number: "000042"}, dst)
+	})
+	t.Run("struct-to-any-map", func(t *testing.T) {
+		var dst map[string]any
+		require.NoError(t, Map(Invoice{Number: 42, Balance: -7, Account: 12}, &dst))
+		assert.Equal(t, map[string]any{"number": "000042", "balance": "-0007", "account": "  12"}, dst)
+	})
+	t.Run("interface-source", func(t *testing.T) {
+		type Src struct {
+			Number any `map:"number,width=6"`
+		}
+		var dst map[string]string
+		require.NoError(t, Map(Src{Number: 42}, &dst))
+		assert.Equal(t, map[string]string{"number": "000042"}, dst)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		type Width struct {
+			Number int `map:"number,width=six"`
+		}
+		type Pad struct {
+			Number int `map:"number,width=6,pad=ab"`
+		}
+		var dst map[string]string
+		assert.Error(t, Map(Width{Number: 42}, &dst))
+		assert.Error(t, Map(Pad{Number: 42}, &dst))
+	})
+}
+
+func TestAliasTag(t *testing.T) {
+	type Dst struct {
+		Name string `map:"name|full_name|fullname"`
+		Age  int
+	}
+	t.Run("map", func(t *testing.T) {
+		var dst Dst
+		require.NoError(t, Map(map[string]any{"fullname": "foo", "full_name": "bar", "Age": 1}, &dst))
+		assert.Equal(t, Dst{Name: "bar", Age: 1}, dst)
+	})
+	t.Run("map-primary", func(t *testing.T) {
+		var dst Dst
+		require.NoError(t, Map(map[string]any{"name": "foo", "full_name": "bar"}, &dst))
+		assert.Equal(t, "foo", dst.Name)
+	})
+	t.Run("struct", func(t *testing.T) {
+		type Src struct {
+			FullName string `map:"fullname"`
+		}
+		var dst Dst
+		require.NoError(t, Map(Src{FullName: "foo"}, &dst))
+		assert.Equal(t, "foo", dst.Name)
+	})
+	t.Run("unmapped-keys", func(t *testing.T) {
+		m := New()
+		var keys []string
+		m.Hooks.UnmappedKeyHook = func(m *Mapper, ctx *Context, key string, src reflect.Value) error {
+			keys = append(keys, key)
+			return nil
+		}
+		var dst Dst
+		require.NoError(t, m.Map(map[string]any{"full_name": "foo"}, &dst))
+		assert.Empty(t, keys)
+	})
+	t.Run("struct-unmapped-keys", func(t *testing.T) {
+		type Src struct {
+			Name     string `map:"name"`
+			FullName string `map:"full_name"`
+		}
+		m := New()
+		var keys []string
+		m.Hooks.UnmappedKeyHook = func(m *Mapper, ctx *Context, key string, src reflect.Value) error {
+			keys = append(keys, key)
+			return nil
+		}
+		var dst Dst
+		require.NoError(t, m.Map(Src{Name: "foo", FullName: "bar"}, &dst))
+		assert.Equal(t, "foo", dst.Name)
+		assert.Empty(t, keys)
+		keys = nil
+		require.NoError(t, m.MapContext(m.Context.WithoutFields("name"), Src{Name: "foo", FullName: "bar"}, &dst))
+		assert.Empty(t, keys)
+	})
+	t.Run("encode", func(t *testing.T) {
+		var dst map[string]any
+		require.NoError(t, Map(Dst{Name: "foo"}, &dst))
+		assert.Equal(t, map[string]any{"name": "foo", "Age": 0}, dst)
+	})
+}
+
+func TestDefaultTag(t *testing.T) {
+	type Config struct {
+		Host    string        `map:"host,default=localhost"`
+		Port    uint16        `map:"port,default=8080"`
+		Timeout time.Duration `map:"timeout,default=5s"`
+		Debug   bool          `map:"debug,default=true"`
+		Name    string        `map:"name,default="`
+	}
+	t.Run("map", func(t *testing.T) {
+		var dst Config
+		dst.Name = "old"
+		require.NoError(t, Map(map[string]any{"port": 9090, "debug": false}, &dst))
+		assert.Equal(t, Config{Host: "localhost", Port: 9090, Timeout: 5 * time.Second}, dst)
+	})
+	t.Run("struct", func(t *testing.T) {
+		type Src struct {
+			Host string `map:"host"`
+		}
+		var dst Config
+		require.NoError(t, Map(Src{Host: "example.com"}, &dst))
+		assert.Equal(t, Config{Host: "example.com", Port: 8080, Timeout: 5 * time.Second, Debug: true}, dst)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		var dst struct {
+			Port uint16 `map:"port,default=http"`
+		}
+		assert.Error(t, Map(map[string]any{}, &dst))
+	})
+	t.Run("not-missing", func(t *testing.T) {
+		m := New()
+		var missing []string
+		m.Hooks.MissingFieldHook = func(m *Mapper, ctx *Context, field reflect.StructField, key string, dst reflect.Value) error {
+			missing = append(missing, key)
+			return nil
+		}
+		var dst Config
+		require.NoError(t, m.Map(map[string]any{}, &dst))
+		assert.Empty(t, missing)
+	})
+}
@@ -0,0 +1,74 @@
+package anymapper
+
+import (
+	"reflect"
+	"time"
+)
+
+// isTextBytes reports whether the value v is a byte slice that must be
+// treated as text, because Context.TextBytes is enabled.
+func isTextBytes(ctx *Context, v reflect.Value) bool {
+	return ctx.TextBytes && v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8
+}
+
+// mapTextToNumber parses the byte slice src as a decimal number, in the
+// same way as strings are parsed.
+func mapTextToNumber(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	text := reflect.ValueOf(string(src.Bytes()))
+	var err error
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		err = mapStringToInt(m, ctx, text, dst)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		err = mapStringToUint(m, ctx, text, dst)
+	case reflect.Float32, reflect.Float64:
+		err = mapStringToFloat(m, ctx, text, dst)
+	default:
+		return NewInvalidMappingError(src.Type(), dst.Type(), "")
+	}
+	return textError(src, err)
+}
+
+// mapNumberToText formats the number src as a decimal number, in the same
+// way as numbers are mapped to strings, and stores it in the byte slice dst.
+func mapNumberToText(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	text := reflect.New(stringTy).Elem()
+	var err error
+	switch src.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		err = mapIntToString(m, ctx, src, text)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		err = mapUintToString(m, ctx, src, text)
+	case reflect.Float32, reflect.Float64:
+		err = mapFloatToString(m, ctx, src, text)
+	default:
+		return NewInvalidMappingError(src.Type(), dst.Type(), "")
+	}
+	if err != nil {
+		return err
+	}
+	dst.SetBytes([]byte(text.String()))
+	return nil
+}
+
+// mapTextToTime parses the byte slice src as a time, in the same way as
+// strings are parsed.
+func mapTextToTime(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+	return textError(src, mapStringToTime(m, ctx, reflect.ValueOf(string(src.Bytes())), dst))
+}
+
+// mapTimeToText formats the time src using Context.TimeLayout and stores it
+// in the byte slice dst.
+func mapTimeToText(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	dst.SetBytes([]byte(src.Interface().(time.Time).Format(ctx.timeLayout())))
+	return nil
+}
+
+// textError replaces the source type of a mapping error returned for the
+// text of a byte slice with the type of the byte slice.
+func textError(src reflect.Value, err error) error {
+	if e, ok := err.(*InvalidMappingErr); ok {
+		e.From = src.Type()
+	}
+	return err
+}
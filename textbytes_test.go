@@ -0,0 +1,79 @@
+package anymapper
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextBytes(t *testing.T) {
+	ctx := Default.Context.WithTextBytes(true)
+	t.Run("to-number", func(t *testing.T) {
+		var i int
+		var u uint8
+		var f float64
+		require.NoError(t, MapContext(ctx, []byte("-42"), &i))
+		require.NoError(t, MapContext(ctx, []byte("42"), &u))
+		require.NoError(t, MapContext(ctx, []byte("1.5"), &f))
+		assert.Equal(t, -42, i)
+		assert.Equal(t, uint8(42), u)
+		assert.Equal(t, 1.5, f)
+	})
+	t.Run("from-number", func(t *testing.T) {
+		var b []byte
+		require.NoError(t, MapContext(ctx, -42, &b))
+		assert.Equal(t, []byte("-42"), b)
+		require.NoError(t, MapContext(ctx, uint(42), &b))
+		assert.Equal(t, []byte("42"), b)
+		require.NoError(t, MapContext(ctx, 1.5, &b))
+		assert.Equal(t, []byte("1.5"), b)
+	})
+	t.Run("time", func(t *testing.T) {
+		tm := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		var b []byte
+		require.NoError(t, MapContext(ctx, tm, &b))
+		assert.Equal(t, []byte("2024-01-02T03:04:05Z"), b)
+		var dst time.Time
+		require.NoError(t, MapContext(ctx, b, &dst))
+		assert.True(t, tm.Equal(dst))
+	})
+	t.Run("big-int", func(t *testing.T) {
+		var b []byte
+		require.NoError(t, MapContext(ctx, big.NewInt(-1000), &b))
+		assert.Equal(t, []byte("-1000"), b)
+		var dst big.Int
+		require.NoError(t, MapContext(ctx, []byte("123456789012345678901234567890"), &dst))
+		assert.Equal(t, "123456789012345678901234567890", dst.String())
+	})
+	t.Run("float16", func(t *testing.T) {
+		var h Float16
+		require.NoError(t, MapContext(ctx, []byte("1.5"), &h))
+		assert.Equal(t, 1.5, h.Float64())
+		var b []byte
+		require.NoError(t, MapContext(ctx, h, &b))
+		assert.Equal(t, []byte("1.5"), b)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		var i int
+		err := MapContext(ctx, []byte("abc"), &i)
+		var mErr *InvalidMappingErr
+		require.ErrorAs(t, err, &mErr)
+		assert.Equal(t, "[]uint8", mErr.From.String())
+		var tm time.Time
+		assert.Error(t, MapContext(ctx, []byte("yesterday"), &tm))
+	})
+	t.Run("array", func(t *testing.T) {
+		// Byte arrays keep the binary representation.
+		var i uint16
+		require.NoError(t, MapContext(ctx, [2]byte{1, 2}, &i))
+		assert.Equal(t, uint16(0x0102), i)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		var i uint16
+		require.NoError(t, Map([]byte("42"), &i))
+		assert.Equal(t, uint16(0x3432), i)
+	})
+}
@@ -0,0 +1,68 @@
+package anymapper
+
+import "reflect"
+
+// textMarshaler and textUnmarshaler are equivalent to the interfaces of the
+// encoding package, whose name is taken by the encoding function.
+type (
+	textMarshaler   interface{ MarshalText() ([]byte, error) }
+	textUnmarshaler interface{ UnmarshalText([]byte) error }
+)
+
+var (
+	textMarshalerTy   = reflect.TypeOf((*textMarshaler)(nil)).Elem()
+	textUnmarshalerTy = reflect.TypeOf((*textUnmarshaler)(nil)).Elem()
+)
+
+// implTextMarshaler returns true if the type or a pointer to it implements
+// the encoding.TextMarshaler interface.
+func implTextMarshaler(t reflect.Type) bool {
+	return t.Kind() != reflect.Interface && reflect.PointerTo(t).Implements(textMarshalerTy)
+}
+
+// implTextUnmarshaler returns true if a pointer to the type implements the
+// encoding.TextUnmarshaler interface.
+func implTextUnmarshaler(t reflect.Type) bool {
+	return t.Kind() != reflect.Interface && reflect.PointerTo(t).Implements(textUnmarshalerTy)
+}
+
+// textMarshalerFunc returns a MapFunc that maps strings to types
+// implementing the encoding.TextUnmarshaler interface, and such types to
+// strings using the encoding.TextMarshaler interface, if
+// Context.TextMarshalers is enabled. Otherwise, and for other types, the
+// next MapFunc is returned, which may be nil.
+func textMarshalerFunc(ctx *Context, src, dst reflect.Type, next MapFunc) MapFunc {
+	if src == dst || !ctx.TextMarshalers {
+		return next
+	}
+	switch {
+	case src.Kind() == reflect.String && implTextUnmarshaler(dst):
+		return mapStringToTextUnmarshaler
+	case dst.Kind() == reflect.String && implTextMarshaler(src):
+		return mapTextMarshalerToString
+	}
+	return next
+}
+
+func mapStringToTextUnmarshaler(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	text := []byte(ctx.parseInput(src.String()))
+	if err := dst.Addr().Interface().(textUnmarshaler).UnmarshalText(text); err != nil {
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	return nil
+}
+
+func mapTextMarshalerToString(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	text, err := addr(src).Interface().(textMarshaler).MarshalText()
+	if err != nil {
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	dst.SetString(string(text))
+	return nil
+}
@@ -0,0 +1,89 @@
+package anymapper
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testColor struct {
+	R, G, B uint8
+}
+
+func (c testColor) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)), nil
+}
+
+func (c *testColor) UnmarshalText(text []byte) error {
+	if len(text) != 7 || text[0] != '#' {
+		return errors.New("invalid color")
+	}
+	b, err := hex.DecodeString(string(text[1:]))
+	if err != nil {
+		return err
+	}
+	c.R, c.G, c.B = b[0], b[1], b[2]
+	return nil
+}
+
+type testID [4]byte
+
+func (id testID) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(id[:])), nil
+}
+
+func (id *testID) UnmarshalText(text []byte) error {
+	_, err := hex.Decode(id[:], text)
+	return err
+}
+
+func TestTextMarshalers(t *testing.T) {
+	ctx := Default.Context.WithTextMarshalers(true)
+
+	t.Run("string-to-value", func(t *testing.T) {
+		var c testColor
+		require.NoError(t, MapContext(ctx, "#ff8000", &c))
+		assert.Equal(t, testColor{R: 255, G: 128}, c)
+		assert.Error(t, MapContext(ctx, "red", &c))
+		var id testID
+		require.NoError(t, MapContext(ctx, "0102abcd", &id))
+		assert.Equal(t, testID{1, 2, 0xab, 0xcd}, id)
+	})
+	t.Run("value-to-string", func(t *testing.T) {
+		var s string
+		require.NoError(t, MapContext(ctx, testColor{G: 255}, &s))
+		assert.Equal(t, "#00ff00", s)
+		require.NoError(t, MapContext(ctx, &testID{1, 2, 3, 4}, &s))
+		assert.Equal(t, "01020304", s)
+	})
+	t.Run("struct-fields", func(t *testing.T) {
+		type Theme struct {
+			Color testColor `map:"color"`
+			ID    *testID   `map:"id"`
+		}
+		var theme Theme
+		require.NoError(t, MapContext(ctx, map[string]string{"color": "#000001", "id": "ffffffff"}, &theme))
+		assert.Equal(t, Theme{Color: testColor{B: 1}, ID: &testID{0xff, 0xff, 0xff, 0xff}}, theme)
+		var m map[string]any
+		require.NoError(t, MapContext(ctx, theme, &m))
+		assert.Equal(t, testColor{B: 1}, m["color"])
+		var out map[string]string
+		require.NoError(t, MapContext(ctx, theme, &out))
+		assert.Equal(t, map[string]string{"color": "#000001", "id": "ffffffff"}, out)
+	})
+	t.Run("flag-values-precedence", func(t *testing.T) {
+		var l testLevel
+		require.NoError(t, MapContext(ctx.WithFlagValues(true), "high", &l))
+		assert.Equal(t, testLevel(2), l)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		var c testColor
+		assert.Error(t, Map("#ff8000", &c))
+		var s string
+		assert.Error(t, Map(testColor{}, &s))
+	})
+}
@@ -0,0 +1,18 @@
+module github.com/defiweb/go-anymapper/textnorm
+
+go 1.18
+
+require (
+	github.com/defiweb/go-anymapper v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.8.4
+	golang.org/x/text v0.14.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// The module uses APIs of the mapper that are not in a tagged release yet.
+replace github.com/defiweb/go-anymapper => ../
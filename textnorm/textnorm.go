@@ -0,0 +1,37 @@
+// Package textnorm provides string transforms that apply Unicode
+// normalization to strings mapped by the mapper.
+//
+// The transforms can be used as Context.InputTransforms and
+// Context.OutputTransforms, e.g.:
+//
+//	ctx := anymapper.Default.Context.WithInputTransforms(textnorm.NFC)
+//
+// The package is a separate module, so the golang.org/x/text module is not
+// required by the main module.
+package textnorm
+
+import (
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/defiweb/go-anymapper"
+)
+
+var (
+	_ anymapper.StringTransform = NFC
+	_ anymapper.StringTransform = NFKC
+)
+
+// NFC is a StringTransform that converts strings to the Unicode
+// Normalization Form C, so canonically equivalent strings, like "é" written
+// as one or two code points, are mapped to the same string.
+func NFC(s string) string {
+	return norm.NFC.String(s)
+}
+
+// NFKC is a StringTransform that converts strings to the Unicode
+// Normalization Form KC. In addition to NFC, it replaces compatibility
+// characters, like ligatures or full-width letters, with their canonical
+// equivalents.
+func NFKC(s string) string {
+	return norm.NFKC.String(s)
+}
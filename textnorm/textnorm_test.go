@@ -0,0 +1,29 @@
+package textnorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defiweb/go-anymapper"
+)
+
+func TestNFC(t *testing.T) {
+	ctx := anymapper.Default.Context.WithInputTransforms(NFC)
+	var dst struct{ Name string }
+	require.NoError(t, anymapper.MapContext(ctx, map[string]any{"Name": "Cafe\u0301"}, &dst))
+	assert.Equal(t, "Caf\u00e9", dst.Name)
+	// Compatibility characters are kept.
+	require.NoError(t, anymapper.MapContext(ctx, map[string]any{"Name": "\ufb01le"}, &dst))
+	assert.Equal(t, "\ufb01le", dst.Name)
+}
+
+func TestNFKC(t *testing.T) {
+	ctx := anymapper.Default.Context.WithInputTransforms(NFKC)
+	var dst struct{ Name string }
+	require.NoError(t, anymapper.MapContext(ctx, map[string]any{"Name": "\ufb01le \uff21"}, &dst))
+	assert.Equal(t, "file A", dst.Name)
+	require.NoError(t, anymapper.MapContext(ctx, map[string]any{"Name": "Cafe\u0301"}, &dst))
+	assert.Equal(t, "Caf\u00e9", dst.Name)
+}
@@ -0,0 +1,97 @@
+package anymapper
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// StringTransform transforms a string mapped to or from other types, see
+// Context.InputTransforms and Context.OutputTransforms. Transforms should be
+// idempotent, because a string may be transformed more than once when it
+// is mapped through intermediate values. Unicode normalization transforms
+// are provided by the textnorm module.
+type StringTransform func(string) string
+
+// FoldCase is a StringTransform that applies simple Unicode case folding,
+// so strings that are equal according to strings.EqualFold are mapped to
+// the same string. Every rune is replaced with the lower-case rune of its
+// case folding orbit, see unicode.SimpleFold.
+func FoldCase(s string) string {
+	return strings.Map(foldRune, s)
+}
+
+// foldRune returns the lower-case rune of the case folding orbit of r, or
+// the smallest rune of the orbit if the orbit has no lower-case rune.
+func foldRune(r rune) rune {
+	c := orbitMin(r)
+	if l := unicode.ToLower(c); l != c && orbitMin(l) == c {
+		return l
+	}
+	return c
+}
+
+// orbitMin returns the smallest rune of the case folding orbit of r.
+func orbitMin(r rune) rune {
+	m := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < m {
+			m = f
+		}
+	}
+	return m
+}
+
+// TrimControl is a StringTransform that removes leading and trailing
+// control characters, including whitespace characters such as newlines.
+func TrimControl(s string) string {
+	return strings.TrimFunc(s, unicode.IsControl)
+}
+
+// RemoveControl is a StringTransform that removes all control characters.
+func RemoveControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// transform applies the transforms to s in order.
+func transform(transforms []StringTransform, s string) string {
+	for _, t := range transforms {
+		s = t(s)
+	}
+	return s
+}
+
+// containsStrings reports whether t is a string, or a slice, array or map
+// whose values contain strings.
+func containsStrings(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String:
+		return true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return containsStrings(t.Elem())
+	}
+	return false
+}
+
+// transformInput applies Context.InputTransforms to a string source. Other
+// values are returned as is.
+func (c *Context) transformInput(src reflect.Value) reflect.Value {
+	if len(c.InputTransforms) == 0 || src.Kind() != reflect.String {
+		return src
+	}
+	return reflect.ValueOf(transform(c.InputTransforms, src.String())).Convert(src.Type())
+}
+
+// transformOutput applies Context.OutputTransforms to a string destination
+// mapped from a value that is not a string.
+func (c *Context) transformOutput(src, dst reflect.Value) {
+	if len(c.OutputTransforms) == 0 || dst.Kind() != reflect.String || src.Kind() == reflect.String || !dst.CanSet() {
+		return
+	}
+	dst.SetString(transform(c.OutputTransforms, dst.String()))
+}
@@ -0,0 +1,86 @@
+package anymapper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringTransforms(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   StringTransform
+		src  string
+		exp  string
+	}{
+		{name: "fold-case", fn: FoldCase, src: "Straße ſ ÀB", exp: "straße s àb"},
+		{name: "fold-case-orbits", fn: FoldCase, src: "\u212a Σς \u0130", exp: "k σσ \u0130"},
+		{name: "trim-control", fn: TrimControl, src: "\x00\t a\x01b \n\x7f", exp: " a\x01b "},
+		{name: "remove-control", fn: RemoveControl, src: "\ta\x01b\r\n", exp: "ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.exp, tt.fn(tt.src))
+		})
+	}
+}
+
+func TestInputTransforms(t *testing.T) {
+	ctx := Default.Context.WithInputTransforms(TrimControl, FoldCase)
+	t.Run("string-to-number", func(t *testing.T) {
+		var dst int
+		require.NoError(t, MapContext(ctx, "\x0042\n", &dst))
+		assert.Equal(t, 42, dst)
+	})
+	t.Run("string-to-string", func(t *testing.T) {
+		var dst string
+		require.NoError(t, MapContext(ctx, "Foo\n", &dst))
+		assert.Equal(t, "foo", dst)
+	})
+	t.Run("struct-fields", func(t *testing.T) {
+		type User struct {
+			Email string
+			Tags  []string
+			Attrs map[string]string
+		}
+		var dst User
+		require.NoError(t, MapContext(ctx, map[string]any{
+			"Email": "John@Example.COM\r\n",
+			"Tags":  []string{"A", "b"},
+			"Attrs": map[string]string{"Key": "Value"},
+		}, &dst))
+		assert.Equal(t, User{
+			Email: "john@example.com",
+			Tags:  []string{"a", "b"},
+			Attrs: map[string]string{"Key": "value"},
+		}, dst)
+	})
+	t.Run("same-type-slice", func(t *testing.T) {
+		var dst []string
+		require.NoError(t, MapContext(ctx, []string{"A"}, &dst))
+		assert.Equal(t, []string{"a"}, dst)
+		var arr [1]string
+		require.NoError(t, MapContext(ctx, [1]string{"B"}, &arr))
+		assert.Equal(t, [1]string{"b"}, arr)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		var dst []string
+		require.NoError(t, Map([]string{"A"}, &dst))
+		assert.Equal(t, []string{"A"}, dst)
+	})
+}
+
+func TestOutputTransforms(t *testing.T) {
+	ctx := Default.Context.WithOutputTransforms(func(s string) string { return "<" + s + ">" })
+	var dst string
+	require.NoError(t, MapContext(ctx, 42, &dst))
+	assert.Equal(t, "<42>", dst)
+	// Strings mapped to strings are not transformed.
+	require.NoError(t, MapContext(ctx, "a", &dst))
+	assert.Equal(t, "a", dst)
+	var list []string
+	require.NoError(t, MapContext(ctx.WithOutputTransforms(strings.ToUpper), []bool{true}, &list))
+	assert.Equal(t, []string{"TRUE"}, list)
+}
@@ -1,9 +1,16 @@
 package anymapper
 
 import (
+	"fmt"
+	"io/fs"
 	"math"
 	"math/big"
+	"net"
+	"net/mail"
 	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -12,8 +19,38 @@ var (
 	bigIntTy   = reflect.TypeOf((*big.Int)(nil)).Elem()
 	bigFloatTy = reflect.TypeOf((*big.Float)(nil)).Elem()
 	bigRatTy   = reflect.TypeOf((*big.Rat)(nil)).Elem()
+	monthTy    = reflect.TypeOf((*time.Month)(nil)).Elem()
+	weekdayTy  = reflect.TypeOf((*time.Weekday)(nil)).Elem()
+	fileModeTy = reflect.TypeOf((*fs.FileMode)(nil)).Elem()
+	regexpTy   = reflect.TypeOf((*regexp.Regexp)(nil)).Elem()
+	macTy      = reflect.TypeOf((*net.HardwareAddr)(nil)).Elem()
+	mailAddrTy = reflect.TypeOf((*mail.Address)(nil)).Elem()
+	locationTy = reflect.TypeOf((*time.Location)(nil)).Elem()
 )
 
+// calendarEnum describes an integer enum type from the time package that
+// is mapped to and from its English names.
+type calendarEnum struct {
+	typ   reflect.Type
+	first int64    // value of the first name
+	names []string // names of consecutive values
+}
+
+var (
+	monthEnum   = newCalendarEnum(monthTy, int64(time.January), int64(time.December))
+	weekdayEnum = newCalendarEnum(weekdayTy, int64(time.Sunday), int64(time.Saturday))
+)
+
+func newCalendarEnum(typ reflect.Type, first, last int64) *calendarEnum {
+	e := &calendarEnum{typ: typ, first: first}
+	for n := first; n <= last; n++ {
+		v := reflect.New(typ).Elem()
+		v.SetInt(n)
+		e.names = append(e.names, v.Interface().(interface{ String() string }).String())
+	}
+	return e
+}
+
 func timeTypeMapper(_ *Mapper, src, dst reflect.Type) MapFunc {
 	if src == dst {
 		return mapDirect
@@ -36,6 +73,16 @@ func timeTypeMapper(_ *Mapper, src, dst reflect.Type) MapFunc {
 			case bigFloatTy:
 				return mapTimeToBigFloat
 			}
+			switch {
+			case isSecondsNanos(dst):
+				return mapTimeToSecondsNanos
+			case isCivilDate(dst), isCivilTime(dst):
+				return mapTimeToCivil
+			}
+		case reflect.Map:
+			if isSecondsNanosMap(dst) {
+				return mapTimeToSecondsNanos
+			}
 		case reflect.Bool, reflect.Int8, reflect.Int16, reflect.Uint8, reflect.Uint16:
 			return nil
 		}
@@ -57,6 +104,16 @@ func timeTypeMapper(_ *Mapper, src, dst reflect.Type) MapFunc {
 			case bigFloatTy:
 				return mapBigFloatToTime
 			}
+			switch {
+			case isSecondsNanos(src):
+				return mapSecondsNanosToTime
+			case isCivilDate(src), isCivilTime(src):
+				return mapCivilToTime
+			}
+		case reflect.Map:
+			if isSecondsNanosMap(src) {
+				return mapSecondsNanosToTime
+			}
 		case reflect.Bool, reflect.Int8, reflect.Int16, reflect.Uint8, reflect.Uint16:
 			return nil
 		}
@@ -65,6 +122,331 @@ func timeTypeMapper(_ *Mapper, src, dst reflect.Type) MapFunc {
 	return nil
 }
 
+// typeMapper maps the enum to and from strings using English names. Other
+// conversions use the built-in integer rules, but the values mapped to the
+// enum must be in its range.
+func (e *calendarEnum) typeMapper(m *Mapper, src, dst reflect.Type) MapFunc {
+	if src == dst {
+		return mapDirect
+	}
+	switch {
+	case src == e.typ && dst.Kind() == reflect.String:
+		return e.mapToString
+	case dst == e.typ && src.Kind() == reflect.String:
+		return e.mapFromString
+	case dst == e.typ:
+		mapFunc := builtInTypesMapper(m, src, dst)
+		if mapFunc == nil {
+			return nil
+		}
+		return func(m *Mapper, ctx *Context, src, dst reflect.Value) error {
+			// The value is validated before it is set, so the destination
+			// keeps its value if it is out of range.
+			tmp := reflect.New(dst.Type()).Elem()
+			if err := mapFunc(m, ctx, src, tmp); err != nil {
+				return err
+			}
+			if !e.valid(tmp.Int()) {
+				return NewInvalidMappingError(src.Type(), dst.Type(), "out of range")
+			}
+			dst.Set(tmp)
+			return nil
+		}
+	case dst == anyTy:
+		return mapAny
+	}
+	return builtInTypesMapper(m, src, dst)
+}
+
+func (e *calendarEnum) valid(n int64) bool {
+	return n >= e.first && n < e.first+int64(len(e.names))
+}
+
+func (e *calendarEnum) mapToString(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	if !e.valid(src.Int()) {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "out of range")
+	}
+	dst.SetString(e.names[src.Int()-e.first])
+	return nil
+}
+
+// mapFromString parses a full English name, a three-letter abbreviation or
+// a number. Names are case-insensitive.
+func (e *calendarEnum) mapFromString(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	s := src.String()
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if !e.valid(n) {
+			return NewInvalidMappingError(src.Type(), dst.Type(), "out of range")
+		}
+		dst.SetInt(n)
+		return nil
+	}
+	for i, name := range e.names {
+		if strings.EqualFold(name, s) || (len(s) == 3 && strings.EqualFold(name[:3], s)) {
+			dst.SetInt(e.first + int64(i))
+			return nil
+		}
+	}
+	return NewInvalidMappingError(src.Type(), dst.Type(), "unknown name")
+}
+
+func fileModeTypeMapper(m *Mapper, src, dst reflect.Type) MapFunc {
+	if src == dst {
+		return mapDirect
+	}
+	switch {
+	case src == fileModeTy && dst.Kind() == reflect.String:
+		return mapFileModeToString
+	case dst == fileModeTy && src.Kind() == reflect.String:
+		return mapStringToFileMode
+	case dst == anyTy:
+		return mapAny
+	}
+	return builtInTypesMapper(m, src, dst)
+}
+
+// fileModeSpecial maps the Unix special permission bits to their FileMode
+// counterparts.
+var fileModeSpecial = []struct {
+	unix uint32
+	mode fs.FileMode
+}{
+	{unix: 0o4000, mode: fs.ModeSetuid},
+	{unix: 0o2000, mode: fs.ModeSetgid},
+	{unix: 0o1000, mode: fs.ModeSticky},
+}
+
+func mapFileModeToString(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	mode := fs.FileMode(src.Uint())
+	unix := uint32(mode.Perm())
+	for _, s := range fileModeSpecial {
+		if mode&s.mode != 0 {
+			unix |= s.unix
+		}
+	}
+	dst.SetString(fmt.Sprintf("%04o", unix))
+	return nil
+}
+
+// mapStringToFileMode parses an octal string, like "0644" or "0o644", or
+// a symbolic string, like "rw-r--r--" or "drwxr-xr-x".
+func mapStringToFileMode(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	mode, ok := parseFileMode(src.String())
+	if !ok {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "invalid file mode")
+	}
+	dst.SetUint(uint64(mode))
+	return nil
+}
+
+func parseFileMode(s string) (fs.FileMode, bool) {
+	if len(s) == 9 || len(s) == 10 {
+		return parseSymbolicFileMode(s)
+	}
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0o"), "0O")
+	unix, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || unix > 0o7777 {
+		return 0, false
+	}
+	mode := fs.FileMode(unix & 0o777)
+	for _, s := range fileModeSpecial {
+		if uint32(unix)&s.unix != 0 {
+			mode |= s.mode
+		}
+	}
+	return mode, true
+}
+
+// parseSymbolicFileMode parses the permission bits in the format used by
+// the ls command. The optional first character may be "-" or "d".
+func parseSymbolicFileMode(s string) (fs.FileMode, bool) {
+	var mode fs.FileMode
+	if len(s) == 10 {
+		switch s[0] {
+		case '-':
+		case 'd':
+			mode |= fs.ModeDir
+		default:
+			return 0, false
+		}
+		s = s[1:]
+	}
+	const rwx = "rwxrwxrwx"
+	for i := 0; i < len(rwx); i++ {
+		switch s[i] {
+		case rwx[i]:
+			mode |= 1 << uint(8-i)
+		case '-':
+		default:
+			return 0, false
+		}
+	}
+	return mode, true
+}
+
+func regexpTypeMapper(m *Mapper, src, dst reflect.Type) MapFunc {
+	if src == dst {
+		return mapDirect
+	}
+	switch {
+	case src == regexpTy && dst.Kind() == reflect.String:
+		return mapRegexpToString
+	case dst == regexpTy && src.Kind() == reflect.String:
+		return mapStringToRegexp
+	case dst == anyTy:
+		return mapAny
+	}
+	return builtInTypesMapper(m, src, dst)
+}
+
+func mapRegexpToString(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	dst.SetString(src.Addr().Interface().(*regexp.Regexp).String())
+	return nil
+}
+
+func mapStringToRegexp(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	re, err := regexp.Compile(src.String())
+	if err != nil {
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	dst.Set(reflect.ValueOf(re).Elem())
+	return nil
+}
+
+// macTypeMapper maps MAC addresses to and from strings using net.ParseMAC.
+// Other conversions, like to and from byte slices and arrays, use the
+// built-in rules.
+func macTypeMapper(m *Mapper, src, dst reflect.Type) MapFunc {
+	switch {
+	case src == macTy && dst.Kind() == reflect.String:
+		return mapMACToString
+	case dst == macTy && src.Kind() == reflect.String:
+		return mapStringToMAC
+	case dst == anyTy:
+		return mapAny
+	}
+	return builtInTypesMapper(m, src, dst)
+}
+
+func mapMACToString(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	dst.SetString(net.HardwareAddr(src.Bytes()).String())
+	return nil
+}
+
+func mapStringToMAC(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	mac, err := net.ParseMAC(src.String())
+	if err != nil {
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	dst.SetBytes(mac)
+	return nil
+}
+
+// mailAddrTypeMapper maps email addresses to and from RFC 5322 strings.
+// Other conversions, like to and from maps, use the built-in rules.
+func mailAddrTypeMapper(m *Mapper, src, dst reflect.Type) MapFunc {
+	switch {
+	case src == mailAddrTy && dst.Kind() == reflect.String:
+		return mapMailAddrToString
+	case dst == mailAddrTy && src.Kind() == reflect.String:
+		return mapStringToMailAddr
+	case dst == anyTy:
+		return mapAny
+	}
+	return builtInTypesMapper(m, src, dst)
+}
+
+func mapMailAddrToString(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	addr := src.Addr().Interface().(*mail.Address)
+	if addr.Name == "" {
+		// Without a name, the address is used as is instead of the
+		// "<a@b.c>" form.
+		dst.SetString(addr.Address)
+		return nil
+	}
+	dst.SetString(addr.String())
+	return nil
+}
+
+func mapStringToMailAddr(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	addr, err := mail.ParseAddress(src.String())
+	if err != nil {
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+	}
+	dst.Set(reflect.ValueOf(addr).Elem())
+	return nil
+}
+
+func locationTypeMapper(m *Mapper, src, dst reflect.Type) MapFunc {
+	if src == dst {
+		return mapDirect
+	}
+	switch {
+	case src == locationTy && dst.Kind() == reflect.String:
+		return mapLocationToString
+	case dst == locationTy && src.Kind() == reflect.String:
+		return mapStringToLocation
+	case dst == anyTy:
+		return mapAny
+	}
+	return builtInTypesMapper(m, src, dst)
+}
+
+func mapLocationToString(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	dst.SetString(src.Addr().Interface().(*time.Location).String())
+	return nil
+}
+
+func mapStringToLocation(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	loc, err := time.LoadLocation(src.String())
+	if err != nil {
+		e := NewInvalidMappingError(src.Type(), dst.Type(), "unknown time zone: "+src.String())
+		e.Err = err
+		return e
+	}
+	// The Local location is initialized lazily by its methods, so it must
+	// be initialized before it is copied.
+	_ = loc.String()
+	dst.Set(reflect.ValueOf(loc).Elem())
+	return nil
+}
+
 func bigIntTypeMapper(_ *Mapper, src, dst reflect.Type) MapFunc {
 	if src == dst {
 		return mapDirect
@@ -122,6 +504,12 @@ func bigRatTypeMapper(_ *Mapper, src, dst reflect.Type) MapFunc {
 	}
 	switch {
 	case src == bigRatTy:
+		switch dst {
+		case bigIntTy:
+			return mapBigRatToBigInt
+		case bigFloatTy:
+			return mapBigRatToBigFloat
+		}
 		switch dst.Kind() {
 		case reflect.String:
 			return mapBigRatToString
@@ -130,6 +518,12 @@ func bigRatTypeMapper(_ *Mapper, src, dst reflect.Type) MapFunc {
 		}
 		return mapFromBigRatViaBigFloat
 	case dst == bigRatTy:
+		switch src {
+		case bigIntTy:
+			return mapBigIntToBigRat
+		case bigFloatTy:
+			return mapBigFloatToBigRat
+		}
 		switch src.Kind() {
 		case reflect.String:
 			return mapStringToBigRat
@@ -147,6 +541,10 @@ func bigFloatTypeMapper(_ *Mapper, src, dst reflect.Type) MapFunc {
 	}
 	switch {
 	case src == bigFloatTy:
+		if dst == durationTy {
+			// Durations are mapped to and from big.Float values as seconds.
+			return mapBigFloatToDuration
+		}
 		switch dst.Kind() {
 		case reflect.Bool:
 			return mapBigFloatToBool
@@ -188,7 +586,7 @@ func mapTimeToString(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
-	dst.SetString(src.Interface().(time.Time).Format(time.RFC3339))
+	dst.SetString(src.Interface().(time.Time).Format(ctx.timeLayout()))
 	return nil
 }
 
@@ -255,9 +653,9 @@ func mapStringToTime(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
-	tm, err := time.Parse(time.RFC3339, src.String())
+	tm, err := ctx.parseTime(ctx.parseInput(src.String()))
 	if err != nil {
-		return NewInvalidMappingError(src.Type(), dst.Type(), err.Error())
+		return WrapInvalidMappingError(src.Type(), dst.Type(), err)
 	}
 	dst.Set(reflect.ValueOf(tm))
 	return nil
@@ -318,8 +716,11 @@ func mapFromTimeViaInt64(m *Mapper, ctx *Context, src, dst reflect.Value) error
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
+	if isTextBytes(ctx, dst) {
+		return mapTimeToText(m, ctx, src, dst)
+	}
 	aux := src.Interface().(time.Time).Unix()
-	if err := m.MapRefl(reflect.ValueOf(aux), dst); err != nil {
+	if err := m.MapReflContext(ctx, reflect.ValueOf(aux), dst); err != nil {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "")
 	}
 	return nil
@@ -329,8 +730,11 @@ func mapToTimeViaInt64(m *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
+	if isTextBytes(ctx, src) {
+		return mapTextToTime(m, ctx, src, dst)
+	}
 	var aux int64
-	if err := m.MapRefl(src, reflect.ValueOf(&aux)); err != nil {
+	if err := m.MapReflContext(ctx, src, reflect.ValueOf(&aux)); err != nil {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "")
 	}
 	dst.Set(reflect.ValueOf(time.Unix(aux, 0).UTC()))
@@ -380,6 +784,9 @@ func mapBigIntToFloat(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if dst.OverflowFloat(n) || (math.IsInf(n, 0) && (a == big.Below || a == big.Above)) {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "overflow")
 	}
+	if ctx.Lossless && !exactBigFloat(n, a, dst.Kind()) {
+		return newLossError(src.Type(), dst.Type())
+	}
 	dst.SetFloat(n)
 	return nil
 }
@@ -388,7 +795,14 @@ func mapBigIntToString(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
-	dst.SetString(src.Addr().Interface().(*big.Int).String())
+	v := src.Addr().Interface().(*big.Int)
+	if ctx.Suffixes != NoSuffixes {
+		if s := formatSuffixedBig(ctx.Suffixes, v); s != "" {
+			dst.SetString(s)
+			return nil
+		}
+	}
+	dst.SetString(v.String())
 	return nil
 }
 
@@ -397,6 +811,10 @@ func mapBigIntToBytes(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
 	v := src.Addr().Interface().(*big.Int)
+	if ctx.TextBytes {
+		dst.SetBytes([]byte(v.String()))
+		return nil
+	}
 	if v.Sign() < 0 {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "cannot convert negative big.Int to bytes")
 	}
@@ -444,7 +862,10 @@ func mapFloatToBigInt(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
-	v, _ := new(big.Float).SetFloat64(src.Float()).Int(nil)
+	v, a := new(big.Float).SetFloat64(src.Float()).Int(nil)
+	if ctx.Lossless && a != big.Exact {
+		return newLossError(src.Type(), dst.Type())
+	}
 	dst.Set(reflect.ValueOf(new(big.Int).Set(v)).Elem())
 	return nil
 }
@@ -456,7 +877,21 @@ func mapStringToBigInt(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
-	v, ok := new(big.Int).SetString(src.String(), 0)
+	str := ctx.parseInput(src.String())
+	if ctx.Suffixes != NoSuffixes {
+		r, err := parseSuffixed(str)
+		if err != nil {
+			return WrapInvalidMappingError(src.Type(), dst.Type(), err)
+		}
+		if r != nil {
+			if !r.IsInt() {
+				return NewInvalidMappingError(src.Type(), dst.Type(), "fractional value")
+			}
+			dst.Set(reflect.ValueOf(new(big.Int).Set(r.Num())).Elem())
+			return nil
+		}
+	}
+	v, ok := new(big.Int).SetString(str, 0)
 	if !ok {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "invalid string")
 	}
@@ -464,10 +899,13 @@ func mapStringToBigInt(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 	return nil
 }
 
-func mapBytesToBigInt(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+func mapBytesToBigInt(m *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
+	if ctx.TextBytes {
+		return textError(src, mapStringToBigInt(m, ctx, reflect.ValueOf(string(src.Bytes())), dst))
+	}
 	dst.Set(reflect.ValueOf(new(big.Int).SetBytes(src.Bytes())).Elem())
 	return nil
 }
@@ -476,7 +914,10 @@ func mapBigFloatToBigInt(_ *Mapper, ctx *Context, src, dst reflect.Value) error
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
-	v, _ := src.Addr().Interface().(*big.Float).Int(nil)
+	v, a := src.Addr().Interface().(*big.Float).Int(nil)
+	if ctx.Lossless && a != big.Exact {
+		return newLossError(src.Type(), dst.Type())
+	}
 	dst.Set(reflect.ValueOf(new(big.Int).Set(v)).Elem())
 	return nil
 }
@@ -498,7 +939,10 @@ func mapBigFloatToInt(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
-	v, _ := src.Addr().Interface().(*big.Float).Int(nil)
+	v, a := src.Addr().Interface().(*big.Float).Int(nil)
+	if ctx.Lossless && a != big.Exact {
+		return newLossError(src.Type(), dst.Type())
+	}
 	n := v.Int64()
 	if !v.IsInt64() || dst.OverflowInt(n) {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "overflow")
@@ -511,7 +955,10 @@ func mapBigFloatToUint(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
-	v, _ := src.Addr().Interface().(*big.Float).Int(nil)
+	v, a := src.Addr().Interface().(*big.Float).Int(nil)
+	if ctx.Lossless && a != big.Exact {
+		return newLossError(src.Type(), dst.Type())
+	}
 	n := v.Uint64()
 	if !v.IsUint64() || dst.OverflowUint(n) {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "overflow")
@@ -529,6 +976,9 @@ func mapBigFloatToFloat(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if dst.OverflowFloat(n) || (math.IsInf(n, 0) && (a == big.Below || a == big.Above)) {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "overflow")
 	}
+	if ctx.Lossless && !exactBigFloat(n, a, dst.Kind()) {
+		return newLossError(src.Type(), dst.Type())
+	}
 	dst.SetFloat(n)
 	return nil
 }
@@ -582,7 +1032,7 @@ func mapStringToBigFloat(_ *Mapper, ctx *Context, src, dst reflect.Value) error
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
-	v, ok := new(big.Float).SetString(src.String())
+	v, ok := new(big.Float).SetString(ctx.parseInput(src.String()))
 	if !ok {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "string is not a valid float number")
 	}
@@ -609,10 +1059,10 @@ func mapBigRatToSliceOrArray(m *Mapper, ctx *Context, src, dst reflect.Value) er
 		return NewInvalidMappingError(src.Type(), dst.Type(), "array must have length 2")
 	}
 	v := src.Addr().Interface().(*big.Rat)
-	if err := m.MapRefl(reflect.ValueOf(v.Num()), dst.Index(0)); err != nil {
+	if err := m.MapReflContext(ctx, reflect.ValueOf(v.Num()), dst.Index(0)); err != nil {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "")
 	}
-	if err := m.MapRefl(reflect.ValueOf(v.Denom()), dst.Index(1)); err != nil {
+	if err := m.MapReflContext(ctx, reflect.ValueOf(v.Denom()), dst.Index(1)); err != nil {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "")
 	}
 	return nil
@@ -622,7 +1072,7 @@ func mapStringToBigRat(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
-	v, ok := new(big.Rat).SetString(src.String())
+	v, ok := new(big.Rat).SetString(ctx.parseInput(src.String()))
 	if !ok {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "string is not a valid rational number")
 	}
@@ -638,22 +1088,78 @@ func mapSliceOrArrayToBigRat(m *Mapper, ctx *Context, src, dst reflect.Value) er
 		return NewInvalidMappingError(src.Type(), dst.Type(), "array must have length 2")
 	}
 	var num, den big.Int
-	if err := m.MapRefl(src.Index(0), reflect.ValueOf(&num).Elem()); err != nil {
+	if err := m.MapReflContext(ctx, src.Index(0), reflect.ValueOf(&num).Elem()); err != nil {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "")
 	}
-	if err := m.MapRefl(src.Index(1), reflect.ValueOf(&den).Elem()); err != nil {
+	if err := m.MapReflContext(ctx, src.Index(1), reflect.ValueOf(&den).Elem()); err != nil {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "")
 	}
 	dst.Set(reflect.ValueOf(new(big.Rat).SetFrac(&num, &den)).Elem())
 	return nil
 }
 
+func mapBigRatToBigInt(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	v := src.Addr().Interface().(*big.Rat)
+	if !v.IsInt() {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "not an integer")
+	}
+	dst.Set(reflect.ValueOf(new(big.Int).Set(v.Num())).Elem())
+	return nil
+}
+
+func mapBigRatToBigFloat(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	f := ratToFloat(ctx, src.Addr().Interface().(*big.Rat))
+	if ctx.Lossless && f.Acc() != big.Exact {
+		return newLossError(src.Type(), dst.Type())
+	}
+	dst.Set(reflect.ValueOf(f).Elem())
+	return nil
+}
+
+func mapBigIntToBigRat(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	dst.Set(reflect.ValueOf(new(big.Rat).SetInt(src.Addr().Interface().(*big.Int))).Elem())
+	return nil
+}
+
+// mapBigFloatToBigRat converts finite values exactly, because every finite
+// big.Float value is a binary fraction.
+func mapBigFloatToBigRat(_ *Mapper, ctx *Context, src, dst reflect.Value) error {
+	if ctx.StrictTypes {
+		return NewStrictMappingError(src.Type(), dst.Type())
+	}
+	v := src.Addr().Interface().(*big.Float)
+	if v.IsInf() {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "infinite value")
+	}
+	rat, _ := v.Rat(nil)
+	dst.Set(reflect.ValueOf(rat).Elem())
+	return nil
+}
+
+// ratToFloat converts the big.Rat to big.Float with the precision set in
+// Context.BigFloatPrec.
+func ratToFloat(ctx *Context, r *big.Rat) *big.Float {
+	return new(big.Float).SetPrec(ctx.BigFloatPrec).SetRat(r)
+}
+
 func mapFromBigRatViaBigFloat(m *Mapper, ctx *Context, src, dst reflect.Value) error {
 	if ctx.StrictTypes {
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
-	aux := new(big.Float).SetRat(src.Addr().Interface().(*big.Rat))
-	if err := m.MapRefl(reflect.ValueOf(aux), dst); err != nil {
+	aux := ratToFloat(ctx, src.Addr().Interface().(*big.Rat))
+	if ctx.Lossless && aux.Acc() != big.Exact {
+		return newLossError(src.Type(), dst.Type())
+	}
+	if err := m.MapReflContext(ctx, reflect.ValueOf(aux), dst); err != nil {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "")
 	}
 	return nil
@@ -664,10 +1170,14 @@ func mapToBigRatViaBigFloat(m *Mapper, ctx *Context, src, dst reflect.Value) err
 		return NewStrictMappingError(src.Type(), dst.Type())
 	}
 	aux := reflect.New(bigFloatTy).Elem()
-	if err := m.MapRefl(src, aux); err != nil {
+	if err := m.MapReflContext(ctx, src, aux); err != nil {
 		return NewInvalidMappingError(src.Type(), dst.Type(), "")
 	}
-	rat, _ := aux.Addr().Interface().(*big.Float).Rat(nil)
+	f := aux.Addr().Interface().(*big.Float)
+	if f.IsInf() {
+		return NewInvalidMappingError(src.Type(), dst.Type(), "infinite value")
+	}
+	rat, _ := f.Rat(nil)
 	dst.Set(reflect.ValueOf(rat).Elem())
 	return nil
 }
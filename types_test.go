@@ -1,12 +1,17 @@
 package anymapper
 
 import (
+	"io/fs"
 	"math"
 	"math/big"
+	"net"
+	"net/mail"
+	"regexp"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTypes(t *testing.T) {
@@ -64,7 +69,7 @@ func TestTypes(t *testing.T) {
 		{name: "bool-time.Time", src: true, dst: new(time.Time), err: true},
 		{name: "time.Time-slice", src: tm1, dst: new([]int), err: true},
 		{name: "slice-time.Time", src: []int{1, 2, 3}, dst: new(time.Time), err: true},
-		{name: "time.Time-map", src: tm1, dst: new(map[string]int), err: true},
+		{name: "time.Time-map", src: tm1, dst: new(map[int]int), err: true},
 		{name: "map-time.Time", src: map[string]int{"a": 1, "b": 2}, dst: new(time.Time), err: true},
 		{name: "time.Time-struct", src: tm1, dst: new(struct{}), err: true},
 		{name: "struct-time.Time", src: struct{}{}, dst: new(time.Time), err: true},
@@ -112,6 +117,8 @@ func TestTypes(t *testing.T) {
 		// big.Int <-> big.Rat
 		{name: "big.Int-big.Rat", src: big.NewInt(2), dst: new(big.Rat), exp: big.NewRat(2, 1)},
 		{name: "big.Rat-big.Int", src: big.NewRat(2, 1), dst: new(big.Int), exp: big.NewInt(2)},
+		{name: "big.Rat-big.Int#not-integer", src: big.NewRat(5, 2), dst: new(big.Int), err: true},
+		{name: "big.Int-big.Rat#large", src: new(big.Int).Lsh(big.NewInt(1), 200), dst: new(big.Rat), exp: new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), 200))},
 
 		// big.Int <-> invalid
 		{name: "big.Int-map", src: big.NewInt(1), dst: new(map[string]int), err: true},
@@ -153,6 +160,7 @@ func TestTypes(t *testing.T) {
 		// big.Float <-> big.Rat
 		{name: "big.Float-big.Rat", src: big.NewFloat(0.5), dst: new(big.Rat), exp: big.NewRat(1, 2)},
 		{name: "big.Rat-big.Float", src: big.NewRat(1, 2), dst: new(big.Float), exp: big.NewFloat(0.5)},
+		{name: "big.Float-big.Rat#inf", src: new(big.Float).SetInf(false), dst: new(big.Rat), err: true},
 
 		// big.Float <-> invalid
 		{name: "big.Float-map", src: big.NewFloat(1), dst: new(map[string]int), err: true}, {name: "big.Float-chan", src: big.NewFloat(1), dst: new(chan int), err: true},
@@ -162,6 +170,7 @@ func TestTypes(t *testing.T) {
 
 		// big.Rat <-> big.Rat
 		{name: "big.Rat-big.Rat", src: big.NewRat(1, 2), dst: new(big.Rat), exp: big.NewRat(1, 2)},
+		{name: "big.Rat-any", src: big.NewRat(1, 2), dst: new(any), err: true},
 
 		// big.Rat <-> bool
 		{name: "big.Rat-bool#true", src: big.NewRat(1, 1), dst: new(bool), exp: true},
@@ -202,6 +211,72 @@ func TestTypes(t *testing.T) {
 		{name: "map-big.Rat", src: map[string]string{"foo": "bar"}, dst: new(big.Rat), err: true},
 		{name: "big.Rat-struct", src: big.NewRat(1, 2), dst: new(struct{}), err: true},
 		{name: "struct-big.Rat", src: struct{}{}, dst: new(big.Rat), err: true},
+
+		// time.Month <-> string
+		{name: "time.Month-string", src: time.March, dst: new(string), exp: "March"},
+		{name: "string-time.Month", src: "march", dst: new(time.Month), exp: time.March},
+		{name: "string-time.Month#abbr", src: "Mar", dst: new(time.Month), exp: time.March},
+		{name: "string-time.Month#number", src: "3", dst: new(time.Month), exp: time.March},
+		{name: "string-time.Month#invalid", src: "foo", dst: new(time.Month), err: true},
+		{name: "string-time.Month#out-of-range", src: "13", dst: new(time.Month), err: true},
+		{name: "time.Month-string#out-of-range", src: time.Month(13), dst: new(string), err: true},
+
+		// time.Month <-> int
+		{name: "time.Month-int", src: time.March, dst: new(int), exp: 3},
+		{name: "int-time.Month", src: 3, dst: new(time.Month), exp: time.March},
+		{name: "int-time.Month#out-of-range", src: 0, dst: new(time.Month), err: true},
+		{name: "float64-time.Month", src: 12.0, dst: new(time.Month), exp: time.December},
+
+		// time.Weekday <-> string
+		{name: "time.Weekday-string", src: time.Sunday, dst: new(string), exp: "Sunday"},
+		{name: "string-time.Weekday", src: "SATURDAY", dst: new(time.Weekday), exp: time.Saturday},
+		{name: "string-time.Weekday#abbr", src: "tue", dst: new(time.Weekday), exp: time.Tuesday},
+		{name: "string-time.Weekday#number", src: "0", dst: new(time.Weekday), exp: time.Sunday},
+		{name: "string-time.Weekday#out-of-range", src: "7", dst: new(time.Weekday), err: true},
+
+		// time.Weekday <-> int
+		{name: "time.Weekday-uint8", src: time.Friday, dst: new(uint8), exp: uint8(5)},
+		{name: "int-time.Weekday", src: 6, dst: new(time.Weekday), exp: time.Saturday},
+		{name: "int-time.Weekday#out-of-range", src: -1, dst: new(time.Weekday), err: true},
+
+		// net.HardwareAddr <-> string
+		{name: "net.HardwareAddr-string", src: net.HardwareAddr{0x00, 0x1a, 0x2b, 0x3c, 0x4d, 0x5e}, dst: new(string), exp: "00:1a:2b:3c:4d:5e"},
+		{name: "string-net.HardwareAddr", src: "00:1a:2b:3c:4d:5e", dst: new(net.HardwareAddr), exp: net.HardwareAddr{0x00, 0x1a, 0x2b, 0x3c, 0x4d, 0x5e}},
+		{name: "string-net.HardwareAddr#dash", src: "00-1A-2B-3C-4D-5E", dst: new(net.HardwareAddr), exp: net.HardwareAddr{0x00, 0x1a, 0x2b, 0x3c, 0x4d, 0x5e}},
+		{name: "string-net.HardwareAddr#invalid", src: "foo", dst: new(net.HardwareAddr), err: true},
+
+		// net.HardwareAddr <-> bytes
+		{name: "net.HardwareAddr-[]byte", src: net.HardwareAddr{1, 2, 3, 4, 5, 6}, dst: new([]byte), exp: []byte{1, 2, 3, 4, 5, 6}},
+		{name: "[]byte-net.HardwareAddr", src: []byte{1, 2, 3, 4, 5, 6}, dst: new(net.HardwareAddr), exp: net.HardwareAddr{1, 2, 3, 4, 5, 6}},
+		{name: "net.HardwareAddr-[6]byte", src: net.HardwareAddr{1, 2, 3, 4, 5, 6}, dst: new([6]byte), exp: [6]byte{1, 2, 3, 4, 5, 6}},
+		{name: "[6]byte-net.HardwareAddr", src: [6]byte{1, 2, 3, 4, 5, 6}, dst: new(net.HardwareAddr), exp: net.HardwareAddr{1, 2, 3, 4, 5, 6}},
+		{name: "net.HardwareAddr-[6]byte#length-mismatch", src: net.HardwareAddr{1, 2, 3}, dst: new([6]byte), err: true},
+
+		// mail.Address <-> string
+		{name: "mail.Address-string", src: mail.Address{Name: "Foo Bar", Address: "foo@example.com"}, dst: new(string), exp: `"Foo Bar" <foo@example.com>`},
+		{name: "mail.Address-string#no-name", src: &mail.Address{Address: "foo@example.com"}, dst: new(string), exp: "foo@example.com"},
+		{name: "string-mail.Address", src: "Foo <foo@example.com>", dst: new(mail.Address), exp: mail.Address{Name: "Foo", Address: "foo@example.com"}},
+		{name: "string-mail.Address#no-name", src: "foo@example.com", dst: new(mail.Address), exp: mail.Address{Address: "foo@example.com"}},
+		{name: "string-mail.Address#invalid", src: "foo", dst: new(mail.Address), err: true},
+
+		// mail.Address <-> map
+		{name: "mail.Address-map", src: mail.Address{Name: "Foo", Address: "foo@example.com"}, dst: new(map[string]string), exp: map[string]string{"Name": "Foo", "Address": "foo@example.com"}},
+		{name: "map-mail.Address", src: map[string]string{"Name": "Foo", "Address": "foo@example.com"}, dst: new(mail.Address), exp: mail.Address{Name: "Foo", Address: "foo@example.com"}},
+
+		// fs.FileMode <-> string
+		{name: "fs.FileMode-string", src: fs.FileMode(0o644), dst: new(string), exp: "0644"},
+		{name: "fs.FileMode-string#special", src: fs.FileMode(0o755) | fs.ModeSetuid | fs.ModeDir, dst: new(string), exp: "4755"},
+		{name: "string-fs.FileMode", src: "0644", dst: new(fs.FileMode), exp: fs.FileMode(0o644)},
+		{name: "string-fs.FileMode#prefix", src: "0o750", dst: new(fs.FileMode), exp: fs.FileMode(0o750)},
+		{name: "string-fs.FileMode#special", src: "1777", dst: new(fs.FileMode), exp: fs.FileMode(0o777) | fs.ModeSticky},
+		{name: "string-fs.FileMode#symbolic", src: "rwxr-xr-x", dst: new(fs.FileMode), exp: fs.FileMode(0o755)},
+		{name: "string-fs.FileMode#symbolic-dir", src: "drwxr-x---", dst: new(fs.FileMode), exp: fs.FileMode(0o750) | fs.ModeDir},
+		{name: "string-fs.FileMode#invalid-octal", src: "0899", dst: new(fs.FileMode), err: true},
+		{name: "string-fs.FileMode#invalid-symbolic", src: "rwxr-xr-q", dst: new(fs.FileMode), err: true},
+
+		// fs.FileMode <-> int
+		{name: "fs.FileMode-uint32", src: fs.FileMode(0o644), dst: new(uint32), exp: uint32(0o644)},
+		{name: "int-fs.FileMode", src: 0o600, dst: new(fs.FileMode), exp: fs.FileMode(0o600)},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -220,3 +295,130 @@ func TestTypes(t *testing.T) {
 		})
 	}
 }
+
+func TestCalendarOutOfRange(t *testing.T) {
+	m := time.March
+	assert.Error(t, Map(42, &m))
+	assert.Equal(t, time.March, m)
+	var dst struct{ Day time.Weekday }
+	dst.Day = time.Friday
+	assert.Error(t, Map(map[string]any{"Day": 200}, &dst))
+	assert.Equal(t, time.Friday, dst.Day)
+}
+
+func TestProviderTypesToAny(t *testing.T) {
+	tm := time.Unix(1666666666, 0).UTC()
+
+	var dst any
+	require.NoError(t, Map(tm, &dst))
+	assert.Equal(t, tm.Unix(), dst)
+
+	dst = nil
+	require.NoError(t, MapContext(Default.Context.WithNormalizeAny(true), tm, &dst))
+	assert.Equal(t, tm.Format(time.RFC3339), dst)
+
+	dst = nil
+	assert.Error(t, Map(big.NewRat(1, 2), &dst))
+}
+
+func TestRegexp(t *testing.T) {
+	type Rule struct {
+		Pattern *regexp.Regexp
+	}
+	t.Run("string-regexp", func(t *testing.T) {
+		var dst Rule
+		require.NoError(t, Map(map[string]any{"Pattern": "^a+$"}, &dst))
+		require.NotNil(t, dst.Pattern)
+		assert.True(t, dst.Pattern.MatchString("aaa"))
+		assert.False(t, dst.Pattern.MatchString("b"))
+	})
+	t.Run("regexp-string", func(t *testing.T) {
+		var dst map[string]string
+		require.NoError(t, Map(Rule{Pattern: regexp.MustCompile("^a+$")}, &dst))
+		assert.Equal(t, map[string]string{"Pattern": "^a+$"}, dst)
+	})
+	t.Run("regexp-regexp", func(t *testing.T) {
+		var dst Rule
+		require.NoError(t, Map(Rule{Pattern: regexp.MustCompile("^a+$")}, &dst))
+		assert.Equal(t, "^a+$", dst.Pattern.String())
+	})
+	t.Run("invalid", func(t *testing.T) {
+		var dst Rule
+		err := Map(map[string]any{"Pattern": "a("}, &dst)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing closing )")
+	})
+	t.Run("strict", func(t *testing.T) {
+		var dst Rule
+		ctx := Default.Context.WithStrictTypes(true)
+		assert.Error(t, MapContext(ctx, map[string]any{"Pattern": "a"}, &dst))
+	})
+	t.Run("regexp-any", func(t *testing.T) {
+		var dst map[string]any
+		require.NoError(t, Map(Rule{Pattern: regexp.MustCompile("^a+$")}, &dst))
+		re, ok := dst["Pattern"].(regexp.Regexp)
+		require.True(t, ok)
+		assert.Equal(t, "^a+$", re.String())
+	})
+}
+
+func TestLocation(t *testing.T) {
+	type Config struct {
+		Zone *time.Location
+	}
+	t.Run("string-location", func(t *testing.T) {
+		var dst Config
+		require.NoError(t, Map(map[string]any{"Zone": "Europe/Warsaw"}, &dst))
+		require.NotNil(t, dst.Zone)
+		assert.Equal(t, "Europe/Warsaw", dst.Zone.String())
+		tm := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC).In(dst.Zone)
+		assert.Equal(t, 13, tm.Hour())
+	})
+	t.Run("location-string", func(t *testing.T) {
+		var dst map[string]string
+		require.NoError(t, Map(Config{Zone: time.UTC}, &dst))
+		assert.Equal(t, map[string]string{"Zone": "UTC"}, dst)
+	})
+	t.Run("local", func(t *testing.T) {
+		var dst Config
+		require.NoError(t, Map(map[string]any{"Zone": "Local"}, &dst))
+		assert.Equal(t, "Local", dst.Zone.String())
+	})
+	t.Run("unknown", func(t *testing.T) {
+		var dst Config
+		err := Map(map[string]any{"Zone": "Mars/Olympus_Mons"}, &dst)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown time zone: Mars/Olympus_Mons")
+	})
+	t.Run("location-any", func(t *testing.T) {
+		var dst map[string]any
+		require.NoError(t, Map(Config{Zone: time.UTC}, &dst))
+		loc, ok := dst["Zone"].(time.Location)
+		require.True(t, ok)
+		assert.Equal(t, "UTC", loc.String())
+	})
+}
+
+func TestBigFloatPrec(t *testing.T) {
+	third := big.NewRat(1, 3)
+	var def, prec big.Float
+	require.NoError(t, Map(third, &def))
+	assert.Equal(t, uint(64), def.Prec())
+	require.NoError(t, MapContext(Default.Context.WithBigFloatPrec(256), third, &prec))
+	assert.Equal(t, uint(256), prec.Prec())
+	assert.Equal(t, new(big.Float).SetPrec(256).SetRat(third).Text('g', 70), prec.Text('g', 70))
+
+	// Conversion from big.Float to big.Rat is exact.
+	var rat big.Rat
+	require.NoError(t, Map(&prec, &rat))
+	back, _ := prec.Rat(nil)
+	assert.Equal(t, back.String(), rat.String())
+}
+
+func TestUnaddressableSource(t *testing.T) {
+	var s string
+	require.NoError(t, Map(*big.NewInt(42), &s))
+	assert.Equal(t, "42", s)
+	require.NoError(t, Map(any(*big.NewFloat(1.5)), &s))
+	assert.Equal(t, "1.5", s)
+}
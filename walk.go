@@ -0,0 +1,220 @@
+package anymapper
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// SkipValue can be returned by a WalkFunc to skip the nested values of the
+// current value, like struct fields or slice elements. It is not returned
+// as an error by Walk.
+var SkipValue = errors.New("mapper: skip value")
+
+// StructTagInfo describes a struct field as it is seen by the mapper. It is
+// the parsed struct tag, with the name resolved using the same rules as in
+// the mapping, that is, using renames, the tag and the FieldMapper
+// function.
+type StructTagInfo struct {
+	// Field is the struct field. It is the zero value for values that are
+	// not struct fields.
+	Field reflect.StructField
+
+	// Name is the key of the field.
+	Name string
+
+	// Aliases are the alternative source keys of the field.
+	Aliases []string
+
+	// Secret indicates that the field value is sensitive.
+	Secret bool
+
+	// OmitEmpty indicates that the field is omitted if empty.
+	OmitEmpty bool
+
+	// Width and Pad are the width and the padding character of strings
+	// mapped from integers. Width is zero if not set.
+	Width int
+	Pad   byte
+
+	// Encoding is the name of the string encoding used for bytes.
+	Encoding string
+
+	// Float16 indicates that the field holds a half-precision number.
+	Float16 bool
+
+	// Conv is the name of the converter applied to the field.
+	Conv string
+}
+
+// WalkFunc is called by Walk for every visited value. The path is the path
+// of the value, in the format used by MapPath, and tag describes the value
+// if it is a struct field.
+//
+// If the function returns SkipValue, the nested values of the value are
+// not visited. Any other error stops the traversal and is returned by Walk.
+type WalkFunc func(path string, v reflect.Value, tag StructTagInfo) error
+
+// Walk traverses the value and calls fn for the value and every nested
+// value.
+//
+// It is shorthand for Default.Walk(src, fn).
+func Walk(src any, fn WalkFunc) error {
+	return Default.Walk(src, fn)
+}
+
+// WalkContext traverses the value using the given context and calls fn for
+// the value and every nested value.
+//
+// It is shorthand for Default.WalkContext(ctx, src, fn).
+func WalkContext(ctx *Context, src any, fn WalkFunc) error {
+	return Default.WalkContext(ctx, src, fn)
+}
+
+// Walk traverses the value and calls fn for the value and every nested
+// value: struct fields, map values and slice and array elements. Struct
+// fields are resolved in the same way as in the mapping, so skipped,
+// unexported and ignored fields are not visited, and field keys are taken
+// from tags.
+//
+// Pointers and interfaces are dereferenced using the mapper, so fn receives
+// the values they point to, or the nil pointers and interfaces. Nested
+// values of values pointed to by the same pointer are visited only once,
+// so cyclic values can be traversed. Map values are
+// visited in the order of their keys. Nested values of values with custom
+// mapping functions, like time.Time or big.Int, are not visited.
+func (m *Mapper) Walk(src any, fn WalkFunc) error {
+	return m.WalkContext(m.Context, src, fn)
+}
+
+// WalkContext traverses the value using the given context and calls fn for
+// the value and every nested value. See Walk for details.
+func (m *Mapper) WalkContext(ctx *Context, src any, fn WalkFunc) error {
+	if ctx == nil {
+		ctx = m.Context
+	}
+	w := &walker{m: m, ctx: ctx, fn: fn, seen: map[walkPtr]bool{}}
+	err := w.walk("", reflect.ValueOf(src), StructTagInfo{})
+	if err == SkipValue {
+		return nil
+	}
+	return err
+}
+
+type walkPtr struct {
+	typ reflect.Type
+	ptr uintptr
+}
+
+type walker struct {
+	m    *Mapper
+	ctx  *Context
+	fn   WalkFunc
+	seen map[walkPtr]bool
+}
+
+func (w *walker) walk(path string, v reflect.Value, tag StructTagInfo) error {
+	repeated := false
+	if v.Kind() == reflect.Pointer && !v.IsNil() {
+		p := walkPtr{typ: v.Type(), ptr: v.Pointer()}
+		repeated = w.seen[p]
+		w.seen[p] = true
+	}
+	if val := w.m.srcValue(w.ctx, v); val.IsValid() {
+		v = val
+	}
+	if err := w.fn(path, v, tag); err != nil {
+		if err == SkipValue {
+			return nil
+		}
+		return err
+	}
+	if repeated || !v.IsValid() || w.custom(v.Type()) {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		typ := v.Type()
+		fields := w.m.structFields(w.ctx, typ)
+		for i := 0; i < numFields(typ, fields); i++ {
+			fld := fieldAt(typ, fields, i)
+			if !w.m.mappedField(fld) {
+				continue
+			}
+			st := w.m.parseTag(w.ctx, fld)
+			if st.Skip {
+				continue
+			}
+			val := fieldByIndex(v, fld.Index, false)
+			if !val.IsValid() {
+				continue
+			}
+			key := w.m.fieldKey(w.ctx, fld, st.Name, val)
+			if err := w.walk(joinPath(path, key), val, tagInfo(fld, key, st)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		names := make([]string, len(keys))
+		for i, k := range keys {
+			names[i] = w.keyString(k)
+		}
+		idx := make([]int, len(keys))
+		for i := range idx {
+			idx[i] = i
+		}
+		sort.Slice(idx, func(a, b int) bool { return names[idx[a]] < names[idx[b]] })
+		for _, i := range idx {
+			if err := w.walk(joinPath(path, names[i]), v.MapIndex(keys[i]), StructTagInfo{}); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := w.walk(joinPath(path, fmt.Sprint(i)), v.Index(i), StructTagInfo{}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// custom reports whether the type has a registered mapper provider, in
+// which case its internal structure is not visited.
+func (w *walker) custom(t reflect.Type) bool {
+	_, ok := w.m.Mappers[t]
+	return ok
+}
+
+// keyString returns the map key as a path segment.
+func (w *walker) keyString(k reflect.Value) string {
+	var s string
+	if err := w.m.MapReflContext(w.ctx, k, reflect.ValueOf(&s)); err != nil {
+		return fmt.Sprint(k.Interface())
+	}
+	return s
+}
+
+func joinPath(path, seg string) string {
+	if path == "" {
+		return seg
+	}
+	return path + "." + seg
+}
+
+func tagInfo(fld reflect.StructField, key string, tag structTag) StructTagInfo {
+	return StructTagInfo{
+		Field:     fld,
+		Name:      key,
+		Aliases:   tag.Aliases,
+		Secret:    tag.Secret,
+		OmitEmpty: tag.OmitEmpty,
+		Width:     tag.Width,
+		Pad:       tag.Pad,
+		Encoding:  tag.Encoding,
+		Float16:   tag.Float16,
+		Conv:      tag.Conv,
+	}
+}
@@ -0,0 +1,114 @@
+package anymapper
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalk(t *testing.T) {
+	type address struct {
+		City string `map:"city"`
+	}
+	type user struct {
+		Name     string   `map:"name"`
+		Password string   `map:"password,secret"`
+		Balance  *big.Int `map:"balance"`
+		Address  *address `map:"address"`
+		Tags     []string `map:"tags"`
+		Meta     map[string]any
+		Skipped  string `map:"-"`
+		private  string
+	}
+	src := user{
+		Name:     "foo",
+		Password: "bar",
+		Balance:  big.NewInt(1),
+		Address:  &address{City: "Warsaw"},
+		Tags:     []string{"a", "b"},
+		Meta:     map[string]any{"b": 2, "a": 1},
+		private:  "x",
+	}
+	t.Run("paths", func(t *testing.T) {
+		var paths []string
+		require.NoError(t, Walk(&src, func(path string, v reflect.Value, tag StructTagInfo) error {
+			paths = append(paths, path)
+			return nil
+		}))
+		assert.Equal(t, []string{
+			"", "name", "password", "balance", "address", "address.city",
+			"tags", "tags.0", "tags.1", "Meta", "Meta.a", "Meta.b",
+		}, paths)
+	})
+	t.Run("tags", func(t *testing.T) {
+		var secrets []string
+		require.NoError(t, Walk(src, func(path string, v reflect.Value, tag StructTagInfo) error {
+			if tag.Secret {
+				secrets = append(secrets, tag.Field.Name)
+			}
+			return nil
+		}))
+		assert.Equal(t, []string{"Password"}, secrets)
+	})
+	t.Run("sanitize", func(t *testing.T) {
+		cpy := src
+		require.NoError(t, Walk(&cpy, func(path string, v reflect.Value, tag StructTagInfo) error {
+			if tag.Secret && v.CanSet() {
+				v.SetString("***")
+			}
+			return nil
+		}))
+		assert.Equal(t, "***", cpy.Password)
+	})
+	t.Run("map-path", func(t *testing.T) {
+		// Paths can be used with MapPath.
+		require.NoError(t, Walk(src, func(path string, v reflect.Value, tag StructTagInfo) error {
+			if v.Kind() != reflect.String {
+				return nil
+			}
+			var s string
+			require.NoError(t, MapPath(src, path, &s, ""))
+			assert.Equal(t, v.String(), s)
+			return nil
+		}))
+	})
+	t.Run("skip", func(t *testing.T) {
+		var paths []string
+		require.NoError(t, Walk(src, func(path string, v reflect.Value, tag StructTagInfo) error {
+			paths = append(paths, path)
+			if path != "" {
+				return SkipValue
+			}
+			return nil
+		}))
+		assert.Len(t, paths, 7)
+	})
+	t.Run("error", func(t *testing.T) {
+		stop := errors.New("stop")
+		err := Walk(src, func(path string, v reflect.Value, tag StructTagInfo) error {
+			if path == "address.city" {
+				return stop
+			}
+			return nil
+		})
+		assert.Equal(t, stop, err)
+	})
+	t.Run("cycle", func(t *testing.T) {
+		type node struct {
+			Next *node
+		}
+		n := &node{}
+		n.Next = n
+		var count int
+		require.NoError(t, Walk(n, func(path string, v reflect.Value, tag StructTagInfo) error {
+			count++
+			return nil
+		}))
+		// The repeated pointer is visited, but not traversed again.
+		assert.Equal(t, 2, count)
+	})
+}